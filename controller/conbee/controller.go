@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/GreenLightning/zigbee-conductor/pkg/slip"
+	"github.com/GreenLightning/zigbee-conductor/zdp"
 	"github.com/GreenLightning/zigbee-conductor/zigbee"
 	"github.com/jacobsa/go-serial/serial"
 )
@@ -24,6 +25,9 @@ type Controller struct {
 	sequence        uint32
 	requestSequence uint32
 	port            io.ReadWriteCloser
+
+	// addresses maps between the network and IEEE addresses of devices.
+	addresses zigbee.AddressMap
 }
 
 func NewController(settings zigbee.ControllerSettings) (*Controller, error) {
@@ -105,12 +109,14 @@ func (c *Controller) Start() (chan zigbee.IncomingMessage, error) {
 				c.SendCommand(&ReadReceivedDataRequest{})
 
 			case *ReadReceivedDataResponse:
+				source := c.updateAddresses(cmd)
 				messages <- zigbee.IncomingMessage{
-					Source:              cmd.Source,
+					Source:              source,
 					SourceEndpoint:      cmd.SourceEndpoint,
 					DestinationEndpoint: cmd.DestinationEndpoint,
 					ClusterID:           cmd.ClusterID,
 					LinkQuality:         cmd.LQI,
+					Broadcast:           isBroadcast(cmd.Destination),
 					Data:                cmd.Payload,
 				}
 			}
@@ -119,13 +125,78 @@ func (c *Controller) Start() (chan zigbee.IncomingMessage, error) {
 	return messages, nil
 }
 
+// updateAddresses updates the address map from an incoming message and
+// returns its source address, completed with the IEEE address if known. If
+// the IEEE address is unknown, it is requested from the device.
+// isBroadcast reports whether the destination is a group or one of the
+// broadcast network addresses (0xfff8 to 0xffff).
+func isBroadcast(destination zigbee.Address) bool {
+	switch destination.Mode {
+	case zigbee.AddressModeGroup:
+		return true
+	case zigbee.AddressModeNWK, zigbee.AddressModeCombined:
+		return destination.Short >= 0xfff8
+	default:
+		return false
+	}
+}
+
+func (c *Controller) updateAddresses(cmd *ReadReceivedDataResponse) zigbee.Address {
+	if cmd.Source.Mode == zigbee.AddressModeCombined {
+		c.addresses.Update(cmd.Source.Short, cmd.Source.Extended)
+	}
+
+	if cmd.SourceEndpoint == 0 && cmd.ProfileID == zigbee.ProfileDevice {
+		if _, command, err := zdp.ParseFrame(cmd.ClusterID, cmd.Payload); err == nil {
+			switch command := command.(type) {
+			case *zdp.DeviceAnnce:
+				c.addresses.Update(command.NWKAddr, command.IEEEAddr)
+			case *zdp.IEEEAddrRsp:
+				if command.Status == zdp.StatusSuccess {
+					c.addresses.Update(command.NWKAddrRemoteDev, command.IEEEAddrRemoteDev)
+				}
+			case *zdp.NWKAddrRsp:
+				if command.Status == zdp.StatusSuccess {
+					c.addresses.Update(command.NWKAddrRemoteDev, command.IEEEAddrRemoteDev)
+				}
+			}
+		}
+	}
+
+	source := c.addresses.Complete(cmd.Source)
+	if source.Mode == zigbee.AddressModeNWK && c.addresses.StartLookup(source.Short) {
+		_, data, err := zdp.SerializeFrame(0, &zdp.IEEEAddrReq{NWKAddrOfInterest: source.Short})
+		if err == nil {
+			// Sending only writes to the serial port, so it does not block
+			// the receive loop.
+			err = c.Send(zigbee.OutgoingMessage{
+				Destination: zigbee.Address{Mode: zigbee.AddressModeNWK, Short: source.Short},
+				ClusterID:   zdp.ClusterIEEEAddrReq,
+				Radius:      zigbee.DefaultRadius,
+				Data:        data,
+			})
+		}
+		if err != nil && c.settings.LogErrors {
+			log.Println("[zigbee] failed to request IEEE address:", err)
+		}
+	}
+	return source
+}
+
+// Send sends a message. The ConBee resolves destinations given only by IEEE
+// address itself.
 func (c *Controller) Send(msg zigbee.OutgoingMessage) error {
 	id := atomic.AddUint32(&c.requestSequence, 1)
+	profileID := zigbee.ProfileHomeAutomation // @Todo: Hardcoded.
+	if msg.DestinationEndpoint == 0 {
+		// Endpoint 0 is the ZigBee Device Object.
+		profileID = zigbee.ProfileDevice
+	}
 	return c.SendCommand(&EnqueueSendDataRequest{
 		RequestID:           uint8(id),
 		Destination:         msg.Destination,
 		DestinationEndpoint: msg.DestinationEndpoint,
-		ProfileID:           0x0104, // @Todo: Hardcoded.
+		ProfileID:           profileID,
 		ClusterID:           msg.ClusterID,
 		SourceEndpoint:      msg.SourceEndpoint,
 		Payload:             msg.Data,
@@ -8,6 +8,16 @@ import (
 	"github.com/GreenLightning/zigbee-conductor/pkg/scf"
 )
 
+//go:generate go run ../../pkg/scf/scfgen -func registerCommand -header FrameHeader -output commands_scf.go
+
+// generatedCommand is implemented by all registered commands in
+// commands_scf.go, which is generated from the calls of registerCommand.
+// Remember to run go generate after changing commands.
+type generatedCommand interface {
+	frameHeader() FrameHeader
+}
+
+// The maps are only used for commands without generated code.
 var (
 	frameHeaderByCommandType = make(map[reflect.Type]FrameHeader)
 	commandTypeByFrameHeader = make(map[FrameHeader]reflect.Type)
@@ -35,36 +45,61 @@ func registerCommand(frameType FrameType, subsystem FrameSubsystem, id byte, com
 	commandTypeByFrameHeader[header] = commandType
 }
 
-func getHeaderForCommand(command interface{}) FrameHeader {
+// getHeaderForCommand returns the frame header of a registered command. All
+// registered commands have generated code (see TestGeneratedCommands), the
+// maps are only consulted if commands_scf.go is out of date.
+func getHeaderForCommand(command interface{}) (FrameHeader, error) {
+	if command, ok := command.(generatedCommand); ok {
+		return command.frameHeader(), nil
+	}
 	commandType := reflect.TypeOf(command)
-	return getHeaderForCommandType(commandType)
-}
-
-func getHeaderForCommandType(commandType reflect.Type) FrameHeader {
 	header, ok := frameHeaderByCommandType[commandType]
 	if !ok {
-		panic(fmt.Sprintf("command %s has never been registered", commandType.Name()))
+		return FrameHeader{}, fmt.Errorf("%w: %v", ErrCommandNotRegistered, commandType)
 	}
+	return header, nil
+}
 
+// mustGetHeaderForCommand is like getHeaderForCommand, but panics if the
+// command has not been registered. It is used for command prototypes, which
+// are fixed when the program is written.
+func mustGetHeaderForCommand(command interface{}) FrameHeader {
+	header, err := getHeaderForCommand(command)
+	if err != nil {
+		panic(err)
+	}
 	return header
 }
 
-func buildFrameForCommand(command interface{}) Frame {
-	commandType := reflect.TypeOf(command)
-	frame := Frame{FrameHeader: getHeaderForCommandType(commandType)}
+func buildFrameForCommand(command interface{}) (Frame, error) {
+	header, err := getHeaderForCommand(command)
+	if err != nil {
+		return Frame{}, err
+	}
+	frame := Frame{FrameHeader: header}
 	frame.Data = scf.Serialize(command)
 	if len(frame.Data) > FRAME_MAX_DATA_LENGTH {
-		panic(fmt.Sprintf("command too large: %#v", command))
+		return Frame{}, fmt.Errorf("%w: %d bytes", ErrCommandTooLarge, len(frame.Data))
 	}
-	return frame
+	return frame, nil
 }
 
 var (
 	ErrCommandUnknownFrameHeader = errors.New("unknown serial frame header")
 	ErrCommandInvalidFrame       = errors.New("invalid serial frame")
+	ErrCommandNotRegistered      = errors.New("command not registered")
+	ErrCommandTooLarge           = errors.New("command too large")
 )
 
 func parseCommandFromFrame(frame Frame) (interface{}, error) {
+	if parse, ok := parsersByFrameHeader[frame.FrameHeader]; ok {
+		command, err := parse(frame.Data)
+		if err != nil {
+			return nil, ErrCommandInvalidFrame
+		}
+		return command, nil
+	}
+
 	commandType, ok := commandTypeByFrameHeader[frame.FrameHeader]
 	if !ok {
 		return nil, ErrCommandUnknownFrameHeader
@@ -0,0 +1,149 @@
+package znp
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/GreenLightning/zigbee-conductor/pkg/scf"
+)
+
+// withoutMethods converts a command to an equivalent struct type without
+// methods, so that package scf has to use reflection.
+func withoutMethods(commandValue reflect.Value) reflect.Value {
+	commandType := commandValue.Type()
+	fields := make([]reflect.StructField, commandType.NumField())
+	for i := range fields {
+		fields[i] = commandType.Field(i)
+	}
+	return commandValue.Convert(reflect.StructOf(fields))
+}
+
+func randomize(commandValue reflect.Value, r *rand.Rand) {
+	for i := 0; i < commandValue.NumField(); i++ {
+		field := commandValue.Field(i)
+		if field.Kind() == reflect.Slice {
+			length := r.Intn(300)
+			slice := reflect.MakeSlice(field.Type(), length, length)
+			for j := 0; j < length; j++ {
+				slice.Index(j).SetUint(r.Uint64())
+			}
+			field.Set(slice)
+		} else {
+			field.SetUint(r.Uint64())
+		}
+	}
+}
+
+func TestBuildFrameUnregistered(t *testing.T) {
+	type unregisteredCommand struct{ Value uint8 }
+	_, err := buildFrameForCommand(unregisteredCommand{})
+	if !errors.Is(err, ErrCommandNotRegistered) {
+		t.Fatal("unexpected err:", err)
+	}
+}
+
+func TestGeneratedCommands(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for commandType, header := range frameHeaderByCommandType {
+		command, ok := reflect.Zero(commandType).Interface().(generatedCommand)
+		if !ok {
+			t.Errorf("%s: no generated code, run go generate", commandType.Name())
+			continue
+		}
+		if command.frameHeader() != header {
+			t.Errorf("%s: wrong generated header %v, expected %v", commandType.Name(), command.frameHeader(), header)
+		}
+		if _, ok := parsersByFrameHeader[header]; !ok {
+			t.Errorf("%s: no generated parser", commandType.Name())
+		}
+
+		for i := 0; i < 20; i++ {
+			commandValue := reflect.New(commandType).Elem()
+			randomize(commandValue, r)
+
+			generated := commandValue.Interface().(scf.Marshaler).MarshalSCF()
+			reflected := scf.SerializeValue(withoutMethods(commandValue))
+			if !bytes.Equal(generated, reflected) {
+				t.Errorf("%s: generated serialization %x, expected %x", commandType.Name(), generated, reflected)
+				break
+			}
+
+			parsed, err := parseCommandFromFrame(Frame{FrameHeader: header, Data: generated})
+			if err != nil {
+				t.Errorf("%s: unexpected err: %v", commandType.Name(), err)
+				break
+			}
+			// Slices with more than 255 elements are truncated, therefore
+			// compare the serialization of the parsed command.
+			if reserialized := scf.Serialize(parsed); !bytes.Equal(reserialized, generated) {
+				t.Errorf("%s: wrong parse %+v", commandType.Name(), parsed)
+				break
+			}
+
+			// A trailing rest field may be cut off anywhere, so only
+			// truncations of the fields before it are invalid.
+			required := len(generated)
+			if last := commandType.NumField() - 1; last >= 0 && commandType.Field(last).Tag.Get("scf") == "rest" {
+				required -= commandValue.Field(last).Len()
+			}
+
+			for length := 0; length < required; length++ {
+				_, err := parseCommandFromFrame(Frame{FrameHeader: header, Data: generated[:length]})
+				if err != ErrCommandInvalidFrame {
+					t.Errorf("%s: expected error for %d of %d bytes", commandType.Name(), length, len(generated))
+					break
+				}
+			}
+		}
+	}
+}
+
+var benchmarkMessage = AfIncomingMsg{
+	GroupID:        0x0000,
+	ClusterID:      0x0402,
+	SrcAddr:        0x1234,
+	SrcEndpoint:    1,
+	DstEndpoint:    1,
+	LinkQuality:    120,
+	TimeStamp:      0x12345678,
+	TransSeqNumber: 0x42,
+	Data:           []byte{0x18, 0x42, 0x0a, 0x00, 0x00, 0x29, 0xfc, 0x07},
+}
+
+// reflectedAfIncomingMsg has no methods, so package scf uses reflection.
+type reflectedAfIncomingMsg AfIncomingMsg
+
+func BenchmarkSerializeGenerated(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		scf.Serialize(benchmarkMessage)
+	}
+}
+
+func BenchmarkSerializeReflection(b *testing.B) {
+	command := reflectedAfIncomingMsg(benchmarkMessage)
+	for i := 0; i < b.N; i++ {
+		scf.Serialize(command)
+	}
+}
+
+func BenchmarkParseGenerated(b *testing.B) {
+	data := scf.Serialize(benchmarkMessage)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var command AfIncomingMsg
+		scf.Parse(&command, data)
+	}
+}
+
+func BenchmarkParseReflection(b *testing.B) {
+	data := scf.Serialize(benchmarkMessage)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var command reflectedAfIncomingMsg
+		scf.Parse(&command, data)
+	}
+}
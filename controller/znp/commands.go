@@ -188,6 +188,109 @@ type AfIncomingMsg struct {
 
 /* FRAME_SUBSYSTEM_ZDO*/
 
+// Request types of ZdoNwkAddrRequest and ZdoIEEEAddrRequest.
+const (
+	AddrReqTypeSingle   = 0x00 // only the address of the device
+	AddrReqTypeExtended = 0x01 // additionally the addresses of associated devices
+)
+
+func init() {
+	registerCommand(FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x00, ZdoNwkAddrRequest{})
+	registerCommand(FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x00, ZdoNwkAddrResponse{})
+	registerCommand(FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0x80, ZdoNwkAddr{})
+}
+
+// Requests the network address of the device with the given IEEE address.
+// The request is broadcast by the ZNP.
+type ZdoNwkAddrRequest struct {
+	IEEEAddress uint64
+	ReqType     uint8
+	StartIndex  uint8
+}
+
+type ZdoNwkAddrResponse struct {
+	Status byte
+}
+
+type ZdoNwkAddr struct {
+	Status       byte
+	IEEEAddr     uint64
+	NwkAddr      uint16
+	StartIndex   uint8
+	AssocDevList []uint16
+}
+
+func init() {
+	registerCommand(FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x01, ZdoIEEEAddrRequest{})
+	registerCommand(FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x01, ZdoIEEEAddrResponse{})
+	registerCommand(FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0x81, ZdoIEEEAddr{})
+}
+
+// Requests the IEEE address of the device with the given network address.
+type ZdoIEEEAddrRequest struct {
+	ShortAddr  uint16
+	ReqType    uint8
+	StartIndex uint8
+}
+
+type ZdoIEEEAddrResponse struct {
+	Status byte
+}
+
+type ZdoIEEEAddr struct {
+	Status       byte
+	IEEEAddr     uint64
+	NwkAddr      uint16
+	StartIndex   uint8
+	AssocDevList []uint16
+}
+
+// The ZDP requests below are answered with a callback, which starts with the
+// network address of the source. The controller receives the responses
+// through ZdoMsgCbIncoming instead, so the callbacks only contain the raw
+// payload.
+
+func init() {
+	registerCommand(FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x02, ZdoNodeDescRequest{})
+	registerCommand(FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x02, ZdoNodeDescResponse{})
+	registerCommand(FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0x82, ZdoNodeDesc{})
+}
+
+type ZdoNodeDescRequest struct {
+	DstAddr           uint16
+	NWKAddrOfInterest uint16
+}
+
+type ZdoNodeDescResponse struct {
+	Status byte
+}
+
+type ZdoNodeDesc struct {
+	SrcAddr uint16
+	Payload []byte `scf:"rest"`
+}
+
+func init() {
+	registerCommand(FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x04, ZdoSimpleDescRequest{})
+	registerCommand(FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x04, ZdoSimpleDescResponse{})
+	registerCommand(FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0x84, ZdoSimpleDesc{})
+}
+
+type ZdoSimpleDescRequest struct {
+	DstAddr           uint16
+	NWKAddrOfInterest uint16
+	Endpoint          uint8
+}
+
+type ZdoSimpleDescResponse struct {
+	Status byte
+}
+
+type ZdoSimpleDesc struct {
+	SrcAddr uint16
+	Payload []byte `scf:"rest"`
+}
+
 func init() {
 	registerCommand(FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x05, ZdoActiveEPRequest{})
 	registerCommand(FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x05, ZdoActiveEPResponse{})
@@ -210,6 +313,119 @@ type ZdoActiveEP struct {
 	ActiveEPs []uint8
 }
 
+func init() {
+	registerCommand(FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x21, ZdoBindRequest{})
+	registerCommand(FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x21, ZdoBindResponse{})
+	registerCommand(FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xa1, ZdoBind{})
+}
+
+// The destination address is always 8 bytes long. For group addresses, only
+// the lower two bytes are used.
+type ZdoBindRequest struct {
+	DstAddr     uint16
+	SrcAddress  uint64
+	SrcEndpoint uint8
+	ClusterID   uint16
+	DstAddrMode uint8
+	DstAddress  uint64
+	DstEndpoint uint8
+}
+
+type ZdoBindResponse struct {
+	Status byte
+}
+
+type ZdoBind struct {
+	SrcAddr uint16
+	Payload []byte `scf:"rest"`
+}
+
+func init() {
+	registerCommand(FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x22, ZdoUnbindRequest{})
+	registerCommand(FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x22, ZdoUnbindResponse{})
+	registerCommand(FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xa2, ZdoUnbind{})
+}
+
+// See ZdoBindRequest.
+type ZdoUnbindRequest struct {
+	DstAddr     uint16
+	SrcAddress  uint64
+	SrcEndpoint uint8
+	ClusterID   uint16
+	DstAddrMode uint8
+	DstAddress  uint64
+	DstEndpoint uint8
+}
+
+type ZdoUnbindResponse struct {
+	Status byte
+}
+
+type ZdoUnbind struct {
+	SrcAddr uint16
+	Payload []byte `scf:"rest"`
+}
+
+func init() {
+	registerCommand(FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x31, ZdoMgmtLqiRequest{})
+	registerCommand(FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x31, ZdoMgmtLqiResponse{})
+	registerCommand(FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xb1, ZdoMgmtLqi{})
+}
+
+type ZdoMgmtLqiRequest struct {
+	DstAddr    uint16
+	StartIndex uint8
+}
+
+type ZdoMgmtLqiResponse struct {
+	Status byte
+}
+
+type ZdoMgmtLqi struct {
+	SrcAddr uint16
+	Payload []byte `scf:"rest"`
+}
+
+func init() {
+	registerCommand(FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x32, ZdoMgmtRtgRequest{})
+	registerCommand(FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x32, ZdoMgmtRtgResponse{})
+	registerCommand(FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xb2, ZdoMgmtRtg{})
+}
+
+type ZdoMgmtRtgRequest struct {
+	DstAddr    uint16
+	StartIndex uint8
+}
+
+type ZdoMgmtRtgResponse struct {
+	Status byte
+}
+
+type ZdoMgmtRtg struct {
+	SrcAddr uint16
+	Payload []byte `scf:"rest"`
+}
+
+func init() {
+	registerCommand(FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x33, ZdoMgmtBindRequest{})
+	registerCommand(FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x33, ZdoMgmtBindResponse{})
+	registerCommand(FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xb3, ZdoMgmtBind{})
+}
+
+type ZdoMgmtBindRequest struct {
+	DstAddr    uint16
+	StartIndex uint8
+}
+
+type ZdoMgmtBindResponse struct {
+	Status byte
+}
+
+type ZdoMgmtBind struct {
+	SrcAddr uint16
+	Payload []byte `scf:"rest"`
+}
+
 func init() {
 	registerCommand(FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x36, ZdoMgmtPermitJoinRequest{})
 	registerCommand(FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x36, ZdoMgmtPermitJoinResponse{})
@@ -232,6 +448,20 @@ type ZdoMgmtPermitJoin struct {
 	Status  byte
 }
 
+func init() {
+	registerCommand(FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x3e, ZdoMsgCbRegisterRequest{})
+	registerCommand(FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x3e, ZdoMsgCbRegisterResponse{})
+}
+
+// Registers for ZdoMsgCbIncoming callbacks for the given ZDP cluster.
+type ZdoMsgCbRegisterRequest struct {
+	ClusterID uint16
+}
+
+type ZdoMsgCbRegisterResponse struct {
+	Status byte
+}
+
 func init() {
 	registerCommand(FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x40, ZdoStartupFromAppRequest{})
 	registerCommand(FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x40, ZdoStartupFromAppResponse{})
@@ -317,6 +547,22 @@ type ZdoPermitJoinInd struct {
 	Duration byte
 }
 
+func init() {
+	registerCommand(FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xff, ZdoMsgCbIncoming{})
+}
+
+// Contains an incoming ZDP frame for a cluster registered with
+// ZdoMsgCbRegisterRequest. Data is the ZDP payload without the sequence number.
+type ZdoMsgCbIncoming struct {
+	SrcAddr      uint16
+	WasBroadcast uint8
+	ClusterID    uint16
+	SecurityUse  uint8
+	SeqNum       uint8
+	MacDstAddr   uint16
+	Data         []byte `scf:"rest"`
+}
+
 /* FRAME_SUBSYSTEM_SAPI */
 
 func init() {
@@ -0,0 +1,1968 @@
+// Code generated by scfgen; DO NOT EDIT.
+
+package znp
+
+import (
+	"encoding/binary"
+
+	"github.com/GreenLightning/zigbee-conductor/pkg/scf"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+func (SysVersionRequest) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_SYS, 0x02}
+}
+
+func (command SysVersionRequest) MarshalSCF() []byte {
+	return nil
+}
+
+func (command *SysVersionRequest) UnmarshalSCF(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+func (SysVersionResponse) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_SYS, 0x02}
+}
+
+func (command SysVersionResponse) MarshalSCF() []byte {
+	data := make([]byte, 0, 9)
+	data = append(data, byte(command.TransportRev))
+	data = append(data, byte(command.Product))
+	data = append(data, byte(command.MajorRel))
+	data = append(data, byte(command.MinorRel))
+	data = append(data, byte(command.MaintRel))
+	data = append(data, byte(command.Revision), byte(command.Revision>>8), byte(command.Revision>>16), byte(command.Revision>>24))
+	return data
+}
+
+func (command *SysVersionResponse) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 9 {
+		return nil, scf.ErrInvalidData
+	}
+	command.TransportRev = data[0]
+	command.Product = data[1]
+	command.MajorRel = data[2]
+	command.MinorRel = data[3]
+	command.MaintRel = data[4]
+	command.Revision = binary.LittleEndian.Uint32(data[5:])
+	data = data[9:]
+	return data, nil
+}
+
+func (SysOsalNvReadRequest) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_SYS, 0x08}
+}
+
+func (command SysOsalNvReadRequest) MarshalSCF() []byte {
+	data := make([]byte, 0, 3)
+	data = append(data, byte(command.ID), byte(command.ID>>8))
+	data = append(data, byte(command.Offset))
+	return data
+}
+
+func (command *SysOsalNvReadRequest) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 3 {
+		return nil, scf.ErrInvalidData
+	}
+	command.ID = binary.LittleEndian.Uint16(data)
+	command.Offset = data[2]
+	data = data[3:]
+	return data, nil
+}
+
+func (SysOsalNvReadResponse) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_SYS, 0x08}
+}
+
+func (command SysOsalNvReadResponse) MarshalSCF() []byte {
+	if len(command.Value) > 255 {
+		command.Value = command.Value[:255]
+	}
+	data := make([]byte, 0, 2+len(command.Value))
+	data = append(data, byte(command.Status))
+	data = append(data, byte(len(command.Value)))
+	data = append(data, command.Value...)
+	return data
+}
+
+func (command *SysOsalNvReadResponse) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Status = data[0]
+	data = data[1:]
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	length := int(data[0])
+	data = data[1:]
+	if len(data) < length {
+		return nil, scf.ErrInvalidData
+	}
+	command.Value = make([]byte, length)
+	copy(command.Value, data)
+	data = data[length:]
+	return data, nil
+}
+
+func (SysOsalNvWriteRequest) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_SYS, 0x09}
+}
+
+func (command SysOsalNvWriteRequest) MarshalSCF() []byte {
+	if len(command.Value) > 255 {
+		command.Value = command.Value[:255]
+	}
+	data := make([]byte, 0, 4+len(command.Value))
+	data = append(data, byte(command.ID), byte(command.ID>>8))
+	data = append(data, byte(command.Offset))
+	data = append(data, byte(len(command.Value)))
+	data = append(data, command.Value...)
+	return data
+}
+
+func (command *SysOsalNvWriteRequest) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 3 {
+		return nil, scf.ErrInvalidData
+	}
+	command.ID = binary.LittleEndian.Uint16(data)
+	command.Offset = data[2]
+	data = data[3:]
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	length := int(data[0])
+	data = data[1:]
+	if len(data) < length {
+		return nil, scf.ErrInvalidData
+	}
+	command.Value = make([]byte, length)
+	copy(command.Value, data)
+	data = data[length:]
+	return data, nil
+}
+
+func (SysOsalNvWriteResponse) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_SYS, 0x09}
+}
+
+func (command SysOsalNvWriteResponse) MarshalSCF() []byte {
+	data := make([]byte, 0, 1)
+	data = append(data, byte(command.Status))
+	return data
+}
+
+func (command *SysOsalNvWriteResponse) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Status = data[0]
+	data = data[1:]
+	return data, nil
+}
+
+func (SysResetInd) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_SYS, 0x80}
+}
+
+func (command SysResetInd) MarshalSCF() []byte {
+	data := make([]byte, 0, 6)
+	data = append(data, byte(command.Reason))
+	data = append(data, byte(command.TransportRev))
+	data = append(data, byte(command.Product))
+	data = append(data, byte(command.MajorRel))
+	data = append(data, byte(command.MinorRel))
+	data = append(data, byte(command.MaintRel))
+	return data
+}
+
+func (command *SysResetInd) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 6 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Reason = data[0]
+	command.TransportRev = data[1]
+	command.Product = data[2]
+	command.MajorRel = data[3]
+	command.MinorRel = data[4]
+	command.MaintRel = data[5]
+	data = data[6:]
+	return data, nil
+}
+
+func (AfRegisterRequest) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_AF, 0x00}
+}
+
+func (command AfRegisterRequest) MarshalSCF() []byte {
+	if len(command.AppInClusters) > 255 {
+		command.AppInClusters = command.AppInClusters[:255]
+	}
+	if len(command.AppOutClusters) > 255 {
+		command.AppOutClusters = command.AppOutClusters[:255]
+	}
+	data := make([]byte, 0, 9+2*len(command.AppInClusters)+2*len(command.AppOutClusters))
+	data = append(data, byte(command.Endpoint))
+	data = append(data, byte(command.AppProfID), byte(command.AppProfID>>8))
+	data = append(data, byte(command.AppDeviceID), byte(command.AppDeviceID>>8))
+	data = append(data, byte(command.AddDevVer))
+	data = append(data, byte(command.LatencyReq))
+	data = append(data, byte(len(command.AppInClusters)))
+	for _, value := range command.AppInClusters {
+		data = append(data, byte(value), byte(value>>8))
+	}
+	data = append(data, byte(len(command.AppOutClusters)))
+	for _, value := range command.AppOutClusters {
+		data = append(data, byte(value), byte(value>>8))
+	}
+	return data
+}
+
+func (command *AfRegisterRequest) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 7 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Endpoint = data[0]
+	command.AppProfID = zigbee.ProfileID(binary.LittleEndian.Uint16(data[1:]))
+	command.AppDeviceID = binary.LittleEndian.Uint16(data[3:])
+	command.AddDevVer = data[5]
+	command.LatencyReq = data[6]
+	data = data[7:]
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	length := int(data[0])
+	data = data[1:]
+	if len(data) < 2*length {
+		return nil, scf.ErrInvalidData
+	}
+	command.AppInClusters = make([]uint16, length)
+	for i := range command.AppInClusters {
+		command.AppInClusters[i] = binary.LittleEndian.Uint16(data[2*i:])
+	}
+	data = data[2*length:]
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	length = int(data[0])
+	data = data[1:]
+	if len(data) < 2*length {
+		return nil, scf.ErrInvalidData
+	}
+	command.AppOutClusters = make([]uint16, length)
+	for i := range command.AppOutClusters {
+		command.AppOutClusters[i] = binary.LittleEndian.Uint16(data[2*i:])
+	}
+	data = data[2*length:]
+	return data, nil
+}
+
+func (AfRegisterResponse) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_AF, 0x00}
+}
+
+func (command AfRegisterResponse) MarshalSCF() []byte {
+	data := make([]byte, 0, 1)
+	data = append(data, byte(command.Status))
+	return data
+}
+
+func (command *AfRegisterResponse) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Status = data[0]
+	data = data[1:]
+	return data, nil
+}
+
+func (AfDataRequest) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_AF, 0x01}
+}
+
+func (command AfDataRequest) MarshalSCF() []byte {
+	if len(command.Data) > 255 {
+		command.Data = command.Data[:255]
+	}
+	data := make([]byte, 0, 10+len(command.Data))
+	data = append(data, byte(command.DstAddr), byte(command.DstAddr>>8))
+	data = append(data, byte(command.DstEndpoint))
+	data = append(data, byte(command.SrcEndpoint))
+	data = append(data, byte(command.ClusterID), byte(command.ClusterID>>8))
+	data = append(data, byte(command.TransSeqNumber))
+	data = append(data, byte(command.Options))
+	data = append(data, byte(command.Radius))
+	data = append(data, byte(len(command.Data)))
+	data = append(data, command.Data...)
+	return data
+}
+
+func (command *AfDataRequest) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 9 {
+		return nil, scf.ErrInvalidData
+	}
+	command.DstAddr = binary.LittleEndian.Uint16(data)
+	command.DstEndpoint = data[2]
+	command.SrcEndpoint = data[3]
+	command.ClusterID = binary.LittleEndian.Uint16(data[4:])
+	command.TransSeqNumber = data[6]
+	command.Options = data[7]
+	command.Radius = data[8]
+	data = data[9:]
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	length := int(data[0])
+	data = data[1:]
+	if len(data) < length {
+		return nil, scf.ErrInvalidData
+	}
+	command.Data = make([]byte, length)
+	copy(command.Data, data)
+	data = data[length:]
+	return data, nil
+}
+
+func (AfDataResponse) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_AF, 0x01}
+}
+
+func (command AfDataResponse) MarshalSCF() []byte {
+	data := make([]byte, 0, 1)
+	data = append(data, byte(command.Status))
+	return data
+}
+
+func (command *AfDataResponse) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Status = data[0]
+	data = data[1:]
+	return data, nil
+}
+
+func (AfDataConfirm) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_AF, 0x80}
+}
+
+func (command AfDataConfirm) MarshalSCF() []byte {
+	data := make([]byte, 0, 3)
+	data = append(data, byte(command.Status))
+	data = append(data, byte(command.Endpoint))
+	data = append(data, byte(command.TransSeqNumber))
+	return data
+}
+
+func (command *AfDataConfirm) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 3 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Status = data[0]
+	command.Endpoint = data[1]
+	command.TransSeqNumber = data[2]
+	data = data[3:]
+	return data, nil
+}
+
+func (AfIncomingMsg) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_AF, 0x81}
+}
+
+func (command AfIncomingMsg) MarshalSCF() []byte {
+	if len(command.Data) > 255 {
+		command.Data = command.Data[:255]
+	}
+	data := make([]byte, 0, 17+len(command.Data))
+	data = append(data, byte(command.GroupID), byte(command.GroupID>>8))
+	data = append(data, byte(command.ClusterID), byte(command.ClusterID>>8))
+	data = append(data, byte(command.SrcAddr), byte(command.SrcAddr>>8))
+	data = append(data, byte(command.SrcEndpoint))
+	data = append(data, byte(command.DstEndpoint))
+	data = append(data, byte(command.WasBroadcast))
+	data = append(data, byte(command.LinkQuality))
+	data = append(data, byte(command.SecureUse))
+	data = append(data, byte(command.TimeStamp), byte(command.TimeStamp>>8), byte(command.TimeStamp>>16), byte(command.TimeStamp>>24))
+	data = append(data, byte(command.TransSeqNumber))
+	data = append(data, byte(len(command.Data)))
+	data = append(data, command.Data...)
+	return data
+}
+
+func (command *AfIncomingMsg) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 16 {
+		return nil, scf.ErrInvalidData
+	}
+	command.GroupID = binary.LittleEndian.Uint16(data)
+	command.ClusterID = binary.LittleEndian.Uint16(data[2:])
+	command.SrcAddr = binary.LittleEndian.Uint16(data[4:])
+	command.SrcEndpoint = data[6]
+	command.DstEndpoint = data[7]
+	command.WasBroadcast = data[8]
+	command.LinkQuality = data[9]
+	command.SecureUse = data[10]
+	command.TimeStamp = binary.LittleEndian.Uint32(data[11:])
+	command.TransSeqNumber = data[15]
+	data = data[16:]
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	length := int(data[0])
+	data = data[1:]
+	if len(data) < length {
+		return nil, scf.ErrInvalidData
+	}
+	command.Data = make([]byte, length)
+	copy(command.Data, data)
+	data = data[length:]
+	return data, nil
+}
+
+func (ZdoNwkAddrRequest) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x00}
+}
+
+func (command ZdoNwkAddrRequest) MarshalSCF() []byte {
+	data := make([]byte, 0, 10)
+	data = append(data, byte(command.IEEEAddress), byte(command.IEEEAddress>>8), byte(command.IEEEAddress>>16), byte(command.IEEEAddress>>24), byte(command.IEEEAddress>>32), byte(command.IEEEAddress>>40), byte(command.IEEEAddress>>48), byte(command.IEEEAddress>>56))
+	data = append(data, byte(command.ReqType))
+	data = append(data, byte(command.StartIndex))
+	return data
+}
+
+func (command *ZdoNwkAddrRequest) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 10 {
+		return nil, scf.ErrInvalidData
+	}
+	command.IEEEAddress = binary.LittleEndian.Uint64(data)
+	command.ReqType = data[8]
+	command.StartIndex = data[9]
+	data = data[10:]
+	return data, nil
+}
+
+func (ZdoNwkAddrResponse) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x00}
+}
+
+func (command ZdoNwkAddrResponse) MarshalSCF() []byte {
+	data := make([]byte, 0, 1)
+	data = append(data, byte(command.Status))
+	return data
+}
+
+func (command *ZdoNwkAddrResponse) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Status = data[0]
+	data = data[1:]
+	return data, nil
+}
+
+func (ZdoNwkAddr) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0x80}
+}
+
+func (command ZdoNwkAddr) MarshalSCF() []byte {
+	if len(command.AssocDevList) > 255 {
+		command.AssocDevList = command.AssocDevList[:255]
+	}
+	data := make([]byte, 0, 13+2*len(command.AssocDevList))
+	data = append(data, byte(command.Status))
+	data = append(data, byte(command.IEEEAddr), byte(command.IEEEAddr>>8), byte(command.IEEEAddr>>16), byte(command.IEEEAddr>>24), byte(command.IEEEAddr>>32), byte(command.IEEEAddr>>40), byte(command.IEEEAddr>>48), byte(command.IEEEAddr>>56))
+	data = append(data, byte(command.NwkAddr), byte(command.NwkAddr>>8))
+	data = append(data, byte(command.StartIndex))
+	data = append(data, byte(len(command.AssocDevList)))
+	for _, value := range command.AssocDevList {
+		data = append(data, byte(value), byte(value>>8))
+	}
+	return data
+}
+
+func (command *ZdoNwkAddr) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 12 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Status = data[0]
+	command.IEEEAddr = binary.LittleEndian.Uint64(data[1:])
+	command.NwkAddr = binary.LittleEndian.Uint16(data[9:])
+	command.StartIndex = data[11]
+	data = data[12:]
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	length := int(data[0])
+	data = data[1:]
+	if len(data) < 2*length {
+		return nil, scf.ErrInvalidData
+	}
+	command.AssocDevList = make([]uint16, length)
+	for i := range command.AssocDevList {
+		command.AssocDevList[i] = binary.LittleEndian.Uint16(data[2*i:])
+	}
+	data = data[2*length:]
+	return data, nil
+}
+
+func (ZdoIEEEAddrRequest) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x01}
+}
+
+func (command ZdoIEEEAddrRequest) MarshalSCF() []byte {
+	data := make([]byte, 0, 4)
+	data = append(data, byte(command.ShortAddr), byte(command.ShortAddr>>8))
+	data = append(data, byte(command.ReqType))
+	data = append(data, byte(command.StartIndex))
+	return data
+}
+
+func (command *ZdoIEEEAddrRequest) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 4 {
+		return nil, scf.ErrInvalidData
+	}
+	command.ShortAddr = binary.LittleEndian.Uint16(data)
+	command.ReqType = data[2]
+	command.StartIndex = data[3]
+	data = data[4:]
+	return data, nil
+}
+
+func (ZdoIEEEAddrResponse) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x01}
+}
+
+func (command ZdoIEEEAddrResponse) MarshalSCF() []byte {
+	data := make([]byte, 0, 1)
+	data = append(data, byte(command.Status))
+	return data
+}
+
+func (command *ZdoIEEEAddrResponse) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Status = data[0]
+	data = data[1:]
+	return data, nil
+}
+
+func (ZdoIEEEAddr) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0x81}
+}
+
+func (command ZdoIEEEAddr) MarshalSCF() []byte {
+	if len(command.AssocDevList) > 255 {
+		command.AssocDevList = command.AssocDevList[:255]
+	}
+	data := make([]byte, 0, 13+2*len(command.AssocDevList))
+	data = append(data, byte(command.Status))
+	data = append(data, byte(command.IEEEAddr), byte(command.IEEEAddr>>8), byte(command.IEEEAddr>>16), byte(command.IEEEAddr>>24), byte(command.IEEEAddr>>32), byte(command.IEEEAddr>>40), byte(command.IEEEAddr>>48), byte(command.IEEEAddr>>56))
+	data = append(data, byte(command.NwkAddr), byte(command.NwkAddr>>8))
+	data = append(data, byte(command.StartIndex))
+	data = append(data, byte(len(command.AssocDevList)))
+	for _, value := range command.AssocDevList {
+		data = append(data, byte(value), byte(value>>8))
+	}
+	return data
+}
+
+func (command *ZdoIEEEAddr) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 12 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Status = data[0]
+	command.IEEEAddr = binary.LittleEndian.Uint64(data[1:])
+	command.NwkAddr = binary.LittleEndian.Uint16(data[9:])
+	command.StartIndex = data[11]
+	data = data[12:]
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	length := int(data[0])
+	data = data[1:]
+	if len(data) < 2*length {
+		return nil, scf.ErrInvalidData
+	}
+	command.AssocDevList = make([]uint16, length)
+	for i := range command.AssocDevList {
+		command.AssocDevList[i] = binary.LittleEndian.Uint16(data[2*i:])
+	}
+	data = data[2*length:]
+	return data, nil
+}
+
+func (ZdoNodeDescRequest) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x02}
+}
+
+func (command ZdoNodeDescRequest) MarshalSCF() []byte {
+	data := make([]byte, 0, 4)
+	data = append(data, byte(command.DstAddr), byte(command.DstAddr>>8))
+	data = append(data, byte(command.NWKAddrOfInterest), byte(command.NWKAddrOfInterest>>8))
+	return data
+}
+
+func (command *ZdoNodeDescRequest) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 4 {
+		return nil, scf.ErrInvalidData
+	}
+	command.DstAddr = binary.LittleEndian.Uint16(data)
+	command.NWKAddrOfInterest = binary.LittleEndian.Uint16(data[2:])
+	data = data[4:]
+	return data, nil
+}
+
+func (ZdoNodeDescResponse) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x02}
+}
+
+func (command ZdoNodeDescResponse) MarshalSCF() []byte {
+	data := make([]byte, 0, 1)
+	data = append(data, byte(command.Status))
+	return data
+}
+
+func (command *ZdoNodeDescResponse) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Status = data[0]
+	data = data[1:]
+	return data, nil
+}
+
+func (ZdoNodeDesc) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0x82}
+}
+
+func (command ZdoNodeDesc) MarshalSCF() []byte {
+	data := make([]byte, 0, 2+len(command.Payload))
+	data = append(data, byte(command.SrcAddr), byte(command.SrcAddr>>8))
+	data = append(data, command.Payload...)
+	return data
+}
+
+func (command *ZdoNodeDesc) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 2 {
+		return nil, scf.ErrInvalidData
+	}
+	command.SrcAddr = binary.LittleEndian.Uint16(data)
+	data = data[2:]
+	command.Payload = make([]byte, len(data))
+	copy(command.Payload, data)
+	data = data[len(data):]
+	return data, nil
+}
+
+func (ZdoSimpleDescRequest) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x04}
+}
+
+func (command ZdoSimpleDescRequest) MarshalSCF() []byte {
+	data := make([]byte, 0, 5)
+	data = append(data, byte(command.DstAddr), byte(command.DstAddr>>8))
+	data = append(data, byte(command.NWKAddrOfInterest), byte(command.NWKAddrOfInterest>>8))
+	data = append(data, byte(command.Endpoint))
+	return data
+}
+
+func (command *ZdoSimpleDescRequest) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 5 {
+		return nil, scf.ErrInvalidData
+	}
+	command.DstAddr = binary.LittleEndian.Uint16(data)
+	command.NWKAddrOfInterest = binary.LittleEndian.Uint16(data[2:])
+	command.Endpoint = data[4]
+	data = data[5:]
+	return data, nil
+}
+
+func (ZdoSimpleDescResponse) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x04}
+}
+
+func (command ZdoSimpleDescResponse) MarshalSCF() []byte {
+	data := make([]byte, 0, 1)
+	data = append(data, byte(command.Status))
+	return data
+}
+
+func (command *ZdoSimpleDescResponse) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Status = data[0]
+	data = data[1:]
+	return data, nil
+}
+
+func (ZdoSimpleDesc) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0x84}
+}
+
+func (command ZdoSimpleDesc) MarshalSCF() []byte {
+	data := make([]byte, 0, 2+len(command.Payload))
+	data = append(data, byte(command.SrcAddr), byte(command.SrcAddr>>8))
+	data = append(data, command.Payload...)
+	return data
+}
+
+func (command *ZdoSimpleDesc) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 2 {
+		return nil, scf.ErrInvalidData
+	}
+	command.SrcAddr = binary.LittleEndian.Uint16(data)
+	data = data[2:]
+	command.Payload = make([]byte, len(data))
+	copy(command.Payload, data)
+	data = data[len(data):]
+	return data, nil
+}
+
+func (ZdoActiveEPRequest) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x05}
+}
+
+func (command ZdoActiveEPRequest) MarshalSCF() []byte {
+	data := make([]byte, 0, 4)
+	data = append(data, byte(command.DstAddr), byte(command.DstAddr>>8))
+	data = append(data, byte(command.NWKAddrOfInterest), byte(command.NWKAddrOfInterest>>8))
+	return data
+}
+
+func (command *ZdoActiveEPRequest) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 4 {
+		return nil, scf.ErrInvalidData
+	}
+	command.DstAddr = binary.LittleEndian.Uint16(data)
+	command.NWKAddrOfInterest = binary.LittleEndian.Uint16(data[2:])
+	data = data[4:]
+	return data, nil
+}
+
+func (ZdoActiveEPResponse) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x05}
+}
+
+func (command ZdoActiveEPResponse) MarshalSCF() []byte {
+	data := make([]byte, 0, 1)
+	data = append(data, byte(command.Status))
+	return data
+}
+
+func (command *ZdoActiveEPResponse) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Status = data[0]
+	data = data[1:]
+	return data, nil
+}
+
+func (ZdoActiveEP) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0x85}
+}
+
+func (command ZdoActiveEP) MarshalSCF() []byte {
+	if len(command.ActiveEPs) > 255 {
+		command.ActiveEPs = command.ActiveEPs[:255]
+	}
+	data := make([]byte, 0, 6+len(command.ActiveEPs))
+	data = append(data, byte(command.SrcAddr), byte(command.SrcAddr>>8))
+	data = append(data, byte(command.Status))
+	data = append(data, byte(command.NWKAddr), byte(command.NWKAddr>>8))
+	data = append(data, byte(len(command.ActiveEPs)))
+	data = append(data, command.ActiveEPs...)
+	return data
+}
+
+func (command *ZdoActiveEP) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 5 {
+		return nil, scf.ErrInvalidData
+	}
+	command.SrcAddr = binary.LittleEndian.Uint16(data)
+	command.Status = data[2]
+	command.NWKAddr = binary.LittleEndian.Uint16(data[3:])
+	data = data[5:]
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	length := int(data[0])
+	data = data[1:]
+	if len(data) < length {
+		return nil, scf.ErrInvalidData
+	}
+	command.ActiveEPs = make([]uint8, length)
+	copy(command.ActiveEPs, data)
+	data = data[length:]
+	return data, nil
+}
+
+func (ZdoBindRequest) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x21}
+}
+
+func (command ZdoBindRequest) MarshalSCF() []byte {
+	data := make([]byte, 0, 23)
+	data = append(data, byte(command.DstAddr), byte(command.DstAddr>>8))
+	data = append(data, byte(command.SrcAddress), byte(command.SrcAddress>>8), byte(command.SrcAddress>>16), byte(command.SrcAddress>>24), byte(command.SrcAddress>>32), byte(command.SrcAddress>>40), byte(command.SrcAddress>>48), byte(command.SrcAddress>>56))
+	data = append(data, byte(command.SrcEndpoint))
+	data = append(data, byte(command.ClusterID), byte(command.ClusterID>>8))
+	data = append(data, byte(command.DstAddrMode))
+	data = append(data, byte(command.DstAddress), byte(command.DstAddress>>8), byte(command.DstAddress>>16), byte(command.DstAddress>>24), byte(command.DstAddress>>32), byte(command.DstAddress>>40), byte(command.DstAddress>>48), byte(command.DstAddress>>56))
+	data = append(data, byte(command.DstEndpoint))
+	return data
+}
+
+func (command *ZdoBindRequest) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 23 {
+		return nil, scf.ErrInvalidData
+	}
+	command.DstAddr = binary.LittleEndian.Uint16(data)
+	command.SrcAddress = binary.LittleEndian.Uint64(data[2:])
+	command.SrcEndpoint = data[10]
+	command.ClusterID = binary.LittleEndian.Uint16(data[11:])
+	command.DstAddrMode = data[13]
+	command.DstAddress = binary.LittleEndian.Uint64(data[14:])
+	command.DstEndpoint = data[22]
+	data = data[23:]
+	return data, nil
+}
+
+func (ZdoBindResponse) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x21}
+}
+
+func (command ZdoBindResponse) MarshalSCF() []byte {
+	data := make([]byte, 0, 1)
+	data = append(data, byte(command.Status))
+	return data
+}
+
+func (command *ZdoBindResponse) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Status = data[0]
+	data = data[1:]
+	return data, nil
+}
+
+func (ZdoBind) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xa1}
+}
+
+func (command ZdoBind) MarshalSCF() []byte {
+	data := make([]byte, 0, 2+len(command.Payload))
+	data = append(data, byte(command.SrcAddr), byte(command.SrcAddr>>8))
+	data = append(data, command.Payload...)
+	return data
+}
+
+func (command *ZdoBind) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 2 {
+		return nil, scf.ErrInvalidData
+	}
+	command.SrcAddr = binary.LittleEndian.Uint16(data)
+	data = data[2:]
+	command.Payload = make([]byte, len(data))
+	copy(command.Payload, data)
+	data = data[len(data):]
+	return data, nil
+}
+
+func (ZdoUnbindRequest) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x22}
+}
+
+func (command ZdoUnbindRequest) MarshalSCF() []byte {
+	data := make([]byte, 0, 23)
+	data = append(data, byte(command.DstAddr), byte(command.DstAddr>>8))
+	data = append(data, byte(command.SrcAddress), byte(command.SrcAddress>>8), byte(command.SrcAddress>>16), byte(command.SrcAddress>>24), byte(command.SrcAddress>>32), byte(command.SrcAddress>>40), byte(command.SrcAddress>>48), byte(command.SrcAddress>>56))
+	data = append(data, byte(command.SrcEndpoint))
+	data = append(data, byte(command.ClusterID), byte(command.ClusterID>>8))
+	data = append(data, byte(command.DstAddrMode))
+	data = append(data, byte(command.DstAddress), byte(command.DstAddress>>8), byte(command.DstAddress>>16), byte(command.DstAddress>>24), byte(command.DstAddress>>32), byte(command.DstAddress>>40), byte(command.DstAddress>>48), byte(command.DstAddress>>56))
+	data = append(data, byte(command.DstEndpoint))
+	return data
+}
+
+func (command *ZdoUnbindRequest) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 23 {
+		return nil, scf.ErrInvalidData
+	}
+	command.DstAddr = binary.LittleEndian.Uint16(data)
+	command.SrcAddress = binary.LittleEndian.Uint64(data[2:])
+	command.SrcEndpoint = data[10]
+	command.ClusterID = binary.LittleEndian.Uint16(data[11:])
+	command.DstAddrMode = data[13]
+	command.DstAddress = binary.LittleEndian.Uint64(data[14:])
+	command.DstEndpoint = data[22]
+	data = data[23:]
+	return data, nil
+}
+
+func (ZdoUnbindResponse) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x22}
+}
+
+func (command ZdoUnbindResponse) MarshalSCF() []byte {
+	data := make([]byte, 0, 1)
+	data = append(data, byte(command.Status))
+	return data
+}
+
+func (command *ZdoUnbindResponse) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Status = data[0]
+	data = data[1:]
+	return data, nil
+}
+
+func (ZdoUnbind) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xa2}
+}
+
+func (command ZdoUnbind) MarshalSCF() []byte {
+	data := make([]byte, 0, 2+len(command.Payload))
+	data = append(data, byte(command.SrcAddr), byte(command.SrcAddr>>8))
+	data = append(data, command.Payload...)
+	return data
+}
+
+func (command *ZdoUnbind) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 2 {
+		return nil, scf.ErrInvalidData
+	}
+	command.SrcAddr = binary.LittleEndian.Uint16(data)
+	data = data[2:]
+	command.Payload = make([]byte, len(data))
+	copy(command.Payload, data)
+	data = data[len(data):]
+	return data, nil
+}
+
+func (ZdoMgmtLqiRequest) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x31}
+}
+
+func (command ZdoMgmtLqiRequest) MarshalSCF() []byte {
+	data := make([]byte, 0, 3)
+	data = append(data, byte(command.DstAddr), byte(command.DstAddr>>8))
+	data = append(data, byte(command.StartIndex))
+	return data
+}
+
+func (command *ZdoMgmtLqiRequest) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 3 {
+		return nil, scf.ErrInvalidData
+	}
+	command.DstAddr = binary.LittleEndian.Uint16(data)
+	command.StartIndex = data[2]
+	data = data[3:]
+	return data, nil
+}
+
+func (ZdoMgmtLqiResponse) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x31}
+}
+
+func (command ZdoMgmtLqiResponse) MarshalSCF() []byte {
+	data := make([]byte, 0, 1)
+	data = append(data, byte(command.Status))
+	return data
+}
+
+func (command *ZdoMgmtLqiResponse) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Status = data[0]
+	data = data[1:]
+	return data, nil
+}
+
+func (ZdoMgmtLqi) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xb1}
+}
+
+func (command ZdoMgmtLqi) MarshalSCF() []byte {
+	data := make([]byte, 0, 2+len(command.Payload))
+	data = append(data, byte(command.SrcAddr), byte(command.SrcAddr>>8))
+	data = append(data, command.Payload...)
+	return data
+}
+
+func (command *ZdoMgmtLqi) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 2 {
+		return nil, scf.ErrInvalidData
+	}
+	command.SrcAddr = binary.LittleEndian.Uint16(data)
+	data = data[2:]
+	command.Payload = make([]byte, len(data))
+	copy(command.Payload, data)
+	data = data[len(data):]
+	return data, nil
+}
+
+func (ZdoMgmtRtgRequest) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x32}
+}
+
+func (command ZdoMgmtRtgRequest) MarshalSCF() []byte {
+	data := make([]byte, 0, 3)
+	data = append(data, byte(command.DstAddr), byte(command.DstAddr>>8))
+	data = append(data, byte(command.StartIndex))
+	return data
+}
+
+func (command *ZdoMgmtRtgRequest) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 3 {
+		return nil, scf.ErrInvalidData
+	}
+	command.DstAddr = binary.LittleEndian.Uint16(data)
+	command.StartIndex = data[2]
+	data = data[3:]
+	return data, nil
+}
+
+func (ZdoMgmtRtgResponse) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x32}
+}
+
+func (command ZdoMgmtRtgResponse) MarshalSCF() []byte {
+	data := make([]byte, 0, 1)
+	data = append(data, byte(command.Status))
+	return data
+}
+
+func (command *ZdoMgmtRtgResponse) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Status = data[0]
+	data = data[1:]
+	return data, nil
+}
+
+func (ZdoMgmtRtg) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xb2}
+}
+
+func (command ZdoMgmtRtg) MarshalSCF() []byte {
+	data := make([]byte, 0, 2+len(command.Payload))
+	data = append(data, byte(command.SrcAddr), byte(command.SrcAddr>>8))
+	data = append(data, command.Payload...)
+	return data
+}
+
+func (command *ZdoMgmtRtg) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 2 {
+		return nil, scf.ErrInvalidData
+	}
+	command.SrcAddr = binary.LittleEndian.Uint16(data)
+	data = data[2:]
+	command.Payload = make([]byte, len(data))
+	copy(command.Payload, data)
+	data = data[len(data):]
+	return data, nil
+}
+
+func (ZdoMgmtBindRequest) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x33}
+}
+
+func (command ZdoMgmtBindRequest) MarshalSCF() []byte {
+	data := make([]byte, 0, 3)
+	data = append(data, byte(command.DstAddr), byte(command.DstAddr>>8))
+	data = append(data, byte(command.StartIndex))
+	return data
+}
+
+func (command *ZdoMgmtBindRequest) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 3 {
+		return nil, scf.ErrInvalidData
+	}
+	command.DstAddr = binary.LittleEndian.Uint16(data)
+	command.StartIndex = data[2]
+	data = data[3:]
+	return data, nil
+}
+
+func (ZdoMgmtBindResponse) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x33}
+}
+
+func (command ZdoMgmtBindResponse) MarshalSCF() []byte {
+	data := make([]byte, 0, 1)
+	data = append(data, byte(command.Status))
+	return data
+}
+
+func (command *ZdoMgmtBindResponse) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Status = data[0]
+	data = data[1:]
+	return data, nil
+}
+
+func (ZdoMgmtBind) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xb3}
+}
+
+func (command ZdoMgmtBind) MarshalSCF() []byte {
+	data := make([]byte, 0, 2+len(command.Payload))
+	data = append(data, byte(command.SrcAddr), byte(command.SrcAddr>>8))
+	data = append(data, command.Payload...)
+	return data
+}
+
+func (command *ZdoMgmtBind) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 2 {
+		return nil, scf.ErrInvalidData
+	}
+	command.SrcAddr = binary.LittleEndian.Uint16(data)
+	data = data[2:]
+	command.Payload = make([]byte, len(data))
+	copy(command.Payload, data)
+	data = data[len(data):]
+	return data, nil
+}
+
+func (ZdoMgmtPermitJoinRequest) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x36}
+}
+
+func (command ZdoMgmtPermitJoinRequest) MarshalSCF() []byte {
+	data := make([]byte, 0, 5)
+	data = append(data, byte(command.AddrMode))
+	data = append(data, byte(command.DstAddr), byte(command.DstAddr>>8))
+	data = append(data, byte(command.Duration))
+	data = append(data, byte(command.TCSignificance))
+	return data
+}
+
+func (command *ZdoMgmtPermitJoinRequest) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 5 {
+		return nil, scf.ErrInvalidData
+	}
+	command.AddrMode = data[0]
+	command.DstAddr = binary.LittleEndian.Uint16(data[1:])
+	command.Duration = data[3]
+	command.TCSignificance = data[4]
+	data = data[5:]
+	return data, nil
+}
+
+func (ZdoMgmtPermitJoinResponse) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x36}
+}
+
+func (command ZdoMgmtPermitJoinResponse) MarshalSCF() []byte {
+	data := make([]byte, 0, 1)
+	data = append(data, byte(command.Status))
+	return data
+}
+
+func (command *ZdoMgmtPermitJoinResponse) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Status = data[0]
+	data = data[1:]
+	return data, nil
+}
+
+func (ZdoMgmtPermitJoin) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xb6}
+}
+
+func (command ZdoMgmtPermitJoin) MarshalSCF() []byte {
+	data := make([]byte, 0, 3)
+	data = append(data, byte(command.SrcAddr), byte(command.SrcAddr>>8))
+	data = append(data, byte(command.Status))
+	return data
+}
+
+func (command *ZdoMgmtPermitJoin) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 3 {
+		return nil, scf.ErrInvalidData
+	}
+	command.SrcAddr = binary.LittleEndian.Uint16(data)
+	command.Status = data[2]
+	data = data[3:]
+	return data, nil
+}
+
+func (ZdoMsgCbRegisterRequest) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x3e}
+}
+
+func (command ZdoMsgCbRegisterRequest) MarshalSCF() []byte {
+	data := make([]byte, 0, 2)
+	data = append(data, byte(command.ClusterID), byte(command.ClusterID>>8))
+	return data
+}
+
+func (command *ZdoMsgCbRegisterRequest) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 2 {
+		return nil, scf.ErrInvalidData
+	}
+	command.ClusterID = binary.LittleEndian.Uint16(data)
+	data = data[2:]
+	return data, nil
+}
+
+func (ZdoMsgCbRegisterResponse) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x3e}
+}
+
+func (command ZdoMsgCbRegisterResponse) MarshalSCF() []byte {
+	data := make([]byte, 0, 1)
+	data = append(data, byte(command.Status))
+	return data
+}
+
+func (command *ZdoMsgCbRegisterResponse) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Status = data[0]
+	data = data[1:]
+	return data, nil
+}
+
+func (ZdoStartupFromAppRequest) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x40}
+}
+
+func (command ZdoStartupFromAppRequest) MarshalSCF() []byte {
+	data := make([]byte, 0, 2)
+	data = append(data, byte(command.StartDelay), byte(command.StartDelay>>8))
+	return data
+}
+
+func (command *ZdoStartupFromAppRequest) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 2 {
+		return nil, scf.ErrInvalidData
+	}
+	command.StartDelay = binary.LittleEndian.Uint16(data)
+	data = data[2:]
+	return data, nil
+}
+
+func (ZdoStartupFromAppResponse) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x40}
+}
+
+func (command ZdoStartupFromAppResponse) MarshalSCF() []byte {
+	data := make([]byte, 0, 1)
+	data = append(data, byte(command.Status))
+	return data
+}
+
+func (command *ZdoStartupFromAppResponse) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Status = data[0]
+	data = data[1:]
+	return data, nil
+}
+
+func (ZdoExtNwkInfoRequest) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x50}
+}
+
+func (command ZdoExtNwkInfoRequest) MarshalSCF() []byte {
+	return nil
+}
+
+func (command *ZdoExtNwkInfoRequest) UnmarshalSCF(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+func (ZdoExtNwkInfoResponse) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x50}
+}
+
+func (command ZdoExtNwkInfoResponse) MarshalSCF() []byte {
+	data := make([]byte, 0, 24)
+	data = append(data, byte(command.ShortAddress), byte(command.ShortAddress>>8))
+	data = append(data, byte(command.PanID), byte(command.PanID>>8))
+	data = append(data, byte(command.ParentAddress), byte(command.ParentAddress>>8))
+	data = append(data, byte(command.ExtendedPanID), byte(command.ExtendedPanID>>8), byte(command.ExtendedPanID>>16), byte(command.ExtendedPanID>>24), byte(command.ExtendedPanID>>32), byte(command.ExtendedPanID>>40), byte(command.ExtendedPanID>>48), byte(command.ExtendedPanID>>56))
+	data = append(data, byte(command.ExtendedParentAddress), byte(command.ExtendedParentAddress>>8), byte(command.ExtendedParentAddress>>16), byte(command.ExtendedParentAddress>>24), byte(command.ExtendedParentAddress>>32), byte(command.ExtendedParentAddress>>40), byte(command.ExtendedParentAddress>>48), byte(command.ExtendedParentAddress>>56))
+	data = append(data, byte(command.Channel), byte(command.Channel>>8))
+	return data
+}
+
+func (command *ZdoExtNwkInfoResponse) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 24 {
+		return nil, scf.ErrInvalidData
+	}
+	command.ShortAddress = binary.LittleEndian.Uint16(data)
+	command.PanID = binary.LittleEndian.Uint16(data[2:])
+	command.ParentAddress = binary.LittleEndian.Uint16(data[4:])
+	command.ExtendedPanID = binary.LittleEndian.Uint64(data[6:])
+	command.ExtendedParentAddress = binary.LittleEndian.Uint64(data[14:])
+	command.Channel = binary.LittleEndian.Uint16(data[22:])
+	data = data[24:]
+	return data, nil
+}
+
+func (ZdoStateChangeInd) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xc0}
+}
+
+func (command ZdoStateChangeInd) MarshalSCF() []byte {
+	data := make([]byte, 0, 1)
+	data = append(data, byte(command.State))
+	return data
+}
+
+func (command *ZdoStateChangeInd) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	command.State = DeviceState(data[0])
+	data = data[1:]
+	return data, nil
+}
+
+func (ZdoEndDeviceAnnceInd) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xc1}
+}
+
+func (command ZdoEndDeviceAnnceInd) MarshalSCF() []byte {
+	data := make([]byte, 0, 13)
+	data = append(data, byte(command.SrcAddr), byte(command.SrcAddr>>8))
+	data = append(data, byte(command.NwkAddr), byte(command.NwkAddr>>8))
+	data = append(data, byte(command.IEEEAddr), byte(command.IEEEAddr>>8), byte(command.IEEEAddr>>16), byte(command.IEEEAddr>>24), byte(command.IEEEAddr>>32), byte(command.IEEEAddr>>40), byte(command.IEEEAddr>>48), byte(command.IEEEAddr>>56))
+	data = append(data, byte(command.Capabilities))
+	return data
+}
+
+func (command *ZdoEndDeviceAnnceInd) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 13 {
+		return nil, scf.ErrInvalidData
+	}
+	command.SrcAddr = binary.LittleEndian.Uint16(data)
+	command.NwkAddr = binary.LittleEndian.Uint16(data[2:])
+	command.IEEEAddr = binary.LittleEndian.Uint64(data[4:])
+	command.Capabilities = data[12]
+	data = data[13:]
+	return data, nil
+}
+
+func (ZdoTcDevInd) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xca}
+}
+
+func (command ZdoTcDevInd) MarshalSCF() []byte {
+	data := make([]byte, 0, 12)
+	data = append(data, byte(command.SrcNwkAddr), byte(command.SrcNwkAddr>>8))
+	data = append(data, byte(command.SrcIEEEAddr), byte(command.SrcIEEEAddr>>8), byte(command.SrcIEEEAddr>>16), byte(command.SrcIEEEAddr>>24), byte(command.SrcIEEEAddr>>32), byte(command.SrcIEEEAddr>>40), byte(command.SrcIEEEAddr>>48), byte(command.SrcIEEEAddr>>56))
+	data = append(data, byte(command.ParentNwkAddr), byte(command.ParentNwkAddr>>8))
+	return data
+}
+
+func (command *ZdoTcDevInd) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 12 {
+		return nil, scf.ErrInvalidData
+	}
+	command.SrcNwkAddr = binary.LittleEndian.Uint16(data)
+	command.SrcIEEEAddr = binary.LittleEndian.Uint64(data[2:])
+	command.ParentNwkAddr = binary.LittleEndian.Uint16(data[10:])
+	data = data[12:]
+	return data, nil
+}
+
+func (ZdoSrcRtgInd) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xc4}
+}
+
+func (command ZdoSrcRtgInd) MarshalSCF() []byte {
+	if len(command.RelayList) > 255 {
+		command.RelayList = command.RelayList[:255]
+	}
+	data := make([]byte, 0, 3+2*len(command.RelayList))
+	data = append(data, byte(command.DstAddr), byte(command.DstAddr>>8))
+	data = append(data, byte(len(command.RelayList)))
+	for _, value := range command.RelayList {
+		data = append(data, byte(value), byte(value>>8))
+	}
+	return data
+}
+
+func (command *ZdoSrcRtgInd) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 2 {
+		return nil, scf.ErrInvalidData
+	}
+	command.DstAddr = binary.LittleEndian.Uint16(data)
+	data = data[2:]
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	length := int(data[0])
+	data = data[1:]
+	if len(data) < 2*length {
+		return nil, scf.ErrInvalidData
+	}
+	command.RelayList = make([]uint16, length)
+	for i := range command.RelayList {
+		command.RelayList[i] = binary.LittleEndian.Uint16(data[2*i:])
+	}
+	data = data[2*length:]
+	return data, nil
+}
+
+func (ZdoPermitJoinInd) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xcb}
+}
+
+func (command ZdoPermitJoinInd) MarshalSCF() []byte {
+	data := make([]byte, 0, 1)
+	data = append(data, byte(command.Duration))
+	return data
+}
+
+func (command *ZdoPermitJoinInd) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Duration = data[0]
+	data = data[1:]
+	return data, nil
+}
+
+func (ZdoMsgCbIncoming) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xff}
+}
+
+func (command ZdoMsgCbIncoming) MarshalSCF() []byte {
+	data := make([]byte, 0, 9+len(command.Data))
+	data = append(data, byte(command.SrcAddr), byte(command.SrcAddr>>8))
+	data = append(data, byte(command.WasBroadcast))
+	data = append(data, byte(command.ClusterID), byte(command.ClusterID>>8))
+	data = append(data, byte(command.SecurityUse))
+	data = append(data, byte(command.SeqNum))
+	data = append(data, byte(command.MacDstAddr), byte(command.MacDstAddr>>8))
+	data = append(data, command.Data...)
+	return data
+}
+
+func (command *ZdoMsgCbIncoming) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 9 {
+		return nil, scf.ErrInvalidData
+	}
+	command.SrcAddr = binary.LittleEndian.Uint16(data)
+	command.WasBroadcast = data[2]
+	command.ClusterID = binary.LittleEndian.Uint16(data[3:])
+	command.SecurityUse = data[5]
+	command.SeqNum = data[6]
+	command.MacDstAddr = binary.LittleEndian.Uint16(data[7:])
+	data = data[9:]
+	command.Data = make([]byte, len(data))
+	copy(command.Data, data)
+	data = data[len(data):]
+	return data, nil
+}
+
+func (ZbReadConfigurationRequest) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_SAPI, 0x04}
+}
+
+func (command ZbReadConfigurationRequest) MarshalSCF() []byte {
+	data := make([]byte, 0, 1)
+	data = append(data, byte(command.ConfigID))
+	return data
+}
+
+func (command *ZbReadConfigurationRequest) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	command.ConfigID = data[0]
+	data = data[1:]
+	return data, nil
+}
+
+func (ZBReadConfigurationResponse) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_SAPI, 0x04}
+}
+
+func (command ZBReadConfigurationResponse) MarshalSCF() []byte {
+	if len(command.Value) > 255 {
+		command.Value = command.Value[:255]
+	}
+	data := make([]byte, 0, 3+len(command.Value))
+	data = append(data, byte(command.Status))
+	data = append(data, byte(command.ConfigID))
+	data = append(data, byte(len(command.Value)))
+	data = append(data, command.Value...)
+	return data
+}
+
+func (command *ZBReadConfigurationResponse) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 2 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Status = data[0]
+	command.ConfigID = data[1]
+	data = data[2:]
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	length := int(data[0])
+	data = data[1:]
+	if len(data) < length {
+		return nil, scf.ErrInvalidData
+	}
+	command.Value = make([]byte, length)
+	copy(command.Value, data)
+	data = data[length:]
+	return data, nil
+}
+
+func (ZbWriteConfigurationRequest) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_SAPI, 0x05}
+}
+
+func (command ZbWriteConfigurationRequest) MarshalSCF() []byte {
+	if len(command.Value) > 255 {
+		command.Value = command.Value[:255]
+	}
+	data := make([]byte, 0, 2+len(command.Value))
+	data = append(data, byte(command.ConfigID))
+	data = append(data, byte(len(command.Value)))
+	data = append(data, command.Value...)
+	return data
+}
+
+func (command *ZbWriteConfigurationRequest) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	command.ConfigID = data[0]
+	data = data[1:]
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	length := int(data[0])
+	data = data[1:]
+	if len(data) < length {
+		return nil, scf.ErrInvalidData
+	}
+	command.Value = make([]byte, length)
+	copy(command.Value, data)
+	data = data[length:]
+	return data, nil
+}
+
+func (ZbWriteConfigurationResponse) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_SAPI, 0x05}
+}
+
+func (command ZbWriteConfigurationResponse) MarshalSCF() []byte {
+	data := make([]byte, 0, 1)
+	data = append(data, byte(command.Status))
+	return data
+}
+
+func (command *ZbWriteConfigurationResponse) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Status = data[0]
+	data = data[1:]
+	return data, nil
+}
+
+func (UtilGetDeviceInfoRequest) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_UTIL, 0x00}
+}
+
+func (command UtilGetDeviceInfoRequest) MarshalSCF() []byte {
+	return nil
+}
+
+func (command *UtilGetDeviceInfoRequest) UnmarshalSCF(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+func (UtilGetDeviceInfoResponse) frameHeader() FrameHeader {
+	return FrameHeader{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_UTIL, 0x00}
+}
+
+func (command UtilGetDeviceInfoResponse) MarshalSCF() []byte {
+	if len(command.AssocDevices) > 255 {
+		command.AssocDevices = command.AssocDevices[:255]
+	}
+	data := make([]byte, 0, 14+2*len(command.AssocDevices))
+	data = append(data, byte(command.Status))
+	data = append(data, byte(command.IEEEAddr), byte(command.IEEEAddr>>8), byte(command.IEEEAddr>>16), byte(command.IEEEAddr>>24), byte(command.IEEEAddr>>32), byte(command.IEEEAddr>>40), byte(command.IEEEAddr>>48), byte(command.IEEEAddr>>56))
+	data = append(data, byte(command.ShortAddr), byte(command.ShortAddr>>8))
+	data = append(data, byte(command.DeviceType))
+	data = append(data, byte(command.DeviceState))
+	data = append(data, byte(len(command.AssocDevices)))
+	for _, value := range command.AssocDevices {
+		data = append(data, byte(value), byte(value>>8))
+	}
+	return data
+}
+
+func (command *UtilGetDeviceInfoResponse) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 13 {
+		return nil, scf.ErrInvalidData
+	}
+	command.Status = data[0]
+	command.IEEEAddr = binary.LittleEndian.Uint64(data[1:])
+	command.ShortAddr = binary.LittleEndian.Uint16(data[9:])
+	command.DeviceType = data[11]
+	command.DeviceState = DeviceState(data[12])
+	data = data[13:]
+	if len(data) < 1 {
+		return nil, scf.ErrInvalidData
+	}
+	length := int(data[0])
+	data = data[1:]
+	if len(data) < 2*length {
+		return nil, scf.ErrInvalidData
+	}
+	command.AssocDevices = make([]uint16, length)
+	for i := range command.AssocDevices {
+		command.AssocDevices[i] = binary.LittleEndian.Uint16(data[2*i:])
+	}
+	data = data[2*length:]
+	return data, nil
+}
+
+var parsersByFrameHeader = map[FrameHeader]func(data []byte) (interface{}, error){
+	{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_SYS, 0x02}: func(data []byte) (interface{}, error) {
+		var command SysVersionRequest
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_SYS, 0x02}: func(data []byte) (interface{}, error) {
+		var command SysVersionResponse
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_SYS, 0x08}: func(data []byte) (interface{}, error) {
+		var command SysOsalNvReadRequest
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_SYS, 0x08}: func(data []byte) (interface{}, error) {
+		var command SysOsalNvReadResponse
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_SYS, 0x09}: func(data []byte) (interface{}, error) {
+		var command SysOsalNvWriteRequest
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_SYS, 0x09}: func(data []byte) (interface{}, error) {
+		var command SysOsalNvWriteResponse
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_SYS, 0x80}: func(data []byte) (interface{}, error) {
+		var command SysResetInd
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_AF, 0x00}: func(data []byte) (interface{}, error) {
+		var command AfRegisterRequest
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_AF, 0x00}: func(data []byte) (interface{}, error) {
+		var command AfRegisterResponse
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_AF, 0x01}: func(data []byte) (interface{}, error) {
+		var command AfDataRequest
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_AF, 0x01}: func(data []byte) (interface{}, error) {
+		var command AfDataResponse
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_AF, 0x80}: func(data []byte) (interface{}, error) {
+		var command AfDataConfirm
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_AF, 0x81}: func(data []byte) (interface{}, error) {
+		var command AfIncomingMsg
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x00}: func(data []byte) (interface{}, error) {
+		var command ZdoNwkAddrRequest
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x00}: func(data []byte) (interface{}, error) {
+		var command ZdoNwkAddrResponse
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0x80}: func(data []byte) (interface{}, error) {
+		var command ZdoNwkAddr
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x01}: func(data []byte) (interface{}, error) {
+		var command ZdoIEEEAddrRequest
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x01}: func(data []byte) (interface{}, error) {
+		var command ZdoIEEEAddrResponse
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0x81}: func(data []byte) (interface{}, error) {
+		var command ZdoIEEEAddr
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x02}: func(data []byte) (interface{}, error) {
+		var command ZdoNodeDescRequest
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x02}: func(data []byte) (interface{}, error) {
+		var command ZdoNodeDescResponse
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0x82}: func(data []byte) (interface{}, error) {
+		var command ZdoNodeDesc
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x04}: func(data []byte) (interface{}, error) {
+		var command ZdoSimpleDescRequest
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x04}: func(data []byte) (interface{}, error) {
+		var command ZdoSimpleDescResponse
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0x84}: func(data []byte) (interface{}, error) {
+		var command ZdoSimpleDesc
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x05}: func(data []byte) (interface{}, error) {
+		var command ZdoActiveEPRequest
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x05}: func(data []byte) (interface{}, error) {
+		var command ZdoActiveEPResponse
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0x85}: func(data []byte) (interface{}, error) {
+		var command ZdoActiveEP
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x21}: func(data []byte) (interface{}, error) {
+		var command ZdoBindRequest
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x21}: func(data []byte) (interface{}, error) {
+		var command ZdoBindResponse
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xa1}: func(data []byte) (interface{}, error) {
+		var command ZdoBind
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x22}: func(data []byte) (interface{}, error) {
+		var command ZdoUnbindRequest
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x22}: func(data []byte) (interface{}, error) {
+		var command ZdoUnbindResponse
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xa2}: func(data []byte) (interface{}, error) {
+		var command ZdoUnbind
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x31}: func(data []byte) (interface{}, error) {
+		var command ZdoMgmtLqiRequest
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x31}: func(data []byte) (interface{}, error) {
+		var command ZdoMgmtLqiResponse
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xb1}: func(data []byte) (interface{}, error) {
+		var command ZdoMgmtLqi
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x32}: func(data []byte) (interface{}, error) {
+		var command ZdoMgmtRtgRequest
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x32}: func(data []byte) (interface{}, error) {
+		var command ZdoMgmtRtgResponse
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xb2}: func(data []byte) (interface{}, error) {
+		var command ZdoMgmtRtg
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x33}: func(data []byte) (interface{}, error) {
+		var command ZdoMgmtBindRequest
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x33}: func(data []byte) (interface{}, error) {
+		var command ZdoMgmtBindResponse
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xb3}: func(data []byte) (interface{}, error) {
+		var command ZdoMgmtBind
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x36}: func(data []byte) (interface{}, error) {
+		var command ZdoMgmtPermitJoinRequest
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x36}: func(data []byte) (interface{}, error) {
+		var command ZdoMgmtPermitJoinResponse
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xb6}: func(data []byte) (interface{}, error) {
+		var command ZdoMgmtPermitJoin
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x3e}: func(data []byte) (interface{}, error) {
+		var command ZdoMsgCbRegisterRequest
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x3e}: func(data []byte) (interface{}, error) {
+		var command ZdoMsgCbRegisterResponse
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x40}: func(data []byte) (interface{}, error) {
+		var command ZdoStartupFromAppRequest
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x40}: func(data []byte) (interface{}, error) {
+		var command ZdoStartupFromAppResponse
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_ZDO, 0x50}: func(data []byte) (interface{}, error) {
+		var command ZdoExtNwkInfoRequest
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_ZDO, 0x50}: func(data []byte) (interface{}, error) {
+		var command ZdoExtNwkInfoResponse
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xc0}: func(data []byte) (interface{}, error) {
+		var command ZdoStateChangeInd
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xc1}: func(data []byte) (interface{}, error) {
+		var command ZdoEndDeviceAnnceInd
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xca}: func(data []byte) (interface{}, error) {
+		var command ZdoTcDevInd
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xc4}: func(data []byte) (interface{}, error) {
+		var command ZdoSrcRtgInd
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xcb}: func(data []byte) (interface{}, error) {
+		var command ZdoPermitJoinInd
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_AREQ, FRAME_SUBSYSTEM_ZDO, 0xff}: func(data []byte) (interface{}, error) {
+		var command ZdoMsgCbIncoming
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_SAPI, 0x04}: func(data []byte) (interface{}, error) {
+		var command ZbReadConfigurationRequest
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_SAPI, 0x04}: func(data []byte) (interface{}, error) {
+		var command ZBReadConfigurationResponse
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_SAPI, 0x05}: func(data []byte) (interface{}, error) {
+		var command ZbWriteConfigurationRequest
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_SAPI, 0x05}: func(data []byte) (interface{}, error) {
+		var command ZbWriteConfigurationResponse
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_UTIL, 0x00}: func(data []byte) (interface{}, error) {
+		var command UtilGetDeviceInfoRequest
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+	{FRAME_TYPE_SRSP, FRAME_SUBSYSTEM_UTIL, 0x00}: func(data []byte) (interface{}, error) {
+		var command UtilGetDeviceInfoResponse
+		_, err := command.UnmarshalSCF(data)
+		return command, err
+	},
+}
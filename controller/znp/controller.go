@@ -5,12 +5,15 @@
 package znp
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/GreenLightning/zigbee-conductor/zdp"
 	"github.com/GreenLightning/zigbee-conductor/zigbee"
 )
 
@@ -18,8 +21,44 @@ type Controller struct {
 	settings zigbee.ControllerSettings
 	sequence uint32
 	port     *Port
+
+	// addresses maps between the network and IEEE addresses of devices.
+	// addressUpdated is closed and replaced whenever the map changes.
+	// pending contains the messages waiting for the network address of their
+	// destination in the order of their deadlines. expiryTimer runs while
+	// pending is not empty and drops the expired messages.
+	addresses         zigbee.AddressMap
+	addressMutex      sync.Mutex
+	addressUpdated    chan struct{}
+	pending           []pendingMessage
+	expiryTimer       *time.Timer
+	resolutionTimeout time.Duration
+
+	// zdpWaiters receive the ZDP responses forwarded as ZdoMsgCbIncoming.
+	// The ZNP assigns the sequence numbers of ZDP requests itself, therefore
+	// only one request per device and response cluster can wait at a time.
+	// zdpWaiterRemoved is closed and replaced whenever a waiter is removed.
+	zdpWaiters       map[zdpWaiterKey]chan zigbee.IncomingMessage
+	zdpMutex         sync.Mutex
+	zdpWaiterRemoved chan struct{}
+}
+
+type zdpWaiterKey struct {
+	nwkAddr   uint16
+	clusterID uint16 // of the response
+}
+
+// pendingMessage is a message to a destination given only by IEEE address,
+// which is sent once the network address of the destination is known.
+type pendingMessage struct {
+	message  zigbee.OutgoingMessage
+	deadline time.Time
 }
 
+// addressResolutionTimeout is the time a message to a destination given only
+// by IEEE address waits for the network address of the destination.
+var addressResolutionTimeout = 10 * time.Second
+
 func NewController(settings zigbee.ControllerSettings) (*Controller, error) {
 	callbacks := Callbacks{
 		OnReadError: func(err error) ErrorHandling {
@@ -63,10 +102,18 @@ func NewController(settings zigbee.ControllerSettings) (*Controller, error) {
 		return nil, err
 	}
 
+	return newController(settings, port), nil
+}
+
+func newController(settings zigbee.ControllerSettings, port *Port) *Controller {
 	return &Controller{
-		settings: settings,
-		port:     port,
-	}, nil
+		settings:          settings,
+		port:              port,
+		addressUpdated:    make(chan struct{}),
+		resolutionTimeout: addressResolutionTimeout,
+		zdpWaiters:        make(map[zdpWaiterKey]chan zigbee.IncomingMessage),
+		zdpWaiterRemoved:  make(chan struct{}),
+	}
 }
 
 type Endpoint struct {
@@ -103,7 +150,8 @@ func (c *Controller) Start() (chan zigbee.IncomingMessage, error) {
 		return nil, fmt.Errorf("getting device info: %w", err)
 	}
 
-	if response := response.(UtilGetDeviceInfoResponse); response.DeviceState != DeviceStateCoordinator {
+	deviceInfo := response.(UtilGetDeviceInfoResponse)
+	if deviceInfo.DeviceState != DeviceStateCoordinator {
 		handler := c.port.RegisterOneOffHandler(ZdoStateChangeInd{})
 		_, err = c.port.WriteCommand(ZdoStartupFromAppRequest{StartDelay: 100})
 		if err != nil {
@@ -128,10 +176,50 @@ func (c *Controller) Start() (chan zigbee.IncomingMessage, error) {
 		}
 	}
 
+	// The ZNP handles ZDP responses internally. Register to receive the
+	// responses to the requests sent by RequestZDP as ZdoMsgCbIncoming.
+	zdpHandler := c.RegisterPermanentHandler(ZdoMsgCbIncoming{})
+	for _, clusterID := range zdpResponseClusters {
+		response, err := c.port.WriteCommand(ZdoMsgCbRegisterRequest{ClusterID: clusterID})
+		if err != nil {
+			return nil, fmt.Errorf("registering ZDO callback: %w", err)
+		}
+		if status := response.(ZdoMsgCbRegisterResponse).Status; status != 0 {
+			return nil, fmt.Errorf("registering ZDO callback: status 0x%02x", status)
+		}
+	}
+
 	handler := c.RegisterPermanentHandler(AfIncomingMsg{})
+	announceHandler := c.RegisterPermanentHandler(ZdoEndDeviceAnnceInd{})
 	output := make(chan zigbee.IncomingMessage)
 
+	// Keep track of the IEEE addresses of devices.
+	c.handleAddresses(ZdoTcDevInd{}, func(command interface{}) {
+		ind := command.(ZdoTcDevInd)
+		c.updateAddress(ind.SrcNwkAddr, zigbee.MACAddress(ind.SrcIEEEAddr))
+	})
+	c.handleAddresses(ZdoIEEEAddr{}, func(command interface{}) {
+		if rsp := command.(ZdoIEEEAddr); zdp.Status(rsp.Status) == zdp.StatusSuccess {
+			c.updateAddress(rsp.NwkAddr, zigbee.MACAddress(rsp.IEEEAddr))
+		}
+	})
+	c.handleAddresses(ZdoNwkAddr{}, func(command interface{}) {
+		if rsp := command.(ZdoNwkAddr); zdp.Status(rsp.Status) == zdp.StatusSuccess {
+			c.updateAddress(rsp.NwkAddr, zigbee.MACAddress(rsp.IEEEAddr))
+		}
+	})
+
+	// The devices associated with the coordinator are only reported with
+	// their network address.
+	for _, nwkAddr := range deviceInfo.AssocDevices {
+		c.lookupIEEEAddress(nwkAddr)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+
 	go func() {
+		defer wg.Done()
 		for {
 			cmd, err := handler.Receive()
 			if err != nil {
@@ -140,18 +228,85 @@ func (c *Controller) Start() (chan zigbee.IncomingMessage, error) {
 			}
 
 			message := cmd.(AfIncomingMsg)
+			source := c.addresses.Complete(zigbee.Address{
+				Mode:  zigbee.AddressModeNWK,
+				Short: message.SrcAddr,
+			})
+			if source.Mode != zigbee.AddressModeCombined {
+				c.lookupIEEEAddress(message.SrcAddr)
+			}
+
 			output <- zigbee.IncomingMessage{
-				Source: zigbee.Address{
-					Mode:  zigbee.AddressModeNWK,
-					Short: message.SrcAddr,
-				},
+				Source:              source,
 				SourceEndpoint:      message.SrcEndpoint,
 				DestinationEndpoint: message.DstEndpoint,
 				ClusterID:           message.ClusterID,
 				LinkQuality:         message.LinkQuality,
+				Broadcast:           message.WasBroadcast != 0 || message.GroupID != 0,
 				Data:                message.Data,
 			}
 		}
+	}()
+
+	// The ZNP handles ZDP messages internally, but device announcements are
+	// forwarded to the application as ZDP messages (like other controllers
+	// do), so that it can keep track of the addresses of devices.
+	go func() {
+		defer wg.Done()
+		for {
+			cmd, err := announceHandler.Receive()
+			if err != nil {
+				break
+			}
+
+			announce := cmd.(ZdoEndDeviceAnnceInd)
+			c.updateAddress(announce.NwkAddr, zigbee.MACAddress(announce.IEEEAddr))
+
+			clusterID, data, err := zdp.SerializeFrame(0, &zdp.DeviceAnnce{
+				NWKAddr:    announce.NwkAddr,
+				IEEEAddr:   zigbee.MACAddress(announce.IEEEAddr),
+				Capability: announce.Capabilities,
+			})
+			if err != nil {
+				continue
+			}
+			output <- zigbee.IncomingMessage{
+				Source: c.addresses.Complete(zigbee.Address{
+					Mode:  zigbee.AddressModeNWK,
+					Short: announce.SrcAddr,
+				}),
+				ClusterID: clusterID,
+				Data:      data,
+			}
+		}
+	}()
+
+	// ZDP responses are passed to the waiting request and forwarded to the
+	// application like on other controllers.
+	go func() {
+		defer wg.Done()
+		for {
+			cmd, err := zdpHandler.Receive()
+			if err != nil {
+				break
+			}
+
+			callback := cmd.(ZdoMsgCbIncoming)
+			message := zigbee.IncomingMessage{
+				Source: c.addresses.Complete(zigbee.Address{
+					Mode:  zigbee.AddressModeNWK,
+					Short: callback.SrcAddr,
+				}),
+				ClusterID: callback.ClusterID,
+				Data:      append([]byte{callback.SeqNum}, callback.Data...),
+			}
+			c.deliverZDP(callback.SrcAddr, message)
+			output <- message
+		}
+	}()
+
+	go func() {
+		wg.Wait()
 		close(output)
 	}()
 
@@ -159,10 +314,32 @@ func (c *Controller) Start() (chan zigbee.IncomingMessage, error) {
 }
 
 func (c *Controller) Close() error {
+	c.addressMutex.Lock()
+	if c.expiryTimer != nil {
+		c.expiryTimer.Stop()
+		c.expiryTimer = nil
+	}
+	c.addressMutex.Unlock()
 	return c.port.Close()
 }
 
+// Send sends a message. Destinations given only by IEEE address are resolved
+// to their network address. If the network address is not known, Send does
+// not wait for it. Instead, the message is queued and a network address
+// request is sent. The message is sent when the response arrives. If it does
+// not arrive within addressResolutionTimeout, the message is dropped and
+// ControllerSettings.OnSendError is called with zigbee.ErrAddressUnresolved.
 func (c *Controller) Send(message zigbee.OutgoingMessage) error {
+	if message.Destination.Mode == zigbee.AddressModeIEEE {
+		nwkAddr, ok := c.addresses.NetworkAddress(message.Destination.Extended)
+		if !ok {
+			c.queueMessage(message)
+			return nil
+		}
+		message.Destination.Mode = zigbee.AddressModeCombined
+		message.Destination.Short = nwkAddr
+	}
+
 	if mode := message.Destination.Mode; mode != zigbee.AddressModeNWK && mode != zigbee.AddressModeCombined {
 		return fmt.Errorf("address mode not supported: %v", mode)
 	}
@@ -199,6 +376,423 @@ func (c *Controller) PermitJoining(enabled bool) error {
 	return nil
 }
 
+// zdpResponseClusters contains the clusters of the responses to the requests
+// supported by RequestZDP.
+var zdpResponseClusters = []uint16{
+	zdp.ClusterIEEEAddrRsp,
+	zdp.ClusterNodeDescRsp,
+	zdp.ClusterSimpleDescRsp,
+	zdp.ClusterActiveEPRsp,
+	zdp.ClusterBindRsp,
+	zdp.ClusterUnbindRsp,
+	zdp.ClusterMgmtLqiRsp,
+	zdp.ClusterMgmtRtgRsp,
+	zdp.ClusterMgmtBindRsp,
+}
+
+// RequestZDP sends a ZDP request to the device with the given network address
+// and waits for the response (see zdp.Requester). The ZNP does not send ZDP
+// messages passed to AfDataRequest, therefore requests are translated to the
+// corresponding ZDO commands. Only IEEEAddrReq, NodeDescReq, SimpleDescReq,
+// ActiveEPReq, BindReq, UnbindReq, MgmtLqiReq, MgmtRtgReq and MgmtBindReq are
+// supported, other requests fail with zdp.ErrNotSupported.
+//
+// Concurrent requests to the same device expecting the same response cluster
+// are sent one after the other.
+func (c *Controller) RequestZDP(ctx context.Context, nwkAddr uint16, command interface{}) (interface{}, error) {
+	clusterID, _, err := zdp.SerializeFrame(0, command)
+	if err != nil {
+		return nil, err
+	}
+	request, err := zdoRequest(nwkAddr, command)
+	if err != nil {
+		return nil, err
+	}
+
+	key := zdpWaiterKey{nwkAddr, clusterID | zdp.ClusterResponseFlag}
+	responses, err := c.addZDPWaiter(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer c.removeZDPWaiter(key)
+
+	response, err := c.port.WriteCommand(request)
+	if err != nil {
+		return nil, fmt.Errorf("sending %s: %w", zdp.ClusterName(clusterID), err)
+	}
+	if status := zdoRequestStatus(response); status != 0 {
+		return nil, fmt.Errorf("sending %s: status 0x%02x", zdp.ClusterName(clusterID), status)
+	}
+
+	select {
+	case message := <-responses:
+		_, result, err := zdp.ParseFrame(message.ClusterID, message.Data)
+		return result, err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// zdoRequest returns the ZDO command sending the given ZDP request to the
+// device with the given network address.
+func zdoRequest(nwkAddr uint16, command interface{}) (interface{}, error) {
+	switch cmd := command.(type) {
+	case *zdp.IEEEAddrReq:
+		// The ZNP always asks the destination for its own address.
+		if cmd.NWKAddrOfInterest != nwkAddr {
+			break
+		}
+		return ZdoIEEEAddrRequest{ShortAddr: nwkAddr, ReqType: cmd.RequestType, StartIndex: cmd.StartIndex}, nil
+	case *zdp.NodeDescReq:
+		return ZdoNodeDescRequest{DstAddr: nwkAddr, NWKAddrOfInterest: cmd.NWKAddrOfInterest}, nil
+	case *zdp.SimpleDescReq:
+		return ZdoSimpleDescRequest{DstAddr: nwkAddr, NWKAddrOfInterest: cmd.NWKAddrOfInterest, Endpoint: cmd.Endpoint}, nil
+	case *zdp.ActiveEPReq:
+		return ZdoActiveEPRequest{DstAddr: nwkAddr, NWKAddrOfInterest: cmd.NWKAddrOfInterest}, nil
+	case *zdp.BindReq:
+		mode, address := zdoDestination(cmd.DstAddress)
+		return ZdoBindRequest{
+			DstAddr:     nwkAddr,
+			SrcAddress:  uint64(cmd.SrcAddress),
+			SrcEndpoint: cmd.SrcEndpoint,
+			ClusterID:   cmd.ClusterID,
+			DstAddrMode: mode,
+			DstAddress:  address,
+			DstEndpoint: cmd.DstEndpoint,
+		}, nil
+	case *zdp.UnbindReq:
+		mode, address := zdoDestination(cmd.DstAddress)
+		return ZdoUnbindRequest{
+			DstAddr:     nwkAddr,
+			SrcAddress:  uint64(cmd.SrcAddress),
+			SrcEndpoint: cmd.SrcEndpoint,
+			ClusterID:   cmd.ClusterID,
+			DstAddrMode: mode,
+			DstAddress:  address,
+			DstEndpoint: cmd.DstEndpoint,
+		}, nil
+	case *zdp.MgmtLqiReq:
+		return ZdoMgmtLqiRequest{DstAddr: nwkAddr, StartIndex: cmd.StartIndex}, nil
+	case *zdp.MgmtRtgReq:
+		return ZdoMgmtRtgRequest{DstAddr: nwkAddr, StartIndex: cmd.StartIndex}, nil
+	case *zdp.MgmtBindReq:
+		return ZdoMgmtBindRequest{DstAddr: nwkAddr, StartIndex: cmd.StartIndex}, nil
+	}
+	return nil, fmt.Errorf("%T: %w", command, zdp.ErrNotSupported)
+}
+
+// zdoDestination returns the destination of a binding in the format of
+// ZdoBindRequest. The address has already been validated by
+// zdp.SerializeFrame, so it is either a group or an IEEE address.
+func zdoDestination(address zigbee.Address) (mode uint8, value uint64) {
+	if address.Mode == zigbee.AddressModeGroup {
+		return uint8(zigbee.AddressModeGroup), uint64(address.Short)
+	}
+	return uint8(zigbee.AddressModeIEEE), uint64(address.Extended)
+}
+
+// zdoRequestStatus returns the status of the SRSP to a command returned by
+// zdoRequest.
+func zdoRequestStatus(response interface{}) byte {
+	switch rsp := response.(type) {
+	case ZdoIEEEAddrResponse:
+		return rsp.Status
+	case ZdoNodeDescResponse:
+		return rsp.Status
+	case ZdoSimpleDescResponse:
+		return rsp.Status
+	case ZdoActiveEPResponse:
+		return rsp.Status
+	case ZdoBindResponse:
+		return rsp.Status
+	case ZdoUnbindResponse:
+		return rsp.Status
+	case ZdoMgmtLqiResponse:
+		return rsp.Status
+	case ZdoMgmtRtgResponse:
+		return rsp.Status
+	case ZdoMgmtBindResponse:
+		return rsp.Status
+	}
+	panic(fmt.Sprintf("unexpected response %T", response))
+}
+
+// addZDPWaiter registers a waiter for the given key, waiting until the key is
+// free if another request uses it.
+func (c *Controller) addZDPWaiter(ctx context.Context, key zdpWaiterKey) (chan zigbee.IncomingMessage, error) {
+	for {
+		c.zdpMutex.Lock()
+		if _, ok := c.zdpWaiters[key]; !ok {
+			responses := make(chan zigbee.IncomingMessage, 1)
+			c.zdpWaiters[key] = responses
+			c.zdpMutex.Unlock()
+			return responses, nil
+		}
+		removed := c.zdpWaiterRemoved
+		c.zdpMutex.Unlock()
+
+		select {
+		case <-removed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (c *Controller) removeZDPWaiter(key zdpWaiterKey) {
+	c.zdpMutex.Lock()
+	defer c.zdpMutex.Unlock()
+	delete(c.zdpWaiters, key)
+	close(c.zdpWaiterRemoved)
+	c.zdpWaiterRemoved = make(chan struct{})
+}
+
+// deliverZDP passes a ZDP response to the request waiting for it, if any. It
+// does not block, because it is called while receiving commands from the port.
+func (c *Controller) deliverZDP(srcAddr uint16, message zigbee.IncomingMessage) {
+	c.zdpMutex.Lock()
+	defer c.zdpMutex.Unlock()
+	if responses, ok := c.zdpWaiters[zdpWaiterKey{srcAddr, message.ClusterID}]; ok {
+		select {
+		case responses <- message:
+		default:
+		}
+	}
+}
+
+// IEEEAddress returns the IEEE address of the device with the given network
+// address. If the address is not known, it is requested from the device.
+func (c *Controller) IEEEAddress(ctx context.Context, nwkAddr uint16) (zigbee.MACAddress, error) {
+	var ieeeAddr zigbee.MACAddress
+	err := c.resolveAddress(ctx, func() bool {
+		var ok bool
+		ieeeAddr, ok = c.addresses.IEEEAddress(nwkAddr)
+		return ok
+	}, func() error {
+		return c.requestIEEEAddress(nwkAddr)
+	})
+	return ieeeAddr, err
+}
+
+// NetworkAddress returns the network address of the device with the given
+// IEEE address. If the address is not known, it is requested using a
+// broadcast.
+func (c *Controller) NetworkAddress(ctx context.Context, ieeeAddr zigbee.MACAddress) (uint16, error) {
+	var nwkAddr uint16
+	err := c.resolveAddress(ctx, func() bool {
+		var ok bool
+		nwkAddr, ok = c.addresses.NetworkAddress(ieeeAddr)
+		return ok
+	}, func() error {
+		return c.requestNetworkAddress(ieeeAddr)
+	})
+	return nwkAddr, err
+}
+
+// resolveAddress sends a request if lookup fails and waits until lookup
+// succeeds. Failed requests are not reported by the devices, so this only
+// returns with an error if the context is done.
+func (c *Controller) resolveAddress(ctx context.Context, lookup func() bool, request func() error) error {
+	updated := c.addressUpdates()
+	if lookup() {
+		return nil
+	}
+	if err := request(); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-updated:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		updated = c.addressUpdates()
+		if lookup() {
+			return nil
+		}
+	}
+}
+
+func (c *Controller) addressUpdates() chan struct{} {
+	c.addressMutex.Lock()
+	defer c.addressMutex.Unlock()
+	return c.addressUpdated
+}
+
+func (c *Controller) updateAddress(nwkAddr uint16, ieeeAddr zigbee.MACAddress) {
+	c.addresses.Update(nwkAddr, ieeeAddr)
+	c.addressMutex.Lock()
+	close(c.addressUpdated)
+	c.addressUpdated = make(chan struct{})
+	resolved := c.takePending(ieeeAddr)
+	c.addressMutex.Unlock()
+
+	// Do not block the caller, which receives commands from the port.
+	c.sendResolved(resolved, nwkAddr)
+}
+
+// queueMessage queues a message until the network address of its destination
+// is known and requests the address, unless it has already been requested.
+func (c *Controller) queueMessage(message zigbee.OutgoingMessage) {
+	ieeeAddr := message.Destination.Extended
+
+	c.addressMutex.Lock()
+	// The address may have been updated since Send looked it up.
+	if nwkAddr, ok := c.addresses.NetworkAddress(ieeeAddr); ok {
+		c.addressMutex.Unlock()
+		c.sendResolved([]zigbee.OutgoingMessage{message}, nwkAddr)
+		return
+	}
+	requested := false
+	for _, pending := range c.pending {
+		if pending.message.Destination.Extended == ieeeAddr {
+			requested = true
+		}
+	}
+	c.pending = append(c.pending, pendingMessage{message, time.Now().Add(c.resolutionTimeout)})
+	if c.expiryTimer == nil {
+		c.expiryTimer = time.AfterFunc(c.resolutionTimeout, c.expirePending)
+	}
+	c.addressMutex.Unlock()
+
+	if requested {
+		return
+	}
+	go func() {
+		err := c.requestNetworkAddress(ieeeAddr)
+		if err != nil && c.settings.LogErrors {
+			log.Println("[zigbee]", err)
+		}
+	}()
+}
+
+// takePending removes the pending messages to the given destination from the
+// queue. The addressMutex must be held.
+func (c *Controller) takePending(ieeeAddr zigbee.MACAddress) []zigbee.OutgoingMessage {
+	var taken []zigbee.OutgoingMessage
+	remaining := c.pending[:0]
+	for _, pending := range c.pending {
+		if pending.message.Destination.Extended == ieeeAddr {
+			taken = append(taken, pending.message)
+		} else {
+			remaining = append(remaining, pending)
+		}
+	}
+	c.pending = remaining
+	return taken
+}
+
+// expirePending is called by the expiryTimer. It drops the pending messages
+// whose destination could not be resolved in time and restarts the timer for
+// the next deadline.
+func (c *Controller) expirePending() {
+	c.addressMutex.Lock()
+	if c.expiryTimer == nil {
+		// The controller has been closed.
+		c.addressMutex.Unlock()
+		return
+	}
+	now := time.Now()
+	var expired []zigbee.OutgoingMessage
+	for len(c.pending) != 0 && !now.Before(c.pending[0].deadline) {
+		expired = append(expired, c.pending[0].message)
+		c.pending = c.pending[1:]
+	}
+	if len(c.pending) != 0 {
+		c.expiryTimer.Reset(c.pending[0].deadline.Sub(now))
+	} else {
+		c.expiryTimer = nil
+	}
+	c.addressMutex.Unlock()
+
+	for _, message := range expired {
+		c.sendError(message, zigbee.ErrAddressUnresolved)
+	}
+}
+
+// sendError reports a message which could not be sent in the background.
+func (c *Controller) sendError(message zigbee.OutgoingMessage, err error) {
+	if c.settings.LogErrors {
+		log.Printf("[zigbee] dropping message to %v: %v", message.Destination, err)
+	}
+	if c.settings.OnSendError != nil {
+		c.settings.OnSendError(message, err)
+	}
+}
+
+// sendResolved sends messages to the given network address in the background.
+func (c *Controller) sendResolved(messages []zigbee.OutgoingMessage, nwkAddr uint16) {
+	if len(messages) == 0 {
+		return
+	}
+	go func() {
+		for _, message := range messages {
+			message.Destination.Mode = zigbee.AddressModeCombined
+			message.Destination.Short = nwkAddr
+			if err := c.Send(message); err != nil {
+				c.sendError(message, err)
+			}
+		}
+	}()
+}
+
+// lookupIEEEAddress requests the IEEE address of a device in the background,
+// unless it is known or has been requested recently. It does not block,
+// because it is called while receiving commands from the port, which also
+// delivers the response.
+func (c *Controller) lookupIEEEAddress(nwkAddr uint16) {
+	if !c.addresses.StartLookup(nwkAddr) {
+		return
+	}
+	go func() {
+		err := c.requestIEEEAddress(nwkAddr)
+		if err != nil && c.settings.LogErrors {
+			log.Println("[zigbee]", err)
+		}
+	}()
+}
+
+func (c *Controller) requestNetworkAddress(ieeeAddr zigbee.MACAddress) error {
+	response, err := c.port.WriteCommand(ZdoNwkAddrRequest{IEEEAddress: uint64(ieeeAddr), ReqType: AddrReqTypeSingle})
+	if err != nil {
+		return fmt.Errorf("sending network address request: %w", err)
+	}
+	if status := response.(ZdoNwkAddrResponse).Status; status != 0 {
+		return fmt.Errorf("sending network address request: status 0x%02x", status)
+	}
+	return nil
+}
+
+func (c *Controller) requestIEEEAddress(nwkAddr uint16) error {
+	response, err := c.port.WriteCommand(ZdoIEEEAddrRequest{ShortAddr: nwkAddr, ReqType: AddrReqTypeSingle})
+	if err != nil {
+		return fmt.Errorf("sending IEEE address request: %w", err)
+	}
+	if status := response.(ZdoIEEEAddrResponse).Status; status != 0 {
+		return fmt.Errorf("sending IEEE address request: status 0x%02x", status)
+	}
+	return nil
+}
+
+// handleAddresses calls handle for every received command of the given type.
+func (c *Controller) handleAddresses(commandPrototype interface{}, handle func(command interface{})) {
+	handler := c.RegisterPermanentHandler(commandPrototype)
+	go func() {
+		for {
+			cmd, err := handler.Receive()
+			if err != nil {
+				break
+			}
+			handle(cmd)
+		}
+	}()
+}
+
+// RegisterPermanentHandler registers a handler receiving all commands of the
+// given type (see Port.RegisterPermanentHandler). The controller itself uses
+// handlers for AfIncomingMsg, ZdoEndDeviceAnnceInd, ZdoTcDevInd, ZdoIEEEAddr
+// and ZdoNwkAddr, but these commands are delivered to the handlers of the
+// application as well.
 func (c *Controller) RegisterPermanentHandler(commandPrototype interface{}) *Handler {
 	return c.port.RegisterPermanentHandler(commandPrototype)
 }
@@ -0,0 +1,246 @@
+package znp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/zdp"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+// serve answers all requests of the port like a ZNP which has already formed a
+// network. The SRSP is the zero value of the response, followed by the
+// callbacks returned by respond. All received commands are passed to
+// received, if it is not full.
+func (z *testZNP) serve(received chan<- interface{}, respond func(request interface{}) []interface{}) {
+	for {
+		command, err := z.read()
+		if errors.Is(err, ErrInvalidFrame) || errors.Is(err, ErrGarbage) || err == ErrCommandUnknownFrameHeader {
+			continue
+		}
+		if err != nil {
+			return
+		}
+
+		select {
+		case received <- command:
+		default:
+		}
+
+		header := mustGetHeaderForCommand(command)
+		if header.Type != FRAME_TYPE_SREQ {
+			continue
+		}
+		header.Type = FRAME_TYPE_SRSP
+		response := reflect.New(commandTypeByFrameHeader[header]).Elem().Interface()
+		if _, ok := response.(UtilGetDeviceInfoResponse); ok {
+			response = UtilGetDeviceInfoResponse{DeviceState: DeviceStateCoordinator}
+		}
+		if z.write(response) != nil {
+			return
+		}
+
+		if respond != nil {
+			for _, callback := range respond(command) {
+				if z.write(callback) != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+func startTestController(t *testing.T, received chan<- interface{}, respond func(request interface{}) []interface{}) (*Controller, *testZNP, chan zigbee.IncomingMessage) {
+	port, znp := newTestPort()
+	go znp.serve(received, respond)
+
+	controller := newController(zigbee.ControllerSettings{}, port)
+	output, err := controller.Start()
+	if err != nil {
+		controller.Close()
+		t.Fatal("unexpected err:", err)
+	}
+	return controller, znp, output
+}
+
+func receiveMessage(t *testing.T, output chan zigbee.IncomingMessage) zigbee.IncomingMessage {
+	select {
+	case message := <-output:
+		return message
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+		return zigbee.IncomingMessage{}
+	}
+}
+
+func TestSendUnknownIEEEAddress(t *testing.T) {
+	const ieeeAddr = zigbee.MACAddress(0x00124b0001abcdef)
+
+	received := make(chan interface{}, 64)
+	controller, znp, output := startTestController(t, received, func(request interface{}) []interface{} {
+		if req, ok := request.(ZdoNwkAddrRequest); ok && zigbee.MACAddress(req.IEEEAddress) == ieeeAddr {
+			return []interface{}{ZdoNwkAddr{IEEEAddr: req.IEEEAddress, NwkAddr: 0x1234}}
+		}
+		return nil
+	})
+	defer controller.Close()
+
+	// Enough messages to fill all buffers between the port and the
+	// application, so that the port stalls until the application receives
+	// messages again.
+	const count = 4
+	go func() {
+		for i := 0; i < count; i++ {
+			znp.write(AfIncomingMsg{SrcAddr: 0x5678, SrcEndpoint: 1, DstEndpoint: 1, ClusterID: 0x0006, Data: []byte{byte(i)}})
+		}
+	}()
+	receiveMessage(t, output)
+
+	// Send from the goroutine receiving the messages, which is what the
+	// handlers of a dispatcher do.
+	start := time.Now()
+	err := controller.Send(zigbee.OutgoingMessage{
+		Destination:         zigbee.Address{Mode: zigbee.AddressModeIEEE, Extended: ieeeAddr},
+		DestinationEndpoint: 1,
+		SourceEndpoint:      1,
+		ClusterID:           0x0006,
+		Radius:              zigbee.DefaultRadius,
+		Data:                []byte{0x01, 0x02, 0x03},
+	})
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Send blocked for %v", elapsed)
+	}
+
+	for i := 1; i < count; i++ {
+		receiveMessage(t, output)
+	}
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case command := <-received:
+			if request, ok := command.(AfDataRequest); ok {
+				if request.DstAddr != 0x1234 || request.ClusterID != 0x0006 {
+					t.Errorf("wrong request: %+v", request)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("message not sent")
+		}
+	}
+}
+
+func TestSendUnresolvedAddress(t *testing.T) {
+	port, znp := newTestPort()
+	go znp.serve(nil, nil)
+
+	errs := make(chan error, 1)
+	controller := newController(zigbee.ControllerSettings{
+		OnSendError: func(message zigbee.OutgoingMessage, err error) {
+			errs <- err
+		},
+	}, port)
+	controller.resolutionTimeout = 20 * time.Millisecond
+	output, err := controller.Start()
+	if err != nil {
+		controller.Close()
+		t.Fatal("unexpected err:", err)
+	}
+	defer controller.Close()
+	go func() {
+		for range output {
+		}
+	}()
+
+	err = controller.Send(zigbee.OutgoingMessage{
+		Destination:         zigbee.Address{Mode: zigbee.AddressModeIEEE, Extended: 0x00124b0001abcdef},
+		DestinationEndpoint: 1,
+		SourceEndpoint:      1,
+		ClusterID:           0x0006,
+		Radius:              zigbee.DefaultRadius,
+	})
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	// No further traffic is required to drop the message.
+	select {
+	case err := <-errs:
+		if err != zigbee.ErrAddressUnresolved {
+			t.Errorf("expected ErrAddressUnresolved, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message not dropped")
+	}
+
+	controller.addressMutex.Lock()
+	defer controller.addressMutex.Unlock()
+	if len(controller.pending) != 0 || controller.expiryTimer != nil {
+		t.Errorf("pending messages not cleaned up: %+v", controller.pending)
+	}
+}
+
+func TestRequestZDP(t *testing.T) {
+	received := make(chan interface{}, 64)
+	controller, _, output := startTestController(t, received, func(request interface{}) []interface{} {
+		if req, ok := request.(ZdoNodeDescRequest); ok && req.DstAddr == 0x1234 {
+			return []interface{}{
+				// The ZNP also sends the dedicated callback, which must not
+				// be confused with the forwarded ZDP frame.
+				ZdoNodeDesc{SrcAddr: 0x1234, Payload: []byte{0x00, 0x34, 0x12}},
+				ZdoMsgCbIncoming{
+					SrcAddr:   0x1234,
+					ClusterID: zdp.ClusterNodeDescRsp,
+					SeqNum:    0x17,
+					Data:      []byte{0x00, 0x34, 0x12, 0x01, 0x40, 0x8e, 0x7c, 0x11, 0x52, 0x52, 0x00, 0x00, 0x2c, 0x52, 0x00, 0x00},
+				},
+			}
+		}
+		return nil
+	})
+	defer controller.Close()
+
+	registered := make(map[uint16]bool)
+	for len(registered) < len(zdpResponseClusters) {
+		select {
+		case command := <-received:
+			if request, ok := command.(ZdoMsgCbRegisterRequest); ok {
+				registered[request.ClusterID] = true
+			}
+		case <-time.After(time.Second):
+			t.Fatal("ZDO callbacks not registered:", registered)
+		}
+	}
+
+	// The forwarded response is also passed to the application.
+	go func() {
+		for range output {
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	response, err := controller.RequestZDP(ctx, 0x1234, &zdp.NodeDescReq{NWKAddrOfInterest: 0x1234})
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	rsp, ok := response.(*zdp.NodeDescRsp)
+	if !ok {
+		t.Fatalf("wrong response: %#v", response)
+	}
+	if rsp.Status != zdp.StatusSuccess || rsp.NWKAddrOfInterest != 0x1234 || rsp.NodeDescriptor.ManufacturerCode != 0x117c {
+		t.Errorf("wrong response: %+v", rsp)
+	}
+
+	_, err = controller.RequestZDP(ctx, 0x1234, &zdp.PowerDescReq{NWKAddrOfInterest: 0x1234})
+	if !errors.Is(err, zdp.ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported, got %v", err)
+	}
+}
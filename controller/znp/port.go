@@ -66,8 +66,9 @@ type Port struct {
 	sp  io.ReadWriteCloser
 	cbs Callbacks
 
-	handlerMutex sync.Mutex
-	handlers     map[FrameHeader]*Handler
+	handlerMutex      sync.Mutex
+	handlers          map[FrameHeader]*Handler // one-off handlers
+	permanentHandlers map[FrameHeader][]*Handler
 }
 
 func NewPort(name string, callbacks Callbacks) (*Port, error) {
@@ -85,17 +86,22 @@ func NewPort(name string, callbacks Callbacks) (*Port, error) {
 		return nil, err
 	}
 
+	return newPort(sp, callbacks), nil
+}
+
+func newPort(sp io.ReadWriteCloser, callbacks Callbacks) *Port {
 	port := &Port{
 		sp:  sp,
 		cbs: callbacks,
 
-		handlerMutex: sync.Mutex{},
-		handlers:     make(map[FrameHeader]*Handler),
+		handlerMutex:      sync.Mutex{},
+		handlers:          make(map[FrameHeader]*Handler),
+		permanentHandlers: make(map[FrameHeader][]*Handler),
 	}
 
 	go port.loop()
 
-	return port, nil
+	return port
 }
 
 func (p *Port) Close() error {
@@ -103,13 +109,23 @@ func (p *Port) Close() error {
 }
 
 func (p *Port) RegisterOneOffHandler(commandPrototype interface{}) *Handler {
-	header := getHeaderForCommand(commandPrototype)
+	header := mustGetHeaderForCommand(commandPrototype)
 	return p.registerHandler(header, 10*time.Second)
 }
 
+// RegisterPermanentHandler registers a handler receiving all commands of the
+// given type. Multiple permanent handlers can be registered for the same
+// command type, each of them receives every command. The port waits until a
+// handler has received the previous command, so handlers must be drained
+// continuously. It panics if the command type has not been registered.
 func (p *Port) RegisterPermanentHandler(commandPrototype interface{}) *Handler {
-	header := getHeaderForCommand(commandPrototype)
-	return p.registerHandler(header, 0)
+	header := mustGetHeaderForCommand(commandPrototype)
+	handler := newHandler()
+
+	p.handlerMutex.Lock()
+	defer p.handlerMutex.Unlock()
+	p.permanentHandlers[header] = append(p.permanentHandlers[header], handler)
+	return handler
 }
 
 func (p *Port) registerHandler(header FrameHeader, timeout time.Duration) *Handler {
@@ -174,7 +190,10 @@ func (p *Port) WriteCommandTimeout(command interface{}, timeout time.Duration) (
 		p.cbs.BeforeWrite(command)
 	}
 
-	frame := buildFrameForCommand(command)
+	frame, err := buildFrameForCommand(command)
+	if err != nil {
+		return nil, err
+	}
 
 	var handler *Handler
 
@@ -184,7 +203,7 @@ func (p *Port) WriteCommandTimeout(command interface{}, timeout time.Duration) (
 		handler = p.registerHandler(responseHeader, timeout)
 	}
 
-	err := writeFrame(p.sp, frame)
+	err = writeFrame(p.sp, frame)
 	if err != nil {
 		return nil, err
 	}
@@ -241,13 +260,17 @@ func (p *Port) loop() {
 
 		p.handlerMutex.Lock()
 		handler := p.handlers[frame.FrameHeader]
-		if handler != nil && handler.timer != nil {
+		if handler != nil {
 			delete(p.handlers, frame.FrameHeader)
 		}
+		permanentHandlers := p.permanentHandlers[frame.FrameHeader]
 		p.handlerMutex.Unlock()
 
 		if handler != nil {
 			handler.fulfill(command)
 		}
+		for _, handler := range permanentHandlers {
+			handler.fulfill(command)
+		}
 	}
 }
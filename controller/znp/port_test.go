@@ -0,0 +1,90 @@
+package znp
+
+import (
+	"bufio"
+	"io"
+	"testing"
+	"time"
+)
+
+// testZNP simulates the device on the other side of a port.
+type testZNP struct {
+	reader *bufio.Reader
+	writer io.WriteCloser
+}
+
+// testConnection is the serial connection of the port to a testZNP.
+type testConnection struct {
+	io.Reader
+	io.Writer
+	closers []io.Closer
+}
+
+func (c *testConnection) Close() error {
+	for _, closer := range c.closers {
+		closer.Close()
+	}
+	return nil
+}
+
+func newTestPort() (*Port, *testZNP) {
+	toPort, fromZNP := io.Pipe()
+	toZNP, fromPort := io.Pipe()
+	connection := &testConnection{
+		Reader:  toPort,
+		Writer:  fromPort,
+		closers: []io.Closer{toPort, fromPort},
+	}
+	port := newPort(connection, Callbacks{
+		OnReadError: func(err error) ErrorHandling { return ErrorHandlingStop },
+	})
+	return port, &testZNP{reader: bufio.NewReader(toZNP), writer: fromZNP}
+}
+
+func (z *testZNP) write(command interface{}) error {
+	frame, err := buildFrameForCommand(command)
+	if err != nil {
+		return err
+	}
+	return writeFrame(z.writer, frame)
+}
+
+func (z *testZNP) read() (interface{}, error) {
+	frame, err := readFrame(z.reader)
+	if err != nil {
+		return nil, err
+	}
+	return parseCommandFromFrame(frame)
+}
+
+func receive(t *testing.T, handler *Handler) interface{} {
+	select {
+	case result := <-handler.results:
+		if result.err != nil {
+			t.Fatal("unexpected err:", result.err)
+		}
+		return result.command
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+		return nil
+	}
+}
+
+func TestPermanentHandlers(t *testing.T) {
+	port, znp := newTestPort()
+	defer port.Close()
+
+	first := port.RegisterPermanentHandler(ZdoTcDevInd{})
+	second := port.RegisterPermanentHandler(ZdoTcDevInd{})
+
+	for i := uint16(1); i <= 2; i++ {
+		if err := znp.write(ZdoTcDevInd{SrcNwkAddr: i}); err != nil {
+			t.Fatal("unexpected err:", err)
+		}
+		for _, handler := range []*Handler{first, second} {
+			if ind := receive(t, handler).(ZdoTcDevInd); ind.SrcNwkAddr != i {
+				t.Errorf("wrong command: %+v", ind)
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Package defaultresponse sends ZCL Default Responses for incoming commands.
+//
+// The ZigBee Cluster Library requires a Default Response for every command
+// that does not have the DisableDefaultResponse bit set and for which no
+// other response is generated. Some devices (notably Xiaomi and some Tuya
+// devices) repeat their reports or even leave the network if the response
+// is missing.
+//
+// The Responder is opt-in. It must be created after all other handlers of the
+// dispatcher have been added, because it only sees messages which have not
+// been consumed by another handler (and therefore have not been answered).
+package defaultresponse
+
+import (
+	"github.com/GreenLightning/zigbee-conductor/dispatch"
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+// Responder sends a Default Response with zcl.StatusSuccess for every incoming
+// unicast report and cluster-specific command which requires one (see
+// Required). Commands which are answered with a specific response command (see
+// zcl.HasResponseCommand) are left to the application. The Responder does not
+// consume any messages, so they are still passed on to the application.
+type Responder struct {
+	dispatcher *dispatch.Dispatcher
+}
+
+// NewResponder creates a Responder and registers it as a handler with the dispatcher.
+func NewResponder(dispatcher *dispatch.Dispatcher) *Responder {
+	responder := &Responder{dispatcher: dispatcher}
+	dispatcher.AddHandler(responder)
+	return responder
+}
+
+// HandleMessage sends a Default Response if required. It always returns false.
+func (r *Responder) HandleMessage(message zigbee.IncomingMessage) bool {
+	if message.Broadcast {
+		return false
+	}
+
+	frame, err := zcl.ParseFrame(message.Data)
+	if err != nil || !Required(frame) || zcl.HasResponseCommand(zcl.ClusterID(message.ClusterID), frame) {
+		return false
+	}
+
+	response := Frame(frame, zcl.StatusSuccess)
+
+	outgoing := zigbee.OutgoingMessage{
+		Destination:         zigbee.Address{Mode: zigbee.AddressModeNWK, Short: message.Source.Short},
+		DestinationEndpoint: message.SourceEndpoint,
+		SourceEndpoint:      message.DestinationEndpoint,
+		ClusterID:           message.ClusterID,
+		Radius:              zigbee.DefaultRadius,
+		Data:                zcl.SerializeFrame(response),
+	}
+
+	// Must not block the dispatcher.
+	go r.dispatcher.Send(outgoing)
+
+	return false
+}
+
+// Required reports whether a Default Response has to be sent for the frame
+// (assuming no other response is sent). Default Responses are never sent for
+// frames with the DisableDefaultResponse bit set. Of the global commands only
+// reports require one; the other global commands are either responses
+// themselves or requests with a specific response (e.g. Read Attributes),
+// which is left to the application or package zclserver.
+func Required(frame zcl.Frame) bool {
+	if frame.DisableDefaultResponse {
+		return false
+	}
+	if frame.Type != zcl.FrameTypeGlobal {
+		return true
+	}
+	return frame.CommandID == zcl.CommandReportAttributes
+}
+
+// Frame returns the Default Response to the frame with the given status. The
+// response has the same transaction sequence number and manufacturer code as
+// the frame and is sent in the opposite direction.
+func Frame(frame zcl.Frame, status zcl.Status) zcl.Frame {
+	return zcl.Frame{
+		FrameHeader: zcl.FrameHeader{
+			Type:                    zcl.FrameTypeGlobal,
+			ManufacturerSpecific:    frame.ManufacturerSpecific,
+			DirectionServerToClient: !frame.DirectionServerToClient,
+			DisableDefaultResponse:  true,
+			ManufacturerCode:        frame.ManufacturerCode,
+			TransSeqNumber:          frame.TransSeqNumber,
+			CommandID:               zcl.CommandDefaultResponse,
+		},
+		Data: zcl.SerializeDefaultResponseCommand(zcl.DefaultResponseCommand{
+			CommandID: frame.CommandID,
+			Status:    status,
+		}),
+	}
+}
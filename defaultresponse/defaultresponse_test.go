@@ -0,0 +1,178 @@
+package defaultresponse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/dispatch"
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+func TestRequired(t *testing.T) {
+	type TestCase struct {
+		Header   zcl.FrameHeader
+		Required bool
+	}
+
+	testCases := []TestCase{
+		TestCase{zcl.FrameHeader{Type: zcl.FrameTypeGlobal, CommandID: zcl.CommandReportAttributes}, true},
+		TestCase{zcl.FrameHeader{Type: zcl.FrameTypeGlobal, CommandID: zcl.CommandReportAttributes, DisableDefaultResponse: true}, false},
+		TestCase{zcl.FrameHeader{Type: zcl.FrameTypeGlobal, CommandID: zcl.CommandReadAttributesResponse}, false},
+		TestCase{zcl.FrameHeader{Type: zcl.FrameTypeGlobal, CommandID: zcl.CommandDefaultResponse}, false},
+		TestCase{zcl.FrameHeader{Type: zcl.FrameTypeGlobal, CommandID: zcl.CommandReadAttributes}, false},
+		TestCase{zcl.FrameHeader{Type: zcl.FrameTypeGlobal, CommandID: zcl.CommandWriteAttributes}, false},
+		TestCase{zcl.FrameHeader{Type: zcl.FrameTypeGlobal, CommandID: zcl.CommandConfigureReporting}, false},
+		TestCase{zcl.FrameHeader{Type: zcl.FrameTypeLocal, CommandID: zcl.CommandOnOffToggle}, true},
+	}
+
+	for _, tc := range testCases {
+		if actual := Required(zcl.Frame{FrameHeader: tc.Header}); actual != tc.Required {
+			t.Errorf("%+v: got %v, expected %v", tc.Header, actual, tc.Required)
+		}
+	}
+}
+
+// testDevice sends reports to the coordinator and records the responses.
+type testDevice struct {
+	incoming chan zigbee.IncomingMessage
+	sent     chan zigbee.OutgoingMessage
+}
+
+func (d *testDevice) Start() (chan zigbee.IncomingMessage, error) { return d.incoming, nil }
+func (d *testDevice) Close() error                                { close(d.incoming); return nil }
+func (d *testDevice) PermitJoining(enabled bool) error            { return nil }
+
+func (d *testDevice) Send(message zigbee.OutgoingMessage) error {
+	d.sent <- message
+	return nil
+}
+
+func TestResponder(t *testing.T) {
+	device := &testDevice{
+		incoming: make(chan zigbee.IncomingMessage, 16),
+		sent:     make(chan zigbee.OutgoingMessage, 16),
+	}
+	dispatcher := dispatch.New(device)
+	output, err := dispatcher.Start()
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	NewResponder(dispatcher)
+
+	// Requests with a specific response are not answered.
+	read := zcl.Frame{
+		FrameHeader: zcl.FrameHeader{
+			Type:           zcl.FrameTypeGlobal,
+			TransSeqNumber: 41,
+			CommandID:      zcl.CommandReadAttributes,
+		},
+		Data: zcl.SerializeReadAttributesCommand(zcl.ReadAttributesCommand{Attributes: []zcl.AttributeID{0x0000}}),
+	}
+	device.incoming <- zigbee.IncomingMessage{
+		Source:              zigbee.Address{Mode: zigbee.AddressModeNWK, Short: 0x1234},
+		SourceEndpoint:      1,
+		DestinationEndpoint: 1,
+		ClusterID:           uint16(zcl.ClusterGeneralBasic),
+		Data:                zcl.SerializeFrame(read),
+	}
+	select {
+	case <-output:
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+
+	// Neither are cluster-specific commands with a specific response.
+	query := zcl.NewClusterCommandFrame(&zcl.QueryNextImageRequestCommand{ManufacturerCode: 0x115f})
+	query.TransSeqNumber = 43
+	device.incoming <- zigbee.IncomingMessage{
+		Source:              zigbee.Address{Mode: zigbee.AddressModeNWK, Short: 0x1234},
+		SourceEndpoint:      1,
+		DestinationEndpoint: 1,
+		ClusterID:           uint16(zcl.ClusterGeneralOTAUpgrade),
+		Data:                zcl.SerializeFrame(query),
+	}
+	select {
+	case <-output:
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+
+	// Nor are broadcast messages.
+	broadcast := zcl.NewClusterCommandFrame(&zcl.ToggleCommand{})
+	broadcast.TransSeqNumber = 44
+	device.incoming <- zigbee.IncomingMessage{
+		Source:              zigbee.Address{Mode: zigbee.AddressModeNWK, Short: 0x1234},
+		SourceEndpoint:      1,
+		DestinationEndpoint: 1,
+		ClusterID:           uint16(zcl.ClusterGeneralOnOff),
+		Broadcast:           true,
+		Data:                zcl.SerializeFrame(broadcast),
+	}
+	select {
+	case <-output:
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+
+	report := zcl.Frame{
+		FrameHeader: zcl.FrameHeader{
+			Type:                    zcl.FrameTypeGlobal,
+			ManufacturerSpecific:    true,
+			DirectionServerToClient: true,
+			ManufacturerCode:        0x115f,
+			TransSeqNumber:          42,
+			CommandID:               zcl.CommandReportAttributes,
+		},
+	}
+	device.incoming <- zigbee.IncomingMessage{
+		Source:              zigbee.Address{Mode: zigbee.AddressModeNWK, Short: 0x1234},
+		SourceEndpoint:      1,
+		DestinationEndpoint: 1,
+		ClusterID:           uint16(zcl.ClusterGeneralBasic),
+		Data:                zcl.SerializeFrame(report),
+	}
+
+	// The report is still passed on to the application.
+	select {
+	case <-output:
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+
+	var message zigbee.OutgoingMessage
+	select {
+	case message = <-device.sent:
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+
+	if message.Destination.Short != 0x1234 || message.ClusterID != uint16(zcl.ClusterGeneralBasic) {
+		t.Errorf("wrong addressing: %+v", message)
+	}
+
+	frame, err := zcl.ParseFrame(message.Data)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	expected := zcl.FrameHeader{
+		Type:                   zcl.FrameTypeGlobal,
+		ManufacturerSpecific:   true,
+		DisableDefaultResponse: true,
+		ManufacturerCode:       0x115f,
+		TransSeqNumber:         42,
+		CommandID:              zcl.CommandDefaultResponse,
+	}
+	if frame.FrameHeader != expected {
+		t.Errorf("got %+v, expected %+v", frame.FrameHeader, expected)
+	}
+	cmd, err := zcl.ParseDefaultResponseCommand(frame.Data)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if cmd.CommandID != zcl.CommandReportAttributes || cmd.Status != zcl.StatusSuccess {
+		t.Errorf("unexpected default response: %+v", cmd)
+	}
+}
@@ -0,0 +1,444 @@
+// Package devicedb remembers the devices of the network across restarts.
+//
+// Devices are identified by their IEEE address, because the network address
+// can change when a device rejoins the network. The DB keeps the mapping
+// between both addresses, the results of interviews (see package interview),
+// the time a device was last seen, the link quality of its last message and
+// the last known values of its attributes.
+//
+// The DB is registered as a handler with the dispatcher and updates itself
+// from the incoming messages: device announcements update the address
+// mapping, every message updates the last-seen time and link quality, and
+// attribute reports and read attributes responses update the attribute values.
+// Responses to requests made through the dispatcher are consumed by the
+// waiting request and are therefore not seen by the DB. The DB does not
+// consume any messages, but it should be created before other handlers, so
+// that it sees all messages.
+//
+// Changes are written to a pluggable Storage in the background. JSONFile and
+// KVStore are provided.
+package devicedb
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/dispatch"
+	"github.com/GreenLightning/zigbee-conductor/interview"
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zdp"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+// flushDelay is the time changes from incoming messages are collected before
+// they are written to the storage.
+var flushDelay = 5 * time.Second
+
+// A Device is the stored state of a device. It only consists of plain data,
+// so it can be persisted using encoding/json.
+type Device struct {
+	IEEEAddress    zigbee.MACAddress
+	NetworkAddress uint16
+
+	// Description contains the results of the last interview. The
+	// endpoints and clusters of the device are listed in
+	// Description.Endpoints.
+	Description interview.DeviceDescription
+
+	LastSeen    time.Time
+	LinkQuality uint8
+
+	Attributes []Attribute
+}
+
+// Interviewed returns true if Description contains the results of an interview.
+func (d *Device) Interviewed() bool {
+	return !d.Description.InterviewedAt.IsZero()
+}
+
+// Attribute returns the last known value of a standard (not manufacturer
+// specific) attribute.
+func (d *Device) Attribute(endpoint uint8, clusterID zcl.ClusterID, attributeID zcl.AttributeID) (Attribute, bool) {
+	for _, attribute := range d.Attributes {
+		if attribute.Endpoint == endpoint && attribute.ClusterID == clusterID && attribute.AttributeID == attributeID && attribute.ManufacturerCode == 0 {
+			return attribute, true
+		}
+	}
+	return Attribute{}, false
+}
+
+func (d *Device) clone() Device {
+	result := *d
+	result.Attributes = append([]Attribute(nil), d.Attributes...)
+	return result
+}
+
+// An Attribute is the last known value of an attribute. The value is stored
+// in its serialized form, so that the data type is preserved.
+type Attribute struct {
+	Endpoint         uint8
+	ClusterID        zcl.ClusterID
+	ManufacturerCode uint16 // 0 for standard attributes
+	AttributeID      zcl.AttributeID
+	DataType         zcl.DataType
+	Data             []byte
+	Updated          time.Time
+}
+
+// Value parses the stored value (see zcl.ParseValue).
+func (a Attribute) Value() (interface{}, error) {
+	value, _, err := zcl.ParseValue(a.DataType, a.Data)
+	return value, err
+}
+
+type DB struct {
+	storage Storage
+
+	mutex      sync.Mutex
+	devices    map[zigbee.MACAddress]*Device
+	byNWK      map[uint16]zigbee.MACAddress
+	dirty      map[zigbee.MACAddress]struct{}
+	storageErr error
+
+	// Serializes calls to the storage.
+	storageMutex sync.Mutex
+
+	flushDelay time.Duration
+	changed    chan struct{}
+	done       chan struct{}
+	stopped    chan struct{}
+}
+
+// Open loads the devices from the storage and registers the DB as a handler
+// with the dispatcher. The DB takes ownership of the storage.
+func Open(dispatcher *dispatch.Dispatcher, storage Storage) (*DB, error) {
+	devices, err := storage.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	db := &DB{
+		storage:    storage,
+		devices:    make(map[zigbee.MACAddress]*Device),
+		byNWK:      make(map[uint16]zigbee.MACAddress),
+		dirty:      make(map[zigbee.MACAddress]struct{}),
+		flushDelay: flushDelay,
+		changed:    make(chan struct{}, 1),
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+	for i := range devices {
+		device := &devices[i]
+		db.devices[device.IEEEAddress] = device
+		db.byNWK[device.NetworkAddress] = device.IEEEAddress
+	}
+
+	go db.run()
+	dispatcher.AddHandler(db)
+	return db, nil
+}
+
+func (db *DB) run() {
+	defer close(db.stopped)
+	for {
+		select {
+		case <-db.changed:
+		case <-db.done:
+			return
+		}
+		select {
+		case <-time.After(db.flushDelay):
+		case <-db.done:
+			return
+		}
+		if err := db.Flush(); err != nil {
+			// Keep the first error for the next call to Flush or Close.
+			db.mutex.Lock()
+			if db.storageErr == nil {
+				db.storageErr = err
+			}
+			db.mutex.Unlock()
+		}
+	}
+}
+
+// Close writes all pending changes and closes the storage. Incoming messages
+// are ignored afterwards.
+func (db *DB) Close() error {
+	db.mutex.Lock()
+	select {
+	case <-db.done:
+		db.mutex.Unlock()
+		return nil
+	default:
+		close(db.done)
+	}
+	db.mutex.Unlock()
+	<-db.stopped
+
+	err := db.Flush()
+	if closeErr := db.storage.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Flush writes all pending changes to the storage. It returns the first error
+// which occurred while writing in the background since the last call.
+func (db *DB) Flush() error {
+	db.storageMutex.Lock()
+	defer db.storageMutex.Unlock()
+
+	db.mutex.Lock()
+	var devices []Device
+	for ieee := range db.dirty {
+		devices = append(devices, db.devices[ieee].clone())
+	}
+	db.dirty = make(map[zigbee.MACAddress]struct{})
+	err := db.storageErr
+	db.storageErr = nil
+	db.mutex.Unlock()
+
+	if len(devices) == 0 {
+		return err
+	}
+
+	sortDevices(devices)
+	if saveErr := db.storage.Save(devices); saveErr != nil {
+		// Try again with the next flush.
+		db.mutex.Lock()
+		for _, device := range devices {
+			if _, ok := db.devices[device.IEEEAddress]; ok {
+				db.dirty[device.IEEEAddress] = struct{}{}
+			}
+		}
+		db.mutex.Unlock()
+		if err == nil {
+			err = saveErr
+		}
+	}
+	return err
+}
+
+// Devices returns all known devices ordered by IEEE address.
+func (db *DB) Devices() []Device {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	devices := make([]Device, 0, len(db.devices))
+	for _, device := range db.devices {
+		devices = append(devices, device.clone())
+	}
+	sortDevices(devices)
+	return devices
+}
+
+// Lookup returns the device with the given IEEE address.
+func (db *DB) Lookup(ieee zigbee.MACAddress) (Device, bool) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	device, ok := db.devices[ieee]
+	if !ok {
+		return Device{}, false
+	}
+	return device.clone(), true
+}
+
+// LookupNetworkAddress returns the device currently using the given network address.
+func (db *DB) LookupNetworkAddress(nwkAddr uint16) (Device, bool) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	ieee, ok := db.byNWK[nwkAddr]
+	if !ok {
+		return Device{}, false
+	}
+	return db.devices[ieee].clone(), true
+}
+
+// UpdateAddress records that the device with the given IEEE address uses the
+// given network address. The device is added if it is not known yet.
+func (db *DB) UpdateAddress(ieee zigbee.MACAddress, nwkAddr uint16) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	db.markDirty(db.setAddress(ieee, nwkAddr))
+}
+
+// StoreInterview stores the results of an interview and writes them to the
+// storage immediately.
+func (db *DB) StoreInterview(description interview.DeviceDescription) error {
+	db.mutex.Lock()
+	device := db.setAddress(description.IEEEAddress, description.NetworkAddress)
+	device.Description = description
+	db.markDirty(device)
+	db.mutex.Unlock()
+	return db.Flush()
+}
+
+// Remove forgets a device, for example after it has left the network.
+func (db *DB) Remove(ieee zigbee.MACAddress) error {
+	db.storageMutex.Lock()
+	defer db.storageMutex.Unlock()
+
+	db.mutex.Lock()
+	if device, ok := db.devices[ieee]; ok {
+		if db.byNWK[device.NetworkAddress] == ieee {
+			delete(db.byNWK, device.NetworkAddress)
+		}
+		delete(db.devices, ieee)
+		delete(db.dirty, ieee)
+	}
+	db.mutex.Unlock()
+
+	return db.storage.Delete(ieee)
+}
+
+// HandleMessage updates the DB from an incoming message. It always returns
+// false. Messages from devices whose IEEE address is unknown are ignored.
+func (db *DB) HandleMessage(message zigbee.IncomingMessage) bool {
+	now := time.Now()
+
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	select {
+	case <-db.done:
+		return false
+	default:
+	}
+
+	var device *Device
+	switch message.Source.Mode {
+	case zigbee.AddressModeCombined:
+		device = db.setAddress(message.Source.Extended, message.Source.Short)
+	case zigbee.AddressModeIEEE:
+		device = db.devices[message.Source.Extended]
+	case zigbee.AddressModeNWK:
+		if ieee, ok := db.byNWK[message.Source.Short]; ok {
+			device = db.devices[ieee]
+		}
+	}
+
+	if message.SourceEndpoint == 0 && message.ClusterID == zdp.ClusterDeviceAnnce {
+		if _, command, err := zdp.ParseFrame(message.ClusterID, message.Data); err == nil {
+			if announce, ok := command.(*zdp.DeviceAnnce); ok {
+				device = db.setAddress(announce.IEEEAddr, announce.NWKAddr)
+			}
+		}
+	}
+
+	if device == nil {
+		return false
+	}
+
+	device.LastSeen = now
+	device.LinkQuality = message.LinkQuality
+	if message.SourceEndpoint != 0 {
+		updateAttributes(device, message, now)
+	}
+	db.markDirty(device)
+
+	return false
+}
+
+// setAddress returns the device with the given IEEE address, adding it if
+// necessary, and updates its network address. The mutex must be held.
+func (db *DB) setAddress(ieee zigbee.MACAddress, nwkAddr uint16) *Device {
+	device, ok := db.devices[ieee]
+	if !ok {
+		device = &Device{IEEEAddress: ieee, NetworkAddress: nwkAddr}
+		db.devices[ieee] = device
+	}
+
+	if db.byNWK[device.NetworkAddress] == ieee {
+		delete(db.byNWK, device.NetworkAddress)
+	}
+	device.NetworkAddress = nwkAddr
+
+	// The network address is now used by this device. A device that used it
+	// before must have left or changed its address.
+	db.byNWK[nwkAddr] = ieee
+
+	return device
+}
+
+// markDirty schedules the device to be written. The mutex must be held.
+func (db *DB) markDirty(device *Device) {
+	db.dirty[device.IEEEAddress] = struct{}{}
+	select {
+	case db.changed <- struct{}{}:
+	default:
+	}
+}
+
+// updateAttributes stores the values of attribute reports and read attributes
+// responses.
+func updateAttributes(device *Device, message zigbee.IncomingMessage, now time.Time) {
+	frame, err := zcl.ParseFrame(message.Data)
+	if err != nil || frame.Type != zcl.FrameTypeGlobal {
+		return
+	}
+
+	manufacturerCode := uint16(0)
+	if frame.ManufacturerSpecific {
+		manufacturerCode = frame.ManufacturerCode
+	}
+
+	set := func(attributeID zcl.AttributeID, dataType zcl.DataType, value interface{}) {
+		data, err := zcl.SerializeValue(dataType, value)
+		if err != nil {
+			return
+		}
+		attribute := Attribute{
+			Endpoint:         message.SourceEndpoint,
+			ClusterID:        zcl.ClusterID(message.ClusterID),
+			ManufacturerCode: manufacturerCode,
+			AttributeID:      attributeID,
+			DataType:         dataType,
+			Data:             data,
+			Updated:          now,
+		}
+		for i, existing := range device.Attributes {
+			if existing.Endpoint == attribute.Endpoint && existing.ClusterID == attribute.ClusterID &&
+				existing.ManufacturerCode == attribute.ManufacturerCode && existing.AttributeID == attribute.AttributeID {
+				device.Attributes[i] = attribute
+				return
+			}
+		}
+		device.Attributes = append(device.Attributes, attribute)
+		sort.Slice(device.Attributes, func(i, j int) bool {
+			a, b := device.Attributes[i], device.Attributes[j]
+			if a.Endpoint != b.Endpoint {
+				return a.Endpoint < b.Endpoint
+			}
+			if a.ClusterID != b.ClusterID {
+				return a.ClusterID < b.ClusterID
+			}
+			if a.ManufacturerCode != b.ManufacturerCode {
+				return a.ManufacturerCode < b.ManufacturerCode
+			}
+			return a.AttributeID < b.AttributeID
+		})
+	}
+
+	switch frame.CommandID {
+	case zcl.CommandReportAttributes:
+		cmd, err := zcl.ParseReportAttributesCommand(frame.Data)
+		if err != nil {
+			return
+		}
+		for _, report := range cmd.Reports {
+			set(report.AttributeID, report.DataType, report.Value)
+		}
+
+	case zcl.CommandReadAttributesResponse:
+		cmd, err := zcl.ParseReadAttributesResponseCommand(frame.Data)
+		if err != nil {
+			return
+		}
+		for _, record := range cmd.Records {
+			if record.Status == zcl.StatusSuccess {
+				set(record.AttributeID, record.DataType, record.Value)
+			}
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package devicedb
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/dispatch"
+	"github.com/GreenLightning/zigbee-conductor/interview"
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zdp"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+type testDevice struct {
+	incoming chan zigbee.IncomingMessage
+}
+
+func (d *testDevice) Start() (chan zigbee.IncomingMessage, error) { return d.incoming, nil }
+func (d *testDevice) Close() error                                { close(d.incoming); return nil }
+func (d *testDevice) Send(message zigbee.OutgoingMessage) error   { return nil }
+func (d *testDevice) PermitJoining(enabled bool) error            { return nil }
+
+// memoryStorage keeps the saved devices in memory.
+type memoryStorage struct {
+	devices map[zigbee.MACAddress]Device
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{devices: make(map[zigbee.MACAddress]Device)}
+}
+
+func (s *memoryStorage) Load() ([]Device, error) {
+	var devices []Device
+	for _, device := range s.devices {
+		devices = append(devices, device)
+	}
+	sortDevices(devices)
+	return devices, nil
+}
+
+func (s *memoryStorage) Save(devices []Device) error {
+	for _, device := range devices {
+		s.devices[device.IEEEAddress] = device
+	}
+	return nil
+}
+
+func (s *memoryStorage) Delete(ieee zigbee.MACAddress) error {
+	delete(s.devices, ieee)
+	return nil
+}
+
+func (s *memoryStorage) Close() error { return nil }
+
+// failingStorage fails to save the given number of times and signals each
+// failure on the failed channel.
+type failingStorage struct {
+	*memoryStorage
+	failures int
+	failed   chan struct{}
+}
+
+var errStorage = errors.New("storage failed")
+
+func (s *failingStorage) Save(devices []Device) error {
+	if s.failures > 0 {
+		s.failures--
+		s.failed <- struct{}{}
+		return errStorage
+	}
+	return s.memoryStorage.Save(devices)
+}
+
+func announceMessage(nwkAddr uint16, ieee zigbee.MACAddress) zigbee.IncomingMessage {
+	clusterID, data, _ := zdp.SerializeFrame(0, &zdp.DeviceAnnce{NWKAddr: nwkAddr, IEEEAddr: ieee})
+	return zigbee.IncomingMessage{
+		Source:      zigbee.Address{Mode: zigbee.AddressModeNWK, Short: nwkAddr},
+		ClusterID:   clusterID,
+		LinkQuality: 50,
+		Data:        data,
+	}
+}
+
+func reportMessage(nwkAddr uint16, value int16) zigbee.IncomingMessage {
+	data, _ := zcl.SerializeReportAttributesCommand(zcl.ReportAttributesCommand{
+		Reports: []zcl.AttributeReport{{AttributeID: 0x0000, DataType: zcl.DataTypeInt16, Value: value}},
+	})
+	return zigbee.IncomingMessage{
+		Source:              zigbee.Address{Mode: zigbee.AddressModeNWK, Short: nwkAddr},
+		SourceEndpoint:      1,
+		DestinationEndpoint: 1,
+		ClusterID:           uint16(zcl.ClusterMSTemperatureMeasurement),
+		LinkQuality:         120,
+		Data: zcl.SerializeFrame(zcl.Frame{
+			FrameHeader: zcl.FrameHeader{
+				Type:                    zcl.FrameTypeGlobal,
+				DirectionServerToClient: true,
+				CommandID:               zcl.CommandReportAttributes,
+			},
+			Data: data,
+		}),
+	}
+}
+
+// deliver passes messages through the dispatcher and waits until they have
+// been handled.
+func deliver(t *testing.T, device *testDevice, output chan zigbee.IncomingMessage, messages ...zigbee.IncomingMessage) {
+	for _, message := range messages {
+		device.incoming <- message
+		select {
+		case <-output:
+		case <-time.After(time.Second):
+			t.Fatal("message not forwarded")
+		}
+	}
+}
+
+func TestHandleMessage(t *testing.T) {
+	device := &testDevice{incoming: make(chan zigbee.IncomingMessage, 16)}
+	dispatcher := dispatch.New(device)
+	storage := newMemoryStorage()
+	db, err := Open(dispatcher, storage)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	output, err := dispatcher.Start()
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	const ieee = zigbee.MACAddress(0x00158d0001234567)
+
+	// Unknown devices are ignored.
+	deliver(t, device, output, reportMessage(0x1234, 100))
+	if devices := db.Devices(); len(devices) != 0 {
+		t.Errorf("unexpected devices: %+v", devices)
+	}
+
+	deliver(t, device, output, announceMessage(0x1234, ieee), reportMessage(0x1234, 2150))
+
+	d, ok := db.LookupNetworkAddress(0x1234)
+	if !ok || d.IEEEAddress != ieee {
+		t.Fatalf("device not found: %+v", d)
+	}
+	if d.LinkQuality != 120 || d.LastSeen.IsZero() {
+		t.Errorf("wrong link quality or last seen: %+v", d)
+	}
+	attribute, ok := d.Attribute(1, zcl.ClusterMSTemperatureMeasurement, 0x0000)
+	if !ok {
+		t.Fatalf("attribute not found: %+v", d.Attributes)
+	}
+	if value, err := attribute.Value(); err != nil || value != int16(2150) {
+		t.Errorf("wrong value: %v %v", value, err)
+	}
+
+	// The device rejoins with a new network address.
+	deliver(t, device, output, announceMessage(0x5678, ieee), reportMessage(0x5678, 2200))
+	if _, ok := db.LookupNetworkAddress(0x1234); ok {
+		t.Errorf("old network address still mapped")
+	}
+	d, ok = db.Lookup(ieee)
+	if !ok || d.NetworkAddress != 0x5678 || len(d.Attributes) != 1 {
+		t.Fatalf("wrong device: %+v", d)
+	}
+	if value, _ := d.Attributes[0].Value(); value != int16(2200) {
+		t.Errorf("wrong value: %v", value)
+	}
+
+	// Changes are written in the background.
+	if len(storage.devices) != 0 {
+		t.Errorf("devices saved too early")
+	}
+	if err := db.Flush(); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if saved := storage.devices[ieee]; saved.NetworkAddress != 0x5678 || len(saved.Attributes) != 1 {
+		t.Errorf("wrong saved device: %+v", saved)
+	}
+
+	description := interview.DeviceDescription{
+		NetworkAddress:   0x5678,
+		IEEEAddress:      ieee,
+		ModelIdentifier:  "lumi.weather",
+		Endpoints:        []zdp.SimpleDescriptor{{Endpoint: 1, InClusters: []uint16{0x0402}}},
+		InterviewedAt:    time.Now(),
+		ManufacturerName: "LUMI",
+	}
+	if err := db.StoreInterview(description); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if saved := storage.devices[ieee]; !saved.Interviewed() || saved.Description.ModelIdentifier != "lumi.weather" {
+		t.Errorf("interview not saved: %+v", saved)
+	}
+
+	if err := db.Remove(ieee); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if _, ok := db.Lookup(ieee); ok || len(storage.devices) != 0 {
+		t.Errorf("device not removed")
+	}
+}
+
+func TestCombinedAddress(t *testing.T) {
+	device := &testDevice{incoming: make(chan zigbee.IncomingMessage, 16)}
+	dispatcher := dispatch.New(device)
+	db, err := Open(dispatcher, newMemoryStorage())
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	output, err := dispatcher.Start()
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	message := reportMessage(0x1234, 100)
+	message.Source = zigbee.Address{Mode: zigbee.AddressModeCombined, Short: 0x1234, Extended: 0x1122334455667788}
+	deliver(t, device, output, message)
+
+	if d, ok := db.LookupNetworkAddress(0x1234); !ok || d.IEEEAddress != 0x1122334455667788 {
+		t.Errorf("device not added: %+v", d)
+	}
+}
+
+func TestBackgroundFlushError(t *testing.T) {
+	defer func(delay time.Duration) { flushDelay = delay }(flushDelay)
+	flushDelay = 10 * time.Millisecond
+
+	device := &testDevice{incoming: make(chan zigbee.IncomingMessage, 16)}
+	dispatcher := dispatch.New(device)
+	storage := &failingStorage{memoryStorage: newMemoryStorage(), failures: 1, failed: make(chan struct{}, 1)}
+	db, err := Open(dispatcher, storage)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	output, err := dispatcher.Start()
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	deliver(t, device, output, announceMessage(0x1234, 0x00158d0001234567))
+
+	select {
+	case <-storage.failed:
+	case <-time.After(time.Second):
+		t.Fatal("no background flush")
+	}
+
+	// The error of the background flush is reported by the next flush, which
+	// saves the device successfully.
+	deadline := time.Now().Add(time.Second)
+	for {
+		err = db.Flush()
+		if err != nil || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != errStorage {
+		t.Fatalf("expected storage error, got %v", err)
+	}
+	if _, ok := storage.devices[0x00158d0001234567]; !ok {
+		t.Errorf("device not saved")
+	}
+	if err := db.Close(); err != nil {
+		t.Errorf("unexpected err: %v", err)
+	}
+}
+
+func testStorage(t *testing.T, open func(path string) (Storage, error)) {
+	dir, err := ioutil.TempDir("", "devicedb")
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "devices")
+
+	devices := []Device{
+		{
+			IEEEAddress:    0x00158d0001234567,
+			NetworkAddress: 0x1234,
+			Description: interview.DeviceDescription{
+				NetworkAddress:  0x1234,
+				IEEEAddress:     0x00158d0001234567,
+				NodeDescriptor:  zdp.NodeDescriptor{LogicalType: zdp.LogicalTypeEndDevice, ManufacturerCode: 0x115f},
+				Endpoints:       []zdp.SimpleDescriptor{{Endpoint: 1, ProfileID: zigbee.ProfileHomeAutomation, InClusters: []uint16{0x0000, 0x0402}, OutClusters: []uint16{}}},
+				BasicEndpoint:   1,
+				ModelIdentifier: "lumi.weather",
+				PowerSource:     zcl.PowerSourceBattery,
+				InterviewedAt:   time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC),
+			},
+			LastSeen:    time.Date(2021, 3, 4, 6, 0, 0, 0, time.UTC),
+			LinkQuality: 80,
+			Attributes: []Attribute{
+				{Endpoint: 1, ClusterID: zcl.ClusterMSTemperatureMeasurement, DataType: zcl.DataTypeInt16, Data: []byte{0x66, 0x08}, Updated: time.Date(2021, 3, 4, 6, 0, 0, 0, time.UTC)},
+			},
+		},
+		{IEEEAddress: 0x0017880100abcdef, NetworkAddress: 0x0001},
+	}
+
+	storage, err := open(path)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if err := storage.Save(devices); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if err := storage.Save([]Device{{IEEEAddress: 0x1111, NetworkAddress: 0x2222}}); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if err := storage.Delete(0x1111); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if err := storage.Close(); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	storage, err = open(path)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer storage.Close()
+
+	loaded, err := storage.Load()
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if !reflect.DeepEqual(loaded, devices) {
+		t.Errorf("wrong devices:\n%+v\nexpected:\n%+v", loaded, devices)
+	}
+}
+
+func TestJSONFile(t *testing.T) {
+	testStorage(t, func(path string) (Storage, error) { return OpenJSONFile(path) })
+}
+
+func TestKVStore(t *testing.T) {
+	testStorage(t, func(path string) (Storage, error) { return OpenKVStore(path) })
+}
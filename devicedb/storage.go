@@ -0,0 +1,179 @@
+package devicedb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/GreenLightning/zigbee-conductor/pkg/kvlog"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+// Storage persists devices. The DB serializes all calls, so implementations
+// do not have to be safe for concurrent use.
+type Storage interface {
+	// Load returns all stored devices.
+	Load() ([]Device, error)
+	// Save stores the devices, replacing previously stored versions.
+	Save(devices []Device) error
+	// Delete removes a device. It is not an error if the device does not exist.
+	Delete(ieee zigbee.MACAddress) error
+	Close() error
+}
+
+// JSONFile stores all devices in a single, human-readable JSON file. The file
+// is rewritten completely on every change, which is fine for the number of
+// devices in a typical network.
+type JSONFile struct {
+	path    string
+	devices map[zigbee.MACAddress]Device
+}
+
+// OpenJSONFile opens the JSON file at path. The file is created on the first
+// save if it does not exist.
+func OpenJSONFile(path string) (*JSONFile, error) {
+	s := &JSONFile{
+		path:    path,
+		devices: make(map[zigbee.MACAddress]Device),
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var devices []Device
+	if err := json.Unmarshal(data, &devices); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for _, device := range devices {
+		s.devices[device.IEEEAddress] = device
+	}
+	return s, nil
+}
+
+func (s *JSONFile) Load() ([]Device, error) {
+	devices := make([]Device, 0, len(s.devices))
+	for _, device := range s.devices {
+		devices = append(devices, device)
+	}
+	sortDevices(devices)
+	return devices, nil
+}
+
+func (s *JSONFile) Save(devices []Device) error {
+	for _, device := range devices {
+		s.devices[device.IEEEAddress] = device
+	}
+	return s.write()
+}
+
+func (s *JSONFile) Delete(ieee zigbee.MACAddress) error {
+	if _, ok := s.devices[ieee]; !ok {
+		return nil
+	}
+	delete(s.devices, ieee)
+	return s.write()
+}
+
+func (s *JSONFile) Close() error {
+	return nil
+}
+
+// write replaces the file atomically, so that it is never left half-written.
+func (s *JSONFile) write() error {
+	devices, _ := s.Load()
+	data, err := json.MarshalIndent(devices, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	temp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), s.path)
+}
+
+// KVStore stores each device as a separate JSON value in an embedded
+// key-value store (see package kvlog), so that only changed devices are
+// written.
+type KVStore struct {
+	store *kvlog.Store
+}
+
+// OpenKVStore opens or creates the key-value store at path.
+func OpenKVStore(path string) (*KVStore, error) {
+	store, err := kvlog.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &KVStore{store: store}, nil
+}
+
+func (s *KVStore) Load() ([]Device, error) {
+	var devices []Device
+	for _, key := range s.store.Keys() {
+		value, _ := s.store.Get(key)
+		var device Device
+		if err := json.Unmarshal(value, &device); err != nil {
+			return nil, fmt.Errorf("parsing device %s: %w", key, err)
+		}
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
+
+func (s *KVStore) Save(devices []Device) error {
+	for _, device := range devices {
+		value, err := json.Marshal(device)
+		if err != nil {
+			return err
+		}
+		if err := s.store.Put(kvKey(device.IEEEAddress), value); err != nil {
+			return err
+		}
+	}
+	return s.store.Sync()
+}
+
+func (s *KVStore) Delete(ieee zigbee.MACAddress) error {
+	if err := s.store.Delete(kvKey(ieee)); err != nil {
+		return err
+	}
+	return s.store.Sync()
+}
+
+func (s *KVStore) Close() error {
+	return s.store.Close()
+}
+
+// kvKey returns a fixed-width key, so that the keys sort by address.
+func kvKey(ieee zigbee.MACAddress) string {
+	return ieee.String()
+}
+
+func sortDevices(devices []Device) {
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].IEEEAddress < devices[j].IEEEAddress
+	})
+}
@@ -0,0 +1,262 @@
+// Package dispatch distributes the incoming messages of a zigbee.Controller
+// between multiple consumers and matches responses to requests.
+//
+// A zigbee.Controller only provides a single channel of incoming messages.
+// Higher-level features (e.g. managing groups or answering requests from
+// devices) need to see the messages on that channel as well. The Dispatcher
+// wraps a controller, passes every incoming message to its handlers and pending
+// requests and forwards all remaining messages to the application.
+//
+// The Dispatcher itself implements zigbee.Controller, so it can be used in
+// place of the wrapped controller.
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zdp"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+var ErrClosed = errors.New("controller closed")
+
+// A Handler processes incoming messages.
+//
+// HandleMessage is called from the goroutine that receives messages from the
+// controller, therefore it must not block. In particular, it must not wait for
+// responses from the network and it should send messages from a separate
+// goroutine, because some controllers wait for the serial device to
+// acknowledge a message, which may be delayed by unprocessed incoming messages.
+// If HandleMessage returns true, the message has been consumed and is not
+// passed to other handlers or to the application.
+type Handler interface {
+	HandleMessage(message zigbee.IncomingMessage) bool
+}
+
+// HandlerFunc adapts a function to the Handler interface.
+type HandlerFunc func(message zigbee.IncomingMessage) bool
+
+func (f HandlerFunc) HandleMessage(message zigbee.IncomingMessage) bool {
+	return f(message)
+}
+
+type waiter struct {
+	match    func(message zigbee.IncomingMessage) bool
+	response chan zigbee.IncomingMessage
+}
+
+type Dispatcher struct {
+	controller zigbee.Controller
+	sequence   uint32
+
+	mutex    sync.Mutex
+	handlers []Handler
+	waiters  map[*waiter]struct{}
+	done     chan struct{}
+}
+
+func New(controller zigbee.Controller) *Dispatcher {
+	return &Dispatcher{
+		controller: controller,
+		waiters:    make(map[*waiter]struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Controller returns the wrapped controller.
+func (d *Dispatcher) Controller() zigbee.Controller {
+	return d.controller
+}
+
+// AddHandler registers a handler. Handlers are called in registration order.
+func (d *Dispatcher) AddHandler(handler Handler) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.handlers = append(d.handlers, handler)
+}
+
+// Start starts the wrapped controller. The returned channel receives all
+// messages that have neither been consumed by a handler nor by a pending
+// request. The application must keep reading from the channel.
+func (d *Dispatcher) Start() (chan zigbee.IncomingMessage, error) {
+	input, err := d.controller.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	output := make(chan zigbee.IncomingMessage)
+
+	go func() {
+		defer close(output)
+		defer close(d.done)
+		for message := range input {
+			if !d.dispatch(message) {
+				output <- message
+			}
+		}
+	}()
+
+	return output, nil
+}
+
+func (d *Dispatcher) dispatch(message zigbee.IncomingMessage) bool {
+	d.mutex.Lock()
+	handlers := d.handlers
+	for w := range d.waiters {
+		if w.match(message) {
+			delete(d.waiters, w)
+			w.response <- message
+			d.mutex.Unlock()
+			return true
+		}
+	}
+	d.mutex.Unlock()
+
+	for _, handler := range handlers {
+		if handler.HandleMessage(message) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d *Dispatcher) Close() error {
+	return d.controller.Close()
+}
+
+func (d *Dispatcher) Send(message zigbee.OutgoingMessage) error {
+	return d.controller.Send(message)
+}
+
+func (d *Dispatcher) PermitJoining(enabled bool) error {
+	return d.controller.PermitJoining(enabled)
+}
+
+// NextTransactionSequenceNumber returns a sequence number for the next ZCL or ZDP transaction.
+func (d *Dispatcher) NextTransactionSequenceNumber() uint8 {
+	return uint8(atomic.AddUint32(&d.sequence, 1))
+}
+
+// Request sends a message and waits for the first incoming message for which
+// match returns true. The matching message is consumed and not passed on to
+// the handlers or the application.
+func (d *Dispatcher) Request(ctx context.Context, message zigbee.OutgoingMessage, match func(message zigbee.IncomingMessage) bool) (zigbee.IncomingMessage, error) {
+	w := &waiter{
+		match:    match,
+		response: make(chan zigbee.IncomingMessage, 1),
+	}
+
+	// Register before sending to avoid missing a fast response.
+	d.mutex.Lock()
+	d.waiters[w] = struct{}{}
+	d.mutex.Unlock()
+
+	defer func() {
+		d.mutex.Lock()
+		delete(d.waiters, w)
+		d.mutex.Unlock()
+	}()
+
+	err := d.controller.Send(message)
+	if err != nil {
+		return zigbee.IncomingMessage{}, err
+	}
+
+	select {
+	case response := <-w.response:
+		return response, nil
+	case <-ctx.Done():
+		return zigbee.IncomingMessage{}, ctx.Err()
+	case <-d.done:
+		return zigbee.IncomingMessage{}, ErrClosed
+	}
+}
+
+// RequestFrame sends a ZCL frame and waits for the frame with the same
+// transaction sequence number sent back by the destination.
+//
+// The transaction sequence number of the frame is set automatically. The
+// response can be a cluster-specific response or a global Default Response.
+func (d *Dispatcher) RequestFrame(ctx context.Context, message zigbee.OutgoingMessage, frame zcl.Frame) (zcl.Frame, error) {
+	frame.TransSeqNumber = d.NextTransactionSequenceNumber()
+	message.Data = zcl.SerializeFrame(frame)
+
+	incoming, err := d.Request(ctx, message, func(incoming zigbee.IncomingMessage) bool {
+		if !matchesAddress(incoming.Source, message.Destination) || incoming.ClusterID != message.ClusterID {
+			return false
+		}
+		if incoming.SourceEndpoint != message.DestinationEndpoint {
+			return false
+		}
+		response, err := zcl.ParseFrame(incoming.Data)
+		if err != nil || response.TransSeqNumber != frame.TransSeqNumber {
+			return false
+		}
+		return response.DirectionServerToClient != frame.DirectionServerToClient
+	})
+	if err != nil {
+		return zcl.Frame{}, err
+	}
+
+	return zcl.ParseFrame(incoming.Data)
+}
+
+// matchesAddress reports whether source is the device addressed by
+// destination. Destinations given only by IEEE address can only be matched if
+// the controller reports the IEEE address of incoming messages.
+func matchesAddress(source, destination zigbee.Address) bool {
+	if destination.Mode == zigbee.AddressModeIEEE {
+		hasExtended := source.Mode == zigbee.AddressModeIEEE || source.Mode == zigbee.AddressModeCombined
+		return hasExtended && source.Extended == destination.Extended
+	}
+	return source.Short == destination.Short
+}
+
+// RequestZDP sends a ZigBee Device Profile request to the device with the given
+// network address and waits for the corresponding response. ZDP messages are
+// exchanged between endpoint 0 of both devices.
+//
+// The command must be one of the request types of package zdp and the result
+// is a pointer to the corresponding response type. The transaction sequence
+// number is set automatically.
+//
+// Controllers which handle ZDP internally (like the ZNP) cannot send ZDP
+// messages to endpoint 0. If the controller implements zdp.Requester, the
+// request is passed to it instead. Such controllers may support only some
+// requests and fail with zdp.ErrNotSupported for others.
+func (d *Dispatcher) RequestZDP(ctx context.Context, nwkAddr uint16, command interface{}) (interface{}, error) {
+	if requester, ok := d.controller.(zdp.Requester); ok {
+		return requester.RequestZDP(ctx, nwkAddr, command)
+	}
+
+	tsn := d.NextTransactionSequenceNumber()
+	clusterID, data, err := zdp.SerializeFrame(tsn, command)
+	if err != nil {
+		return nil, err
+	}
+
+	message := zigbee.OutgoingMessage{
+		Destination: zigbee.Address{Mode: zigbee.AddressModeNWK, Short: nwkAddr},
+		ClusterID:   clusterID,
+		Radius:      zigbee.DefaultRadius,
+		Data:        data,
+	}
+
+	incoming, err := d.Request(ctx, message, func(incoming zigbee.IncomingMessage) bool {
+		if incoming.Source.Short != nwkAddr || incoming.SourceEndpoint != 0 {
+			return false
+		}
+		return incoming.ClusterID == clusterID|zdp.ClusterResponseFlag && len(incoming.Data) != 0 && incoming.Data[0] == tsn
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, response, err := zdp.ParseFrame(incoming.ClusterID, incoming.Data)
+	return response, err
+}
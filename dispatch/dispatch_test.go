@@ -0,0 +1,236 @@
+package dispatch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+// testController passes every sent message to respond, which can inject
+// incoming messages.
+type testController struct {
+	incoming chan zigbee.IncomingMessage
+	respond  func(message zigbee.OutgoingMessage)
+}
+
+func newTestController() *testController {
+	return &testController{incoming: make(chan zigbee.IncomingMessage, 16)}
+}
+
+func (c *testController) Start() (chan zigbee.IncomingMessage, error) { return c.incoming, nil }
+func (c *testController) Close() error                                { close(c.incoming); return nil }
+func (c *testController) PermitJoining(enabled bool) error            { return nil }
+
+func (c *testController) Send(message zigbee.OutgoingMessage) error {
+	if c.respond != nil {
+		c.respond(message)
+	}
+	return nil
+}
+
+func receive(t *testing.T, output chan zigbee.IncomingMessage) zigbee.IncomingMessage {
+	t.Helper()
+	select {
+	case message := <-output:
+		return message
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+		return zigbee.IncomingMessage{}
+	}
+}
+
+func TestRequestFrame(t *testing.T) {
+	controller := newTestController()
+	controller.respond = func(message zigbee.OutgoingMessage) {
+		frame, err := zcl.ParseFrame(message.Data)
+		if err != nil {
+			t.Error("unexpected err:", err)
+			return
+		}
+		response := zcl.Frame{FrameHeader: zcl.FrameHeader{
+			Type:                    zcl.FrameTypeGlobal,
+			DirectionServerToClient: true,
+			CommandID:               zcl.CommandDefaultResponse,
+		}}
+		for _, tsn := range []uint8{frame.TransSeqNumber + 1, frame.TransSeqNumber} {
+			response.TransSeqNumber = tsn
+			controller.incoming <- zigbee.IncomingMessage{
+				Source:         message.Destination,
+				SourceEndpoint: message.DestinationEndpoint,
+				ClusterID:      message.ClusterID,
+				Data:           zcl.SerializeFrame(response),
+			}
+		}
+	}
+
+	dispatcher := New(controller)
+	output, err := dispatcher.Start()
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	message := zigbee.OutgoingMessage{
+		Destination:         zigbee.Address{Mode: zigbee.AddressModeNWK, Short: 0x1234},
+		DestinationEndpoint: 1,
+		ClusterID:           0x0006,
+	}
+	frame := zcl.Frame{FrameHeader: zcl.FrameHeader{Type: zcl.FrameTypeLocal, CommandID: 0x02}}
+
+	// The response with the wrong sequence number is passed to the
+	// application, which must keep reading while the request is pending.
+	others := make(chan zigbee.IncomingMessage, 1)
+	go func() {
+		others <- <-output
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	response, err := dispatcher.RequestFrame(ctx, message, frame)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	other, err := zcl.ParseFrame(receive(t, others).Data)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if other.TransSeqNumber != response.TransSeqNumber+1 {
+		t.Errorf("got response %d and other %d", response.TransSeqNumber, other.TransSeqNumber)
+	}
+}
+
+func TestRequestBeforeHandlers(t *testing.T) {
+	controller := newTestController()
+	controller.respond = func(message zigbee.OutgoingMessage) {
+		controller.incoming <- zigbee.IncomingMessage{ClusterID: message.ClusterID}
+	}
+
+	dispatcher := New(controller)
+	handled := 0
+	dispatcher.AddHandler(HandlerFunc(func(message zigbee.IncomingMessage) bool {
+		handled++
+		return true
+	}))
+	if _, err := dispatcher.Start(); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := dispatcher.Request(ctx, zigbee.OutgoingMessage{ClusterID: 0x0006}, func(message zigbee.IncomingMessage) bool {
+		return message.ClusterID == 0x0006
+	})
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if handled != 0 {
+		t.Errorf("handler called %d times", handled)
+	}
+}
+
+func TestHandlerOrder(t *testing.T) {
+	controller := newTestController()
+	dispatcher := New(controller)
+
+	var calls []string
+	dispatcher.AddHandler(HandlerFunc(func(message zigbee.IncomingMessage) bool {
+		calls = append(calls, "first")
+		return message.ClusterID == 0x0001
+	}))
+	dispatcher.AddHandler(HandlerFunc(func(message zigbee.IncomingMessage) bool {
+		calls = append(calls, "second")
+		return false
+	}))
+
+	output, err := dispatcher.Start()
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	controller.incoming <- zigbee.IncomingMessage{ClusterID: 0x0001}
+	controller.incoming <- zigbee.IncomingMessage{ClusterID: 0x0002}
+
+	// Only the message not consumed by the first handler reaches the output.
+	if message := receive(t, output); message.ClusterID != 0x0002 {
+		t.Errorf("got cluster %#04x", message.ClusterID)
+	}
+	expected := []string{"first", "first", "second"}
+	if len(calls) != len(expected) {
+		t.Fatalf("got calls %v, expected %v", calls, expected)
+	}
+	for i := range calls {
+		if calls[i] != expected[i] {
+			t.Fatalf("got calls %v, expected %v", calls, expected)
+		}
+	}
+}
+
+func TestRequestCanceled(t *testing.T) {
+	controller := newTestController()
+	dispatcher := New(controller)
+	output, err := dispatcher.Start()
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = dispatcher.Request(ctx, zigbee.OutgoingMessage{}, func(message zigbee.IncomingMessage) bool {
+		return true
+	})
+	if err != context.Canceled {
+		t.Fatal("unexpected err:", err)
+	}
+
+	// The canceled request no longer consumes messages.
+	controller.incoming <- zigbee.IncomingMessage{ClusterID: 0x0006}
+	if message := receive(t, output); message.ClusterID != 0x0006 {
+		t.Errorf("got cluster %#04x", message.ClusterID)
+	}
+}
+
+func TestRequestClosed(t *testing.T) {
+	controller := newTestController()
+	dispatcher := New(controller)
+	output, err := dispatcher.Start()
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := dispatcher.Request(context.Background(), zigbee.OutgoingMessage{}, func(message zigbee.IncomingMessage) bool {
+			return false
+		})
+		result <- err
+	}()
+
+	dispatcher.Close()
+	select {
+	case err := <-result:
+		if err != ErrClosed {
+			t.Fatal("unexpected err:", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+
+	if _, ok := <-output; ok {
+		t.Error("output not closed")
+	}
+
+	// Requests after closing fail immediately.
+	_, err = dispatcher.Request(context.Background(), zigbee.OutgoingMessage{}, func(message zigbee.IncomingMessage) bool {
+		return true
+	})
+	if err != ErrClosed {
+		t.Fatal("unexpected err:", err)
+	}
+}
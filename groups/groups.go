@@ -0,0 +1,257 @@
+// Package groups maintains the group membership of devices using the Groups cluster.
+package groups
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/GreenLightning/zigbee-conductor/dispatch"
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+var (
+	ErrUnexpectedResponse = errors.New("unexpected response")
+	ErrVerificationFailed = errors.New("group membership verification failed")
+)
+
+// A Member is an endpoint of a device implementing the server side of the Groups cluster.
+//
+// Members are identified by IEEE address, because the network address of a
+// device can change when it rejoins the network.
+type Member struct {
+	Address  zigbee.MACAddress // IEEE address
+	Endpoint uint8
+}
+
+// Manager adds devices to and removes devices from groups. It keeps track of
+// the group membership reported by the devices.
+type Manager struct {
+	dispatcher *dispatch.Dispatcher
+	addresses  *zigbee.AddressMap
+
+	// SourceEndpoint is the local endpoint used to send commands.
+	SourceEndpoint uint8
+
+	mutex       sync.Mutex
+	memberships map[Member][]uint16
+}
+
+// NewManager creates a manager. Commands are sent to the network address
+// stored in addresses, if it is known, and otherwise to the IEEE address,
+// which must then be resolved by the controller. addresses may be nil.
+func NewManager(dispatcher *dispatch.Dispatcher, addresses *zigbee.AddressMap) *Manager {
+	return &Manager{
+		dispatcher:     dispatcher,
+		addresses:      addresses,
+		SourceEndpoint: 1,
+		memberships:    make(map[Member][]uint16),
+	}
+}
+
+// AddGroup adds the member to a group. It is not an error if the member is
+// already part of the group.
+func (m *Manager) AddGroup(ctx context.Context, member Member, groupID uint16, name string) error {
+	response, err := m.request(ctx, member, &zcl.AddGroupCommand{GroupID: groupID, GroupName: name})
+	if err != nil {
+		return fmt.Errorf("adding group 0x%04x: %w", groupID, err)
+	}
+
+	cmd, ok := response.(*zcl.AddGroupResponseCommand)
+	if !ok || cmd.GroupID != groupID {
+		return fmt.Errorf("adding group 0x%04x: %w", groupID, ErrUnexpectedResponse)
+	}
+	if cmd.Status != zcl.StatusSuccess && cmd.Status != zcl.StatusDuplicateExists {
+		return fmt.Errorf("adding group 0x%04x: %v", groupID, cmd.Status)
+	}
+
+	m.mutex.Lock()
+	m.memberships[member] = insert(m.memberships[member], groupID)
+	m.mutex.Unlock()
+
+	return nil
+}
+
+// RemoveGroup removes the member from a group. It is not an error if the
+// member is not part of the group.
+func (m *Manager) RemoveGroup(ctx context.Context, member Member, groupID uint16) error {
+	response, err := m.request(ctx, member, &zcl.RemoveGroupCommand{GroupID: groupID})
+	if err != nil {
+		return fmt.Errorf("removing group 0x%04x: %w", groupID, err)
+	}
+
+	cmd, ok := response.(*zcl.RemoveGroupResponseCommand)
+	if !ok || cmd.GroupID != groupID {
+		return fmt.Errorf("removing group 0x%04x: %w", groupID, ErrUnexpectedResponse)
+	}
+	if cmd.Status != zcl.StatusSuccess && cmd.Status != zcl.StatusNotFound {
+		return fmt.Errorf("removing group 0x%04x: %v", groupID, cmd.Status)
+	}
+
+	m.mutex.Lock()
+	m.memberships[member] = remove(m.memberships[member], groupID)
+	m.mutex.Unlock()
+
+	return nil
+}
+
+// GetGroupMembership queries the device for all groups the member is part of.
+func (m *Manager) GetGroupMembership(ctx context.Context, member Member) ([]uint16, error) {
+	response, err := m.request(ctx, member, &zcl.GetGroupMembershipCommand{})
+	if err != nil {
+		return nil, fmt.Errorf("getting group membership: %w", err)
+	}
+
+	cmd, ok := response.(*zcl.GetGroupMembershipResponseCommand)
+	if !ok {
+		return nil, fmt.Errorf("getting group membership: %w", ErrUnexpectedResponse)
+	}
+
+	groups := make([]uint16, 0, len(cmd.GroupList))
+	for _, groupID := range cmd.GroupList {
+		groups = insert(groups, groupID)
+	}
+
+	m.mutex.Lock()
+	m.memberships[member] = groups
+	m.mutex.Unlock()
+
+	return append([]uint16(nil), groups...), nil
+}
+
+// SetGroups makes the member part of exactly the given groups by adding and
+// removing groups as necessary. Afterwards the membership is verified and
+// ErrVerificationFailed is returned if the device reports different groups.
+func (m *Manager) SetGroups(ctx context.Context, member Member, groups []uint16) error {
+	current, err := m.GetGroupMembership(ctx, member)
+	if err != nil {
+		return err
+	}
+
+	var desired []uint16
+	for _, groupID := range groups {
+		desired = insert(desired, groupID)
+	}
+
+	for _, groupID := range desired {
+		if !contains(current, groupID) {
+			err := m.AddGroup(ctx, member, groupID, "")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, groupID := range current {
+		if !contains(desired, groupID) {
+			err := m.RemoveGroup(ctx, member, groupID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	actual, err := m.GetGroupMembership(ctx, member)
+	if err != nil {
+		return err
+	}
+
+	if len(actual) != len(desired) {
+		return ErrVerificationFailed
+	}
+	for i := range actual {
+		if actual[i] != desired[i] {
+			return ErrVerificationFailed
+		}
+	}
+
+	return nil
+}
+
+// Groups returns the groups of the member as last reported by the device.
+func (m *Manager) Groups(member Member) []uint16 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]uint16(nil), m.memberships[member]...)
+}
+
+// Members returns all known members of a group.
+func (m *Manager) Members(groupID uint16) []Member {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var members []Member
+	for member, groups := range m.memberships {
+		if contains(groups, groupID) {
+			members = append(members, member)
+		}
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Address != members[j].Address {
+			return members[i].Address < members[j].Address
+		}
+		return members[i].Endpoint < members[j].Endpoint
+	})
+
+	return members
+}
+
+func (m *Manager) request(ctx context.Context, member Member, command zcl.ClusterCommand) (zcl.ClusterCommand, error) {
+	destination := zigbee.Address{Mode: zigbee.AddressModeIEEE, Extended: member.Address}
+	if m.addresses != nil {
+		destination = m.addresses.Complete(destination)
+	}
+
+	message := zigbee.OutgoingMessage{
+		Destination:         destination,
+		DestinationEndpoint: member.Endpoint,
+		SourceEndpoint:      m.SourceEndpoint,
+		ClusterID:           uint16(zcl.ClusterGeneralGroups),
+		Radius:              zigbee.DefaultRadius,
+	}
+
+	response, err := m.dispatcher.RequestFrame(ctx, message, zcl.NewClusterCommandFrame(command))
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Type == zcl.FrameTypeGlobal && response.CommandID == zcl.CommandDefaultResponse {
+		cmd, err := zcl.ParseDefaultResponseCommand(response.Data)
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("default response: %v", cmd.Status)
+	}
+
+	return zcl.ParseClusterCommand(zcl.ClusterGeneralGroups, response)
+}
+
+// Helpers for sorted slices of group IDs.
+
+func contains(groups []uint16, groupID uint16) bool {
+	index := sort.Search(len(groups), func(i int) bool { return groups[i] >= groupID })
+	return index < len(groups) && groups[index] == groupID
+}
+
+func insert(groups []uint16, groupID uint16) []uint16 {
+	index := sort.Search(len(groups), func(i int) bool { return groups[i] >= groupID })
+	if index < len(groups) && groups[index] == groupID {
+		return groups
+	}
+	groups = append(groups, 0)
+	copy(groups[index+1:], groups[index:])
+	groups[index] = groupID
+	return groups
+}
+
+func remove(groups []uint16, groupID uint16) []uint16 {
+	index := sort.Search(len(groups), func(i int) bool { return groups[i] >= groupID })
+	if index < len(groups) && groups[index] == groupID {
+		groups = append(groups[:index], groups[index+1:]...)
+	}
+	return groups
+}
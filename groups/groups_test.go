@@ -0,0 +1,150 @@
+package groups
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/dispatch"
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+// testDevice simulates the Groups cluster server of a single device.
+type testDevice struct {
+	incoming    chan zigbee.IncomingMessage
+	groups      map[uint16]bool
+	destination zigbee.Address
+}
+
+func newTestDevice(groups ...uint16) *testDevice {
+	device := &testDevice{
+		incoming: make(chan zigbee.IncomingMessage, 16),
+		groups:   make(map[uint16]bool),
+	}
+	for _, groupID := range groups {
+		device.groups[groupID] = true
+	}
+	return device
+}
+
+func (d *testDevice) Start() (chan zigbee.IncomingMessage, error) { return d.incoming, nil }
+func (d *testDevice) Close() error                                { close(d.incoming); return nil }
+func (d *testDevice) PermitJoining(enabled bool) error            { return nil }
+
+func (d *testDevice) Send(message zigbee.OutgoingMessage) error {
+	d.destination = message.Destination
+	frame, err := zcl.ParseFrame(message.Data)
+	if err != nil {
+		return err
+	}
+	command, err := zcl.ParseClusterCommand(zcl.ClusterID(message.ClusterID), frame)
+	if err != nil {
+		return err
+	}
+
+	var response zcl.ClusterCommand
+	switch cmd := command.(type) {
+	case *zcl.AddGroupCommand:
+		d.groups[cmd.GroupID] = true
+		response = &zcl.AddGroupResponseCommand{Status: zcl.StatusSuccess, GroupID: cmd.GroupID}
+	case *zcl.RemoveGroupCommand:
+		status := zcl.StatusNotFound
+		if d.groups[cmd.GroupID] {
+			status = zcl.StatusSuccess
+		}
+		delete(d.groups, cmd.GroupID)
+		response = &zcl.RemoveGroupResponseCommand{Status: status, GroupID: cmd.GroupID}
+	case *zcl.GetGroupMembershipCommand:
+		var list []uint16
+		for groupID := range d.groups {
+			list = append(list, groupID)
+		}
+		response = &zcl.GetGroupMembershipResponseCommand{Capacity: 0xfe, GroupList: list}
+	}
+
+	responseFrame := zcl.NewClusterCommandFrame(response)
+	responseFrame.TransSeqNumber = frame.TransSeqNumber
+	d.incoming <- zigbee.IncomingMessage{
+		Source:              message.Destination,
+		SourceEndpoint:      message.DestinationEndpoint,
+		DestinationEndpoint: message.SourceEndpoint,
+		ClusterID:           message.ClusterID,
+		Data:                zcl.SerializeFrame(responseFrame),
+	}
+	return nil
+}
+
+func TestSetGroups(t *testing.T) {
+	device := newTestDevice(0x0001, 0x0002)
+	dispatcher := dispatch.New(device)
+	if _, err := dispatcher.Start(); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	manager := NewManager(dispatcher, nil)
+	member := Member{Address: 0x0011223344556677, Endpoint: 1}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err := manager.SetGroups(ctx, member, []uint16{0x0003, 0x0002})
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	if !reflect.DeepEqual(device.groups, map[uint16]bool{0x0002: true, 0x0003: true}) {
+		t.Errorf("wrong device groups: %v", device.groups)
+	}
+	if groups := manager.Groups(member); !reflect.DeepEqual(groups, []uint16{0x0002, 0x0003}) {
+		t.Errorf("wrong groups: %v", groups)
+	}
+	if members := manager.Members(0x0003); !reflect.DeepEqual(members, []Member{member}) {
+		t.Errorf("wrong members: %v", members)
+	}
+	if members := manager.Members(0x0001); len(members) != 0 {
+		t.Errorf("wrong members: %v", members)
+	}
+}
+
+func TestResolveAddress(t *testing.T) {
+	device := newTestDevice(0x0001)
+	dispatcher := dispatch.New(device)
+	if _, err := dispatcher.Start(); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	var addresses zigbee.AddressMap
+	addresses.Update(0x1234, 0x0011223344556677)
+	manager := NewManager(dispatcher, &addresses)
+	member := Member{Address: 0x0011223344556677, Endpoint: 1}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	groups, err := manager.GetGroupMembership(ctx, member)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if !reflect.DeepEqual(groups, []uint16{0x0001}) {
+		t.Errorf("wrong groups: %v", groups)
+	}
+	if device.destination.Short != 0x1234 {
+		t.Errorf("wrong destination: %+v", device.destination)
+	}
+
+	// The membership is kept when the device changes its network address.
+	addresses.Update(0x5678, 0x0011223344556677)
+	if err := manager.AddGroup(ctx, member, 0x0002, ""); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if device.destination.Short != 0x5678 {
+		t.Errorf("wrong destination: %+v", device.destination)
+	}
+	if groups := manager.Groups(member); !reflect.DeepEqual(groups, []uint16{0x0001, 0x0002}) {
+		t.Errorf("wrong groups: %v", groups)
+	}
+}
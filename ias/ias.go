@@ -0,0 +1,323 @@
+// Package ias enrolls security sensors (IAS zones) with the coordinator.
+//
+// An IAS Zone device only reports alarms after it has been enrolled, i.e.
+// after its IAS_CIE_Address attribute has been set to the address of the
+// coordinator and the coordinator has assigned a zone ID to the device.
+package ias
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/dispatch"
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+var (
+	ErrUnexpectedResponse = errors.New("unexpected response")
+	ErrTooManyZones       = errors.New("too many zones")
+	ErrInvalidZoneTable   = errors.New("invalid zone table")
+)
+
+// MaxZones is the number of zone IDs available. Zone ID 0xff is not a valid
+// zone ID and indicates that a device is not enrolled.
+const MaxZones = 0xff
+
+// AutoEnrollTimeout limits the time spent writing the IAS_CIE_Address of a
+// device that is enrolled automatically.
+const AutoEnrollTimeout = 10 * time.Second
+
+// Enroller answers Zone Enroll Requests and writes the IAS_CIE_Address of
+// devices. Devices are identified by IEEE address and each device is assigned
+// the lowest free zone ID when it is enrolled. The zone table can be saved
+// using Zones and restored using SetZones.
+type Enroller struct {
+	dispatcher *dispatch.Dispatcher
+	addresses  *zigbee.AddressMap
+
+	// CIEAddress is the IEEE address of the coordinator, which acts as the CIE
+	// (control and indicating equipment).
+	CIEAddress zigbee.MACAddress
+
+	// SourceEndpoint is the local endpoint used to send commands.
+	SourceEndpoint uint8
+
+	// AutoEnroll enables enrollment of unknown devices that send a Zone Enroll
+	// Request. The IAS_CIE_Address of the device is written before the request
+	// is answered. If AutoEnroll is false, requests from unknown devices are
+	// answered with EnrollResponseCodeNoEnrollPermit.
+	AutoEnroll bool
+
+	// OnZoneAssigned is called when a device is assigned a new zone ID, so that
+	// the application can save the zone table. It must not block.
+	OnZoneAssigned func(address zigbee.MACAddress, zoneID uint8)
+
+	mutex sync.Mutex
+	zones map[zigbee.MACAddress]uint8
+}
+
+// NewEnroller creates an Enroller and registers it as a handler with the
+// dispatcher. Commands are sent to the network address stored in addresses,
+// if it is known, and otherwise to the IEEE address, which must then be
+// resolved by the controller. addresses is also used to find the IEEE address
+// of devices sending Zone Enroll Requests, if the controller does not report
+// it. addresses may be nil.
+func NewEnroller(dispatcher *dispatch.Dispatcher, cieAddress zigbee.MACAddress, addresses *zigbee.AddressMap) *Enroller {
+	enroller := &Enroller{
+		dispatcher:     dispatcher,
+		addresses:      addresses,
+		CIEAddress:     cieAddress,
+		SourceEndpoint: 1,
+		zones:          make(map[zigbee.MACAddress]uint8),
+	}
+	dispatcher.AddHandler(enroller)
+	return enroller
+}
+
+// ZoneID returns the zone ID assigned to the device.
+func (e *Enroller) ZoneID(address zigbee.MACAddress) (uint8, bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	zoneID, ok := e.zones[address]
+	return zoneID, ok
+}
+
+// Zones returns a copy of the zone table.
+func (e *Enroller) Zones() map[zigbee.MACAddress]uint8 {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	zones := make(map[zigbee.MACAddress]uint8, len(e.zones))
+	for address, zoneID := range e.zones {
+		zones[address] = zoneID
+	}
+	return zones
+}
+
+// SetZones replaces the zone table, e.g. with a table saved by a previous run.
+// It returns ErrInvalidZoneTable if the table contains zone ID 0xff or assigns
+// the same zone ID to multiple devices.
+func (e *Enroller) SetZones(zones map[zigbee.MACAddress]uint8) error {
+	table := make(map[zigbee.MACAddress]uint8, len(zones))
+	var used [MaxZones]bool
+	for address, zoneID := range zones {
+		if zoneID >= MaxZones || used[zoneID] {
+			return ErrInvalidZoneTable
+		}
+		used[zoneID] = true
+		table[address] = zoneID
+	}
+
+	e.mutex.Lock()
+	e.zones = table
+	e.mutex.Unlock()
+	return nil
+}
+
+// RemoveZone removes the device from the zone table, so that its zone ID can
+// be assigned to another device.
+func (e *Enroller) RemoveZone(address zigbee.MACAddress) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	delete(e.zones, address)
+}
+
+// Enroll writes the IAS_CIE_Address attribute of the device and then sends
+// an unsolicited Zone Enroll Response. Devices which use the trip-to-pair
+// procedure instead send a Zone Enroll Request after the address has been
+// written, which is answered by HandleMessage.
+//
+// Enroll returns ErrTooManyZones if all zone IDs have been assigned.
+func (e *Enroller) Enroll(ctx context.Context, address zigbee.MACAddress, endpoint uint8) error {
+	destination := zigbee.Address{Mode: zigbee.AddressModeIEEE, Extended: address}
+	if e.addresses != nil {
+		destination = e.addresses.Complete(destination)
+	}
+
+	err := e.writeCIEAddress(ctx, destination, endpoint)
+	if err != nil {
+		return err
+	}
+
+	zoneID, err := e.assignZoneID(address)
+	if err != nil {
+		return err
+	}
+
+	return e.respond(destination, endpoint, e.dispatcher.NextTransactionSequenceNumber(), zcl.EnrollResponseCodeSuccess, zoneID)
+}
+
+func (e *Enroller) writeCIEAddress(ctx context.Context, destination zigbee.Address, endpoint uint8) error {
+	data, err := zcl.SerializeWriteAttributesCommand(zcl.WriteAttributesCommand{
+		Records: []zcl.WriteAttributeRecord{{
+			AttributeID: zcl.AttributeIASZoneIASCIEAddress,
+			DataType:    zcl.DataTypeIEEEAddress,
+			Value:       uint64(e.CIEAddress),
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("writing CIE address: %w", err)
+	}
+
+	frame := zcl.Frame{
+		FrameHeader: zcl.FrameHeader{
+			Type:      zcl.FrameTypeGlobal,
+			CommandID: zcl.CommandWriteAttributes,
+		},
+		Data: data,
+	}
+
+	response, err := e.dispatcher.RequestFrame(ctx, e.message(destination, endpoint), frame)
+	if err != nil {
+		return fmt.Errorf("writing CIE address: %w", err)
+	}
+
+	switch {
+	case response.Type == zcl.FrameTypeGlobal && response.CommandID == zcl.CommandWriteAttributesResponse:
+		cmd, err := zcl.ParseWriteAttributesResponseCommand(response.Data)
+		if err != nil {
+			return fmt.Errorf("writing CIE address: %w", err)
+		}
+		for _, record := range cmd.Records {
+			if record.Status != zcl.StatusSuccess {
+				return fmt.Errorf("writing CIE address: %v", record.Status)
+			}
+		}
+	case response.Type == zcl.FrameTypeGlobal && response.CommandID == zcl.CommandDefaultResponse:
+		cmd, err := zcl.ParseDefaultResponseCommand(response.Data)
+		if err != nil {
+			return fmt.Errorf("writing CIE address: %w", err)
+		}
+		return fmt.Errorf("writing CIE address: default response: %v", cmd.Status)
+	default:
+		return fmt.Errorf("writing CIE address: %w", ErrUnexpectedResponse)
+	}
+
+	return nil
+}
+
+// HandleMessage answers Zone Enroll Requests. Zone Status Change
+// Notifications are passed on to the application.
+func (e *Enroller) HandleMessage(message zigbee.IncomingMessage) bool {
+	if message.ClusterID != uint16(zcl.ClusterSecurityIASZone) {
+		return false
+	}
+
+	frame, err := zcl.ParseFrame(message.Data)
+	if err != nil {
+		return false
+	}
+
+	command, err := zcl.ParseClusterCommand(zcl.ClusterSecurityIASZone, frame)
+	if err != nil {
+		return false
+	}
+
+	if _, ok := command.(*zcl.ZoneEnrollRequestCommand); !ok {
+		return false
+	}
+
+	address, ok := e.ieeeAddress(message.Source)
+	if !ok {
+		// The device cannot be identified, so let the application decide.
+		return false
+	}
+
+	// Sending must not block the dispatcher.
+	zoneID, enrolled := e.ZoneID(address)
+	switch {
+	case enrolled:
+		go e.respond(message.Source, message.SourceEndpoint, frame.TransSeqNumber, zcl.EnrollResponseCodeSuccess, zoneID)
+	case e.AutoEnroll:
+		go e.autoEnroll(address, message.Source, message.SourceEndpoint, frame.TransSeqNumber)
+	default:
+		go e.respond(message.Source, message.SourceEndpoint, frame.TransSeqNumber, zcl.EnrollResponseCodeNoEnrollPermit, MaxZones)
+	}
+
+	return true
+}
+
+func (e *Enroller) autoEnroll(address zigbee.MACAddress, source zigbee.Address, endpoint uint8, transSeqNumber uint8) {
+	ctx, cancel := context.WithTimeout(context.Background(), AutoEnrollTimeout)
+	defer cancel()
+
+	// If writing fails, the request is not answered and the device repeats it.
+	if e.writeCIEAddress(ctx, source, endpoint) != nil {
+		return
+	}
+
+	zoneID, err := e.assignZoneID(address)
+	if err != nil {
+		e.respond(source, endpoint, transSeqNumber, zcl.EnrollResponseCodeTooManyZones, MaxZones)
+		return
+	}
+
+	e.respond(source, endpoint, transSeqNumber, zcl.EnrollResponseCodeSuccess, zoneID)
+}
+
+func (e *Enroller) respond(destination zigbee.Address, endpoint uint8, transSeqNumber uint8, code zcl.EnrollResponseCode, zoneID uint8) error {
+	frame := zcl.NewClusterCommandFrame(&zcl.ZoneEnrollResponseCommand{
+		EnrollResponseCode: code,
+		ZoneID:             zoneID,
+	})
+	frame.TransSeqNumber = transSeqNumber
+	message := e.message(destination, endpoint)
+	message.Data = zcl.SerializeFrame(frame)
+	return e.dispatcher.Send(message)
+}
+
+func (e *Enroller) ieeeAddress(source zigbee.Address) (zigbee.MACAddress, bool) {
+	if source.Mode == zigbee.AddressModeIEEE || source.Mode == zigbee.AddressModeCombined {
+		return source.Extended, true
+	}
+	if e.addresses != nil {
+		return e.addresses.IEEEAddress(source.Short)
+	}
+	return 0, false
+}
+
+// assignZoneID returns the zone ID of the device, assigning the lowest free
+// zone ID if the device has none yet.
+func (e *Enroller) assignZoneID(address zigbee.MACAddress) (uint8, error) {
+	e.mutex.Lock()
+
+	if zoneID, ok := e.zones[address]; ok {
+		e.mutex.Unlock()
+		return zoneID, nil
+	}
+
+	var used [MaxZones]bool
+	for _, zoneID := range e.zones {
+		used[zoneID] = true
+	}
+
+	zoneID := uint8(0)
+	for zoneID < MaxZones && used[zoneID] {
+		zoneID++
+	}
+	if zoneID == MaxZones {
+		e.mutex.Unlock()
+		return 0, ErrTooManyZones
+	}
+
+	e.zones[address] = zoneID
+	e.mutex.Unlock()
+
+	if e.OnZoneAssigned != nil {
+		e.OnZoneAssigned(address, zoneID)
+	}
+	return zoneID, nil
+}
+
+func (e *Enroller) message(destination zigbee.Address, endpoint uint8) zigbee.OutgoingMessage {
+	return zigbee.OutgoingMessage{
+		Destination:         destination,
+		DestinationEndpoint: endpoint,
+		SourceEndpoint:      e.SourceEndpoint,
+		ClusterID:           uint16(zcl.ClusterSecurityIASZone),
+		Radius:              zigbee.DefaultRadius,
+	}
+}
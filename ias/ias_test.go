@@ -0,0 +1,240 @@
+package ias
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/dispatch"
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+// testDevice simulates the IAS Zone cluster server of a single device.
+type testDevice struct {
+	incoming   chan zigbee.IncomingMessage
+	cieAddress chan uint64
+	enrolled   chan *zcl.ZoneEnrollResponseCommand
+}
+
+func newTestDevice() *testDevice {
+	return &testDevice{
+		incoming:   make(chan zigbee.IncomingMessage, 16),
+		cieAddress: make(chan uint64, 1),
+		enrolled:   make(chan *zcl.ZoneEnrollResponseCommand, 1),
+	}
+}
+
+func (d *testDevice) Start() (chan zigbee.IncomingMessage, error) { return d.incoming, nil }
+func (d *testDevice) Close() error                                { close(d.incoming); return nil }
+func (d *testDevice) PermitJoining(enabled bool) error            { return nil }
+
+func (d *testDevice) Send(message zigbee.OutgoingMessage) error {
+	frame, err := zcl.ParseFrame(message.Data)
+	if err != nil {
+		return err
+	}
+
+	if frame.Type == zcl.FrameTypeGlobal && frame.CommandID == zcl.CommandWriteAttributes {
+		cmd, err := zcl.ParseWriteAttributesCommand(frame.Data)
+		if err != nil {
+			return err
+		}
+		var response zcl.WriteAttributesResponseCommand
+		for _, record := range cmd.Records {
+			if record.AttributeID == zcl.AttributeIASZoneIASCIEAddress {
+				d.cieAddress <- record.Value.(uint64)
+				continue
+			}
+			response.Records = append(response.Records, zcl.WriteAttributeStatusRecord{
+				Status:      zcl.StatusUnsupportedAttribute,
+				AttributeID: record.AttributeID,
+			})
+		}
+		d.receive(message, zcl.Frame{
+			FrameHeader: zcl.FrameHeader{
+				Type:                    zcl.FrameTypeGlobal,
+				DirectionServerToClient: true,
+				TransSeqNumber:          frame.TransSeqNumber,
+				CommandID:               zcl.CommandWriteAttributesResponse,
+			},
+			Data: zcl.SerializeWriteAttributesResponseCommand(response),
+		})
+		return nil
+	}
+
+	command, err := zcl.ParseClusterCommand(zcl.ClusterID(message.ClusterID), frame)
+	if err != nil {
+		return err
+	}
+	if cmd, ok := command.(*zcl.ZoneEnrollResponseCommand); ok {
+		d.enrolled <- cmd
+	}
+	return nil
+}
+
+func (d *testDevice) receive(message zigbee.OutgoingMessage, frame zcl.Frame) {
+	d.incoming <- zigbee.IncomingMessage{
+		Source:              message.Destination,
+		SourceEndpoint:      message.DestinationEndpoint,
+		DestinationEndpoint: message.SourceEndpoint,
+		ClusterID:           message.ClusterID,
+		Data:                zcl.SerializeFrame(frame),
+	}
+}
+
+func enrollRequest(device *testDevice, source zigbee.Address) {
+	request := zcl.NewClusterCommandFrame(&zcl.ZoneEnrollRequestCommand{ZoneType: zcl.ZoneTypeWaterSensor})
+	request.TransSeqNumber = 0x42
+	device.receive(zigbee.OutgoingMessage{
+		Destination:         source,
+		DestinationEndpoint: 1,
+		SourceEndpoint:      1,
+		ClusterID:           uint16(zcl.ClusterSecurityIASZone),
+	}, request)
+}
+
+func expectResponse(t *testing.T, device *testDevice, output chan zigbee.IncomingMessage, code zcl.EnrollResponseCode, zoneID uint8) {
+	t.Helper()
+	select {
+	case cmd := <-device.enrolled:
+		if cmd.EnrollResponseCode != code || cmd.ZoneID != zoneID {
+			t.Errorf("wrong response: %+v", cmd)
+		}
+	case message := <-output:
+		t.Fatalf("enroll request not consumed: %+v", message)
+	case <-time.After(time.Second):
+		t.Fatal("no enroll response")
+	}
+}
+
+func TestEnroll(t *testing.T) {
+	device := newTestDevice()
+	dispatcher := dispatch.New(device)
+	output, err := dispatcher.Start()
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	var addresses zigbee.AddressMap
+	addresses.Update(0x1234, 0x0011223344556677)
+	enroller := NewEnroller(dispatcher, 0x00124b0001020304, &addresses)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err = enroller.Enroll(ctx, 0x0011223344556677, 1)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	if address := <-device.cieAddress; address != 0x00124b0001020304 {
+		t.Errorf("wrong CIE address: 0x%016x", address)
+	}
+	expectResponse(t, device, output, zcl.EnrollResponseCodeSuccess, 0)
+
+	// The zone ID is kept when the device rejoins with a new network address.
+	addresses.Update(0x5678, 0x0011223344556677)
+	enrollRequest(device, zigbee.Address{Mode: zigbee.AddressModeNWK, Short: 0x5678})
+	expectResponse(t, device, output, zcl.EnrollResponseCodeSuccess, 0)
+
+	// Unknown devices are not enrolled automatically.
+	enrollRequest(device, zigbee.Address{Mode: zigbee.AddressModeCombined, Short: 0x9abc, Extended: 0x8899aabbccddeeff})
+	expectResponse(t, device, output, zcl.EnrollResponseCodeNoEnrollPermit, 0xff)
+
+	if zoneID, ok := enroller.ZoneID(0x8899aabbccddeeff); ok {
+		t.Errorf("unexpected zone ID: %d", zoneID)
+	}
+}
+
+func TestAutoEnroll(t *testing.T) {
+	device := newTestDevice()
+	dispatcher := dispatch.New(device)
+	output, err := dispatcher.Start()
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	enroller := NewEnroller(dispatcher, 0x00124b0001020304, nil)
+	enroller.AutoEnroll = true
+	assigned := make(chan uint8, 1)
+	enroller.OnZoneAssigned = func(address zigbee.MACAddress, zoneID uint8) {
+		assigned <- zoneID
+	}
+	if err := enroller.SetZones(map[zigbee.MACAddress]uint8{0x0011223344556677: 0}); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	enrollRequest(device, zigbee.Address{Mode: zigbee.AddressModeCombined, Short: 0x9abc, Extended: 0x8899aabbccddeeff})
+
+	select {
+	case address := <-device.cieAddress:
+		if address != 0x00124b0001020304 {
+			t.Errorf("wrong CIE address: 0x%016x", address)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("CIE address not written")
+	}
+	expectResponse(t, device, output, zcl.EnrollResponseCodeSuccess, 1)
+
+	if zoneID := <-assigned; zoneID != 1 {
+		t.Errorf("wrong assigned zone ID: %d", zoneID)
+	}
+	zones := enroller.Zones()
+	if len(zones) != 2 || zones[0x8899aabbccddeeff] != 1 {
+		t.Errorf("wrong zones: %v", zones)
+	}
+}
+
+func TestZoneTable(t *testing.T) {
+	device := newTestDevice()
+	dispatcher := dispatch.New(device)
+	output, err := dispatcher.Start()
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	enroller := NewEnroller(dispatcher, 0x00124b0001020304, nil)
+
+	if err := enroller.SetZones(map[zigbee.MACAddress]uint8{1: 0xff}); err != ErrInvalidZoneTable {
+		t.Error("unexpected err:", err)
+	}
+	if err := enroller.SetZones(map[zigbee.MACAddress]uint8{1: 3, 2: 3}); err != ErrInvalidZoneTable {
+		t.Error("unexpected err:", err)
+	}
+
+	zones := make(map[zigbee.MACAddress]uint8)
+	for zoneID := 0; zoneID < MaxZones-1; zoneID++ {
+		zones[zigbee.MACAddress(zoneID+1)] = uint8(zoneID)
+	}
+	if err := enroller.SetZones(zones); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// The last zone ID is 0xfe.
+	if err := enroller.Enroll(ctx, 0x0011223344556677, 1); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	<-device.cieAddress
+	expectResponse(t, device, output, zcl.EnrollResponseCodeSuccess, 0xfe)
+
+	if err := enroller.Enroll(ctx, 0x8899aabbccddeeff, 1); !errors.Is(err, ErrTooManyZones) {
+		t.Fatal("unexpected err:", err)
+	}
+	<-device.cieAddress
+
+	// Removing a device frees its zone ID.
+	enroller.RemoveZone(0x0000000000000006)
+	if err := enroller.Enroll(ctx, 0x8899aabbccddeeff, 1); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	<-device.cieAddress
+	expectResponse(t, device, output, zcl.EnrollResponseCodeSuccess, 5)
+}
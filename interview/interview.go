@@ -0,0 +1,321 @@
+// Package interview discovers what a device is, usually right after it joined
+// the network.
+//
+// An interview requests the addresses, the node descriptor, the active
+// endpoints and the simple descriptor of each endpoint using the ZigBee Device
+// Profile and then reads the identification attributes of the Basic cluster.
+// ZDP requests are sent with dispatch.Dispatcher.RequestZDP, which passes them
+// to the controller if it sends ZDP requests itself (e.g. znp.Controller).
+//
+// Sleepy end devices only turn on their receiver from time to time to poll
+// their parent for messages, which is why every request is retried several
+// times. The interview should be started as soon as possible after the device
+// announced itself, because most battery powered devices stay awake for a few
+// seconds after joining.
+package interview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/dispatch"
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zdp"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+var ErrUnexpectedResponse = errors.New("unexpected response")
+
+// IEEEAddressRequester is implemented by controllers which keep track of the
+// IEEE addresses of devices (e.g. znp.Controller). If the controller wrapped
+// by the dispatcher implements this interface, it is used instead of an
+// IEEE_addr_req.
+type IEEEAddressRequester interface {
+	IEEEAddress(ctx context.Context, nwkAddr uint16) (zigbee.MACAddress, error)
+}
+
+// A DeviceDescription contains the results of an interview. It only consists
+// of plain data, so it can be persisted, for example using encoding/json.
+type DeviceDescription struct {
+	NetworkAddress uint16
+	IEEEAddress    zigbee.MACAddress
+	NodeDescriptor zdp.NodeDescriptor
+	Endpoints      []zdp.SimpleDescriptor
+
+	// Attributes of the Basic cluster. Attributes not supported by the
+	// device are left empty.
+	BasicEndpoint    uint8 // the endpoint the attributes were read from, 0 if none
+	ManufacturerName string
+	ModelIdentifier  string
+	PowerSource      zcl.PowerSource
+	SWBuildID        string
+	DateCode         string
+
+	InterviewedAt time.Time
+}
+
+// Sleepy returns true if the device turns off its receiver when idle.
+func (d *DeviceDescription) Sleepy() bool {
+	return d.NodeDescriptor.MACCapabilities&zdp.MACCapabilityReceiverOnWhenIdle == 0
+}
+
+// Endpoint returns the simple descriptor of an endpoint.
+func (d *DeviceDescription) Endpoint(endpoint uint8) (zdp.SimpleDescriptor, bool) {
+	for _, descriptor := range d.Endpoints {
+		if descriptor.Endpoint == endpoint {
+			return descriptor, true
+		}
+	}
+	return zdp.SimpleDescriptor{}, false
+}
+
+// FindServer returns the first endpoint implementing the server side of a
+// cluster.
+func (d *DeviceDescription) FindServer(clusterID zcl.ClusterID) (uint8, bool) {
+	for _, descriptor := range d.Endpoints {
+		for _, id := range descriptor.InClusters {
+			if id == uint16(clusterID) {
+				return descriptor.Endpoint, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// Options control how requests are repeated.
+type Options struct {
+	// Attempts is the maximum number of times each request is sent.
+	Attempts int
+	// Timeout is the time to wait for a response to each attempt.
+	Timeout time.Duration
+	// SourceEndpoint is the local endpoint used to read attributes.
+	SourceEndpoint uint8
+}
+
+// DefaultOptions are used by Interview. The timeout is long enough for a
+// parent to deliver a message to a sleepy child after its next poll.
+var DefaultOptions = Options{
+	Attempts:       4,
+	Timeout:        8 * time.Second,
+	SourceEndpoint: 1,
+}
+
+// Interview interviews the device with the given network address using the
+// DefaultOptions.
+func Interview(ctx context.Context, dispatcher *dispatch.Dispatcher, nwkAddr uint16) (DeviceDescription, error) {
+	return InterviewWithOptions(ctx, dispatcher, nwkAddr, DefaultOptions)
+}
+
+// InterviewWithOptions interviews the device with the given network address.
+// On error, the returned description contains the information gathered before
+// the failing step.
+func InterviewWithOptions(ctx context.Context, dispatcher *dispatch.Dispatcher, nwkAddr uint16, options Options) (DeviceDescription, error) {
+	i := &interviewer{dispatcher: dispatcher, options: options}
+	d := DeviceDescription{NetworkAddress: nwkAddr}
+
+	ieeeAddr, err := i.ieeeAddress(ctx, nwkAddr)
+	if err != nil {
+		return d, fmt.Errorf("requesting IEEE address: %w", err)
+	}
+	d.IEEEAddress = ieeeAddr
+
+	response, err := i.requestZDP(ctx, nwkAddr, &zdp.NodeDescReq{NWKAddrOfInterest: nwkAddr})
+	if err != nil {
+		return d, fmt.Errorf("requesting node descriptor: %w", err)
+	}
+	nodeRsp, ok := response.(*zdp.NodeDescRsp)
+	if !ok {
+		return d, fmt.Errorf("requesting node descriptor: %w", ErrUnexpectedResponse)
+	}
+	if nodeRsp.Status != zdp.StatusSuccess {
+		return d, fmt.Errorf("requesting node descriptor: %w", &zdp.StatusError{ClusterID: zdp.ClusterNodeDescRsp, Status: nodeRsp.Status})
+	}
+	d.NodeDescriptor = nodeRsp.NodeDescriptor
+
+	endpoints, err := i.activeEndpoints(ctx, nwkAddr)
+	if err != nil {
+		return d, fmt.Errorf("requesting active endpoints: %w", err)
+	}
+
+	for _, endpoint := range endpoints {
+		response, err := i.requestZDP(ctx, nwkAddr, &zdp.SimpleDescReq{NWKAddrOfInterest: nwkAddr, Endpoint: endpoint})
+		if err != nil {
+			return d, fmt.Errorf("requesting simple descriptor of endpoint %d: %w", endpoint, err)
+		}
+		simpleRsp, ok := response.(*zdp.SimpleDescRsp)
+		if !ok {
+			return d, fmt.Errorf("requesting simple descriptor of endpoint %d: %w", endpoint, ErrUnexpectedResponse)
+		}
+		if simpleRsp.Status != zdp.StatusSuccess {
+			return d, fmt.Errorf("requesting simple descriptor of endpoint %d: %w", endpoint, &zdp.StatusError{ClusterID: zdp.ClusterSimpleDescRsp, Status: simpleRsp.Status})
+		}
+		d.Endpoints = append(d.Endpoints, simpleRsp.SimpleDescriptor)
+	}
+
+	if endpoint, ok := d.FindServer(zcl.ClusterGeneralBasic); ok {
+		values, err := i.readAttributes(ctx, nwkAddr, endpoint, zcl.ClusterGeneralBasic,
+			zcl.AttributeBasicManufacturerName,
+			zcl.AttributeBasicModelIdentifier,
+			zcl.AttributeBasicPowerSource,
+			zcl.AttributeBasicSWBuildID,
+			zcl.AttributeBasicDateCode,
+		)
+		if err != nil {
+			return d, fmt.Errorf("reading basic attributes: %w", err)
+		}
+		d.BasicEndpoint = endpoint
+		d.ManufacturerName, _ = values[zcl.AttributeBasicManufacturerName].(string)
+		d.ModelIdentifier, _ = values[zcl.AttributeBasicModelIdentifier].(string)
+		if powerSource, ok := values[zcl.AttributeBasicPowerSource].(uint8); ok {
+			d.PowerSource = zcl.PowerSource(powerSource)
+		}
+		d.SWBuildID, _ = values[zcl.AttributeBasicSWBuildID].(string)
+		d.DateCode, _ = values[zcl.AttributeBasicDateCode].(string)
+	}
+
+	d.InterviewedAt = time.Now()
+	return d, nil
+}
+
+type interviewer struct {
+	dispatcher *dispatch.Dispatcher
+	options    Options
+}
+
+// retry calls request until it succeeds, the context is done or the number
+// of attempts is exhausted. Each call gets its own timeout.
+func (i *interviewer) retry(ctx context.Context, request func(ctx context.Context) error) error {
+	var err error
+	for attempt := 0; attempt < i.options.Attempts || attempt == 0; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, i.options.Timeout)
+		err = request(attemptCtx)
+		cancel()
+
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+
+		// A response from the device will not change when asking again.
+		var statusError *zdp.StatusError
+		if errors.As(err, &statusError) || errors.Is(err, ErrUnexpectedResponse) {
+			return err
+		}
+	}
+	return err
+}
+
+func (i *interviewer) requestZDP(ctx context.Context, nwkAddr uint16, command interface{}) (interface{}, error) {
+	var response interface{}
+	err := i.retry(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = i.dispatcher.RequestZDP(ctx, nwkAddr, command)
+		return err
+	})
+	return response, err
+}
+
+func (i *interviewer) ieeeAddress(ctx context.Context, nwkAddr uint16) (zigbee.MACAddress, error) {
+	var ieeeAddr zigbee.MACAddress
+	err := i.retry(ctx, func(ctx context.Context) error {
+		if requester, ok := i.dispatcher.Controller().(IEEEAddressRequester); ok {
+			var err error
+			ieeeAddr, err = requester.IEEEAddress(ctx, nwkAddr)
+			return err
+		}
+
+		response, err := i.dispatcher.RequestZDP(ctx, nwkAddr, &zdp.IEEEAddrReq{NWKAddrOfInterest: nwkAddr})
+		if err != nil {
+			return err
+		}
+		rsp, ok := response.(*zdp.IEEEAddrRsp)
+		if !ok {
+			return ErrUnexpectedResponse
+		}
+		if rsp.Status != zdp.StatusSuccess {
+			return &zdp.StatusError{ClusterID: zdp.ClusterIEEEAddrRsp, Status: rsp.Status}
+		}
+		ieeeAddr = rsp.IEEEAddrRemoteDev
+		return nil
+	})
+	return ieeeAddr, err
+}
+
+func (i *interviewer) activeEndpoints(ctx context.Context, nwkAddr uint16) ([]uint8, error) {
+	var endpoints []uint8
+	err := i.retry(ctx, func(ctx context.Context) error {
+		response, err := i.dispatcher.RequestZDP(ctx, nwkAddr, &zdp.ActiveEPReq{NWKAddrOfInterest: nwkAddr})
+		if err != nil {
+			return err
+		}
+		rsp, ok := response.(*zdp.ActiveEPRsp)
+		if !ok {
+			return ErrUnexpectedResponse
+		}
+		if rsp.Status != zdp.StatusSuccess {
+			return &zdp.StatusError{ClusterID: zdp.ClusterActiveEPRsp, Status: rsp.Status}
+		}
+		endpoints = rsp.ActiveEPs
+		return nil
+	})
+	return endpoints, err
+}
+
+// readAttributes returns the values of the attributes supported by the device.
+func (i *interviewer) readAttributes(ctx context.Context, nwkAddr uint16, endpoint uint8, clusterID zcl.ClusterID, attributes ...zcl.AttributeID) (map[zcl.AttributeID]interface{}, error) {
+	message := zigbee.OutgoingMessage{
+		Destination:         zigbee.Address{Mode: zigbee.AddressModeNWK, Short: nwkAddr},
+		DestinationEndpoint: endpoint,
+		SourceEndpoint:      i.options.SourceEndpoint,
+		ClusterID:           uint16(clusterID),
+		Radius:              zigbee.DefaultRadius,
+	}
+
+	frame := zcl.Frame{
+		FrameHeader: zcl.FrameHeader{
+			Type:      zcl.FrameTypeGlobal,
+			CommandID: zcl.CommandReadAttributes,
+		},
+		Data: zcl.SerializeReadAttributesCommand(zcl.ReadAttributesCommand{Attributes: attributes}),
+	}
+
+	var response zcl.Frame
+	err := i.retry(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = i.dispatcher.RequestFrame(ctx, message, frame)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Type != zcl.FrameTypeGlobal {
+		return nil, ErrUnexpectedResponse
+	}
+
+	switch response.CommandID {
+	case zcl.CommandReadAttributesResponse:
+		cmd, err := zcl.ParseReadAttributesResponseCommand(response.Data)
+		if err != nil {
+			return nil, err
+		}
+		values := make(map[zcl.AttributeID]interface{})
+		for _, record := range cmd.Records {
+			if record.Status == zcl.StatusSuccess {
+				values[record.AttributeID] = record.Value
+			}
+		}
+		return values, nil
+
+	case zcl.CommandDefaultResponse:
+		cmd, err := zcl.ParseDefaultResponseCommand(response.Data)
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("default response: %v", cmd.Status)
+
+	default:
+		return nil, ErrUnexpectedResponse
+	}
+}
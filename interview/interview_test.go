@@ -0,0 +1,268 @@
+package interview
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/dispatch"
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zdp"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+var testEndpoints = []zdp.SimpleDescriptor{
+	{Endpoint: 1, ProfileID: zigbee.ProfileHomeAutomation, DeviceID: 0x0302, DeviceVersion: 1, InClusters: []uint16{0x0000, 0x0001, 0x0402}, OutClusters: []uint16{0x0019}},
+	{Endpoint: 2, ProfileID: zigbee.ProfileHomeAutomation, DeviceID: 0x0302, InClusters: []uint16{0x0405}, OutClusters: []uint16{}},
+}
+
+var testNodeDescriptor = zdp.NodeDescriptor{
+	LogicalType:      zdp.LogicalTypeEndDevice,
+	FrequencyBand:    zdp.FrequencyBand2400MHz,
+	MACCapabilities:  zdp.MACCapabilityAllocateAddress,
+	ManufacturerCode: 0x115f,
+	MaxBufferSize:    80,
+}
+
+// testDevice simulates a sleepy device, which ignores the first request of
+// each type.
+type testDevice struct {
+	incoming chan zigbee.IncomingMessage
+
+	mutex    sync.Mutex
+	requests map[uint16]int // by cluster ID
+}
+
+func newTestDevice() *testDevice {
+	return &testDevice{
+		incoming: make(chan zigbee.IncomingMessage, 16),
+		requests: make(map[uint16]int),
+	}
+}
+
+func (d *testDevice) Start() (chan zigbee.IncomingMessage, error) { return d.incoming, nil }
+func (d *testDevice) Close() error                                { close(d.incoming); return nil }
+func (d *testDevice) PermitJoining(enabled bool) error            { return nil }
+
+func (d *testDevice) Send(message zigbee.OutgoingMessage) error {
+	d.mutex.Lock()
+	d.requests[message.ClusterID]++
+	first := d.requests[message.ClusterID] == 1
+	d.mutex.Unlock()
+
+	if first {
+		return nil
+	}
+
+	if message.DestinationEndpoint == 0 {
+		return d.respondZDP(message)
+	}
+
+	frame, err := zcl.ParseFrame(message.Data)
+	if err != nil {
+		return err
+	}
+	if message.ClusterID != uint16(zcl.ClusterGeneralBasic) || frame.CommandID != zcl.CommandReadAttributes {
+		return errors.New("unexpected message")
+	}
+
+	cmd, err := zcl.ParseReadAttributesCommand(frame.Data)
+	if err != nil {
+		return err
+	}
+	values := map[zcl.AttributeID]zcl.ReadAttributeStatusRecord{
+		zcl.AttributeBasicManufacturerName: {DataType: zcl.DataTypeCharacterString, Value: "LUMI"},
+		zcl.AttributeBasicModelIdentifier:  {DataType: zcl.DataTypeCharacterString, Value: "lumi.weather"},
+		zcl.AttributeBasicPowerSource:      {DataType: zcl.DataTypeEnum8, Value: uint8(zcl.PowerSourceBattery)},
+		zcl.AttributeBasicDateCode:         {DataType: zcl.DataTypeCharacterString, Value: "20161129"},
+	}
+	var response zcl.ReadAttributesResponseCommand
+	for _, attributeID := range cmd.Attributes {
+		record, ok := values[attributeID]
+		if !ok {
+			record.Status = zcl.StatusUnsupportedAttribute
+		}
+		record.AttributeID = attributeID
+		response.Records = append(response.Records, record)
+	}
+	data, err := zcl.SerializeReadAttributesResponseCommand(response)
+	if err != nil {
+		return err
+	}
+
+	d.incoming <- zigbee.IncomingMessage{
+		Source:              message.Destination,
+		SourceEndpoint:      message.DestinationEndpoint,
+		DestinationEndpoint: message.SourceEndpoint,
+		ClusterID:           message.ClusterID,
+		Data: zcl.SerializeFrame(zcl.Frame{
+			FrameHeader: zcl.FrameHeader{
+				Type:                    zcl.FrameTypeGlobal,
+				DirectionServerToClient: true,
+				TransSeqNumber:          frame.TransSeqNumber,
+				CommandID:               zcl.CommandReadAttributesResponse,
+			},
+			Data: data,
+		}),
+	}
+	return nil
+}
+
+func (d *testDevice) respondZDP(message zigbee.OutgoingMessage) error {
+	tsn, command, err := zdp.ParseFrame(message.ClusterID, message.Data)
+	if err != nil {
+		return err
+	}
+	response, err := zdpResponse(command)
+	if err != nil {
+		return err
+	}
+
+	clusterID, data, err := zdp.SerializeFrame(tsn, response)
+	if err != nil {
+		return err
+	}
+	d.incoming <- zigbee.IncomingMessage{
+		Source:    message.Destination,
+		ClusterID: clusterID,
+		Data:      data,
+	}
+	return nil
+}
+
+func zdpResponse(command interface{}) (interface{}, error) {
+	var response interface{}
+	switch cmd := command.(type) {
+	case *zdp.IEEEAddrReq:
+		response = &zdp.IEEEAddrRsp{Status: zdp.StatusSuccess, IEEEAddrRemoteDev: 0x00158d0001234567, NWKAddrRemoteDev: cmd.NWKAddrOfInterest}
+	case *zdp.NodeDescReq:
+		response = &zdp.NodeDescRsp{Status: zdp.StatusSuccess, NWKAddrOfInterest: cmd.NWKAddrOfInterest, NodeDescriptor: testNodeDescriptor}
+	case *zdp.ActiveEPReq:
+		response = &zdp.ActiveEPRsp{Status: zdp.StatusSuccess, NWKAddrOfInterest: cmd.NWKAddrOfInterest, ActiveEPs: []uint8{1, 2}}
+	case *zdp.SimpleDescReq:
+		for _, descriptor := range testEndpoints {
+			if descriptor.Endpoint == cmd.Endpoint {
+				response = &zdp.SimpleDescRsp{Status: zdp.StatusSuccess, NWKAddrOfInterest: cmd.NWKAddrOfInterest, SimpleDescriptor: descriptor}
+			}
+		}
+		if response == nil {
+			response = &zdp.SimpleDescRsp{Status: zdp.StatusInvalidEP, NWKAddrOfInterest: cmd.NWKAddrOfInterest}
+		}
+	default:
+		return nil, errors.New("unexpected request")
+	}
+	return response, nil
+}
+
+// testZNPDevice behaves like a ZNP: ZDP messages passed to Send are never
+// answered, but the controller sends ZDP requests itself and additionally
+// provides the IEEE address directly.
+type testZNPDevice struct {
+	*testDevice
+}
+
+func (d *testZNPDevice) Send(message zigbee.OutgoingMessage) error {
+	if message.DestinationEndpoint == 0 {
+		return nil
+	}
+	return d.testDevice.Send(message)
+}
+
+func (d *testZNPDevice) RequestZDP(ctx context.Context, nwkAddr uint16, command interface{}) (interface{}, error) {
+	return zdpResponse(command)
+}
+
+func (d *testZNPDevice) IEEEAddress(ctx context.Context, nwkAddr uint16) (zigbee.MACAddress, error) {
+	return 0x00124b0001abcdef, nil
+}
+
+var testOptions = Options{Attempts: 3, Timeout: 50 * time.Millisecond, SourceEndpoint: 1}
+
+func TestInterview(t *testing.T) {
+	device := newTestDevice()
+	dispatcher := dispatch.New(device)
+	if _, err := dispatcher.Start(); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	d, err := InterviewWithOptions(context.Background(), dispatcher, 0x1234, testOptions)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	if d.NetworkAddress != 0x1234 || d.IEEEAddress != 0x00158d0001234567 {
+		t.Errorf("wrong addresses: 0x%04x %v", d.NetworkAddress, d.IEEEAddress)
+	}
+	if d.NodeDescriptor != testNodeDescriptor {
+		t.Errorf("wrong node descriptor: %+v", d.NodeDescriptor)
+	}
+	if !d.Sleepy() {
+		t.Errorf("device should be sleepy")
+	}
+	if !reflect.DeepEqual(d.Endpoints, testEndpoints) {
+		t.Errorf("wrong endpoints: %+v", d.Endpoints)
+	}
+	if d.BasicEndpoint != 1 || d.ManufacturerName != "LUMI" || d.ModelIdentifier != "lumi.weather" || d.DateCode != "20161129" || d.SWBuildID != "" {
+		t.Errorf("wrong basic attributes: %+v", d)
+	}
+	if d.PowerSource != zcl.PowerSourceBattery {
+		t.Errorf("wrong power source: %v", d.PowerSource)
+	}
+	if endpoint, ok := d.FindServer(zcl.ClusterMSRelativeHumidity); !ok || endpoint != 2 {
+		t.Errorf("wrong humidity endpoint: %d", endpoint)
+	}
+	if d.InterviewedAt.IsZero() {
+		t.Errorf("missing interview time")
+	}
+}
+
+func TestInterviewRequesters(t *testing.T) {
+	device := &testZNPDevice{testDevice: newTestDevice()}
+	dispatcher := dispatch.New(device)
+	if _, err := dispatcher.Start(); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	d, err := InterviewWithOptions(context.Background(), dispatcher, 0x1234, testOptions)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if d.IEEEAddress != 0x00124b0001abcdef {
+		t.Errorf("IEEE address requester not used: %v", d.IEEEAddress)
+	}
+	if d.NodeDescriptor != testNodeDescriptor {
+		t.Errorf("wrong node descriptor: %+v", d.NodeDescriptor)
+	}
+	if !reflect.DeepEqual(d.Endpoints, testEndpoints) {
+		t.Errorf("wrong endpoints: %+v", d.Endpoints)
+	}
+	if d.ManufacturerName != "LUMI" {
+		t.Errorf("wrong basic attributes: %+v", d)
+	}
+}
+
+func TestInterviewTimeout(t *testing.T) {
+	device := newTestDevice()
+	dispatcher := dispatch.New(device)
+	if _, err := dispatcher.Start(); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	// The device never answers the second attempt.
+	options := testOptions
+	options.Attempts = 1
+
+	d, err := InterviewWithOptions(context.Background(), dispatcher, 0x1234, options)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected timeout, got %v", err)
+	}
+	if d.NetworkAddress != 0x1234 || d.IEEEAddress != 0 {
+		t.Errorf("wrong partial description: %+v", d)
+	}
+}
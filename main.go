@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"fmt"
+	"os"
 
 	"github.com/GreenLightning/zigbee-conductor/controller/controllerregistry"
 	"github.com/GreenLightning/zigbee-conductor/zcl"
@@ -14,17 +15,32 @@ func main() {
 	controllerFlag := flag.String("controller", "znp", "type of the controller: conbee, znp")
 	permitJoinFlag := flag.Bool("permitJoin", false, "permit devices to join the network")
 
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [topology [topology flags]]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
+	command := flag.Arg(0)
+	if command != "" && command != "topology" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
 	controller, err := controllerregistry.NewController(*controllerFlag, zigbee.ControllerSettings{
 		Port:        *portFlag,
-		LogCommands: true,
+		LogCommands: true, // logged to stderr, so the topology on stdout stays clean
 		LogErrors:   true,
 	})
 	check(err)
 
 	defer controller.Close()
 
+	if command == "topology" {
+		runTopology(controller, flag.Args()[1:])
+		return
+	}
+
 	incoming, err := controller.Start()
 	check(err)
 
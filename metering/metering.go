@@ -0,0 +1,338 @@
+// Package metering converts the raw values of the Metering and Electrical
+// Measurement clusters into physical units.
+//
+// Both clusters report integer values which have to be multiplied and divided
+// by device-specific factors. The Converter reads these factors once per
+// device endpoint and caches them.
+package metering
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/GreenLightning/zigbee-conductor/dispatch"
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+var ErrUnexpectedResponse = errors.New("unexpected response")
+
+// An Endpoint is an endpoint of a device implementing the server side of the
+// Metering or Electrical Measurement cluster.
+type Endpoint struct {
+	Address  uint16 // network address
+	Endpoint uint8
+}
+
+// A Factor converts a raw value into a physical value.
+type Factor struct {
+	Multiplier uint32
+	Divisor    uint32
+}
+
+// Apply returns raw * Multiplier / Divisor. As required by the specification,
+// a multiplier or divisor of zero is treated as one.
+func (f Factor) Apply(raw float64) float64 {
+	multiplier, divisor := f.Multiplier, f.Divisor
+	if multiplier == 0 {
+		multiplier = 1
+	}
+	if divisor == 0 {
+		divisor = 1
+	}
+	return raw * float64(multiplier) / float64(divisor)
+}
+
+// ElectricalFormatting contains the AC formatting attributes of the
+// Electrical Measurement cluster.
+type ElectricalFormatting struct {
+	Voltage Factor // to volts
+	Current Factor // to amperes
+	Power   Factor // to watts
+}
+
+// MeteringFormatting contains the formatting attributes of the Metering cluster.
+type MeteringFormatting struct {
+	UnitOfMeasure uint8
+	Factor        Factor // to the unit of measure (e.g. kWh)
+}
+
+type formatting struct {
+	electrical *ElectricalFormatting
+	metering   *MeteringFormatting
+}
+
+// Converter reads and caches the formatting attributes of devices.
+type Converter struct {
+	dispatcher *dispatch.Dispatcher
+
+	// SourceEndpoint is the local endpoint used to send commands.
+	SourceEndpoint uint8
+
+	mutex       sync.Mutex
+	formattings map[Endpoint]*formatting
+}
+
+func NewConverter(dispatcher *dispatch.Dispatcher) *Converter {
+	return &Converter{
+		dispatcher:     dispatcher,
+		SourceEndpoint: 1,
+		formattings:    make(map[Endpoint]*formatting),
+	}
+}
+
+// LoadElectricalMeasurement reads the AC multiplier and divisor attributes of
+// the Electrical Measurement cluster. Attributes not supported by the device
+// default to one.
+func (c *Converter) LoadElectricalMeasurement(ctx context.Context, endpoint Endpoint) (ElectricalFormatting, error) {
+	values, err := c.readAttributes(ctx, endpoint, zcl.ClusterMSElectricalMeasurement,
+		zcl.AttributeElectricalMeasurementACVoltageMultiplier,
+		zcl.AttributeElectricalMeasurementACVoltageDivisor,
+		zcl.AttributeElectricalMeasurementACCurrentMultiplier,
+		zcl.AttributeElectricalMeasurementACCurrentDivisor,
+		zcl.AttributeElectricalMeasurementACPowerMultiplier,
+		zcl.AttributeElectricalMeasurementACPowerDivisor,
+	)
+	if err != nil {
+		return ElectricalFormatting{}, fmt.Errorf("reading electrical measurement formatting: %w", err)
+	}
+
+	result := ElectricalFormatting{
+		Voltage: Factor{
+			Multiplier: factorValue(values[zcl.AttributeElectricalMeasurementACVoltageMultiplier]),
+			Divisor:    factorValue(values[zcl.AttributeElectricalMeasurementACVoltageDivisor]),
+		},
+		Current: Factor{
+			Multiplier: factorValue(values[zcl.AttributeElectricalMeasurementACCurrentMultiplier]),
+			Divisor:    factorValue(values[zcl.AttributeElectricalMeasurementACCurrentDivisor]),
+		},
+		Power: Factor{
+			Multiplier: factorValue(values[zcl.AttributeElectricalMeasurementACPowerMultiplier]),
+			Divisor:    factorValue(values[zcl.AttributeElectricalMeasurementACPowerDivisor]),
+		},
+	}
+
+	c.mutex.Lock()
+	c.formatting(endpoint).electrical = &result
+	c.mutex.Unlock()
+
+	return result, nil
+}
+
+// LoadMetering reads the UnitOfMeasure, Multiplier and Divisor attributes of
+// the Metering cluster. Attributes not supported by the device default to
+// kWh and one respectively.
+func (c *Converter) LoadMetering(ctx context.Context, endpoint Endpoint) (MeteringFormatting, error) {
+	values, err := c.readAttributes(ctx, endpoint, zcl.ClusterSEMetering,
+		zcl.AttributeMeteringUnitOfMeasure,
+		zcl.AttributeMeteringMultiplier,
+		zcl.AttributeMeteringDivisor,
+	)
+	if err != nil {
+		return MeteringFormatting{}, fmt.Errorf("reading metering formatting: %w", err)
+	}
+
+	result := MeteringFormatting{
+		UnitOfMeasure: zcl.UnitOfMeasureKilowattHours,
+		Factor: Factor{
+			Multiplier: factorValue(values[zcl.AttributeMeteringMultiplier]),
+			Divisor:    factorValue(values[zcl.AttributeMeteringDivisor]),
+		},
+	}
+	if unit, ok := values[zcl.AttributeMeteringUnitOfMeasure].(uint8); ok {
+		result.UnitOfMeasure = unit
+	}
+
+	c.mutex.Lock()
+	c.formatting(endpoint).metering = &result
+	c.mutex.Unlock()
+
+	return result, nil
+}
+
+// Forget removes the cached formatting of an endpoint, for example after the
+// device has left the network.
+func (c *Converter) Forget(endpoint Endpoint) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.formattings, endpoint)
+}
+
+// Convert converts a reported attribute value into a physical value and
+// returns it together with its unit symbol. Supported attributes are
+// ActivePower (W), ReactivePower (var), ApparentPower (VA), RMSVoltage (V) and
+// RMSCurrent (A) of the Electrical Measurement cluster as well as
+// CurrentSummationDelivered, CurrentSummationReceived (kWh for electricity
+// meters) and InstantaneousDemand (W for electricity meters) of the Metering cluster.
+//
+// The last return value is false if the attribute is not supported, the value
+// is invalid or the formatting of the endpoint has not been loaded.
+func (c *Converter) Convert(endpoint Endpoint, clusterID zcl.ClusterID, attributeID zcl.AttributeID, value interface{}) (float64, string, bool) {
+	raw, ok := rawValue(value)
+	if !ok {
+		return 0, "", false
+	}
+
+	c.mutex.Lock()
+	f := c.formattings[endpoint]
+	var electrical *ElectricalFormatting
+	var metering *MeteringFormatting
+	if f != nil {
+		electrical, metering = f.electrical, f.metering
+	}
+	c.mutex.Unlock()
+
+	switch clusterID {
+	case zcl.ClusterMSElectricalMeasurement:
+		if electrical == nil {
+			return 0, "", false
+		}
+		switch attributeID {
+		case zcl.AttributeElectricalMeasurementActivePower:
+			return electrical.Power.Apply(raw), "W", true
+		case zcl.AttributeElectricalMeasurementReactivePower:
+			return electrical.Power.Apply(raw), "var", true
+		case zcl.AttributeElectricalMeasurementApparentPower:
+			return electrical.Power.Apply(raw), "VA", true
+		case zcl.AttributeElectricalMeasurementRMSVoltage:
+			return electrical.Voltage.Apply(raw), "V", true
+		case zcl.AttributeElectricalMeasurementRMSCurrent:
+			return electrical.Current.Apply(raw), "A", true
+		}
+
+	case zcl.ClusterSEMetering:
+		if metering == nil {
+			return 0, "", false
+		}
+		symbol, ok := unitSymbols[metering.UnitOfMeasure&0x7f]
+		if !ok {
+			return 0, "", false
+		}
+		switch attributeID {
+		case zcl.AttributeMeteringCurrentSummationDelivered, zcl.AttributeMeteringCurrentSummationReceived:
+			return metering.Factor.Apply(raw), symbol, true
+		case zcl.AttributeMeteringInstantaneousDemand:
+			// Demand is reported in the unit of measure per hour.
+			if metering.UnitOfMeasure&0x7f == zcl.UnitOfMeasureKilowattHours {
+				return metering.Factor.Apply(raw) * 1000, "W", true
+			}
+			return metering.Factor.Apply(raw), symbol + "/h", true
+		}
+	}
+
+	return 0, "", false
+}
+
+var unitSymbols = map[uint8]string{
+	zcl.UnitOfMeasureKilowattHours:      "kWh",
+	zcl.UnitOfMeasureCubicMeters:        "m³",
+	zcl.UnitOfMeasureCubicFeet:          "ft³",
+	zcl.UnitOfMeasureCentumCubicFeet:    "ccf",
+	zcl.UnitOfMeasureUSGallons:          "US gal",
+	zcl.UnitOfMeasureImperialGallons:    "imp gal",
+	zcl.UnitOfMeasureBTUs:               "BTU",
+	zcl.UnitOfMeasureLiters:             "l",
+	zcl.UnitOfMeasureKilopascalGauge:    "kPa",
+	zcl.UnitOfMeasureKilopascalAbsolute: "kPa",
+	zcl.UnitOfMeasureMegaCubicFeet:      "mcf",
+	zcl.UnitOfMeasureUnitless:           "",
+	zcl.UnitOfMeasureMegajoule:          "MJ",
+}
+
+// formatting must be called with the mutex held.
+func (c *Converter) formatting(endpoint Endpoint) *formatting {
+	f, ok := c.formattings[endpoint]
+	if !ok {
+		f = new(formatting)
+		c.formattings[endpoint] = f
+	}
+	return f
+}
+
+func (c *Converter) readAttributes(ctx context.Context, endpoint Endpoint, clusterID zcl.ClusterID, attributes ...zcl.AttributeID) (map[zcl.AttributeID]interface{}, error) {
+	message := zigbee.OutgoingMessage{
+		Destination:         zigbee.Address{Mode: zigbee.AddressModeNWK, Short: endpoint.Address},
+		DestinationEndpoint: endpoint.Endpoint,
+		SourceEndpoint:      c.SourceEndpoint,
+		ClusterID:           uint16(clusterID),
+		Radius:              zigbee.DefaultRadius,
+	}
+
+	frame := zcl.Frame{
+		FrameHeader: zcl.FrameHeader{
+			Type:      zcl.FrameTypeGlobal,
+			CommandID: zcl.CommandReadAttributes,
+		},
+		Data: zcl.SerializeReadAttributesCommand(zcl.ReadAttributesCommand{Attributes: attributes}),
+	}
+
+	response, err := c.dispatcher.RequestFrame(ctx, message, frame)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Type != zcl.FrameTypeGlobal {
+		return nil, ErrUnexpectedResponse
+	}
+
+	switch response.CommandID {
+	case zcl.CommandReadAttributesResponse:
+		cmd, err := zcl.ParseReadAttributesResponseCommand(response.Data)
+		if err != nil {
+			return nil, err
+		}
+		values := make(map[zcl.AttributeID]interface{})
+		for _, record := range cmd.Records {
+			if record.Status == zcl.StatusSuccess {
+				values[record.AttributeID] = record.Value
+			}
+		}
+		return values, nil
+
+	case zcl.CommandDefaultResponse:
+		cmd, err := zcl.ParseDefaultResponseCommand(response.Data)
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("default response: %v", cmd.Status)
+
+	default:
+		return nil, ErrUnexpectedResponse
+	}
+}
+
+func factorValue(value interface{}) uint32 {
+	switch v := value.(type) {
+	case uint16:
+		return uint32(v)
+	case uint32:
+		return v
+	default:
+		return 1
+	}
+}
+
+func rawValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
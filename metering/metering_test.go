@@ -0,0 +1,130 @@
+package metering
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/dispatch"
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+// testDevice simulates a smart plug which answers Read Attributes commands.
+type testDevice struct {
+	incoming   chan zigbee.IncomingMessage
+	attributes map[zcl.ClusterID]map[zcl.AttributeID]zcl.AttributeReport
+}
+
+func (d *testDevice) Start() (chan zigbee.IncomingMessage, error) { return d.incoming, nil }
+func (d *testDevice) Close() error                                { close(d.incoming); return nil }
+func (d *testDevice) PermitJoining(enabled bool) error            { return nil }
+
+func (d *testDevice) Send(message zigbee.OutgoingMessage) error {
+	frame, err := zcl.ParseFrame(message.Data)
+	if err != nil {
+		return err
+	}
+	cmd, err := zcl.ParseReadAttributesCommand(frame.Data)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	for _, id := range cmd.Attributes {
+		data = append(data, byte(id), byte(id>>8))
+		attribute, ok := d.attributes[zcl.ClusterID(message.ClusterID)][id]
+		if !ok {
+			data = append(data, byte(zcl.StatusUnsupportedAttribute))
+			continue
+		}
+		value, err := zcl.SerializeValue(attribute.DataType, attribute.Value)
+		if err != nil {
+			return err
+		}
+		data = append(data, byte(zcl.StatusSuccess), byte(attribute.DataType))
+		data = append(data, value...)
+	}
+
+	response := zcl.Frame{
+		FrameHeader: zcl.FrameHeader{
+			Type:                    zcl.FrameTypeGlobal,
+			DirectionServerToClient: true,
+			TransSeqNumber:          frame.TransSeqNumber,
+			CommandID:               zcl.CommandReadAttributesResponse,
+		},
+		Data: data,
+	}
+
+	d.incoming <- zigbee.IncomingMessage{
+		Source:              message.Destination,
+		SourceEndpoint:      message.DestinationEndpoint,
+		DestinationEndpoint: message.SourceEndpoint,
+		ClusterID:           message.ClusterID,
+		Data:                zcl.SerializeFrame(response),
+	}
+	return nil
+}
+
+func TestConvert(t *testing.T) {
+	device := &testDevice{
+		incoming: make(chan zigbee.IncomingMessage, 16),
+		attributes: map[zcl.ClusterID]map[zcl.AttributeID]zcl.AttributeReport{
+			zcl.ClusterMSElectricalMeasurement: {
+				zcl.AttributeElectricalMeasurementACVoltageDivisor: {DataType: zcl.DataTypeUint16, Value: uint16(10)},
+				zcl.AttributeElectricalMeasurementACCurrentDivisor: {DataType: zcl.DataTypeUint16, Value: uint16(1000)},
+				zcl.AttributeElectricalMeasurementACPowerDivisor:   {DataType: zcl.DataTypeUint16, Value: uint16(10)},
+			},
+			zcl.ClusterSEMetering: {
+				zcl.AttributeMeteringUnitOfMeasure: {DataType: zcl.DataTypeEnum8, Value: uint8(zcl.UnitOfMeasureKilowattHours)},
+				zcl.AttributeMeteringMultiplier:    {DataType: zcl.DataTypeUint24, Value: uint32(1)},
+				zcl.AttributeMeteringDivisor:       {DataType: zcl.DataTypeUint24, Value: uint32(100)},
+			},
+		},
+	}
+
+	dispatcher := dispatch.New(device)
+	if _, err := dispatcher.Start(); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	converter := NewConverter(dispatcher)
+	endpoint := Endpoint{Address: 0x1234, Endpoint: 1}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if _, err := converter.LoadElectricalMeasurement(ctx, endpoint); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if _, err := converter.LoadMetering(ctx, endpoint); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	testCases := []struct {
+		ClusterID   zcl.ClusterID
+		AttributeID zcl.AttributeID
+		Value       interface{}
+		Expected    float64
+		Unit        string
+	}{
+		{zcl.ClusterMSElectricalMeasurement, zcl.AttributeElectricalMeasurementActivePower, int16(1234), 123.4, "W"},
+		{zcl.ClusterMSElectricalMeasurement, zcl.AttributeElectricalMeasurementRMSVoltage, uint16(2301), 230.1, "V"},
+		{zcl.ClusterMSElectricalMeasurement, zcl.AttributeElectricalMeasurementRMSCurrent, uint16(536), 0.536, "A"},
+		{zcl.ClusterSEMetering, zcl.AttributeMeteringCurrentSummationDelivered, uint64(123456), 1234.56, "kWh"},
+		{zcl.ClusterSEMetering, zcl.AttributeMeteringInstantaneousDemand, int32(12), 120, "W"},
+	}
+
+	for _, tc := range testCases {
+		value, unit, ok := converter.Convert(endpoint, tc.ClusterID, tc.AttributeID, tc.Value)
+		if !ok || unit != tc.Unit || math.Abs(value-tc.Expected) > 1e-9 {
+			t.Errorf("wrong value for %v/%v: expected %v %s, actual %v %s (%v)", tc.ClusterID, tc.AttributeID, tc.Expected, tc.Unit, value, unit, ok)
+		}
+	}
+
+	if _, _, ok := converter.Convert(Endpoint{Address: 0x5678, Endpoint: 1}, zcl.ClusterMSElectricalMeasurement, zcl.AttributeElectricalMeasurementActivePower, int16(1)); ok {
+		t.Error("converted value of unknown endpoint")
+	}
+}
@@ -0,0 +1,181 @@
+package ota
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// FileIdentifier is the magic number at the start of every OTA image.
+const FileIdentifier = 0x0beef11e
+
+var (
+	ErrNoFileIdentifier = errors.New("no OTA file identifier found")
+	ErrInvalidImage     = errors.New("invalid OTA image")
+)
+
+// Bits of the HeaderFieldControl field.
+const (
+	headerSecurityCredentialVersionPresent = 1 << 0
+	headerDeviceSpecificFile               = 1 << 1
+	headerHardwareVersionsPresent          = 1 << 2
+)
+
+// Tags of the sub-elements of an image.
+const (
+	TagUpgradeImage            uint16 = 0x0000
+	TagECDSASignature          uint16 = 0x0001
+	TagECDSASigningCertificate uint16 = 0x0002
+	TagImageIntegrityCode      uint16 = 0x0003
+)
+
+// Header is the OTA header as specified in the ZigBee OTA Upgrade cluster.
+// Optional fields are nil if they are not present.
+type Header struct {
+	HeaderVersion             uint16
+	HeaderLength              uint16
+	FieldControl              uint16
+	ManufacturerCode          uint16
+	ImageType                 uint16
+	FileVersion               uint32
+	ZigBeeStackVersion        uint16
+	HeaderString              string
+	TotalImageSize            uint32
+	SecurityCredentialVersion *uint8
+	UpgradeFileDestination    *uint64
+	MinimumHardwareVersion    *uint16
+	MaximumHardwareVersion    *uint16
+}
+
+type SubElement struct {
+	Tag  uint16
+	Data []byte
+}
+
+// An Image is an OTA upgrade file.
+type Image struct {
+	Header      Header
+	SubElements []SubElement
+
+	// Data contains the complete image (starting with the OTA header) as it
+	// is transferred to the device.
+	Data []byte
+}
+
+// LoadImage reads and parses an OTA upgrade file.
+func LoadImage(path string) (*Image, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	image, err := ParseImage(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return image, nil
+}
+
+// ParseImage parses an OTA upgrade file. Some manufacturers prepend their own
+// header to the OTA image, therefore the data is searched for the OTA file
+// identifier and everything before it is ignored.
+func ParseImage(data []byte) (*Image, error) {
+	var identifier [4]byte
+	binary.LittleEndian.PutUint32(identifier[:], FileIdentifier)
+	start := bytes.Index(data, identifier[:])
+	if start < 0 {
+		return nil, ErrNoFileIdentifier
+	}
+	data = data[start:]
+
+	if len(data) < 56 {
+		return nil, fmt.Errorf("%w: header too short", ErrInvalidImage)
+	}
+
+	var header Header
+	header.HeaderVersion = binary.LittleEndian.Uint16(data[4:])
+	header.HeaderLength = binary.LittleEndian.Uint16(data[6:])
+	header.FieldControl = binary.LittleEndian.Uint16(data[8:])
+	header.ManufacturerCode = binary.LittleEndian.Uint16(data[10:])
+	header.ImageType = binary.LittleEndian.Uint16(data[12:])
+	header.FileVersion = binary.LittleEndian.Uint32(data[14:])
+	header.ZigBeeStackVersion = binary.LittleEndian.Uint16(data[18:])
+	header.HeaderString = strings.TrimRight(string(data[20:52]), "\x00")
+	header.TotalImageSize = binary.LittleEndian.Uint32(data[52:])
+
+	if int(header.HeaderLength) < 56 || int(header.HeaderLength) > len(data) {
+		return nil, fmt.Errorf("%w: invalid header length %d", ErrInvalidImage, header.HeaderLength)
+	}
+	if header.TotalImageSize < uint32(header.HeaderLength) || int64(header.TotalImageSize) > int64(len(data)) {
+		return nil, fmt.Errorf("%w: invalid total image size %d", ErrInvalidImage, header.TotalImageSize)
+	}
+
+	optional := data[56:header.HeaderLength]
+	if header.FieldControl&headerSecurityCredentialVersionPresent != 0 {
+		if len(optional) < 1 {
+			return nil, fmt.Errorf("%w: header too short", ErrInvalidImage)
+		}
+		version := optional[0]
+		header.SecurityCredentialVersion = &version
+		optional = optional[1:]
+	}
+	if header.FieldControl&headerDeviceSpecificFile != 0 {
+		if len(optional) < 8 {
+			return nil, fmt.Errorf("%w: header too short", ErrInvalidImage)
+		}
+		destination := binary.LittleEndian.Uint64(optional)
+		header.UpgradeFileDestination = &destination
+		optional = optional[8:]
+	}
+	if header.FieldControl&headerHardwareVersionsPresent != 0 {
+		if len(optional) < 4 {
+			return nil, fmt.Errorf("%w: header too short", ErrInvalidImage)
+		}
+		minimum := binary.LittleEndian.Uint16(optional)
+		maximum := binary.LittleEndian.Uint16(optional[2:])
+		header.MinimumHardwareVersion = &minimum
+		header.MaximumHardwareVersion = &maximum
+	}
+
+	image := &Image{
+		Header: header,
+		Data:   data[:header.TotalImageSize],
+	}
+
+	elements := image.Data[header.HeaderLength:]
+	for len(elements) != 0 {
+		if len(elements) < 6 {
+			return nil, fmt.Errorf("%w: truncated sub-element", ErrInvalidImage)
+		}
+		tag := binary.LittleEndian.Uint16(elements)
+		length := binary.LittleEndian.Uint32(elements[2:])
+		elements = elements[6:]
+		if int64(length) > int64(len(elements)) {
+			return nil, fmt.Errorf("%w: truncated sub-element", ErrInvalidImage)
+		}
+		image.SubElements = append(image.SubElements, SubElement{Tag: tag, Data: elements[:length]})
+		elements = elements[length:]
+	}
+
+	return image, nil
+}
+
+// SupportsHardwareVersion reports whether the image can be installed on a
+// device with the given hardware version.
+func (image *Image) SupportsHardwareVersion(version uint16) bool {
+	header := &image.Header
+	if header.MinimumHardwareVersion != nil && version < *header.MinimumHardwareVersion {
+		return false
+	}
+	if header.MaximumHardwareVersion != nil && version > *header.MaximumHardwareVersion {
+		return false
+	}
+	return true
+}
+
+func (image *Image) String() string {
+	return fmt.Sprintf("OTA image 0x%04x/0x%04x version 0x%08x (%d bytes)",
+		image.Header.ManufacturerCode, image.Header.ImageType, image.Header.FileVersion, len(image.Data))
+}
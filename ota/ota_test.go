@@ -0,0 +1,292 @@
+package ota
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/dispatch"
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+func buildImage(fileVersion uint32, payload []byte) []byte {
+	header := make([]byte, 60)
+	binary.LittleEndian.PutUint32(header[0:], FileIdentifier)
+	binary.LittleEndian.PutUint16(header[4:], 0x0100)
+	binary.LittleEndian.PutUint16(header[6:], uint16(len(header)))
+	binary.LittleEndian.PutUint16(header[8:], headerHardwareVersionsPresent)
+	binary.LittleEndian.PutUint16(header[10:], 0x117c)
+	binary.LittleEndian.PutUint16(header[12:], 0x2101)
+	binary.LittleEndian.PutUint32(header[14:], fileVersion)
+	binary.LittleEndian.PutUint16(header[18:], 0x0002)
+	copy(header[20:52], "Test Image")
+	binary.LittleEndian.PutUint32(header[52:], uint32(len(header)+6+len(payload)))
+	binary.LittleEndian.PutUint16(header[56:], 1)
+	binary.LittleEndian.PutUint16(header[58:], 3)
+
+	element := make([]byte, 6)
+	binary.LittleEndian.PutUint16(element[0:], TagUpgradeImage)
+	binary.LittleEndian.PutUint32(element[2:], uint32(len(payload)))
+
+	data := append(header, element...)
+	return append(data, payload...)
+}
+
+func testPayload(size int) []byte {
+	payload := make([]byte, size)
+	for i := range payload {
+		payload[i] = byte(i)
+	}
+	return payload
+}
+
+func TestParseImage(t *testing.T) {
+	payload := testPayload(100)
+	data := buildImage(0x01020304, payload)
+
+	// Vendor-specific prefixes and trailing data are ignored.
+	prefixed := append([]byte("VENDOR"), data...)
+	prefixed = append(prefixed, 0xff, 0xff)
+
+	image, err := ParseImage(prefixed)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	header := image.Header
+	if header.ManufacturerCode != 0x117c || header.ImageType != 0x2101 || header.FileVersion != 0x01020304 {
+		t.Errorf("wrong header: %+v", header)
+	}
+	if header.HeaderString != "Test Image" {
+		t.Errorf("wrong header string: %q", header.HeaderString)
+	}
+	if header.MinimumHardwareVersion == nil || *header.MinimumHardwareVersion != 1 || header.MaximumHardwareVersion == nil || *header.MaximumHardwareVersion != 3 {
+		t.Errorf("wrong hardware versions: %v, %v", header.MinimumHardwareVersion, header.MaximumHardwareVersion)
+	}
+	if !bytes.Equal(image.Data, data) {
+		t.Errorf("wrong data")
+	}
+	if len(image.SubElements) != 1 || image.SubElements[0].Tag != TagUpgradeImage || !bytes.Equal(image.SubElements[0].Data, payload) {
+		t.Errorf("wrong sub-elements: %+v", image.SubElements)
+	}
+	if image.SupportsHardwareVersion(4) || !image.SupportsHardwareVersion(2) {
+		t.Errorf("wrong hardware version check")
+	}
+
+	_, err = ParseImage(data[:len(data)-1])
+	if err == nil {
+		t.Error("expected error for truncated image")
+	}
+}
+
+// testClient simulates a device downloading an image from the server.
+type testClient struct {
+	incoming chan zigbee.IncomingMessage
+	done     chan zcl.ClusterCommand
+
+	mutex              sync.Mutex
+	data               []byte
+	minimumBlockPeriod uint16
+	waits              int
+}
+
+func newTestClient() *testClient {
+	return &testClient{
+		incoming: make(chan zigbee.IncomingMessage, 16),
+		done:     make(chan zcl.ClusterCommand, 1),
+	}
+}
+
+func (c *testClient) Start() (chan zigbee.IncomingMessage, error) { return c.incoming, nil }
+func (c *testClient) Close() error                                { close(c.incoming); return nil }
+func (c *testClient) PermitJoining(enabled bool) error            { return nil }
+
+func (c *testClient) Send(message zigbee.OutgoingMessage) error {
+	frame, err := zcl.ParseFrame(message.Data)
+	if err != nil {
+		return err
+	}
+	command, err := zcl.ParseClusterCommand(zcl.ClusterGeneralOTAUpgrade, frame)
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	switch cmd := command.(type) {
+	case *zcl.QueryNextImageResponseCommand:
+		if cmd.Status != zcl.StatusSuccess {
+			c.done <- cmd
+			return nil
+		}
+		c.requestBlock(cmd.ManufacturerCode, cmd.ImageType, cmd.FileVersion)
+
+	case *zcl.ImageBlockResponseCommand:
+		switch cmd.Status {
+		case zcl.StatusSuccess:
+			if int(cmd.FileOffset) != len(c.data) {
+				c.done <- cmd
+				return nil
+			}
+			c.data = append(c.data, cmd.ImageData...)
+			if len(c.data) < len(testImage.Data) {
+				c.requestBlock(cmd.ManufacturerCode, cmd.ImageType, cmd.FileVersion)
+			} else {
+				c.send(&zcl.UpgradeEndRequestCommand{
+					Status:           zcl.StatusSuccess,
+					ManufacturerCode: cmd.ManufacturerCode,
+					ImageType:        cmd.ImageType,
+					FileVersion:      cmd.FileVersion,
+				})
+			}
+		case zcl.StatusWaitForData:
+			c.waits++
+			c.minimumBlockPeriod = cmd.MinimumBlockPeriod
+			header := testImage.Header
+			c.requestBlock(header.ManufacturerCode, header.ImageType, header.FileVersion)
+		default:
+			c.done <- cmd
+		}
+
+	case *zcl.UpgradeEndResponseCommand:
+		c.done <- cmd
+	}
+
+	return nil
+}
+
+func (c *testClient) requestBlock(manufacturerCode, imageType uint16, fileVersion uint32) {
+	period := c.minimumBlockPeriod
+	c.send(&zcl.ImageBlockRequestCommand{
+		ManufacturerCode:   manufacturerCode,
+		ImageType:          imageType,
+		FileVersion:        fileVersion,
+		FileOffset:         uint32(len(c.data)),
+		MaximumDataSize:    100,
+		MinimumBlockPeriod: &period,
+	})
+}
+
+func (c *testClient) send(command zcl.ClusterCommand) {
+	c.incoming <- zigbee.IncomingMessage{
+		Source:              zigbee.Address{Mode: zigbee.AddressModeNWK, Short: 0x1234},
+		SourceEndpoint:      1,
+		DestinationEndpoint: 1,
+		ClusterID:           uint16(zcl.ClusterGeneralOTAUpgrade),
+		Data:                zcl.SerializeFrame(zcl.NewClusterCommandFrame(command)),
+	}
+}
+
+var testImage, _ = ParseImage(buildImage(0x00000002, testPayload(300)))
+
+func TestServer(t *testing.T) {
+	client := newTestClient()
+	dispatcher := dispatch.New(client)
+	if _, err := dispatcher.Start(); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	var mutex sync.Mutex
+	var progress []Progress
+
+	server := NewServer(dispatcher)
+	server.MinimumBlockPeriod = 5
+	server.OnProgress = func(p Progress) {
+		mutex.Lock()
+		progress = append(progress, p)
+		mutex.Unlock()
+	}
+	server.AddImage(testImage)
+
+	client.send(&zcl.QueryNextImageRequestCommand{ManufacturerCode: 0x117c, ImageType: 0x2101, CurrentFileVersion: 0x00000001})
+
+	select {
+	case command := <-client.done:
+		if _, ok := command.(*zcl.UpgradeEndResponseCommand); !ok {
+			t.Fatalf("unexpected command: %T%+v", command, command)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	if !bytes.Equal(client.data, testImage.Data) {
+		t.Error("wrong image data")
+	}
+	if client.waits != 1 || client.minimumBlockPeriod != 5 {
+		t.Errorf("rate limiting not negotiated: %d waits, period %d", client.waits, client.minimumBlockPeriod)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if len(progress) == 0 {
+		t.Fatal("no progress reported")
+	}
+	if last := progress[len(progress)-1]; !last.Done || last.Status != zcl.StatusSuccess || last.Percent() != 100 {
+		t.Errorf("wrong final progress: %v", last)
+	}
+}
+
+func TestServerNoImage(t *testing.T) {
+	client := newTestClient()
+	dispatcher := dispatch.New(client)
+	if _, err := dispatcher.Start(); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	server := NewServer(dispatcher)
+	server.AddImage(testImage)
+
+	// The device already runs the newest version.
+	client.send(&zcl.QueryNextImageRequestCommand{ManufacturerCode: 0x117c, ImageType: 0x2101, CurrentFileVersion: 0x00000002})
+
+	select {
+	case command := <-client.done:
+		cmd, ok := command.(*zcl.QueryNextImageResponseCommand)
+		if !ok || cmd.Status != zcl.StatusNoImageAvailable {
+			t.Fatalf("unexpected command: %T%+v", command, command)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+}
+
+func TestServerEmptyPage(t *testing.T) {
+	client := newTestClient()
+	dispatcher := dispatch.New(client)
+	if _, err := dispatcher.Start(); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	server := NewServer(dispatcher)
+	server.AddImage(testImage)
+
+	header := testImage.Header
+	client.send(&zcl.ImagePageRequestCommand{
+		ManufacturerCode: header.ManufacturerCode,
+		ImageType:        header.ImageType,
+		FileVersion:      header.FileVersion,
+		MaximumDataSize:  0,
+		PageSize:         100,
+	})
+
+	select {
+	case command := <-client.done:
+		cmd, ok := command.(*zcl.ImageBlockResponseCommand)
+		if !ok || cmd.Status != zcl.StatusAbort {
+			t.Fatalf("unexpected command: %T%+v", command, command)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+}
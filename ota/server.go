@@ -0,0 +1,379 @@
+// Package ota distributes firmware images to devices using the OTA Upgrade cluster.
+//
+// The Server answers the requests of OTA clients (the devices). A device
+// periodically sends a Query Next Image Request and, if a newer image is
+// available, downloads the image block by block. After the download is
+// complete, the device sends an Upgrade End Request and the server tells the
+// device to install the new image immediately.
+package ota
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/dispatch"
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+// DefaultMaximumDataSize is the default size of the blocks sent to devices.
+// It is small enough to fit into a single unfragmented APS frame.
+const DefaultMaximumDataSize = 64
+
+// Progress describes the state of the transfer of an image to a device.
+type Progress struct {
+	Address uint16 // network address
+	Image   *Image
+	Offset  uint32 // number of bytes transferred
+
+	// Done is set after the device has sent an Upgrade End Request. Status
+	// is the status reported by the device (StatusSuccess if the image was
+	// downloaded and verified successfully).
+	Done   bool
+	Status zcl.Status
+}
+
+// Percent returns the progress of the transfer from 0 to 100.
+func (p Progress) Percent() float64 {
+	if len(p.Image.Data) == 0 {
+		return 100
+	}
+	return 100 * float64(p.Offset) / float64(len(p.Image.Data))
+}
+
+func (p Progress) String() string {
+	if p.Done {
+		return fmt.Sprintf("0x%04x: %v: upgrade end: %v", p.Address, p.Image, p.Status)
+	}
+	return fmt.Sprintf("0x%04x: %v: %.1f%%", p.Address, p.Image, p.Percent())
+}
+
+type transfer struct {
+	lastBlock time.Time
+}
+
+// Server implements the server side of the OTA Upgrade cluster.
+type Server struct {
+	dispatcher *dispatch.Dispatcher
+
+	// SourceEndpoint is the local endpoint used to send commands.
+	SourceEndpoint uint8
+
+	// MinimumBlockPeriod is the minimum delay between two block requests of a
+	// device in milliseconds. Zero disables rate limiting. Devices supporting
+	// rate limiting are informed about the period and delay their requests
+	// accordingly. Devices without support are asked to wait if they request
+	// blocks too quickly.
+	MinimumBlockPeriod uint16
+
+	// MaximumDataSize limits the size of the blocks sent to devices.
+	MaximumDataSize uint8
+
+	// OnProgress is called whenever a transfer progresses. It is called
+	// synchronously, possibly from multiple goroutines, and must not block.
+	OnProgress func(progress Progress)
+
+	mutex     sync.Mutex
+	images    []*Image
+	transfers map[uint16]*transfer
+	now       func() time.Time
+}
+
+// NewServer creates a Server and registers it as a handler with the dispatcher.
+func NewServer(dispatcher *dispatch.Dispatcher) *Server {
+	server := &Server{
+		dispatcher:      dispatcher,
+		SourceEndpoint:  1,
+		MaximumDataSize: DefaultMaximumDataSize,
+		transfers:       make(map[uint16]*transfer),
+		now:             time.Now,
+	}
+	dispatcher.AddHandler(server)
+	return server
+}
+
+// AddImage makes an image available to devices.
+func (s *Server) AddImage(image *Image) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.images = append(s.images, image)
+}
+
+// Notify sends an Image Notify command to a device, which causes the device
+// to query the server for a new image immediately instead of waiting for its
+// next periodic query.
+func (s *Server) Notify(address uint16, endpoint uint8, image *Image) error {
+	frame := zcl.NewClusterCommandFrame(&zcl.ImageNotifyCommand{
+		PayloadType:      zcl.ImageNotifyPayloadFileVersion,
+		QueryJitter:      100,
+		ManufacturerCode: image.Header.ManufacturerCode,
+		ImageType:        image.Header.ImageType,
+		NewFileVersion:   image.Header.FileVersion,
+	})
+	frame.TransSeqNumber = s.dispatcher.NextTransactionSequenceNumber()
+	frame.DisableDefaultResponse = true
+
+	return s.dispatcher.Send(zigbee.OutgoingMessage{
+		Destination:         zigbee.Address{Mode: zigbee.AddressModeNWK, Short: address},
+		DestinationEndpoint: endpoint,
+		SourceEndpoint:      s.SourceEndpoint,
+		ClusterID:           uint16(zcl.ClusterGeneralOTAUpgrade),
+		Radius:              zigbee.DefaultRadius,
+		Data:                zcl.SerializeFrame(frame),
+	})
+}
+
+// HandleMessage answers the requests of OTA clients.
+func (s *Server) HandleMessage(message zigbee.IncomingMessage) bool {
+	if message.ClusterID != uint16(zcl.ClusterGeneralOTAUpgrade) {
+		return false
+	}
+
+	frame, err := zcl.ParseFrame(message.Data)
+	if err != nil || frame.DirectionServerToClient {
+		return false
+	}
+
+	command, err := zcl.ParseClusterCommand(zcl.ClusterGeneralOTAUpgrade, frame)
+	if err != nil {
+		return false
+	}
+
+	address := message.Source.Short
+
+	var response zcl.Frame
+	switch cmd := command.(type) {
+	case *zcl.QueryNextImageRequestCommand:
+		response = zcl.NewClusterCommandFrame(s.queryNextImage(address, cmd))
+	case *zcl.ImageBlockRequestCommand:
+		response = zcl.NewClusterCommandFrame(s.imageBlock(address, cmd))
+	case *zcl.ImagePageRequestCommand:
+		go s.imagePage(message, frame.TransSeqNumber, cmd)
+		return true
+	case *zcl.UpgradeEndRequestCommand:
+		response = s.upgradeEnd(address, cmd)
+	default:
+		return false
+	}
+
+	response.TransSeqNumber = frame.TransSeqNumber
+	response.DisableDefaultResponse = true
+
+	// Must not block the dispatcher.
+	go s.dispatcher.Send(s.reply(message, response))
+
+	return true
+}
+
+func (s *Server) queryNextImage(address uint16, cmd *zcl.QueryNextImageRequestCommand) *zcl.QueryNextImageResponseCommand {
+	s.mutex.Lock()
+	var best *Image
+	for _, image := range s.images {
+		header := &image.Header
+		if header.ManufacturerCode != cmd.ManufacturerCode || header.ImageType != cmd.ImageType {
+			continue
+		}
+		if header.FileVersion <= cmd.CurrentFileVersion {
+			continue
+		}
+		if cmd.HardwareVersion != nil && !image.SupportsHardwareVersion(*cmd.HardwareVersion) {
+			continue
+		}
+		if best == nil || header.FileVersion > best.Header.FileVersion {
+			best = image
+		}
+	}
+	s.mutex.Unlock()
+
+	if best == nil {
+		return &zcl.QueryNextImageResponseCommand{Status: zcl.StatusNoImageAvailable}
+	}
+
+	s.progress(Progress{Address: address, Image: best})
+
+	return &zcl.QueryNextImageResponseCommand{
+		Status:           zcl.StatusSuccess,
+		ManufacturerCode: best.Header.ManufacturerCode,
+		ImageType:        best.Header.ImageType,
+		FileVersion:      best.Header.FileVersion,
+		ImageSize:        uint32(len(best.Data)),
+	}
+}
+
+func (s *Server) imageBlock(address uint16, cmd *zcl.ImageBlockRequestCommand) *zcl.ImageBlockResponseCommand {
+	s.mutex.Lock()
+	image := s.findImage(cmd.ManufacturerCode, cmd.ImageType, cmd.FileVersion)
+	// Blocks without data would never advance the transfer.
+	if image == nil || int64(cmd.FileOffset) >= int64(len(image.Data)) || cmd.MaximumDataSize == 0 {
+		s.mutex.Unlock()
+		return &zcl.ImageBlockResponseCommand{Status: zcl.StatusAbort}
+	}
+
+	t, ok := s.transfers[address]
+	if !ok {
+		t = new(transfer)
+		s.transfers[address] = t
+	}
+
+	now := s.now()
+	period := time.Duration(s.MinimumBlockPeriod) * time.Millisecond
+	if cmd.MinimumBlockPeriod != nil {
+		// The device supports rate limiting, but uses an outdated period.
+		if *cmd.MinimumBlockPeriod != s.MinimumBlockPeriod {
+			s.mutex.Unlock()
+			return &zcl.ImageBlockResponseCommand{
+				Status:             zcl.StatusWaitForData,
+				MinimumBlockPeriod: s.MinimumBlockPeriod,
+			}
+		}
+	} else if elapsed := now.Sub(t.lastBlock); period > 0 && elapsed < period {
+		s.mutex.Unlock()
+		// RequestTime is specified in seconds, so wait at least one second.
+		delay := (period - elapsed + time.Second - 1) / time.Second
+		return &zcl.ImageBlockResponseCommand{
+			Status:             zcl.StatusWaitForData,
+			RequestTime:        uint32(delay),
+			MinimumBlockPeriod: s.MinimumBlockPeriod,
+		}
+	}
+	t.lastBlock = now
+	s.mutex.Unlock()
+
+	data := s.block(image, cmd.FileOffset, cmd.MaximumDataSize)
+	s.progress(Progress{Address: address, Image: image, Offset: cmd.FileOffset + uint32(len(data))})
+
+	return &zcl.ImageBlockResponseCommand{
+		Status:           zcl.StatusSuccess,
+		ManufacturerCode: image.Header.ManufacturerCode,
+		ImageType:        image.Header.ImageType,
+		FileVersion:      image.Header.FileVersion,
+		FileOffset:       cmd.FileOffset,
+		ImageData:        data,
+	}
+}
+
+// imagePage sends the blocks of a page spaced by the requested delay. It runs
+// in its own goroutine.
+func (s *Server) imagePage(message zigbee.IncomingMessage, sequenceNumber uint8, cmd *zcl.ImagePageRequestCommand) {
+	address := message.Source.Short
+
+	s.mutex.Lock()
+	image := s.findImage(cmd.ManufacturerCode, cmd.ImageType, cmd.FileVersion)
+	s.mutex.Unlock()
+
+	// Blocks without data would never advance the offset, so the loop below
+	// would send empty responses forever.
+	if image == nil || int64(cmd.FileOffset) >= int64(len(image.Data)) || cmd.MaximumDataSize == 0 {
+		frame := zcl.NewClusterCommandFrame(&zcl.ImageBlockResponseCommand{Status: zcl.StatusAbort})
+		frame.TransSeqNumber = sequenceNumber
+		frame.DisableDefaultResponse = true
+		s.dispatcher.Send(s.reply(message, frame))
+		return
+	}
+
+	end := uint64(cmd.FileOffset) + uint64(cmd.PageSize)
+	if end > uint64(len(image.Data)) {
+		end = uint64(len(image.Data))
+	}
+
+	for offset := cmd.FileOffset; uint64(offset) < end; {
+		maximum := cmd.MaximumDataSize
+		if remaining := end - uint64(offset); remaining < uint64(maximum) {
+			maximum = uint8(remaining)
+		}
+		data := s.block(image, offset, maximum)
+
+		frame := zcl.NewClusterCommandFrame(&zcl.ImageBlockResponseCommand{
+			Status:           zcl.StatusSuccess,
+			ManufacturerCode: image.Header.ManufacturerCode,
+			ImageType:        image.Header.ImageType,
+			FileVersion:      image.Header.FileVersion,
+			FileOffset:       offset,
+			ImageData:        data,
+		})
+		frame.TransSeqNumber = sequenceNumber
+		frame.DisableDefaultResponse = true
+
+		if err := s.dispatcher.Send(s.reply(message, frame)); err != nil {
+			return
+		}
+
+		offset += uint32(len(data))
+		s.progress(Progress{Address: address, Image: image, Offset: offset})
+
+		time.Sleep(time.Duration(cmd.ResponseSpacing) * time.Millisecond)
+	}
+}
+
+func (s *Server) upgradeEnd(address uint16, cmd *zcl.UpgradeEndRequestCommand) zcl.Frame {
+	s.mutex.Lock()
+	image := s.findImage(cmd.ManufacturerCode, cmd.ImageType, cmd.FileVersion)
+	delete(s.transfers, address)
+	s.mutex.Unlock()
+
+	if image != nil {
+		s.progress(Progress{Address: address, Image: image, Offset: uint32(len(image.Data)), Done: true, Status: cmd.Status})
+	}
+
+	if cmd.Status != zcl.StatusSuccess || image == nil {
+		// The device aborts the upgrade, which is acknowledged with a Default Response.
+		return zcl.Frame{
+			FrameHeader: zcl.FrameHeader{
+				Type:                    zcl.FrameTypeGlobal,
+				DirectionServerToClient: true,
+				CommandID:               zcl.CommandDefaultResponse,
+			},
+			Data: zcl.SerializeDefaultResponseCommand(zcl.DefaultResponseCommand{
+				CommandID: zcl.CommandOTAUpgradeUpgradeEndRequest,
+				Status:    zcl.StatusSuccess,
+			}),
+		}
+	}
+
+	// Install the new image immediately.
+	return zcl.NewClusterCommandFrame(&zcl.UpgradeEndResponseCommand{
+		ManufacturerCode: cmd.ManufacturerCode,
+		ImageType:        cmd.ImageType,
+		FileVersion:      cmd.FileVersion,
+	})
+}
+
+// findImage must be called with the mutex held.
+func (s *Server) findImage(manufacturerCode, imageType uint16, fileVersion uint32) *Image {
+	for _, image := range s.images {
+		header := &image.Header
+		if header.ManufacturerCode == manufacturerCode && header.ImageType == imageType && header.FileVersion == fileVersion {
+			return image
+		}
+	}
+	return nil
+}
+
+func (s *Server) block(image *Image, offset uint32, maximumDataSize uint8) []byte {
+	size := uint32(maximumDataSize)
+	if s.MaximumDataSize != 0 && size > uint32(s.MaximumDataSize) {
+		size = uint32(s.MaximumDataSize)
+	}
+	if remaining := uint32(len(image.Data)) - offset; size > remaining {
+		size = remaining
+	}
+	return image.Data[offset : offset+size]
+}
+
+func (s *Server) progress(progress Progress) {
+	if s.OnProgress != nil {
+		s.OnProgress(progress)
+	}
+}
+
+func (s *Server) reply(message zigbee.IncomingMessage, frame zcl.Frame) zigbee.OutgoingMessage {
+	return zigbee.OutgoingMessage{
+		Destination:         zigbee.Address{Mode: zigbee.AddressModeNWK, Short: message.Source.Short},
+		DestinationEndpoint: message.SourceEndpoint,
+		SourceEndpoint:      message.DestinationEndpoint,
+		ClusterID:           uint16(zcl.ClusterGeneralOTAUpgrade),
+		Radius:              zigbee.DefaultRadius,
+		Data:                zcl.SerializeFrame(frame),
+	}
+}
@@ -0,0 +1,409 @@
+// Package kvlog implements a small embedded key-value store backed by an
+// append-only log file.
+//
+// All keys and values are kept in memory. Every change is appended to the log
+// as a record, so writes are cheap and a crash can only lose the record which
+// was being written. The log is compacted automatically when it has grown
+// considerably larger than the live data.
+//
+// Each record has the following format (integers are little-endian):
+//
+//	op (1 byte) | key length (uvarint) | key | value length (uvarint) | value | CRC-32 (4 bytes)
+//
+// The checksum covers all preceding bytes of the record. A truncated or
+// corrupt record at the end of the log is discarded when the store is opened.
+// Corruption anywhere else is reported by Open, because discarding it would
+// also discard all valid records after it.
+//
+// The format is deliberately minimal instead of using an established embedded
+// database (e.g. bbolt or BadgerDB). The module otherwise only depends on a
+// serial port library, the data (a few hundred small values) always fits in
+// memory and a single append-only file is easy to inspect, back up and
+// recover. Applications needing more can implement devicedb.Storage on top of
+// any other store.
+package kvlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrClosed      = errors.New("store closed")
+	ErrCorruptData = errors.New("corrupt data")
+)
+
+const (
+	opPut    = 0x01
+	opDelete = 0x02
+)
+
+// minCompactionSize is the log size below which the log is never compacted.
+const minCompactionSize = 64 * 1024
+
+type Store struct {
+	path string
+
+	mutex    sync.Mutex
+	file     *os.File
+	values   map[string][]byte
+	size     int64 // size of the log file
+	liveSize int64 // size the log would have after compaction
+
+	// broken is set if a failed write could not be rolled back. The log may
+	// end in a partial record then, after which appended records would be
+	// lost, so all further writes fail.
+	broken error
+
+	// compactErr is the error of the last failed automatic compaction, which
+	// is reported by the next call of Sync.
+	compactErr error
+}
+
+// Open opens the store at path, creating the file if necessary.
+func Open(path string) (*Store, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		path:   path,
+		file:   file,
+		values: make(map[string][]byte),
+	}
+
+	valid, err := s.replay(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	// Drop an incomplete record left behind by a crash.
+	if err := file.Truncate(valid); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(valid, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	s.size = valid
+
+	return s, nil
+}
+
+// replay reads all records and returns the length of the valid prefix of the
+// log. Only the last record may be incomplete or corrupt, otherwise replay
+// fails with ErrCorruptData.
+func (s *Store) replay(file *os.File) (int64, error) {
+	reader := bufio.NewReader(file)
+	var valid int64
+	for {
+		op, key, value, length, err := readRecord(reader)
+		if err == io.EOF {
+			return valid, nil
+		}
+		if err == io.ErrUnexpectedEOF || err == ErrCorruptData {
+			return valid, checkTail(file, valid)
+		}
+		if err != nil {
+			return 0, err
+		}
+		valid += length
+		s.apply(op, key, value)
+	}
+}
+
+// checkTail checks that the damaged data starting at offset is the last
+// record of the log, which was being written during a crash. If a valid
+// record follows, the damage is in the middle of the log and the records
+// after it must not be discarded.
+func checkTail(file *os.File, offset int64) error {
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	tail, err := ioutil.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	for start := 1; start < len(tail); start++ {
+		if _, _, _, _, err := readRecord(bufio.NewReader(bytes.NewReader(tail[start:]))); err == nil {
+			return fmt.Errorf("%w at offset %d", ErrCorruptData, offset)
+		}
+	}
+	return nil
+}
+
+func (s *Store) apply(op byte, key string, value []byte) {
+	if old, ok := s.values[key]; ok {
+		s.liveSize -= recordSize(key, old)
+	}
+	if op == opPut {
+		s.values[key] = value
+		s.liveSize += recordSize(key, value)
+	} else {
+		delete(s.values, key)
+	}
+}
+
+func readRecord(reader *bufio.Reader) (op byte, key string, value []byte, length int64, err error) {
+	var record bytes.Buffer
+	tee := io.TeeReader(reader, &record)
+
+	var header [1]byte
+	if _, err = io.ReadFull(tee, header[:]); err != nil {
+		return
+	}
+	op = header[0]
+	if op != opPut && op != opDelete {
+		err = ErrCorruptData
+		return
+	}
+
+	keyData, err := readBytes(tee)
+	if err != nil {
+		return
+	}
+	value, err = readBytes(tee)
+	if err != nil {
+		return
+	}
+
+	checksum := crc32.ChecksumIEEE(record.Bytes())
+	var trailer [4]byte
+	if _, err = io.ReadFull(reader, trailer[:]); err != nil {
+		return
+	}
+	if binary.LittleEndian.Uint32(trailer[:]) != checksum {
+		err = ErrCorruptData
+		return
+	}
+
+	return op, string(keyData), value, int64(record.Len() + 4), nil
+}
+
+func readBytes(reader io.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(byteReader{reader})
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if length > 1<<30 {
+		return nil, ErrCorruptData
+	}
+	// Do not trust the length before the data has actually been read.
+	var data bytes.Buffer
+	n, err := io.CopyN(&data, reader, int64(length))
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if n == 0 && err == nil {
+		return []byte{}, nil
+	}
+	return data.Bytes(), err
+}
+
+// byteReader adapts an io.Reader to io.ByteReader without reading ahead.
+type byteReader struct {
+	io.Reader
+}
+
+func (r byteReader) ReadByte() (byte, error) {
+	var b [1]byte
+	_, err := io.ReadFull(r.Reader, b[:])
+	return b[0], err
+}
+
+func appendRecord(data []byte, op byte, key string, value []byte) []byte {
+	start := len(data)
+	var buffer [binary.MaxVarintLen64]byte
+	data = append(data, op)
+	data = append(data, buffer[:binary.PutUvarint(buffer[:], uint64(len(key)))]...)
+	data = append(data, key...)
+	data = append(data, buffer[:binary.PutUvarint(buffer[:], uint64(len(value)))]...)
+	data = append(data, value...)
+	checksum := crc32.ChecksumIEEE(data[start:])
+	return append(data, byte(checksum), byte(checksum>>8), byte(checksum>>16), byte(checksum>>24))
+}
+
+func recordSize(key string, value []byte) int64 {
+	return int64(len(appendRecord(nil, opPut, key, value)))
+}
+
+// Get returns the value stored for key. The returned slice must not be modified.
+func (s *Store) Get(key string) ([]byte, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// Keys returns all keys in sorted order.
+func (s *Store) Keys() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Put stores a value for key, replacing any previous value.
+func (s *Store) Put(key string, value []byte) error {
+	value = append([]byte(nil), value...)
+	return s.write(opPut, key, value)
+}
+
+// Delete removes key from the store. It is not an error if the key does not exist.
+func (s *Store) Delete(key string) error {
+	s.mutex.Lock()
+	_, ok := s.values[key]
+	s.mutex.Unlock()
+	if !ok {
+		return nil
+	}
+	return s.write(opDelete, key, nil)
+}
+
+func (s *Store) write(op byte, key string, value []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.file == nil {
+		return ErrClosed
+	}
+	if s.broken != nil {
+		return s.broken
+	}
+
+	record := appendRecord(nil, op, key, value)
+	if _, err := s.file.Write(record); err != nil {
+		if rollbackErr := s.rollback(); rollbackErr != nil {
+			s.broken = fmt.Errorf("rolling back failed write: %w", rollbackErr)
+		}
+		return err
+	}
+	s.size += int64(len(record))
+	s.apply(op, key, value)
+
+	// The record has been written, so a failed compaction does not fail the
+	// write. The old log is still intact and compaction is retried later.
+	if s.size > minCompactionSize && s.size > 2*s.liveSize {
+		s.compactErr = s.compact()
+	}
+	return nil
+}
+
+// rollback removes a partial record written by a failed write, so that the
+// next record is appended directly after the last complete one. The mutex
+// must be held.
+func (s *Store) rollback() error {
+	if err := s.file.Truncate(s.size); err != nil {
+		return err
+	}
+	_, err := s.file.Seek(s.size, io.SeekStart)
+	return err
+}
+
+// Sync commits the log to stable storage. It also reports the error of the
+// last automatic compaction, if it failed.
+func (s *Store) Sync() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.file == nil {
+		return ErrClosed
+	}
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+	if err := s.compactErr; err != nil {
+		s.compactErr = nil
+		return fmt.Errorf("compacting log: %w", err)
+	}
+	return nil
+}
+
+// Compact rewrites the log so that it only contains the live data.
+func (s *Store) Compact() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.file == nil {
+		return ErrClosed
+	}
+	err := s.compact()
+	if err == nil {
+		s.compactErr = nil
+	}
+	return err
+}
+
+// compact writes the live data to a temporary file, which then replaces the
+// log. The mutex must be held.
+func (s *Store) compact() error {
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var data []byte
+	for _, key := range keys {
+		data = appendRecord(data, opPut, key, s.values[key])
+	}
+
+	tempPath := s.path + ".tmp"
+	temp, err := os.OpenFile(tempPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		os.Remove(tempPath)
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		os.Remove(tempPath)
+		return err
+	}
+	if err := os.Rename(tempPath, s.path); err != nil {
+		temp.Close()
+		os.Remove(tempPath)
+		return err
+	}
+
+	s.file.Close()
+	s.file = temp
+	s.size = int64(len(data))
+	s.liveSize = s.size
+	return nil
+}
+
+// Close syncs and closes the log file.
+func (s *Store) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.file == nil {
+		return ErrClosed
+	}
+	err := s.file.Sync()
+	if closeErr := s.file.Close(); err == nil {
+		err = closeErr
+	}
+	s.file = nil
+	return err
+}
@@ -0,0 +1,234 @@
+package kvlog
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func tempPath(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "kvlog")
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	return filepath.Join(dir, "store.log"), func() { os.RemoveAll(dir) }
+}
+
+func TestReopen(t *testing.T) {
+	path, cleanup := tempPath(t)
+	defer cleanup()
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	store.Put("a", []byte("1"))
+	store.Put("b", []byte("2"))
+	store.Put("a", []byte("3"))
+	store.Put("c", []byte{})
+	store.Delete("b")
+	if err := store.Close(); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	store, err = Open(path)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer store.Close()
+
+	if keys := store.Keys(); !reflect.DeepEqual(keys, []string{"a", "c"}) {
+		t.Errorf("wrong keys: %v", keys)
+	}
+	if value, ok := store.Get("a"); !ok || string(value) != "3" {
+		t.Errorf("wrong value: %q %v", value, ok)
+	}
+	if _, ok := store.Get("b"); ok {
+		t.Errorf("deleted key still present")
+	}
+}
+
+func TestTruncatedRecord(t *testing.T) {
+	path, cleanup := tempPath(t)
+	defer cleanup()
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	store.Put("a", []byte("1"))
+	store.Put("b", []byte("2"))
+	store.Close()
+
+	// Simulate a crash while writing the last record.
+	info, _ := os.Stat(path)
+	if err := os.Truncate(path, info.Size()-2); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	store, err = Open(path)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if keys := store.Keys(); !reflect.DeepEqual(keys, []string{"a"}) {
+		t.Errorf("wrong keys: %v", keys)
+	}
+
+	// New records must be appended after the last valid record.
+	store.Put("c", []byte("3"))
+	store.Close()
+
+	store, err = Open(path)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer store.Close()
+	if keys := store.Keys(); !reflect.DeepEqual(keys, []string{"a", "c"}) {
+		t.Errorf("wrong keys: %v", keys)
+	}
+}
+
+func TestCorruptRecord(t *testing.T) {
+	path, cleanup := tempPath(t)
+	defer cleanup()
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	store.Put("a", []byte("1"))
+	store.Put("b", []byte("2"))
+	store.Close()
+
+	// Corrupt the value of the first record.
+	data, _ := ioutil.ReadFile(path)
+	data[3] ^= 0xff
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	if _, err := Open(path); !errors.Is(err, ErrCorruptData) {
+		t.Fatalf("expected ErrCorruptData, got %v", err)
+	}
+	if actual, _ := ioutil.ReadFile(path); len(actual) != len(data) {
+		t.Errorf("log truncated to %d of %d bytes", len(actual), len(data))
+	}
+
+	// Corruption of the last record is a crash during the write.
+	data[3] ^= 0xff
+	data[len(data)-5] ^= 0xff
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	store, err = Open(path)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer store.Close()
+	if keys := store.Keys(); !reflect.DeepEqual(keys, []string{"a"}) {
+		t.Errorf("wrong keys: %v", keys)
+	}
+}
+
+func TestFailedWrite(t *testing.T) {
+	path, cleanup := tempPath(t)
+	defer cleanup()
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	store.Put("a", []byte("1"))
+
+	// Simulate a short write, which leaves a partial record behind.
+	record := appendRecord(nil, opPut, "b", []byte("2"))
+	if _, err := store.file.Write(record[:len(record)-2]); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if err := store.rollback(); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	store.Put("c", []byte("3"))
+	store.Close()
+
+	store, err = Open(path)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer store.Close()
+	if keys := store.Keys(); !reflect.DeepEqual(keys, []string{"a", "c"}) {
+		t.Errorf("wrong keys: %v", keys)
+	}
+}
+
+func TestCompaction(t *testing.T) {
+	path, cleanup := tempPath(t)
+	defer cleanup()
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	value := make([]byte, 1000)
+	for i := 0; i < 1000; i++ {
+		value[0] = byte(i)
+		if err := store.Put("key", value); err != nil {
+			t.Fatal("unexpected err:", err)
+		}
+	}
+
+	info, _ := os.Stat(path)
+	if info.Size() > 2*minCompactionSize {
+		t.Errorf("log not compacted: %d bytes", info.Size())
+	}
+	store.Close()
+
+	store, err = Open(path)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer store.Close()
+	if actual, ok := store.Get("key"); !ok || !reflect.DeepEqual(actual, value) {
+		t.Errorf("wrong value after compaction")
+	}
+}
+
+func TestFailedCompaction(t *testing.T) {
+	path, cleanup := tempPath(t)
+	defer cleanup()
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer store.Close()
+
+	// The temporary file cannot be created.
+	if err := os.Mkdir(path+".tmp", 0755); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	value := make([]byte, 1000)
+	for i := 0; i < 200; i++ {
+		value[0] = byte(i)
+		if err := store.Put("key", value); err != nil {
+			t.Fatal("unexpected err:", err)
+		}
+	}
+	if err := store.Sync(); err == nil {
+		t.Errorf("compaction error not reported")
+	}
+
+	os.Remove(path + ".tmp")
+	if err := store.Compact(); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if err := store.Sync(); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+}
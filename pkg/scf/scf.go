@@ -2,20 +2,50 @@
 // which is a binary format defined to be compatible with multiple layers of the ZigBee stack.
 //
 // SCF supports the following data types: 1-, 2-, 4- and 8-byte long signed and
-// unsigned integers, as well as slices of such integers.
+// unsigned integers, bools, fixed-size arrays, nested structs, as well as
+// slices of these types.
 //
 // Package SCF supports direct conversion between byte arrays and Go structs.
 // The binary representation of a struct is defined as all its members in
-// declaration order in little endian and with no padding. Slices are prefixed
-// with a one byte count indicating the number of elements.
+// declaration order in little endian and with no padding. Bools are stored as
+// one byte. Arrays are stored without a count. Slices are prefixed with a one
+// byte count indicating the number of elements.
+//
+// The representation of a field can be changed with a struct tag containing a
+// comma-separated list of the following options:
+//
+//	scf:"count=2"          The slice is prefixed with a two byte count.
+//	scf:"count=Field"      The number of elements of the slice is stored in the
+//	                       previous integer field Field instead of a prefix.
+//	                       When serializing, the length of the slice is written
+//	                       to Field instead of its value.
+//	scf:"rest"             The slice is not prefixed and contains all remaining
+//	                       data. It must be the last field.
+//	scf:"bits=N"           The integer or bool field is stored in N bits.
+//	                       Consecutive bit fields are packed starting from the
+//	                       least significant bit and must add up to whole bytes.
+//	scf:"if=Field==Value"  The field is only present if the condition on the
+//	                       previous integer or bool field Field holds. The
+//	                       operators ==, !=, <, <=, > and >= are supported.
+//	                       If the field is not present, it is not written and
+//	                       it is set to its zero value when parsing.
+//
+// Integer fields named _ are padding (e.g. reserved bits), which is written as
+// zero and skipped when parsing.
+//
+// The conversion uses reflection, unless the command implements Marshaler and
+// Unmarshaler. The scfgen command generates implementations of these
+// interfaces for the commands of a package.
 package scf
 
 import (
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"math"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 )
 
 // Validate checks that command has a type that is compatible with the
@@ -31,168 +61,435 @@ func Validate(command interface{}) error {
 // Command must be a struct and all fields must be supported data types (see package description).
 // It returns an error if this is not the case.
 func ValidateType(commandType reflect.Type) error {
-	if commandType.Kind() != reflect.Struct {
+	if commandType == nil || commandType.Kind() != reflect.Struct {
 		return errors.New("command must be a struct")
 	}
+	_, err := getStructInfo(commandType)
+	return err
+}
 
-	for f := 0; f < commandType.NumField(); f++ {
-		field := commandType.Field(f)
-		if !isFieldTypeValid(field.Type) {
-			return fmt.Errorf("command field %s.%s has invalid type", commandType.Name(), field.Name)
-		}
+type fieldInfo struct {
+	countSize  int // size of the count prefix of a slice (1 or 2), 0 if there is no prefix
+	countField int // index of the field containing the count of a slice, -1 if none
+	countFor   int // index of the slice whose count is stored in this field, -1 if none
+	rest       bool
+	padding    bool // field named _
+
+	bits      int // size of a bit field, 0 for regular fields
+	groupSize int // for the first field of a group of bit fields, the size of the group in bytes
+
+	condition *condition
+}
+
+type condition struct {
+	field    int
+	operator string
+	value    int64
+}
+
+func (c *condition) holds(structValue reflect.Value) bool {
+	value := intValue(structValue.Field(c.field))
+	switch c.operator {
+	case "==":
+		return value == c.value
+	case "!=":
+		return value != c.value
+	case "<":
+		return value < c.value
+	case "<=":
+		return value <= c.value
+	case ">":
+		return value > c.value
+	case ">=":
+		return value >= c.value
 	}
+	return false
+}
+
+type structInfo struct {
+	fields []fieldInfo
+	err    error
+}
 
-	return nil
+var structInfos sync.Map // map[reflect.Type]*structInfo
+
+func getStructInfo(structType reflect.Type) (*structInfo, error) {
+	if info, ok := structInfos.Load(structType); ok {
+		info := info.(*structInfo)
+		return info, info.err
+	}
+	info := buildStructInfo(structType)
+	structInfos.Store(structType, info)
+	return info, info.err
 }
 
-func isFieldTypeValid(fieldType reflect.Type) bool {
-	fieldKind := fieldType.Kind()
-	if fieldKind == reflect.Slice {
-		fieldKind = fieldType.Elem().Kind()
+func isIntegerKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
 	}
+	return false
+}
 
-	validKinds := []reflect.Kind{
-		reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+func validateElementType(elemType reflect.Type) error {
+	switch kind := elemType.Kind(); {
+	case isIntegerKind(kind) || kind == reflect.Bool:
+		return nil
+	case kind == reflect.Array:
+		return validateElementType(elemType.Elem())
+	case kind == reflect.Struct:
+		_, err := getStructInfo(elemType)
+		return err
 	}
-	for _, kind := range validKinds {
-		if fieldKind == kind {
-			return true
+	return fmt.Errorf("type %v is not supported", elemType)
+}
+
+func buildStructInfo(structType reflect.Type) *structInfo {
+	info := &structInfo{fields: make([]fieldInfo, structType.NumField())}
+	fail := func(f int, format string, args ...interface{}) *structInfo {
+		info.err = fmt.Errorf("command field %s.%s: %s", structType.Name(), structType.Field(f).Name, fmt.Sprintf(format, args...))
+		return info
+	}
+
+	groupStart, groupBits := -1, 0
+
+	for f := range info.fields {
+		field := structType.Field(f)
+		fi := &info.fields[f]
+		fi.countField, fi.countFor = -1, -1
+
+		fieldKind := field.Type.Kind()
+
+		if field.Name == "_" {
+			if !isIntegerKind(fieldKind) {
+				return fail(f, "padding must be an integer")
+			}
+			fi.padding = true
+		} else if field.PkgPath != "" {
+			return fail(f, "field must be exported")
+		}
+
+		if fieldKind == reflect.Slice {
+			if err := validateElementType(field.Type.Elem()); err != nil {
+				return fail(f, "%v", err)
+			}
+			fi.countSize = 1
+		} else if err := validateElementType(field.Type); err != nil {
+			return fail(f, "%v", err)
+		}
+
+		previousField := func(name string) (int, error) {
+			other, ok := structType.FieldByName(name)
+			if !ok || len(other.Index) != 1 || other.Index[0] >= f {
+				return 0, fmt.Errorf("%s must be a previous field", name)
+			}
+			if kind := other.Type.Kind(); !isIntegerKind(kind) && kind != reflect.Bool {
+				return 0, fmt.Errorf("%s must be an integer", name)
+			}
+			return other.Index[0], nil
+		}
+
+		tag := field.Tag.Get("scf")
+		if tag != "" {
+			for _, option := range strings.Split(tag, ",") {
+				key, value := option, ""
+				if i := strings.Index(option, "="); i >= 0 {
+					key, value = option[:i], option[i+1:]
+				}
+
+				switch key {
+				case "count":
+					if fieldKind != reflect.Slice {
+						return fail(f, "count is only supported for slices")
+					}
+					if size, err := strconv.Atoi(value); err == nil {
+						if size != 1 && size != 2 {
+							return fail(f, "count prefix must have 1 or 2 bytes")
+						}
+						fi.countSize = size
+						break
+					}
+					other, err := previousField(value)
+					if err != nil {
+						return fail(f, "%v", err)
+					}
+					if structType.Field(other).Type.Kind() == reflect.Bool || info.fields[other].countFor >= 0 || info.fields[other].bits != 0 {
+						return fail(f, "%s cannot be used as count", value)
+					}
+					fi.countSize, fi.countField = 0, other
+					info.fields[other].countFor = f
+
+				case "rest":
+					if fieldKind != reflect.Slice {
+						return fail(f, "rest is only supported for slices")
+					}
+					if f != len(info.fields)-1 {
+						return fail(f, "rest must be the last field")
+					}
+					fi.countSize, fi.rest = 0, true
+
+				case "bits":
+					bits, err := strconv.Atoi(value)
+					if err != nil || bits <= 0 {
+						return fail(f, "invalid number of bits %q", value)
+					}
+					if fieldKind == reflect.Bool {
+						if bits != 1 {
+							return fail(f, "bool must have 1 bit")
+						}
+					} else if !isIntegerKind(fieldKind) || bits > 8*int(field.Type.Size()) {
+						return fail(f, "%d bits are not supported for type %v", bits, field.Type)
+					}
+					fi.bits = bits
+
+				case "if":
+					operatorIndex := strings.IndexAny(value, "=!<>")
+					if operatorIndex <= 0 {
+						return fail(f, "invalid condition %q", value)
+					}
+					c := &condition{}
+					rest := value[operatorIndex:]
+					for _, operator := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+						if strings.HasPrefix(rest, operator) {
+							c.operator = operator
+							break
+						}
+					}
+					if c.operator == "" {
+						return fail(f, "invalid condition %q", value)
+					}
+					number, err := strconv.ParseInt(rest[len(c.operator):], 0, 64)
+					if err != nil {
+						return fail(f, "invalid condition %q", value)
+					}
+					c.value = number
+					if c.field, err = previousField(value[:operatorIndex]); err != nil {
+						return fail(f, "%v", err)
+					}
+					fi.condition = c
+
+				default:
+					return fail(f, "unknown option %q", option)
+				}
+			}
+		}
+
+		if fi.bits != 0 {
+			if fi.condition != nil {
+				return fail(f, "bit fields cannot be conditional")
+			}
+			if groupStart < 0 {
+				groupStart, groupBits = f, 0
+			}
+			groupBits += fi.bits
+			if groupBits > 64 {
+				return fail(f, "bit fields must not exceed 64 bits")
+			}
+			if groupBits%8 == 0 {
+				info.fields[groupStart].groupSize = groupBits / 8
+				groupStart = -1
+			}
+		} else if groupStart >= 0 {
+			return fail(f, "previous bit fields do not add up to whole bytes")
 		}
 	}
-	return false
+
+	if groupStart >= 0 {
+		return fail(len(info.fields)-1, "bit fields do not add up to whole bytes")
+	}
+
+	return info
+}
+
+func intValue(value reflect.Value) int64 {
+	switch value.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int()
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(value.Uint())
+	case reflect.Bool:
+		if value.Bool() {
+			return 1
+		}
+	}
+	return 0
+}
+
+// setIntValue stores the lowest bits of raw into value.
+func setIntValue(value reflect.Value, raw uint64, bits int) {
+	switch value.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		shift := uint(64 - bits)
+		value.SetInt(int64(raw<<shift) >> shift)
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value.SetUint(raw)
+	case reflect.Bool:
+		value.SetBool(raw != 0)
+	}
+}
+
+func appendInt(data []byte, value uint64, size int) []byte {
+	for i := 0; i < size; i++ {
+		data = append(data, byte(value>>(8*i)))
+	}
+	return data
 }
 
 // Serialize returns the binary representation of a command.
 // Serialize panics if the type of command is not supported (use Validate to check).
-// If a slice has more than 255 elements, it is truncated to the first 255 elements.
+// If a slice has more elements than its count can represent (255 for the
+// default one byte count), it is truncated.
 func Serialize(command interface{}) []byte {
+	if marshaler, ok := command.(Marshaler); ok {
+		return marshaler.MarshalSCF()
+	}
 	return SerializeValue(reflect.ValueOf(command))
 }
 
 // SerializeValue is the same as Serialize, except it accepts command already
 // wrapped in a reflect.Value.
 func SerializeValue(commandValue reflect.Value) (data []byte) {
-	for f := 0; f < commandValue.NumField(); f++ {
-		field := commandValue.Field(f)
-		fieldKind := field.Kind()
+	if commandValue.CanInterface() {
+		if marshaler, ok := commandValue.Interface().(Marshaler); ok {
+			return marshaler.MarshalSCF()
+		}
+	}
+	return serializeStruct(nil, commandValue)
+}
 
-		if fieldKind == reflect.Slice {
-			length := field.Len()
-			if length > math.MaxUint8 {
-				length = math.MaxUint8
-			}
+func serializeStruct(data []byte, structValue reflect.Value) []byte {
+	info, err := getStructInfo(structValue.Type())
+	if err != nil {
+		panic(fmt.Sprintf("serialization for command not implemented: %v", err))
+	}
 
-			switch field.Type().Elem().Kind() {
-			case reflect.Uint8:
-				data = append(data, byte(length))
-				data = append(data, field.Bytes()[:length]...)
-
-			case reflect.Uint16:
-				data = append(data, byte(length))
-				for _, value := range field.Interface().([]uint16)[:length] {
-					start := len(data)
-					data = append(data, 0, 0)
-					binary.LittleEndian.PutUint16(data[start:], uint16(value))
-				}
+	var bits uint64
+	var bitCount int
 
-			case reflect.Uint32:
-				data = append(data, byte(length))
-				for _, value := range field.Interface().([]uint32)[:length] {
-					start := len(data)
-					data = append(data, 0, 0, 0, 0)
-					binary.LittleEndian.PutUint32(data[start:], uint32(value))
-				}
+	for f := range info.fields {
+		fi := &info.fields[f]
+		field := structValue.Field(f)
 
-			case reflect.Uint64:
-				data = append(data, byte(length))
-				for _, value := range field.Interface().([]uint64)[:length] {
-					start := len(data)
-					data = append(data, 0, 0, 0, 0, 0, 0, 0, 0)
-					binary.LittleEndian.PutUint64(data[start:], uint64(value))
-				}
+		if fi.condition != nil && !fi.condition.holds(structValue) {
+			continue
+		}
 
-			case reflect.Int8:
-				data = append(data, byte(length))
-				for _, value := range field.Interface().([]int8)[:length] {
-					data = append(data, byte(value))
-				}
+		if fi.padding {
+			if fi.bits == 0 {
+				data = appendInt(data, 0, int(field.Type().Size()))
+				continue
+			}
+			bitCount += fi.bits
+			if bitCount%8 == 0 {
+				data = appendInt(data, bits, bitCount/8)
+				bits, bitCount = 0, 0
+			}
+			continue
+		}
 
-			case reflect.Int16:
-				data = append(data, byte(length))
-				for _, value := range field.Interface().([]int16)[:length] {
-					start := len(data)
-					data = append(data, 0, 0)
-					binary.LittleEndian.PutUint16(data[start:], uint16(value))
-				}
+		if fi.bits != 0 {
+			bits |= (uint64(intValue(field)) & (1<<uint(fi.bits) - 1)) << uint(bitCount)
+			bitCount += fi.bits
+			if bitCount%8 == 0 {
+				data = appendInt(data, bits, bitCount/8)
+				bits, bitCount = 0, 0
+			}
+			continue
+		}
 
-			case reflect.Int32:
-				data = append(data, byte(length))
-				for _, value := range field.Interface().([]int32)[:length] {
-					start := len(data)
-					data = append(data, 0, 0, 0, 0)
-					binary.LittleEndian.PutUint32(data[start:], uint32(value))
-				}
+		if fi.countFor >= 0 {
+			size := int(field.Type().Size())
+			length := uint64(structValue.Field(fi.countFor).Len())
+			if maximum := maxCount(size); length > maximum {
+				length = maximum
+			}
+			data = appendInt(data, length, size)
+			continue
+		}
 
-			case reflect.Int64:
-				data = append(data, byte(length))
-				for _, value := range field.Interface().([]int64)[:length] {
-					start := len(data)
-					data = append(data, 0, 0, 0, 0, 0, 0, 0, 0)
-					binary.LittleEndian.PutUint64(data[start:], uint64(value))
+		if field.Kind() == reflect.Slice {
+			length := field.Len()
+			switch {
+			case fi.countSize != 0:
+				if maximum := int(maxCount(fi.countSize)); length > maximum {
+					length = maximum
+				}
+				data = appendInt(data, uint64(length), fi.countSize)
+			case fi.countField >= 0:
+				if maximum := int(maxCount(int(structValue.Field(fi.countField).Type().Size()))); length > maximum {
+					length = maximum
 				}
-
-			default:
-				commandType := commandValue.Type()
-				panic(fmt.Sprintf("serialization for command field not implemented: field=%s.%s type=%v kind=%v", commandType.Name(), commandType.Field(f).Name, field.Type(), fieldKind))
 			}
-
+			data = serializeSlice(data, field, length)
 			continue
 		}
 
-		var value uint64
-		switch fieldKind {
-		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			value = uint64(field.Int())
-		case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			value = field.Uint()
-		default:
-			commandType := commandValue.Type()
-			panic(fmt.Sprintf("serialization for command field not implemented: field=%s.%s type=%v kind=%v", commandType.Name(), commandType.Field(f).Name, field.Type(), fieldKind))
-		}
+		data = serializeElement(data, field)
+	}
 
-		switch fieldKind {
-		case reflect.Int8, reflect.Uint8:
-			data = append(data, byte(value))
+	return data
+}
 
-		case reflect.Int16, reflect.Uint16:
-			start := len(data)
-			data = append(data, 0, 0)
-			binary.LittleEndian.PutUint16(data[start:], uint16(value))
+func maxCount(size int) uint64 {
+	if size >= 8 {
+		return math.MaxUint64
+	}
+	return 1<<uint(8*size) - 1
+}
 
-		case reflect.Int32, reflect.Uint32:
-			start := len(data)
-			data = append(data, 0, 0, 0, 0)
-			binary.LittleEndian.PutUint32(data[start:], uint32(value))
+func serializeSlice(data []byte, slice reflect.Value, length int) []byte {
+	if slice.Type().Elem().Kind() == reflect.Uint8 {
+		return append(data, slice.Bytes()[:length]...)
+	}
+	for i := 0; i < length; i++ {
+		data = serializeElement(data, slice.Index(i))
+	}
+	return data
+}
 
-		case reflect.Int64, reflect.Uint64:
-			start := len(data)
-			data = append(data, 0, 0, 0, 0, 0, 0, 0, 0)
-			binary.LittleEndian.PutUint64(data[start:], uint64(value))
+func serializeElement(data []byte, value reflect.Value) []byte {
+	switch kind := value.Kind(); {
+	case isIntegerKind(kind) || kind == reflect.Bool:
+		return appendInt(data, uint64(intValue(value)), int(value.Type().Size()))
+	case kind == reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			data = serializeElement(data, value.Index(i))
 		}
+		return data
+	case kind == reflect.Struct:
+		return serializeStruct(data, value)
 	}
-
-	return
+	panic(fmt.Sprintf("serialization for type not implemented: %v", value.Type()))
 }
 
 var ErrInvalidData = errors.New("invalid data")
 
+// Marshaler is implemented by commands which provide their own serialization,
+// usually generated by scfgen. Serialize uses MarshalSCF instead of reflection
+// for these commands. MarshalSCF must produce the same result as the
+// reflection-based implementation.
+type Marshaler interface {
+	MarshalSCF() []byte
+}
+
+// Unmarshaler is implemented by pointers to commands which provide their own
+// deserialization, usually generated by scfgen. Parse uses UnmarshalSCF
+// instead of reflection for these commands. UnmarshalSCF must return the
+// remaining data or ErrInvalidData like Parse.
+type Unmarshaler interface {
+	UnmarshalSCF(data []byte) ([]byte, error)
+}
+
 // Parse parses a command from the binary representation.
 // command must be a pointer to a struct, as the results are written into it.
 // Parse panics if the type of the struct is not supported (use Validate to check).
 // If the command could not be parsed, ErrInvalidData is returned.
 // Otherwise Parse returns the remaining part of data, that was not read into command.
 func Parse(command interface{}, data []byte) ([]byte, error) {
+	if unmarshaler, ok := command.(Unmarshaler); ok {
+		return unmarshaler.UnmarshalSCF(data)
+	}
 	return ParseValue(reflect.ValueOf(command).Elem(), data)
 }
 
@@ -200,146 +497,181 @@ func Parse(command interface{}, data []byte) ([]byte, error) {
 // In contrast to Parse, commandValue must wrap the struct directly (instead of being a pointer).
 // Also, commandValue must be addressable, as the parsed values are written into it.
 func ParseValue(commandValue reflect.Value, data []byte) ([]byte, error) {
-	for f := 0; f < commandValue.NumField(); f++ {
-		field := commandValue.Field(f)
-		fieldKind := field.Kind()
-
-		if fieldKind == reflect.Slice {
-			if len(data) == 0 {
-				return nil, ErrInvalidData
-			}
+	if commandValue.CanAddr() && commandValue.Addr().CanInterface() {
+		if unmarshaler, ok := commandValue.Addr().Interface().(Unmarshaler); ok {
+			return unmarshaler.UnmarshalSCF(data)
+		}
+	}
+	data, ok := parseStruct(commandValue, data)
+	if !ok {
+		return nil, ErrInvalidData
+	}
+	return data, nil
+}
 
-			length := int(data[0])
-			data = data[1:]
+func readInt(data []byte, size int) uint64 {
+	var value uint64
+	for i := 0; i < size; i++ {
+		value |= uint64(data[i]) << (8 * uint(i))
+	}
+	return value
+}
 
-			elemKind := field.Type().Elem().Kind()
+func parseStruct(structValue reflect.Value, data []byte) ([]byte, bool) {
+	info, err := getStructInfo(structValue.Type())
+	if err != nil {
+		panic(fmt.Sprintf("deserialization for command not implemented: %v", err))
+	}
 
-			switch elemKind {
-			case reflect.Uint8, reflect.Int8:
-				if len(data) < length {
-					return nil, ErrInvalidData
-				}
+	var bits uint64
 
-			case reflect.Uint16, reflect.Int16:
-				if 2*len(data) < length {
-					return nil, ErrInvalidData
-				}
+	for f := range info.fields {
+		fi := &info.fields[f]
+		field := structValue.Field(f)
 
-			case reflect.Uint32, reflect.Int32:
-				if 4*len(data) < length {
-					return nil, ErrInvalidData
-				}
+		if fi.condition != nil && !fi.condition.holds(structValue) {
+			field.Set(reflect.Zero(field.Type()))
+			continue
+		}
 
-			case reflect.Uint64, reflect.Int64:
-				if 8*len(data) < length {
-					return nil, ErrInvalidData
+		if fi.bits != 0 {
+			if fi.groupSize != 0 {
+				if len(data) < fi.groupSize {
+					return nil, false
 				}
-
-			default:
-				commandType := commandValue.Type()
-				panic(fmt.Sprintf("deserialization for command field not implemented: field=%s.%s type=%v kind=%v", commandType.Name(), commandType.Field(f).Name, field.Type(), fieldKind))
+				bits = readInt(data, fi.groupSize)
+				data = data[fi.groupSize:]
 			}
+			if !fi.padding {
+				setIntValue(field, bits&(1<<uint(fi.bits)-1), fi.bits)
+			}
+			bits >>= uint(fi.bits)
+			continue
+		}
 
-			switch elemKind {
-			case reflect.Uint8:
-				fieldData := make([]uint8, length)
-				copy(fieldData, data)
-				data = data[length:]
-				field.SetBytes(fieldData)
-
-			case reflect.Uint16:
-				fieldData := make([]uint16, length)
-				for i := range fieldData {
-					fieldData[i] = binary.LittleEndian.Uint16(data[2*i:])
-				}
-				data = data[2*length:]
-				field.Set(reflect.ValueOf(fieldData))
-
-			case reflect.Uint32:
-				fieldData := make([]uint32, length)
-				for i := range fieldData {
-					fieldData[i] = binary.LittleEndian.Uint32(data[4*i:])
-				}
-				data = data[4*length:]
-				field.Set(reflect.ValueOf(fieldData))
-
-			case reflect.Uint64:
-				fieldData := make([]uint64, length)
-				for i := range fieldData {
-					fieldData[i] = binary.LittleEndian.Uint64(data[8*i:])
-				}
-				data = data[8*length:]
-				field.Set(reflect.ValueOf(fieldData))
-
-			case reflect.Int8:
-				fieldData := make([]int8, length)
-				for i := range fieldData {
-					fieldData[i] = int8(data[i])
-				}
-				data = data[length:]
-				field.Set(reflect.ValueOf(fieldData))
-
-			case reflect.Int16:
-				fieldData := make([]int16, length)
-				for i := range fieldData {
-					fieldData[i] = int16(binary.LittleEndian.Uint16(data[2*i:]))
-				}
-				data = data[2*length:]
-				field.Set(reflect.ValueOf(fieldData))
+		if fi.padding {
+			size := int(field.Type().Size())
+			if len(data) < size {
+				return nil, false
+			}
+			data = data[size:]
+			continue
+		}
 
-			case reflect.Int32:
-				fieldData := make([]int32, length)
-				for i := range fieldData {
-					fieldData[i] = int32(binary.LittleEndian.Uint32(data[4*i:]))
+		if field.Kind() == reflect.Slice {
+			var length int
+			switch {
+			case fi.countSize != 0:
+				if len(data) < fi.countSize {
+					return nil, false
 				}
-				data = data[4*length:]
-				field.Set(reflect.ValueOf(fieldData))
-
-			case reflect.Int64:
-				fieldData := make([]int64, length)
-				for i := range fieldData {
-					fieldData[i] = int64(binary.LittleEndian.Uint64(data[8*i:]))
+				length = int(readInt(data, fi.countSize))
+				data = data[fi.countSize:]
+			case fi.countField >= 0:
+				length = int(intValue(structValue.Field(fi.countField)))
+				if length < 0 {
+					return nil, false
 				}
-				data = data[8*length:]
-				field.Set(reflect.ValueOf(fieldData))
+			case fi.rest:
+				length = -1
+			}
+			var ok bool
+			if data, ok = parseSlice(field, data, length); !ok {
+				return nil, false
 			}
-
 			continue
 		}
 
-		length := int(field.Type().Size())
-		if len(data) < length {
-			return nil, ErrInvalidData
+		var ok bool
+		if data, ok = parseElement(field, data); !ok {
+			return nil, false
 		}
+	}
 
-		var value uint64
-		switch fieldKind {
-		case reflect.Int8, reflect.Uint8:
-			value = uint64(data[0])
-
-		case reflect.Int16, reflect.Uint16:
-			value = uint64(binary.LittleEndian.Uint16(data))
+	return data, true
+}
 
-		case reflect.Int32, reflect.Uint32:
-			value = uint64(binary.LittleEndian.Uint32(data))
+// parseSlice parses length elements into slice. If length is negative, all
+// remaining data is parsed.
+func parseSlice(slice reflect.Value, data []byte, length int) ([]byte, bool) {
+	sliceType := slice.Type()
 
-		case reflect.Int64, reflect.Uint64:
-			value = uint64(binary.LittleEndian.Uint64(data))
+	if sliceType.Elem().Kind() == reflect.Uint8 {
+		if length < 0 {
+			length = len(data)
+		}
+		if len(data) < length {
+			return nil, false
+		}
+		bytes := reflect.MakeSlice(sliceType, length, length)
+		reflect.Copy(bytes, reflect.ValueOf(data[:length]))
+		slice.Set(bytes)
+		return data[length:], true
+	}
 
-		default:
-			commandType := commandValue.Type()
-			panic(fmt.Sprintf("deserialization for command field not implemented: field=%s.%s type=%v kind=%v", commandType.Name(), commandType.Field(f).Name, field.Type(), fieldKind))
+	if length < 0 {
+		result := reflect.MakeSlice(sliceType, 0, 0)
+		for len(data) != 0 {
+			elem := reflect.New(sliceType.Elem()).Elem()
+			remaining, ok := parseElement(elem, data)
+			// Elements without data would never end the loop.
+			if !ok || len(remaining) == len(data) {
+				return nil, false
+			}
+			data = remaining
+			result = reflect.Append(result, elem)
 		}
+		slice.Set(result)
+		return data, true
+	}
 
-		data = data[length:]
+	// Check the size for fixed-size elements before allocating the slice.
+	if size := fixedSize(sliceType.Elem()); size > 0 && len(data) < length*size {
+		return nil, false
+	}
 
-		switch fieldKind {
-		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			field.SetInt(int64(value))
-		case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			field.SetUint(value)
+	result := reflect.MakeSlice(sliceType, length, length)
+	for i := 0; i < length; i++ {
+		var ok bool
+		if data, ok = parseElement(result.Index(i), data); !ok {
+			return nil, false
 		}
 	}
+	slice.Set(result)
+	return data, true
+}
 
-	return data, nil
+// fixedSize returns the minimum size of the binary representation of a type,
+// which is exact for integers, bools and arrays thereof.
+func fixedSize(elemType reflect.Type) int {
+	switch kind := elemType.Kind(); {
+	case isIntegerKind(kind) || kind == reflect.Bool:
+		return int(elemType.Size())
+	case kind == reflect.Array:
+		return elemType.Len() * fixedSize(elemType.Elem())
+	}
+	return 0
+}
+
+func parseElement(value reflect.Value, data []byte) ([]byte, bool) {
+	switch kind := value.Kind(); {
+	case isIntegerKind(kind) || kind == reflect.Bool:
+		size := int(value.Type().Size())
+		if len(data) < size {
+			return nil, false
+		}
+		setIntValue(value, readInt(data, size), 8*size)
+		return data[size:], true
+	case kind == reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			var ok bool
+			if data, ok = parseElement(value.Index(i), data); !ok {
+				return nil, false
+			}
+		}
+		return data, true
+	case kind == reflect.Struct:
+		return parseStruct(value, data)
+	}
+	panic(fmt.Sprintf("deserialization for type not implemented: %v", value.Type()))
 }
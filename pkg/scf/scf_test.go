@@ -0,0 +1,272 @@
+package scf
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+type Plain struct {
+	A uint8
+	B int16
+	C uint32
+	D []uint16
+}
+
+type WideCount struct {
+	Values []uint8 `scf:"count=2"`
+}
+
+type CountField struct {
+	Count  uint8
+	Other  uint8
+	Values []uint16 `scf:"count=Count"`
+}
+
+type BitFields struct {
+	Low   uint8 `scf:"bits=4"`
+	High  uint8 `scf:"bits=3"`
+	Flag  bool  `scf:"bits=1"`
+	Value int16 `scf:"bits=12"`
+	Mode  uint8 `scf:"bits=4"`
+}
+
+type Conditional struct {
+	Mode  uint8
+	Short uint16 `scf:"if=Mode==2"`
+	Long  uint64 `scf:"if=Mode>=3"`
+}
+
+type Inner struct {
+	ID    uint16
+	Valid bool
+}
+
+type Nested struct {
+	Address [3]uint8
+	Inner   Inner
+	Entries []Inner
+	Rest    []uint8 `scf:"rest"`
+}
+
+type RestStructs struct {
+	Entries []Inner `scf:"rest"`
+}
+
+type Padding struct {
+	Low  uint8 `scf:"bits=3"`
+	_    uint8 `scf:"bits=5"`
+	_    uint8
+	High uint8
+}
+
+type TestCase struct {
+	Data    string
+	Command interface{}
+}
+
+var tests = []TestCase{
+	TestCase{Data: "01FEFF78563412" + "020100FFFF", Command: &Plain{A: 1, B: -2, C: 0x12345678, D: []uint16{0x0001, 0xFFFF}}},
+	TestCase{Data: "01FEFF78563412" + "00", Command: &Plain{A: 1, B: -2, C: 0x12345678, D: []uint16{}}},
+	TestCase{Data: "0300AABBCC", Command: &WideCount{Values: []uint8{0xAA, 0xBB, 0xCC}}},
+	TestCase{Data: "0207" + "34127856", Command: &CountField{Count: 2, Other: 7, Values: []uint16{0x1234, 0x5678}}},
+	TestCase{Data: "B5" + "FF" + "AF", Command: &BitFields{Low: 5, High: 3, Flag: true, Value: -1, Mode: 0xA}},
+	TestCase{Data: "01", Command: &Conditional{Mode: 1}},
+	TestCase{Data: "02" + "3412", Command: &Conditional{Mode: 2, Short: 0x1234}},
+	TestCase{Data: "03" + "8877665544332211", Command: &Conditional{Mode: 3, Long: 0x1122334455667788}},
+	TestCase{Data: "010203" + "341201" + "02" + "010000" + "020001" + "AABB", Command: &Nested{Address: [3]uint8{1, 2, 3}, Inner: Inner{ID: 0x1234, Valid: true}, Entries: []Inner{{ID: 1}, {ID: 2, Valid: true}}, Rest: []uint8{0xAA, 0xBB}}},
+	TestCase{Data: "010203" + "000000" + "00", Command: &Nested{Address: [3]uint8{1, 2, 3}, Entries: []Inner{}, Rest: []uint8{}}},
+	TestCase{Data: "010000" + "020001", Command: &RestStructs{Entries: []Inner{{ID: 1}, {ID: 2, Valid: true}}}},
+	TestCase{Data: "05" + "00" + "07", Command: &Padding{Low: 5, High: 7}},
+}
+
+func TestSerialize(t *testing.T) {
+	for _, test := range tests {
+		actual := hex.EncodeToString(SerializeValue(reflect.ValueOf(test.Command).Elem()))
+		expected, _ := hex.DecodeString(test.Data)
+		if actual != hex.EncodeToString(expected) {
+			t.Errorf("wrong serialization of %T: got %s, expected %s", test.Command, actual, test.Data)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, test := range tests {
+		data, _ := hex.DecodeString(test.Data)
+		command := reflect.New(reflect.TypeOf(test.Command).Elem()).Interface()
+		remaining, err := Parse(command, data)
+		if err != nil {
+			t.Errorf("unexpected err for %T %s: %v", test.Command, test.Data, err)
+			continue
+		}
+		if len(remaining) != 0 {
+			t.Errorf("remaining data for %T %s: %x", test.Command, test.Data, remaining)
+		}
+		if !reflect.DeepEqual(command, test.Command) {
+			t.Errorf("wrong parse of %s: got %+v, expected %+v", test.Data, command, test.Command)
+		}
+	}
+}
+
+func TestParseNotEnoughData(t *testing.T) {
+	for _, test := range tests {
+		data, _ := hex.DecodeString(test.Data)
+		if reflect.TypeOf(test.Command).Elem().Field(reflect.TypeOf(test.Command).Elem().NumField()-1).Tag.Get("scf") == "rest" {
+			continue
+		}
+		for length := 0; length < len(data); length++ {
+			command := reflect.New(reflect.TypeOf(test.Command).Elem()).Interface()
+			if _, err := Parse(command, data[:length]); err != ErrInvalidData {
+				t.Errorf("expected error for %T with %d of %d bytes", test.Command, length, len(data))
+			}
+		}
+	}
+}
+
+func TestParseRemaining(t *testing.T) {
+	var command CountField
+	remaining, err := Parse(&command, []byte{0x01, 0x00, 0x34, 0x12, 0xAB})
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if len(remaining) != 1 || remaining[0] != 0xAB {
+		t.Errorf("wrong remaining data: %x", remaining)
+	}
+}
+
+func TestParsePadding(t *testing.T) {
+	// Reserved bits and bytes are ignored.
+	var command Padding
+	if _, err := Parse(&command, []byte{0xfd, 0xff, 0x07}); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if command.Low != 5 || command.High != 7 {
+		t.Errorf("wrong parse: %+v", command)
+	}
+}
+
+func TestParseEmptyRestElements(t *testing.T) {
+	var command struct {
+		Entries []struct{} `scf:"rest"`
+	}
+	if _, err := Parse(&command, []byte{0x01}); err != ErrInvalidData {
+		t.Errorf("expected ErrInvalidData, got %v", err)
+	}
+}
+
+func TestSerializeCountField(t *testing.T) {
+	// The count field is derived from the length of the slice.
+	command := CountField{Count: 5, Values: []uint16{0x1234}}
+	if actual := hex.EncodeToString(Serialize(command)); actual != "01003412" {
+		t.Errorf("wrong serialization: %s", actual)
+	}
+
+	// Slices are truncated to the maximum count.
+	command = CountField{Values: make([]uint16, 300)}
+	if actual := len(Serialize(command)); actual != 2+2*255 {
+		t.Errorf("wrong length: %d", actual)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := []interface{}{Plain{}, WideCount{}, CountField{}, BitFields{}, Conditional{}, Nested{}, RestStructs{}, Padding{}, struct{}{}}
+	for _, command := range valid {
+		if err := Validate(command); err != nil {
+			t.Errorf("unexpected err for %T: %v", command, err)
+		}
+	}
+
+	invalid := []interface{}{
+		0,
+		struct{ A string }{},
+		struct{ A []string }{},
+		struct{ a uint8 }{},
+		struct{ _ []uint8 }{},
+		struct{ A *uint8 }{},
+		struct {
+			A uint8 `scf:"count=2"`
+		}{},
+		struct {
+			A []uint8 `scf:"count=3"`
+		}{},
+		struct {
+			A []uint8 `scf:"count=B"`
+			B uint8
+		}{},
+		struct {
+			A bool
+			B []uint8 `scf:"count=A"`
+		}{},
+		struct {
+			A []uint8 `scf:"rest"`
+			B uint8
+		}{},
+		struct {
+			A uint8 `scf:"bits=4"`
+		}{},
+		struct {
+			A uint8 `scf:"bits=4"`
+			B uint8
+		}{},
+		struct {
+			A uint8 `scf:"bits=9"`
+		}{},
+		struct {
+			A bool `scf:"bits=2"`
+		}{},
+		struct {
+			A uint8
+			B uint8 `scf:"bits=8,if=A==1"`
+		}{},
+		struct {
+			A uint8 `scf:"if=B==1"`
+			B uint8
+		}{},
+		struct {
+			A uint8
+			B uint8 `scf:"if=A~1"`
+		}{},
+		struct {
+			A uint8 `scf:"unknown"`
+		}{},
+	}
+	for _, command := range invalid {
+		if err := Validate(command); err == nil {
+			t.Errorf("expected error for %T", command)
+		}
+	}
+}
+
+type Custom struct {
+	Value uint16
+}
+
+func (c Custom) MarshalSCF() []byte {
+	return []byte{byte(c.Value >> 8), byte(c.Value)}
+}
+
+func (c *Custom) UnmarshalSCF(data []byte) ([]byte, error) {
+	if len(data) < 2 {
+		return nil, ErrInvalidData
+	}
+	c.Value = uint16(data[0])<<8 | uint16(data[1])
+	return data[2:], nil
+}
+
+func TestMarshaler(t *testing.T) {
+	if actual := hex.EncodeToString(Serialize(Custom{Value: 0x1234})); actual != "1234" {
+		t.Errorf("wrong serialization: %s", actual)
+	}
+	if actual := hex.EncodeToString(SerializeValue(reflect.ValueOf(Custom{Value: 0x1234}))); actual != "1234" {
+		t.Errorf("wrong serialization: %s", actual)
+	}
+
+	var command Custom
+	if _, err := Parse(&command, []byte{0x12, 0x34}); err != nil || command.Value != 0x1234 {
+		t.Errorf("wrong parse: %+v, %v", command, err)
+	}
+	command = Custom{}
+	if _, err := ParseValue(reflect.ValueOf(&command).Elem(), []byte{0x12, 0x34}); err != nil || command.Value != 0x1234 {
+		t.Errorf("wrong parse: %+v, %v", command, err)
+	}
+}
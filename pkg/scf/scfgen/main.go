@@ -0,0 +1,501 @@
+// Command scfgen generates static serialization code for the commands of a
+// package, so that package scf does not have to use reflection for them.
+//
+// Commands are found by looking for calls of a registration function, whose
+// last argument is the zero value of the command struct, for example:
+//
+//	registerCommand(FRAME_TYPE_SREQ, FRAME_SUBSYSTEM_SYS, 0x02, SysVersionRequest{})
+//
+// The other arguments are the fields of the header type. For each command,
+// scfgen generates the following methods:
+//
+//	func (command T) MarshalSCF() []byte
+//	func (command *T) UnmarshalSCF(data []byte) ([]byte, error)
+//	func (T) frameHeader() FrameHeader
+//
+// The name of the last method is the name of the header type starting with a
+// lower case letter. Additionally, scfgen generates the map
+// parsersByFrameHeader (again named after the header type) from headers to
+// functions parsing the respective command.
+//
+// Integers and slices of integers are supported, including the count=1 and
+// count=2 options, as well as the rest option for a final byte slice.
+// Commands using other types or options do not get MarshalSCF and
+// UnmarshalSCF methods and are handled by package scf using reflection.
+//
+// Usage:
+//
+//	//go:generate go run ../../pkg/scf/scfgen -func registerCommand -header FrameHeader -output commands_scf.go
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	funcName   = flag.String("func", "registerCommand", "name of the registration function")
+	headerName = flag.String("header", "FrameHeader", "name of the header type")
+	output     = flag.String("output", "commands_scf.go", "name of the output file")
+)
+
+type command struct {
+	name   string
+	header []string // source code of the header arguments
+	fields []field  // nil if the command is not supported
+}
+
+type field struct {
+	name      string
+	typ       string // source code of the type
+	size      int    // size of the integer or the elements of the slice
+	slice     bool
+	elemType  string // source code of the element type for slices
+	bytes     bool   // slice of bytes that can be copied directly
+	countSize int    // 0 for a slice of bytes containing all remaining data
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("scfgen: ")
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	source, err := generate(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, *output), source, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func generate(dir string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		name := info.Name()
+		return !strings.HasSuffix(name, "_test.go") && name != *output
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", dir, len(pkgs))
+	}
+
+	var pkgName string
+	var files []*ast.File
+	for name, pkg := range pkgs {
+		pkgName = name
+		var filenames []string
+		for filename := range pkg.Files {
+			filenames = append(filenames, filename)
+		}
+		sort.Strings(filenames)
+		for _, filename := range filenames {
+			files = append(files, pkg.Files[filename])
+		}
+	}
+
+	// The output file is excluded, so references to generated code cannot be
+	// resolved. Ignore type errors, as long as the commands can be checked.
+	config := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error:    func(err error) {},
+	}
+	typesPkg, _ := config.Check(pkgName, fset, files, nil)
+
+	imports := make(map[string]bool)
+
+	var commands []command
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			if ident, ok := call.Fun.(*ast.Ident); !ok || ident.Name != *funcName {
+				return true
+			}
+			literal, ok := call.Args[len(call.Args)-1].(*ast.CompositeLit)
+			if !ok {
+				return true
+			}
+			ident, ok := literal.Type.(*ast.Ident)
+			if !ok {
+				return true
+			}
+
+			cmd := command{name: ident.Name}
+			for _, arg := range call.Args[:len(call.Args)-1] {
+				cmd.header = append(cmd.header, types.ExprString(arg))
+			}
+
+			fields, err := analyzeCommand(typesPkg, ident.Name, imports)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "scfgen: skipping %s: %v\n", ident.Name, err)
+			} else {
+				cmd.fields = fields
+			}
+
+			commands = append(commands, cmd)
+			return true
+		})
+	}
+
+	if len(commands) == 0 {
+		return nil, fmt.Errorf("no calls of %s found", *funcName)
+	}
+
+	var buffer bytes.Buffer
+	writeFile(&buffer, pkgName, commands, imports)
+
+	source, err := format.Source(buffer.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %v", err)
+	}
+	return source, nil
+}
+
+// analyzeCommand returns the fields of a command. The paths of the packages
+// referenced by the fields are added to imports.
+func analyzeCommand(pkg *types.Package, name string, imports map[string]bool) ([]field, error) {
+	var paths []string
+	qualifier := func(other *types.Package) string {
+		if other == pkg {
+			return ""
+		}
+		paths = append(paths, other.Path())
+		return other.Name()
+	}
+
+	object := pkg.Scope().Lookup(name)
+	if object == nil {
+		return nil, errors.New("type not found")
+	}
+	structType, ok := object.Type().Underlying().(*types.Struct)
+	if !ok {
+		return nil, errors.New("not a struct")
+	}
+
+	fields := make([]field, 0, structType.NumFields())
+	for i := 0; i < structType.NumFields(); i++ {
+		v := structType.Field(i)
+		f := field{name: v.Name(), typ: types.TypeString(v.Type(), qualifier)}
+
+		if !v.Exported() {
+			return nil, fmt.Errorf("field %s is not exported", f.name)
+		}
+
+		typ := v.Type()
+		if slice, ok := typ.Underlying().(*types.Slice); ok {
+			f.slice = true
+			f.countSize = 1
+			f.elemType = types.TypeString(slice.Elem(), qualifier)
+			f.bytes = types.Identical(slice.Elem(), types.Typ[types.Uint8])
+			typ = slice.Elem()
+		}
+
+		f.size = integerSize(typ)
+		if f.size == 0 {
+			return nil, fmt.Errorf("field %s has unsupported type %s", f.name, f.typ)
+		}
+
+		if tag := reflect.StructTag(structType.Tag(i)).Get("scf"); tag != "" {
+			switch {
+			case f.slice && tag == "count=1":
+				f.countSize = 1
+			case f.slice && tag == "count=2":
+				f.countSize = 2
+			case f.bytes && tag == "rest" && i == structType.NumFields()-1:
+				f.countSize = 0
+			default:
+				return nil, fmt.Errorf("field %s has unsupported tag %q", f.name, tag)
+			}
+		}
+
+		fields = append(fields, f)
+	}
+
+	for _, path := range paths {
+		imports[path] = true
+	}
+	return fields, nil
+}
+
+func integerSize(typ types.Type) int {
+	basic, ok := typ.Underlying().(*types.Basic)
+	if !ok {
+		return 0
+	}
+	switch basic.Kind() {
+	case types.Int8, types.Uint8:
+		return 1
+	case types.Int16, types.Uint16:
+		return 2
+	case types.Int32, types.Uint32:
+		return 4
+	case types.Int64, types.Uint64:
+		return 8
+	}
+	return 0
+}
+
+func lowerFirst(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToLower(r)) + name[size:]
+}
+
+func writeFile(w *bytes.Buffer, pkgName string, commands []command, imports map[string]bool) {
+	generated := false
+	usesBinary := false
+	for _, cmd := range commands {
+		if len(cmd.fields) != 0 {
+			generated = true
+		}
+		for _, f := range cmd.fields {
+			if f.size > 1 {
+				usesBinary = true
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "// Code generated by scfgen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(w, "package %s\n\n", pkgName)
+
+	var paths []string
+	if generated {
+		paths = append(paths, "github.com/GreenLightning/zigbee-conductor/pkg/scf")
+	}
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	if usesBinary || len(paths) != 0 {
+		fmt.Fprintf(w, "import (\n")
+		if usesBinary {
+			fmt.Fprintf(w, "\t\"encoding/binary\"\n")
+			if len(paths) != 0 {
+				fmt.Fprintf(w, "\n")
+			}
+		}
+		for _, path := range paths {
+			fmt.Fprintf(w, "\t%q\n", path)
+		}
+		fmt.Fprintf(w, ")\n\n")
+	}
+
+	method := lowerFirst(*headerName)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "func (%s) %s() %s {\n", cmd.name, method, *headerName)
+		fmt.Fprintf(w, "\treturn %s{%s}\n", *headerName, strings.Join(cmd.header, ", "))
+		fmt.Fprintf(w, "}\n\n")
+
+		if cmd.fields != nil {
+			writeMarshal(w, cmd)
+			writeUnmarshal(w, cmd)
+		}
+	}
+
+	fmt.Fprintf(w, "var parsersBy%s = map[%s]func(data []byte) (interface{}, error){\n", *headerName, *headerName)
+	for _, cmd := range commands {
+		if cmd.fields == nil {
+			continue
+		}
+		fmt.Fprintf(w, "\t{%s}: func(data []byte) (interface{}, error) {\n", strings.Join(cmd.header, ", "))
+		fmt.Fprintf(w, "\t\tvar command %s\n", cmd.name)
+		fmt.Fprintf(w, "\t\t_, err := command.UnmarshalSCF(data)\n")
+		fmt.Fprintf(w, "\t\treturn command, err\n")
+		fmt.Fprintf(w, "\t},\n")
+	}
+	fmt.Fprintf(w, "}\n")
+}
+
+func writeMarshal(w *bytes.Buffer, cmd command) {
+	if len(cmd.fields) == 0 {
+		fmt.Fprintf(w, "func (command %s) MarshalSCF() []byte {\n", cmd.name)
+		fmt.Fprintf(w, "\treturn nil\n")
+		fmt.Fprintf(w, "}\n\n")
+		return
+	}
+
+	capacity := 0
+	var lengths []string
+	for _, f := range cmd.fields {
+		if f.slice {
+			capacity += f.countSize
+			if f.size == 1 {
+				lengths = append(lengths, fmt.Sprintf("len(command.%s)", f.name))
+			} else {
+				lengths = append(lengths, fmt.Sprintf("%d*len(command.%s)", f.size, f.name))
+			}
+		} else {
+			capacity += f.size
+		}
+	}
+
+	fmt.Fprintf(w, "func (command %s) MarshalSCF() []byte {\n", cmd.name)
+	for _, f := range cmd.fields {
+		if f.slice && f.countSize != 0 {
+			maximum := 1<<uint(8*f.countSize) - 1
+			fmt.Fprintf(w, "\tif len(command.%s) > %d {\n", f.name, maximum)
+			fmt.Fprintf(w, "\t\tcommand.%s = command.%s[:%d]\n", f.name, f.name, maximum)
+			fmt.Fprintf(w, "\t}\n")
+		}
+	}
+	fmt.Fprintf(w, "\tdata := make([]byte, 0, %s)\n", strings.Join(append([]string{fmt.Sprint(capacity)}, lengths...), "+"))
+
+	for _, f := range cmd.fields {
+		if !f.slice {
+			fmt.Fprintf(w, "\tdata = append(data, %s)\n", byteList("command."+f.name, f.size))
+			continue
+		}
+		if f.countSize != 0 {
+			fmt.Fprintf(w, "\tdata = append(data, %s)\n", byteList(fmt.Sprintf("len(command.%s)", f.name), f.countSize))
+		}
+		if f.bytes {
+			fmt.Fprintf(w, "\tdata = append(data, command.%s...)\n", f.name)
+		} else {
+			fmt.Fprintf(w, "\tfor _, value := range command.%s {\n", f.name)
+			fmt.Fprintf(w, "\t\tdata = append(data, %s)\n", byteList("value", f.size))
+			fmt.Fprintf(w, "\t}\n")
+		}
+	}
+
+	fmt.Fprintf(w, "\treturn data\n")
+	fmt.Fprintf(w, "}\n\n")
+}
+
+func byteList(value string, size int) string {
+	list := make([]string, size)
+	for i := range list {
+		if i == 0 {
+			list[i] = fmt.Sprintf("byte(%s)", value)
+		} else {
+			list[i] = fmt.Sprintf("byte(%s>>%d)", value, 8*i)
+		}
+	}
+	return strings.Join(list, ", ")
+}
+
+func readInteger(typ string, data string, size int) string {
+	value := data
+	if size != 1 {
+		value = fmt.Sprintf("binary.LittleEndian.Uint%d(%s)", 8*size, data)
+	}
+	if typ == fmt.Sprintf("uint%d", 8*size) || (size == 1 && typ == "byte") {
+		return value
+	}
+	return fmt.Sprintf("%s(%s)", typ, value)
+}
+
+func slice(data string, offset int) string {
+	if offset == 0 {
+		return data
+	}
+	return fmt.Sprintf("%s[%d:]", data, offset)
+}
+
+func writeUnmarshal(w *bytes.Buffer, cmd command) {
+	fmt.Fprintf(w, "func (command *%s) UnmarshalSCF(data []byte) ([]byte, error) {\n", cmd.name)
+
+	declared := false
+	for i := 0; i < len(cmd.fields); {
+		f := cmd.fields[i]
+
+		if !f.slice {
+			// Parse consecutive integers with a single length check.
+			end, size := i, 0
+			for end < len(cmd.fields) && !cmd.fields[end].slice {
+				size += cmd.fields[end].size
+				end++
+			}
+			fmt.Fprintf(w, "\tif len(data) < %d {\n", size)
+			fmt.Fprintf(w, "\t\treturn nil, scf.ErrInvalidData\n")
+			fmt.Fprintf(w, "\t}\n")
+			offset := 0
+			for ; i < end; i++ {
+				f := cmd.fields[i]
+				if f.size == 1 {
+					fmt.Fprintf(w, "\tcommand.%s = %s\n", f.name, readInteger(f.typ, fmt.Sprintf("data[%d]", offset), 1))
+				} else {
+					fmt.Fprintf(w, "\tcommand.%s = %s\n", f.name, readInteger(f.typ, slice("data", offset), f.size))
+				}
+				offset += f.size
+			}
+			fmt.Fprintf(w, "\tdata = data[%d:]\n", size)
+			continue
+		}
+
+		if f.countSize == 0 {
+			fmt.Fprintf(w, "\tcommand.%s = make(%s, len(data))\n", f.name, f.typ)
+			fmt.Fprintf(w, "\tcopy(command.%s, data)\n", f.name)
+			fmt.Fprintf(w, "\tdata = data[len(data):]\n")
+			i++
+			continue
+		}
+
+		assign := "="
+		if !declared {
+			assign, declared = ":=", true
+		}
+		fmt.Fprintf(w, "\tif len(data) < %d {\n", f.countSize)
+		fmt.Fprintf(w, "\t\treturn nil, scf.ErrInvalidData\n")
+		fmt.Fprintf(w, "\t}\n")
+		if f.countSize == 1 {
+			fmt.Fprintf(w, "\tlength %s int(data[0])\n", assign)
+		} else {
+			fmt.Fprintf(w, "\tlength %s int(binary.LittleEndian.Uint16(data))\n", assign)
+		}
+		fmt.Fprintf(w, "\tdata = data[%d:]\n", f.countSize)
+
+		total := "length"
+		if f.size != 1 {
+			total = fmt.Sprintf("%d*length", f.size)
+		}
+		fmt.Fprintf(w, "\tif len(data) < %s {\n", total)
+		fmt.Fprintf(w, "\t\treturn nil, scf.ErrInvalidData\n")
+		fmt.Fprintf(w, "\t}\n")
+		fmt.Fprintf(w, "\tcommand.%s = make(%s, length)\n", f.name, f.typ)
+		if f.bytes {
+			fmt.Fprintf(w, "\tcopy(command.%s, data)\n", f.name)
+		} else {
+			index := fmt.Sprintf("data[%d*i:]", f.size)
+			if f.size == 1 {
+				index = "data[i]"
+			}
+			fmt.Fprintf(w, "\tfor i := range command.%s {\n", f.name)
+			fmt.Fprintf(w, "\t\tcommand.%s[i] = %s\n", f.name, readInteger(f.elemType, index, f.size))
+			fmt.Fprintf(w, "\t}\n")
+		}
+		fmt.Fprintf(w, "\tdata = data[%s:]\n", total)
+
+		i++
+	}
+
+	fmt.Fprintf(w, "\treturn data, nil\n")
+	fmt.Fprintf(w, "}\n\n")
+}
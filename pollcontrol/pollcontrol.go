@@ -0,0 +1,215 @@
+// Package pollcontrol communicates with sleepy end devices using the Poll Control cluster.
+//
+// A sleepy end device only polls its parent for messages every few minutes
+// (or even hours), so messages sent to it at other times are likely to time
+// out. Devices implementing the Poll Control cluster periodically send a
+// Check-in command. The Manager answers it and, if messages are pending for
+// the device, instructs the device to poll quickly while the messages are sent.
+package pollcontrol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/dispatch"
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+var ErrUnexpectedResponse = errors.New("unexpected response")
+
+// Manager answers Check-in commands and keeps a queue of pending messages
+// for each device.
+type Manager struct {
+	dispatcher *dispatch.Dispatcher
+
+	// SourceEndpoint is the local endpoint used to send commands.
+	SourceEndpoint uint8
+
+	// FastPollTimeout is the duration of the fast poll mode requested in Check-in
+	// Responses in quarter seconds. Zero uses the value configured on the device.
+	FastPollTimeout uint16
+
+	mutex  sync.Mutex
+	queues map[uint16][]func()
+}
+
+// NewManager creates a Manager and registers it as a handler with the dispatcher.
+func NewManager(dispatcher *dispatch.Dispatcher) *Manager {
+	manager := &Manager{
+		dispatcher:     dispatcher,
+		SourceEndpoint: 1,
+		queues:         make(map[uint16][]func()),
+	}
+	dispatcher.AddHandler(manager)
+	return manager
+}
+
+// Enqueue queues a message for a device. The message is sent after the
+// device has checked in the next time. Errors are ignored, because the
+// caller is no longer waiting at this point.
+func (m *Manager) Enqueue(message zigbee.OutgoingMessage) {
+	m.enqueue(message.Destination.Short, func() {
+		m.dispatcher.Send(message)
+	})
+}
+
+// Pending returns the number of queued messages for a device.
+func (m *Manager) Pending(address uint16) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return len(m.queues[address])
+}
+
+// SetCheckInInterval configures how often the device checks in. The write is
+// performed after the device has checked in the next time, so this method
+// blocks until then or until the context is done.
+func (m *Manager) SetCheckInInterval(ctx context.Context, address uint16, endpoint uint8, interval time.Duration) error {
+	data, err := zcl.SerializeWriteAttributesCommand(zcl.WriteAttributesCommand{
+		Records: []zcl.WriteAttributeRecord{{
+			AttributeID: zcl.AttributePollControlCheckInInterval,
+			DataType:    zcl.DataTypeUint32,
+			Value:       uint32(interval / (time.Second / 4)),
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("writing check-in interval: %w", err)
+	}
+
+	frame := zcl.Frame{
+		FrameHeader: zcl.FrameHeader{
+			Type:      zcl.FrameTypeGlobal,
+			CommandID: zcl.CommandWriteAttributes,
+		},
+		Data: data,
+	}
+
+	result := make(chan error, 1)
+	m.enqueue(address, func() {
+		// The caller has given up, do not send the write anymore.
+		if ctx.Err() != nil {
+			return
+		}
+		response, err := m.dispatcher.RequestFrame(ctx, m.message(address, endpoint), frame)
+		if err == nil {
+			err = checkWriteAttributesResponse(response)
+		}
+		result <- err
+	})
+
+	select {
+	case err := <-result:
+		if err != nil {
+			return fmt.Errorf("writing check-in interval: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// HandleMessage answers Check-in commands. If messages are pending for the
+// device, it is put into fast poll mode until all messages have been sent.
+func (m *Manager) HandleMessage(message zigbee.IncomingMessage) bool {
+	if message.ClusterID != uint16(zcl.ClusterGeneralPollControl) {
+		return false
+	}
+
+	frame, err := zcl.ParseFrame(message.Data)
+	if err != nil {
+		return false
+	}
+
+	command, err := zcl.ParseClusterCommand(zcl.ClusterGeneralPollControl, frame)
+	if err != nil {
+		return false
+	}
+
+	if _, ok := command.(*zcl.CheckInCommand); !ok {
+		return false
+	}
+
+	address := message.Source.Short
+
+	m.mutex.Lock()
+	queue := m.queues[address]
+	delete(m.queues, address)
+	m.mutex.Unlock()
+
+	response := zcl.NewClusterCommandFrame(&zcl.CheckInResponseCommand{
+		StartFastPolling: len(queue) != 0,
+		FastPollTimeout:  m.FastPollTimeout,
+	})
+	response.TransSeqNumber = frame.TransSeqNumber
+
+	// Must not block the dispatcher.
+	go m.flush(address, message.SourceEndpoint, response, queue)
+
+	return true
+}
+
+func (m *Manager) flush(address uint16, endpoint uint8, response zcl.Frame, queue []func()) {
+	outgoing := m.message(address, endpoint)
+	outgoing.Data = zcl.SerializeFrame(response)
+	if err := m.dispatcher.Send(outgoing); err != nil || len(queue) == 0 {
+		return
+	}
+
+	for _, send := range queue {
+		send()
+	}
+
+	stop := zcl.NewClusterCommandFrame(&zcl.FastPollStopCommand{})
+	stop.TransSeqNumber = m.dispatcher.NextTransactionSequenceNumber()
+	outgoing.Data = zcl.SerializeFrame(stop)
+	m.dispatcher.Send(outgoing)
+}
+
+func (m *Manager) enqueue(address uint16, send func()) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.queues[address] = append(m.queues[address], send)
+}
+
+func (m *Manager) message(address uint16, endpoint uint8) zigbee.OutgoingMessage {
+	return zigbee.OutgoingMessage{
+		Destination:         zigbee.Address{Mode: zigbee.AddressModeNWK, Short: address},
+		DestinationEndpoint: endpoint,
+		SourceEndpoint:      m.SourceEndpoint,
+		ClusterID:           uint16(zcl.ClusterGeneralPollControl),
+		Radius:              zigbee.DefaultRadius,
+	}
+}
+
+func checkWriteAttributesResponse(response zcl.Frame) error {
+	if response.Type != zcl.FrameTypeGlobal {
+		return ErrUnexpectedResponse
+	}
+
+	switch response.CommandID {
+	case zcl.CommandWriteAttributesResponse:
+		cmd, err := zcl.ParseWriteAttributesResponseCommand(response.Data)
+		if err != nil {
+			return err
+		}
+		for _, record := range cmd.Records {
+			if record.Status != zcl.StatusSuccess {
+				return fmt.Errorf("status: %v", record.Status)
+			}
+		}
+		return nil
+
+	case zcl.CommandDefaultResponse:
+		cmd, err := zcl.ParseDefaultResponseCommand(response.Data)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("default response: %v", cmd.Status)
+
+	default:
+		return ErrUnexpectedResponse
+	}
+}
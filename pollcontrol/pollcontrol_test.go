@@ -0,0 +1,189 @@
+package pollcontrol
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/dispatch"
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+// testDevice simulates the Poll Control cluster server of a sleepy device.
+type testDevice struct {
+	incoming chan zigbee.IncomingMessage
+	sent     chan zcl.Frame
+	interval chan uint32
+}
+
+func newTestDevice() *testDevice {
+	return &testDevice{
+		incoming: make(chan zigbee.IncomingMessage, 16),
+		sent:     make(chan zcl.Frame, 16),
+		interval: make(chan uint32, 1),
+	}
+}
+
+func (d *testDevice) Start() (chan zigbee.IncomingMessage, error) { return d.incoming, nil }
+func (d *testDevice) Close() error                                { close(d.incoming); return nil }
+func (d *testDevice) PermitJoining(enabled bool) error            { return nil }
+
+func (d *testDevice) Send(message zigbee.OutgoingMessage) error {
+	frame, err := zcl.ParseFrame(message.Data)
+	if err != nil {
+		return err
+	}
+
+	if frame.Type == zcl.FrameTypeGlobal && frame.CommandID == zcl.CommandWriteAttributes {
+		cmd, err := zcl.ParseWriteAttributesCommand(frame.Data)
+		if err != nil {
+			return err
+		}
+		for _, record := range cmd.Records {
+			if record.AttributeID == zcl.AttributePollControlCheckInInterval {
+				d.interval <- record.Value.(uint32)
+			}
+		}
+		d.receive(zcl.Frame{
+			FrameHeader: zcl.FrameHeader{
+				Type:                    zcl.FrameTypeGlobal,
+				DirectionServerToClient: true,
+				TransSeqNumber:          frame.TransSeqNumber,
+				CommandID:               zcl.CommandWriteAttributesResponse,
+			},
+			Data: zcl.SerializeWriteAttributesResponseCommand(zcl.WriteAttributesResponseCommand{}),
+		})
+	}
+
+	d.sent <- frame
+	return nil
+}
+
+func (d *testDevice) receive(frame zcl.Frame) {
+	d.incoming <- zigbee.IncomingMessage{
+		Source:              zigbee.Address{Mode: zigbee.AddressModeNWK, Short: 0x1234},
+		SourceEndpoint:      1,
+		DestinationEndpoint: 1,
+		ClusterID:           uint16(zcl.ClusterGeneralPollControl),
+		Data:                zcl.SerializeFrame(frame),
+	}
+}
+
+func (d *testDevice) checkIn(tsn uint8) {
+	frame := zcl.NewClusterCommandFrame(&zcl.CheckInCommand{})
+	frame.TransSeqNumber = tsn
+	d.receive(frame)
+}
+
+func (d *testDevice) next(t *testing.T) zcl.Frame {
+	t.Helper()
+	select {
+	case frame := <-d.sent:
+		return frame
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+		return zcl.Frame{}
+	}
+}
+
+func (d *testDevice) nextCommand(t *testing.T) zcl.ClusterCommand {
+	t.Helper()
+	frame := d.next(t)
+	command, err := zcl.ParseClusterCommand(zcl.ClusterGeneralPollControl, frame)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	return command
+}
+
+func TestCheckInEmptyQueue(t *testing.T) {
+	device := newTestDevice()
+	dispatcher := dispatch.New(device)
+	if _, err := dispatcher.Start(); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	NewManager(dispatcher)
+
+	device.checkIn(42)
+
+	frame := device.next(t)
+	if frame.TransSeqNumber != 42 {
+		t.Errorf("wrong transaction sequence number: %d", frame.TransSeqNumber)
+	}
+	command, err := zcl.ParseClusterCommand(zcl.ClusterGeneralPollControl, frame)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if cmd, ok := command.(*zcl.CheckInResponseCommand); !ok || cmd.StartFastPolling {
+		t.Fatalf("unexpected command: %T%+v", command, command)
+	}
+}
+
+func TestCheckInFlushesQueue(t *testing.T) {
+	device := newTestDevice()
+	dispatcher := dispatch.New(device)
+	if _, err := dispatcher.Start(); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	manager := NewManager(dispatcher)
+	manager.FastPollTimeout = 40
+
+	queued := zcl.NewClusterCommandFrame(&zcl.SetShortPollIntervalCommand{NewShortPollInterval: 2})
+	manager.Enqueue(zigbee.OutgoingMessage{
+		Destination:         zigbee.Address{Mode: zigbee.AddressModeNWK, Short: 0x1234},
+		DestinationEndpoint: 1,
+		SourceEndpoint:      1,
+		ClusterID:           uint16(zcl.ClusterGeneralPollControl),
+		Radius:              zigbee.DefaultRadius,
+		Data:                zcl.SerializeFrame(queued),
+	})
+
+	result := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		result <- manager.SetCheckInInterval(ctx, 0x1234, 1, time.Hour)
+	}()
+
+	// Wait until both messages have been queued.
+	for manager.Pending(0x1234) != 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	device.checkIn(7)
+
+	command := device.nextCommand(t)
+	if cmd, ok := command.(*zcl.CheckInResponseCommand); !ok || !cmd.StartFastPolling || cmd.FastPollTimeout != 40 {
+		t.Fatalf("unexpected command: %T%+v", command, command)
+	}
+
+	command = device.nextCommand(t)
+	if cmd, ok := command.(*zcl.SetShortPollIntervalCommand); !ok || cmd.NewShortPollInterval != 2 {
+		t.Fatalf("unexpected command: %T%+v", command, command)
+	}
+
+	if frame := device.next(t); frame.Type != zcl.FrameTypeGlobal || frame.CommandID != zcl.CommandWriteAttributes {
+		t.Fatalf("unexpected frame: %+v", frame)
+	}
+	if interval := <-device.interval; interval != 4*60*60 {
+		t.Errorf("wrong check-in interval: %d", interval)
+	}
+
+	if err := <-result; err != nil {
+		t.Error("unexpected err:", err)
+	}
+
+	command = device.nextCommand(t)
+	if _, ok := command.(*zcl.FastPollStopCommand); !ok {
+		t.Fatalf("unexpected command: %T%+v", command, command)
+	}
+
+	if pending := manager.Pending(0x1234); pending != 0 {
+		t.Errorf("%d messages still pending", pending)
+	}
+}
@@ -0,0 +1,215 @@
+// Package timeserver implements the server side of the Time cluster on the
+// coordinator.
+//
+// Many devices (thermostats, door locks, meters) read the Time cluster of the
+// coordinator to set their clocks. The Server provides the attributes of the
+// cluster to package zclserver, which answers the requests, using the system
+// clock and a configurable location for the time zone and daylight saving
+// time attributes.
+package timeserver
+
+import (
+	"sync"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zclserver"
+)
+
+// Server computes the attributes of the Time cluster.
+type Server struct {
+	// Location determines the TimeZone and DST attributes. Defaults to time.Local.
+	Location *time.Location
+
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+
+	mutex sync.Mutex
+	zones map[zoneKey]timeZoneInfo
+}
+
+type zoneKey struct {
+	location *time.Location
+	year     int
+}
+
+// NewServer creates a Server and registers the Time cluster on the endpoint
+// of the ZCL server.
+func NewServer(server *zclserver.Server, endpoint uint8) (*Server, error) {
+	s := &Server{
+		Location: time.Local,
+		Now:      time.Now,
+	}
+	if err := server.AddCluster(endpoint, s.Cluster()); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// clock is the state from which the attributes are computed.
+type clock struct {
+	utc    int64 // seconds since zcl.UTCEpoch
+	offset int   // current offset of the location in seconds east of UTC
+	zone   timeZoneInfo
+}
+
+// Cluster returns the definition of the Time cluster. The values of the
+// attributes are computed whenever they are read.
+func (s *Server) Cluster() zclserver.Cluster {
+	attribute := func(id zcl.AttributeID, typ zcl.DataType, value func(c clock) interface{}) zclserver.Attribute {
+		return zclserver.Attribute{
+			ID:       id,
+			DataType: typ,
+			Value:    value(s.clock()),
+			OnRead:   func() interface{} { return value(s.clock()) },
+		}
+	}
+
+	return zclserver.Cluster{
+		ID: zcl.ClusterGeneralTime,
+		Attributes: []zclserver.Attribute{
+			attribute(zcl.AttributeTimeTime, zcl.DataTypeUTCTime, func(c clock) interface{} {
+				return uint32(c.utc)
+			}),
+			attribute(zcl.AttributeTimeTimeStatus, zcl.DataTypeBitmap8, func(c clock) interface{} {
+				return uint8(zcl.TimeStatusMaster | zcl.TimeStatusMasterZoneDst)
+			}),
+			attribute(zcl.AttributeTimeTimeZone, zcl.DataTypeInt32, func(c clock) interface{} {
+				return int32(c.zone.standardOffset)
+			}),
+			attribute(zcl.AttributeTimeDstStart, zcl.DataTypeUint32, func(c clock) interface{} {
+				return c.zone.dstStart
+			}),
+			attribute(zcl.AttributeTimeDstEnd, zcl.DataTypeUint32, func(c clock) interface{} {
+				return c.zone.dstEnd
+			}),
+			attribute(zcl.AttributeTimeDstShift, zcl.DataTypeInt32, func(c clock) interface{} {
+				return int32(c.zone.dstShift)
+			}),
+			attribute(zcl.AttributeTimeStandardTime, zcl.DataTypeUint32, func(c clock) interface{} {
+				return uint32(c.utc + int64(c.zone.standardOffset))
+			}),
+			attribute(zcl.AttributeTimeLocalTime, zcl.DataTypeUint32, func(c clock) interface{} {
+				return uint32(c.utc + int64(c.offset))
+			}),
+		},
+	}
+}
+
+func (s *Server) clock() clock {
+	location := s.Location
+	if location == nil {
+		location = time.Local
+	}
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+
+	local := now().In(location)
+	_, offset := local.Zone()
+	return clock{
+		utc:    int64(zcl.TimeToUTC(local)),
+		offset: offset,
+		zone:   s.zoneInfo(location, local.Year()),
+	}
+}
+
+// zoneInfo returns the cached time zone information of the location in the
+// given year, because computing it takes several hundred calls to Zone.
+func (s *Server) zoneInfo(location *time.Location, year int) timeZoneInfo {
+	key := zoneKey{location, year}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	info, ok := s.zones[key]
+	if !ok {
+		if s.zones == nil {
+			s.zones = make(map[zoneKey]timeZoneInfo)
+		}
+		info = zoneInfo(location, year)
+		s.zones[key] = info
+	}
+	return info
+}
+
+type timeZoneInfo struct {
+	standardOffset int // seconds east of UTC
+	dstShift       int // seconds
+	dstStart       uint32
+	dstEnd         uint32
+}
+
+// zoneInfo determines the standard offset and the daylight saving time
+// transitions of a location in the given year. The time package does not
+// expose the transitions directly, so the year is scanned day by day and each
+// change of the offset is located to the second using a binary search.
+func zoneInfo(location *time.Location, year int) timeZoneInfo {
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, location).Unix()
+	end := time.Date(year+1, time.January, 1, 0, 0, 0, 0, location).Unix()
+
+	offsetAt := func(unix int64) int {
+		_, offset := time.Unix(unix, 0).In(location).Zone()
+		return offset
+	}
+
+	type transition struct {
+		unix   int64
+		offset int // offset after the transition
+	}
+	var transitions []transition
+
+	const day = 24 * 60 * 60
+	previous, previousOffset := start, offsetAt(start)
+	for current := start + day; previous < end; current += day {
+		if current > end {
+			current = end
+		}
+		currentOffset := offsetAt(current)
+		if currentOffset != previousOffset {
+			// Invariant: offsetAt(low) == previousOffset, offsetAt(high) != previousOffset.
+			low, high := previous, current
+			for high-low > 1 {
+				middle := low + (high-low)/2
+				if offsetAt(middle) == previousOffset {
+					low = middle
+				} else {
+					high = middle
+				}
+			}
+			transitions = append(transitions, transition{high, currentOffset})
+		}
+		previous, previousOffset = current, currentOffset
+	}
+
+	info := timeZoneInfo{standardOffset: offsetAt(start)}
+	if len(transitions) == 0 {
+		return info
+	}
+
+	// The standard time is the smaller offset (daylight saving time moves
+	// the clock forward).
+	minimum, maximum := info.standardOffset, info.standardOffset
+	for _, t := range transitions {
+		if t.offset < minimum {
+			minimum = t.offset
+		}
+		if t.offset > maximum {
+			maximum = t.offset
+		}
+	}
+	info.standardOffset = minimum
+	info.dstShift = maximum - minimum
+
+	for _, t := range transitions {
+		if t.offset == maximum && info.dstStart == 0 {
+			info.dstStart = zcl.TimeToUTC(time.Unix(t.unix, 0))
+		}
+		if t.offset == minimum && info.dstEnd == 0 {
+			info.dstEnd = zcl.TimeToUTC(time.Unix(t.unix, 0))
+		}
+	}
+
+	return info
+}
@@ -0,0 +1,127 @@
+package timeserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/dispatch"
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zclserver"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+func TestZoneInfo(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database not available:", err)
+	}
+
+	info := zoneInfo(berlin, 2024)
+	if info.standardOffset != 3600 || info.dstShift != 3600 {
+		t.Errorf("wrong offsets: %+v", info)
+	}
+	if start := zcl.UTCToTime(info.dstStart); !start.Equal(time.Date(2024, time.March, 31, 1, 0, 0, 0, time.UTC)) {
+		t.Errorf("wrong DST start: %v", start)
+	}
+	if end := zcl.UTCToTime(info.dstEnd); !end.Equal(time.Date(2024, time.October, 27, 1, 0, 0, 0, time.UTC)) {
+		t.Errorf("wrong DST end: %v", end)
+	}
+
+	var server Server
+	if server.zoneInfo(berlin, 2024) != info || len(server.zones) != 1 {
+		t.Errorf("zone info not cached: %v", server.zones)
+	}
+
+	info = zoneInfo(time.FixedZone("UTC-3", -3*3600), 2024)
+	if info != (timeZoneInfo{standardOffset: -3 * 3600}) {
+		t.Errorf("wrong info for fixed zone: %+v", info)
+	}
+}
+
+// testDevice reads the Time cluster of the coordinator.
+type testDevice struct {
+	incoming chan zigbee.IncomingMessage
+	sent     chan zigbee.OutgoingMessage
+}
+
+func (d *testDevice) Start() (chan zigbee.IncomingMessage, error) { return d.incoming, nil }
+func (d *testDevice) Close() error                                { close(d.incoming); return nil }
+func (d *testDevice) PermitJoining(enabled bool) error            { return nil }
+
+func (d *testDevice) Send(message zigbee.OutgoingMessage) error {
+	d.sent <- message
+	return nil
+}
+
+func TestServer(t *testing.T) {
+	device := &testDevice{
+		incoming: make(chan zigbee.IncomingMessage, 1),
+		sent:     make(chan zigbee.OutgoingMessage, 1),
+	}
+	dispatcher := dispatch.New(device)
+	if _, err := dispatcher.Start(); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	now := time.Date(2024, time.July, 1, 12, 0, 0, 0, time.UTC)
+	server, err := NewServer(zclserver.NewServer(dispatcher), 1)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	server.Location = time.FixedZone("UTC+2", 2*3600)
+	server.Now = func() time.Time { return now }
+
+	device.incoming <- zigbee.IncomingMessage{
+		Source:              zigbee.Address{Mode: zigbee.AddressModeNWK, Short: 0x1234},
+		SourceEndpoint:      1,
+		DestinationEndpoint: 1,
+		ClusterID:           uint16(zcl.ClusterGeneralTime),
+		Data: zcl.SerializeFrame(zcl.Frame{
+			FrameHeader: zcl.FrameHeader{
+				Type:           zcl.FrameTypeGlobal,
+				TransSeqNumber: 42,
+				CommandID:      zcl.CommandReadAttributes,
+			},
+			Data: zcl.SerializeReadAttributesCommand(zcl.ReadAttributesCommand{
+				Attributes: []zcl.AttributeID{zcl.AttributeTimeTime, zcl.AttributeTimeTimeZone, zcl.AttributeTimeLocalTime, zcl.AttributeTimeValidUntilTime},
+			}),
+		}),
+	}
+
+	var message zigbee.OutgoingMessage
+	select {
+	case message = <-device.sent:
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+
+	frame, err := zcl.ParseFrame(message.Data)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if frame.CommandID != zcl.CommandReadAttributesResponse || !frame.DirectionServerToClient || frame.TransSeqNumber != 42 {
+		t.Fatalf("unexpected frame: %+v", frame)
+	}
+
+	cmd, err := zcl.ParseReadAttributesResponseCommand(frame.Data)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	utc := uint32(now.Sub(zcl.UTCEpoch) / time.Second)
+	expected := []zcl.ReadAttributeStatusRecord{
+		{AttributeID: zcl.AttributeTimeTime, Status: zcl.StatusSuccess, DataType: zcl.DataTypeUTCTime, Value: utc},
+		{AttributeID: zcl.AttributeTimeTimeZone, Status: zcl.StatusSuccess, DataType: zcl.DataTypeInt32, Value: int32(7200)},
+		{AttributeID: zcl.AttributeTimeLocalTime, Status: zcl.StatusSuccess, DataType: zcl.DataTypeUint32, Value: utc + 7200},
+		{AttributeID: zcl.AttributeTimeValidUntilTime, Status: zcl.StatusUnsupportedAttribute},
+	}
+	if len(cmd.Records) != len(expected) {
+		t.Fatalf("wrong number of records: %+v", cmd.Records)
+	}
+	for i, record := range cmd.Records {
+		if record != expected[i] {
+			t.Errorf("record %d: got %+v, expected %+v", i, record, expected[i])
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/GreenLightning/zigbee-conductor/dispatch"
+	"github.com/GreenLightning/zigbee-conductor/topology"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+// runTopology crawls the network and writes the map to stdout or a file.
+func runTopology(controller zigbee.Controller, args []string) {
+	flags := flag.NewFlagSet("topology", flag.ExitOnError)
+	formatFlag := flags.String("format", "dot", "output format: dot, json")
+	outputFlag := flags.String("output", "", "name of the output file (default stdout)")
+	routesFlag := flags.Bool("routes", true, "read the routing tables of routers")
+	timeoutFlag := flags.Duration("timeout", topology.DefaultOptions.Timeout, "time to wait for the tables of each router")
+	flags.Parse(args)
+
+	if *formatFlag != "dot" && *formatFlag != "json" {
+		fmt.Fprintln(os.Stderr, "unknown format:", *formatFlag)
+		os.Exit(2)
+	}
+
+	dispatcher := dispatch.New(controller)
+	incoming, err := dispatcher.Start()
+	check(err)
+
+	// Messages which are not responses must still be consumed.
+	go func() {
+		for range incoming {
+		}
+	}()
+
+	m, err := topology.CrawlWithOptions(context.Background(), dispatcher, topology.Options{
+		Routes:  *routesFlag,
+		Timeout: *timeoutFlag,
+	})
+	check(err)
+
+	var output io.Writer = os.Stdout
+	if *outputFlag != "" {
+		file, err := os.Create(*outputFlag)
+		check(err)
+		defer file.Close()
+		output = file
+	}
+
+	if *formatFlag == "json" {
+		check(m.WriteJSON(output))
+	} else {
+		check(m.WriteDOT(output))
+	}
+
+	for _, node := range m.Nodes {
+		if node.Error != "" {
+			fmt.Fprintf(os.Stderr, "0x%04x: %s\n", node.NetworkAddress, node.Error)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+// Package topology maps the mesh of the network.
+//
+// Crawl starts at the coordinator and reads the neighbor table (and
+// optionally the routing table) of every router it finds using the ZigBee
+// Device Profile. End devices do not have neighbor tables, they are only
+// known from the tables of their parents and other routers in range.
+//
+// The resulting Map can be written as JSON or as a graph in the DOT language
+// of Graphviz (e.g. "dot -Tsvg topology.dot > topology.svg").
+package topology
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/zdp"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+// A Node is a device of the network.
+type Node struct {
+	NetworkAddress uint16
+	IEEEAddress    zigbee.MACAddress // 0 if unknown
+	DeviceType     zdp.LogicalType
+	Depth          uint8
+
+	// Crawled is true if the neighbor table of the node has been read.
+	Crawled bool
+	// Error describes why the tables of a router could not be read.
+	Error string
+}
+
+// A Link is an entry in the neighbor table of the source node. Usually both
+// nodes report the link, possibly with a different link quality.
+type Link struct {
+	Source       uint16
+	Target       uint16
+	Relationship zdp.Relationship
+	LQI          uint8
+}
+
+// A Route is an entry in the routing table of the source node.
+type Route struct {
+	Source      uint16
+	Destination uint16
+	NextHop     uint16
+	Status      zdp.RouteStatus
+}
+
+// A Map is the result of a crawl. Nodes are ordered by network address, links
+// and routes by source and target or destination address.
+type Map struct {
+	Nodes  []Node
+	Links  []Link
+	Routes []Route
+}
+
+// Node returns the node with the given network address.
+func (m *Map) Node(nwkAddr uint16) (Node, bool) {
+	for _, node := range m.Nodes {
+		if node.NetworkAddress == nwkAddr {
+			return node, true
+		}
+	}
+	return Node{}, false
+}
+
+// Options control the crawl.
+type Options struct {
+	// Routes enables reading the routing tables.
+	Routes bool
+	// Timeout is the time to wait for all parts of a table of one node.
+	Timeout time.Duration
+}
+
+// DefaultOptions are used by Crawl.
+var DefaultOptions = Options{
+	Routes:  true,
+	Timeout: 10 * time.Second,
+}
+
+// Crawl maps the network using the DefaultOptions.
+func Crawl(ctx context.Context, requester zdp.Requester) (*Map, error) {
+	return CrawlWithOptions(ctx, requester, DefaultOptions)
+}
+
+// CrawlWithOptions maps the network starting at the coordinator. Routers whose
+// tables cannot be read are recorded with an error and do not abort the
+// crawl. If the context is done, the partial map is returned together with
+// the error of the context.
+//
+// The requester must be able to send Mgmt_Lqi_req (and Mgmt_Rtg_req if routes
+// are enabled). Otherwise the crawl fails with zdp.ErrNotSupported instead of
+// recording the same error for every router.
+func CrawlWithOptions(ctx context.Context, requester zdp.Requester, options Options) (*Map, error) {
+	nodes := map[uint16]*Node{
+		0x0000: {NetworkAddress: 0x0000, DeviceType: zdp.LogicalTypeCoordinator},
+	}
+	m := &Map{}
+	queue := []uint16{0x0000}
+
+	for len(queue) != 0 {
+		if err := ctx.Err(); err != nil {
+			m.Nodes = sortedNodes(nodes)
+			return m, err
+		}
+
+		nwkAddr := queue[0]
+		queue = queue[1:]
+		node := nodes[nwkAddr]
+
+		tableCtx, cancel := options.tableContext(ctx)
+		neighbors, err := zdp.NeighborTable(tableCtx, requester, nwkAddr)
+		cancel()
+		if errors.Is(err, zdp.ErrNotSupported) {
+			return nil, fmt.Errorf("reading neighbor table: %w", err)
+		}
+		if err != nil {
+			node.Error = fmt.Sprintf("reading neighbor table: %v", err)
+			continue
+		}
+		node.Crawled = true
+
+		for _, entry := range neighbors {
+			neighbor, ok := nodes[entry.NetworkAddr]
+			if !ok {
+				neighbor = &Node{NetworkAddress: entry.NetworkAddr, DeviceType: entry.DeviceType, Depth: entry.Depth}
+				nodes[entry.NetworkAddr] = neighbor
+				if entry.DeviceType == zdp.LogicalTypeRouter {
+					queue = append(queue, entry.NetworkAddr)
+				}
+			}
+			if entry.ExtendedAddr != 0 && entry.ExtendedAddr != 0xffffffffffffffff {
+				neighbor.IEEEAddress = entry.ExtendedAddr
+			}
+			m.Links = append(m.Links, Link{
+				Source:       nwkAddr,
+				Target:       entry.NetworkAddr,
+				Relationship: entry.Relationship,
+				LQI:          entry.LQI,
+			})
+		}
+
+		if options.Routes {
+			tableCtx, cancel := options.tableContext(ctx)
+			routes, err := zdp.RoutingTable(tableCtx, requester, nwkAddr)
+			cancel()
+			if errors.Is(err, zdp.ErrNotSupported) {
+				return nil, fmt.Errorf("reading routing table: %w", err)
+			}
+			if err != nil {
+				// Not all routers support Mgmt_Rtg_req.
+				node.Error = fmt.Sprintf("reading routing table: %v", err)
+				continue
+			}
+			for _, entry := range routes {
+				m.Routes = append(m.Routes, Route{
+					Source:      nwkAddr,
+					Destination: entry.DestinationAddr,
+					NextHop:     entry.NextHopAddr,
+					Status:      entry.Status,
+				})
+			}
+		}
+	}
+
+	m.Nodes = sortedNodes(nodes)
+	sort.SliceStable(m.Links, func(i, j int) bool {
+		a, b := m.Links[i], m.Links[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		return a.Target < b.Target
+	})
+	sort.SliceStable(m.Routes, func(i, j int) bool {
+		a, b := m.Routes[i], m.Routes[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		return a.Destination < b.Destination
+	})
+	return m, nil
+}
+
+func (options Options) tableContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if options.Timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, options.Timeout)
+}
+
+func sortedNodes(nodes map[uint16]*Node) []Node {
+	result := make([]Node, 0, len(nodes))
+	for _, node := range nodes {
+		result = append(result, *node)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].NetworkAddress < result[j].NetworkAddress
+	})
+	return result
+}
+
+// WriteJSON writes the map as indented JSON.
+func (m *Map) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	_, err = w.Write(data)
+	return err
+}
+
+// WriteDOT writes the map as a directed graph in the DOT language of
+// Graphviz. Each edge is a link labeled with its link quality. Links to
+// parents and children are drawn solid, other links dashed. Nodes whose
+// tables could not be read are drawn red.
+func (m *Map) WriteDOT(w io.Writer) error {
+	ew := &errWriter{w: w}
+
+	ew.printf("digraph topology {\n")
+	ew.printf("\tnode [fontname=\"monospace\"];\n")
+
+	for _, node := range m.Nodes {
+		label := fmt.Sprintf("0x%04x\\n%v", node.NetworkAddress, node.DeviceType)
+		if node.IEEEAddress != 0 {
+			label = fmt.Sprintf("0x%04x\\n%v\\n%v", node.NetworkAddress, node.IEEEAddress, node.DeviceType)
+		}
+		shape := "ellipse"
+		switch node.DeviceType {
+		case zdp.LogicalTypeCoordinator:
+			shape = "doubleoctagon"
+		case zdp.LogicalTypeRouter:
+			shape = "box"
+		}
+		attributes := ""
+		if node.Error != "" {
+			attributes = ", color=red"
+		}
+		ew.printf("\t%s [label=\"%s\", shape=%s%s];\n", nodeID(node.NetworkAddress), label, shape, attributes)
+	}
+
+	for _, link := range m.Links {
+		style := "dashed"
+		if link.Relationship == zdp.RelationshipParent || link.Relationship == zdp.RelationshipChild {
+			style = "solid"
+		}
+		ew.printf("\t%s -> %s [label=\"%d\", style=%s];\n", nodeID(link.Source), nodeID(link.Target), link.LQI, style)
+	}
+
+	ew.printf("}\n")
+	return ew.err
+}
+
+func nodeID(nwkAddr uint16) string {
+	return fmt.Sprintf("\"0x%04x\"", nwkAddr)
+}
+
+// errWriter remembers the first error, so that it only has to be checked once.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...interface{}) {
+	if ew.err == nil {
+		_, ew.err = fmt.Fprintf(ew.w, format, args...)
+	}
+}
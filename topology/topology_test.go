@@ -0,0 +1,293 @@
+package topology
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/dispatch"
+	"github.com/GreenLightning/zigbee-conductor/zdp"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+// testNetwork answers Mgmt_Lqi_req and Mgmt_Rtg_req with scripted tables.
+// Devices without a neighbor table do not answer. Responses contain at most
+// two entries, so that tables have to be read in multiple parts.
+type testNetwork struct {
+	incoming  chan zigbee.IncomingMessage
+	neighbors map[uint16][]zdp.NeighborTableEntry
+	routes    map[uint16][]zdp.RoutingTableEntry
+}
+
+func (n *testNetwork) Start() (chan zigbee.IncomingMessage, error) { return n.incoming, nil }
+func (n *testNetwork) Close() error                                { close(n.incoming); return nil }
+func (n *testNetwork) PermitJoining(enabled bool) error            { return nil }
+
+func (n *testNetwork) Send(message zigbee.OutgoingMessage) error {
+	tsn, command, err := zdp.ParseFrame(message.ClusterID, message.Data)
+	if err != nil {
+		return err
+	}
+
+	nwkAddr := message.Destination.Short
+	response, err := n.respond(nwkAddr, command)
+	if response == nil || err != nil {
+		return err
+	}
+
+	clusterID, data, err := zdp.SerializeFrame(tsn, response)
+	if err != nil {
+		return err
+	}
+	n.incoming <- zigbee.IncomingMessage{
+		Source:    zigbee.Address{Mode: zigbee.AddressModeNWK, Short: nwkAddr},
+		ClusterID: clusterID,
+		Data:      data,
+	}
+	return nil
+}
+
+// respond returns the response of the given device, nil if it does not answer.
+func (n *testNetwork) respond(nwkAddr uint16, command interface{}) (interface{}, error) {
+	var response interface{}
+	switch cmd := command.(type) {
+	case *zdp.MgmtLqiReq:
+		table, ok := n.neighbors[nwkAddr]
+		if !ok {
+			return nil, nil
+		}
+		start, end := page(int(cmd.StartIndex), len(table))
+		response = &zdp.MgmtLqiRsp{Status: zdp.StatusSuccess, NeighborTableEntries: uint8(len(table)), StartIndex: cmd.StartIndex, NeighborTableList: table[start:end]}
+	case *zdp.MgmtRtgReq:
+		table, ok := n.routes[nwkAddr]
+		if !ok {
+			response = &zdp.MgmtRtgRsp{Status: zdp.StatusNotSupported}
+			break
+		}
+		start, end := page(int(cmd.StartIndex), len(table))
+		response = &zdp.MgmtRtgRsp{Status: zdp.StatusSuccess, RoutingTableEntries: uint8(len(table)), StartIndex: cmd.StartIndex, RoutingTableList: table[start:end]}
+	default:
+		return nil, errors.New("unexpected request")
+	}
+	return response, nil
+}
+
+// testZNPNetwork behaves like a ZNP: ZDP messages passed to Send are never
+// answered, but the controller sends ZDP requests itself.
+type testZNPNetwork struct {
+	*testNetwork
+}
+
+func (n *testZNPNetwork) Send(message zigbee.OutgoingMessage) error {
+	return nil
+}
+
+func (n *testZNPNetwork) RequestZDP(ctx context.Context, nwkAddr uint16, command interface{}) (interface{}, error) {
+	response, err := n.respond(nwkAddr, command)
+	if response == nil && err == nil {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return response, err
+}
+
+// testUnsupportedNetwork cannot send management requests.
+type testUnsupportedNetwork struct {
+	*testNetwork
+}
+
+func (n *testUnsupportedNetwork) RequestZDP(ctx context.Context, nwkAddr uint16, command interface{}) (interface{}, error) {
+	return nil, zdp.ErrNotSupported
+}
+
+func page(start, length int) (int, int) {
+	end := start + 2
+	if end > length {
+		end = length
+	}
+	return start, end
+}
+
+func neighbor(nwkAddr uint16, ieee zigbee.MACAddress, deviceType zdp.LogicalType, relationship zdp.Relationship, depth, lqi uint8) zdp.NeighborTableEntry {
+	return zdp.NeighborTableEntry{
+		ExtendedPANID: 0xdddddddddddddddd,
+		ExtendedAddr:  ieee,
+		NetworkAddr:   nwkAddr,
+		DeviceType:    deviceType,
+		Relationship:  relationship,
+		Depth:         depth,
+		LQI:           lqi,
+	}
+}
+
+// The coordinator has two routers (0x1111 and 0x2222) and an end device
+// (0x0001). Router 0x1111 has another end device (0x3333) and can hear router
+// 0x2222, which does not answer.
+var testNeighbors = map[uint16][]zdp.NeighborTableEntry{
+	0x0000: {
+		neighbor(0x1111, 0x1111111111111111, zdp.LogicalTypeRouter, zdp.RelationshipChild, 1, 200),
+		neighbor(0x2222, 0x2222222222222222, zdp.LogicalTypeRouter, zdp.RelationshipChild, 1, 150),
+		neighbor(0x0001, 0x0101010101010101, zdp.LogicalTypeEndDevice, zdp.RelationshipChild, 1, 90),
+	},
+	0x1111: {
+		neighbor(0x0000, 0x0000000000000abc, zdp.LogicalTypeCoordinator, zdp.RelationshipParent, 0, 190),
+		neighbor(0x2222, 0x2222222222222222, zdp.LogicalTypeRouter, zdp.RelationshipSibling, 1, 60),
+		neighbor(0x3333, 0x3333333333333333, zdp.LogicalTypeEndDevice, zdp.RelationshipChild, 2, 120),
+	},
+}
+
+var testRoutes = map[uint16][]zdp.RoutingTableEntry{
+	0x0000: {
+		{DestinationAddr: 0x3333, Status: zdp.RouteStatusActive, NextHopAddr: 0x1111},
+	},
+}
+
+func TestCrawl(t *testing.T) {
+	network := &testNetwork{
+		incoming:  make(chan zigbee.IncomingMessage, 16),
+		neighbors: testNeighbors,
+		routes:    testRoutes,
+	}
+	dispatcher := dispatch.New(network)
+	if _, err := dispatcher.Start(); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	m, err := CrawlWithOptions(context.Background(), dispatcher, Options{Routes: true, Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	var addresses []uint16
+	for _, node := range m.Nodes {
+		addresses = append(addresses, node.NetworkAddress)
+	}
+	if !reflect.DeepEqual(addresses, []uint16{0x0000, 0x0001, 0x1111, 0x2222, 0x3333}) {
+		t.Errorf("wrong nodes: %04x", addresses)
+	}
+
+	coordinator, _ := m.Node(0x0000)
+	if !coordinator.Crawled || coordinator.IEEEAddress != 0x0abc || coordinator.Error != "" {
+		t.Errorf("wrong coordinator: %+v", coordinator)
+	}
+	router, _ := m.Node(0x1111)
+	if !router.Crawled || router.DeviceType != zdp.LogicalTypeRouter || router.Depth != 1 {
+		t.Errorf("wrong router: %+v", router)
+	}
+	if !strings.Contains(router.Error, "routing table") {
+		t.Errorf("missing routing table error: %q", router.Error)
+	}
+	silent, _ := m.Node(0x2222)
+	if silent.Crawled || !strings.Contains(silent.Error, "neighbor table") {
+		t.Errorf("wrong silent router: %+v", silent)
+	}
+	endDevice, _ := m.Node(0x3333)
+	if endDevice.Crawled || endDevice.Error != "" || endDevice.Depth != 2 || endDevice.IEEEAddress != 0x3333333333333333 {
+		t.Errorf("wrong end device: %+v", endDevice)
+	}
+
+	if len(m.Links) != 6 {
+		t.Errorf("wrong number of links: %+v", m.Links)
+	}
+	expectedLink := Link{Source: 0x1111, Target: 0x2222, Relationship: zdp.RelationshipSibling, LQI: 60}
+	if m.Links[4] != expectedLink {
+		t.Errorf("wrong link: %+v", m.Links[4])
+	}
+	expectedRoutes := []Route{{Source: 0x0000, Destination: 0x3333, NextHop: 0x1111, Status: zdp.RouteStatusActive}}
+	if !reflect.DeepEqual(m.Routes, expectedRoutes) {
+		t.Errorf("wrong routes: %+v", m.Routes)
+	}
+
+	var buffer bytes.Buffer
+	if err := m.WriteJSON(&buffer); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	var decoded Map
+	if err := json.Unmarshal(buffer.Bytes(), &decoded); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if !reflect.DeepEqual(&decoded, m) {
+		t.Errorf("wrong JSON round trip: %+v", decoded)
+	}
+
+	buffer.Reset()
+	if err := m.WriteDOT(&buffer); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	dot := buffer.String()
+	for _, expected := range []string{
+		"digraph topology {\n",
+		"\t\"0x0000\" [label=\"0x0000\\n0000000000000abc\\nCoordinator\", shape=doubleoctagon];\n",
+		"\t\"0x2222\" [label=\"0x2222\\n2222222222222222\\nRouter\", shape=box, color=red];\n",
+		"\t\"0x0000\" -> \"0x1111\" [label=\"200\", style=solid];\n",
+		"\t\"0x1111\" -> \"0x2222\" [label=\"60\", style=dashed];\n",
+	} {
+		if !strings.Contains(dot, expected) {
+			t.Errorf("missing %q in:\n%s", expected, dot)
+		}
+	}
+}
+
+func TestCrawlRequester(t *testing.T) {
+	network := &testZNPNetwork{&testNetwork{
+		incoming:  make(chan zigbee.IncomingMessage, 16),
+		neighbors: testNeighbors,
+		routes:    testRoutes,
+	}}
+	dispatcher := dispatch.New(network)
+	if _, err := dispatcher.Start(); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	m, err := CrawlWithOptions(context.Background(), dispatcher, Options{Routes: true, Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if len(m.Nodes) != 5 || len(m.Links) != 6 || len(m.Routes) != 1 {
+		t.Errorf("wrong map: %+v", m)
+	}
+	if router, _ := m.Node(0x1111); !router.Crawled {
+		t.Errorf("wrong router: %+v", router)
+	}
+}
+
+func TestCrawlNotSupported(t *testing.T) {
+	network := &testUnsupportedNetwork{&testNetwork{incoming: make(chan zigbee.IncomingMessage, 16)}}
+	dispatcher := dispatch.New(network)
+	if _, err := dispatcher.Start(); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	_, err := Crawl(context.Background(), dispatcher)
+	if !errors.Is(err, zdp.ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported, got %v", err)
+	}
+}
+
+func TestCrawlCanceled(t *testing.T) {
+	network := &testNetwork{incoming: make(chan zigbee.IncomingMessage, 16)}
+	dispatcher := dispatch.New(network)
+	if _, err := dispatcher.Start(); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	m, err := Crawl(ctx, dispatcher)
+	if err != context.Canceled {
+		t.Errorf("expected canceled, got %v", err)
+	}
+	if len(m.Nodes) != 1 {
+		t.Errorf("wrong partial map: %+v", m)
+	}
+}
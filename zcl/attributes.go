@@ -0,0 +1,89 @@
+package zcl
+
+import "fmt"
+
+// An AttributeDefinition describes an attribute of a cluster as specified in
+// the ZigBee Cluster Library.
+type AttributeDefinition struct {
+	ID       AttributeID
+	Name     string
+	DataType DataType
+}
+
+// A Unit describes how the raw value of a numeric attribute is converted to a
+// physical quantity. The physical value is the raw value multiplied by Scale.
+type Unit struct {
+	Symbol string
+	Scale  float64
+}
+
+var (
+	UnitNone           Unit // zero value, for attributes without a unit
+	UnitCentiCelsius   = Unit{"°C", 0.01}
+	UnitDeciCelsius    = Unit{"°C", 0.1}
+	UnitPercent        = Unit{"%", 1}
+	UnitMinutes        = Unit{"min", 1}
+	UnitSeconds        = Unit{"s", 1}
+	UnitQuarterSeconds = Unit{"s", 0.25}
+)
+
+// Convert returns the physical value of a raw attribute value (as returned by
+// ParseValue). The second return value is false if the unit is UnitNone or the
+// value is invalid.
+func (unit Unit) Convert(value interface{}) (float64, bool) {
+	if unit.Scale == 0 || value == nil {
+		return 0, false
+	}
+	raw, ok := toFloat64(value)
+	if !ok {
+		return 0, false
+	}
+	return raw * unit.Scale, true
+}
+
+var attributeDefinitions = make(map[ClusterID]map[AttributeID]AttributeDefinition)
+
+func registerAttributes(clusterID ClusterID, definitions ...AttributeDefinition) {
+	attributes, ok := attributeDefinitions[clusterID]
+	if !ok {
+		attributes = make(map[AttributeID]AttributeDefinition)
+		attributeDefinitions[clusterID] = attributes
+	}
+	for _, definition := range definitions {
+		if _, ok := attributes[definition.ID]; ok {
+			panic(fmt.Sprintf("attribute %v/%v already registered", clusterID, definition.ID))
+		}
+		attributes[definition.ID] = definition
+	}
+}
+
+var attributeUnits = make(map[ClusterID]map[AttributeID]Unit)
+
+// registerUnits registers the units of the numeric attributes of a cluster.
+// Attributes without a unit are omitted.
+func registerUnits(clusterID ClusterID, units map[AttributeID]Unit) {
+	registered, ok := attributeUnits[clusterID]
+	if !ok {
+		registered = make(map[AttributeID]Unit)
+		attributeUnits[clusterID] = registered
+	}
+	for attributeID, unit := range units {
+		if _, ok := attributeDefinitions[clusterID][attributeID]; !ok {
+			panic(fmt.Sprintf("unit for unknown attribute %v/%v", clusterID, attributeID))
+		}
+		registered[attributeID] = unit
+	}
+}
+
+// LookupUnit returns the unit of an attribute of the given cluster. UnitNone is
+// returned if the attribute has no unit or is not known.
+func LookupUnit(clusterID ClusterID, attributeID AttributeID) Unit {
+	return attributeUnits[clusterID][attributeID]
+}
+
+// LookupAttribute returns the definition of an attribute of the given cluster.
+// The second return value is false if the attribute is not known.
+func LookupAttribute(clusterID ClusterID, attributeID AttributeID) (AttributeDefinition, bool) {
+	definition, ok := attributeDefinitions[clusterID][attributeID]
+	return definition, ok
+}
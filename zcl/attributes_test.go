@@ -0,0 +1,53 @@
+package zcl
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAttributeConvert(t *testing.T) {
+	// LocalTemperature = 21.5 °C, PIHeatingDemand = 40 %, LocalTemperatureCalibration = -1.5 °C, OutdoorTemperature invalid.
+	data := []byte{0x00, 0x00, 0x29, 0x66, 0x08, 0x08, 0x00, 0x20, 0x28, 0x10, 0x00, 0x28, 0xf1, 0x01, 0x00, 0x29, 0x00, 0x80}
+	command, err := ParseReportAttributesCommand(data)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	expected := []struct {
+		value  float64
+		symbol string
+		ok     bool
+	}{
+		{21.5, "°C", true},
+		{40, "%", true},
+		{-1.5, "°C", true},
+		{0, "°C", false},
+	}
+
+	if len(command.Reports) != len(expected) {
+		t.Fatalf("wrong number of reports: %d", len(command.Reports))
+	}
+
+	for i, report := range command.Reports {
+		definition, ok := LookupAttribute(ClusterHVACThermostat, report.AttributeID)
+		if !ok {
+			t.Fatalf("unknown attribute: %v", report.AttributeID)
+		}
+		unit := LookupUnit(ClusterHVACThermostat, report.AttributeID)
+		value, ok := unit.Convert(report.Value)
+		if ok != expected[i].ok || math.Abs(value-expected[i].value) > 1e-9 || unit.Symbol != expected[i].symbol {
+			t.Errorf("wrong value for %s: expected %v %s (%v), actual %v %s (%v)", definition.Name,
+				expected[i].value, expected[i].symbol, expected[i].ok, value, unit.Symbol, ok)
+		}
+	}
+}
+
+func TestAttributeConvertWindowCovering(t *testing.T) {
+	unit := LookupUnit(ClusterClosuresWindowCovering, AttributeWindowCoveringCurrentPositionLiftPercentage)
+	if value, ok := unit.Convert(uint8(30)); !ok || value != 30 {
+		t.Errorf("wrong value: %v (%v)", value, ok)
+	}
+	if _, ok := unit.Convert(nil); ok {
+		t.Error("invalid value converted")
+	}
+}
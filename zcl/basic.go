@@ -0,0 +1,97 @@
+package zcl
+
+import "fmt"
+
+// Attributes of the Basic cluster.
+const (
+	AttributeBasicZCLVersion          AttributeID = 0x0000
+	AttributeBasicApplicationVersion  AttributeID = 0x0001
+	AttributeBasicStackVersion        AttributeID = 0x0002
+	AttributeBasicHWVersion           AttributeID = 0x0003
+	AttributeBasicManufacturerName    AttributeID = 0x0004
+	AttributeBasicModelIdentifier     AttributeID = 0x0005
+	AttributeBasicDateCode            AttributeID = 0x0006
+	AttributeBasicPowerSource         AttributeID = 0x0007
+	AttributeBasicLocationDescription AttributeID = 0x0010
+	AttributeBasicPhysicalEnvironment AttributeID = 0x0011
+	AttributeBasicDeviceEnabled       AttributeID = 0x0012
+	AttributeBasicAlarmMask           AttributeID = 0x0013
+	AttributeBasicDisableLocalConfig  AttributeID = 0x0014
+	AttributeBasicSWBuildID           AttributeID = 0x4000
+)
+
+func init() {
+	registerAttributes(ClusterGeneralBasic,
+		AttributeDefinition{AttributeBasicZCLVersion, "ZCLVersion", DataTypeUint8},
+		AttributeDefinition{AttributeBasicApplicationVersion, "ApplicationVersion", DataTypeUint8},
+		AttributeDefinition{AttributeBasicStackVersion, "StackVersion", DataTypeUint8},
+		AttributeDefinition{AttributeBasicHWVersion, "HWVersion", DataTypeUint8},
+		AttributeDefinition{AttributeBasicManufacturerName, "ManufacturerName", DataTypeCharacterString},
+		AttributeDefinition{AttributeBasicModelIdentifier, "ModelIdentifier", DataTypeCharacterString},
+		AttributeDefinition{AttributeBasicDateCode, "DateCode", DataTypeCharacterString},
+		AttributeDefinition{AttributeBasicPowerSource, "PowerSource", DataTypeEnum8},
+		AttributeDefinition{AttributeBasicLocationDescription, "LocationDescription", DataTypeCharacterString},
+		AttributeDefinition{AttributeBasicPhysicalEnvironment, "PhysicalEnvironment", DataTypeEnum8},
+		AttributeDefinition{AttributeBasicDeviceEnabled, "DeviceEnabled", DataTypeBool},
+		AttributeDefinition{AttributeBasicAlarmMask, "AlarmMask", DataTypeBitmap8},
+		AttributeDefinition{AttributeBasicDisableLocalConfig, "DisableLocalConfig", DataTypeBitmap8},
+		AttributeDefinition{AttributeBasicSWBuildID, "SWBuildID", DataTypeCharacterString},
+	)
+}
+
+// PowerSource is the value of the PowerSource attribute of the Basic cluster.
+// The lower bits contain the primary power source. PowerSourceBatteryBackup is
+// set if the device has a secondary battery.
+type PowerSource uint8
+
+const (
+	PowerSourceUnknown                PowerSource = 0x00
+	PowerSourceMainsSinglePhase       PowerSource = 0x01
+	PowerSourceMainsThreePhase        PowerSource = 0x02
+	PowerSourceBattery                PowerSource = 0x03
+	PowerSourceDCSource               PowerSource = 0x04
+	PowerSourceEmergencyMainsConstant PowerSource = 0x05
+	PowerSourceEmergencyMainsTransfer PowerSource = 0x06
+
+	PowerSourceBatteryBackup PowerSource = 0x80
+)
+
+// Primary returns the primary power source without the battery backup flag.
+func (s PowerSource) Primary() PowerSource {
+	return s &^ PowerSourceBatteryBackup
+}
+
+// MainsPowered returns true if the primary power source is mains power.
+func (s PowerSource) MainsPowered() bool {
+	switch s.Primary() {
+	case PowerSourceMainsSinglePhase, PowerSourceMainsThreePhase, PowerSourceEmergencyMainsConstant, PowerSourceEmergencyMainsTransfer:
+		return true
+	}
+	return false
+}
+
+func (s PowerSource) String() string {
+	var name string
+	switch s.Primary() {
+	case PowerSourceUnknown:
+		name = "Unknown"
+	case PowerSourceMainsSinglePhase:
+		name = "MainsSinglePhase"
+	case PowerSourceMainsThreePhase:
+		name = "MainsThreePhase"
+	case PowerSourceBattery:
+		name = "Battery"
+	case PowerSourceDCSource:
+		name = "DCSource"
+	case PowerSourceEmergencyMainsConstant:
+		name = "EmergencyMainsConstant"
+	case PowerSourceEmergencyMainsTransfer:
+		name = "EmergencyMainsTransfer"
+	default:
+		name = fmt.Sprintf("PowerSource(0x%02x)", uint8(s.Primary()))
+	}
+	if s&PowerSourceBatteryBackup != 0 {
+		name += "+BatteryBackup"
+	}
+	return name
+}
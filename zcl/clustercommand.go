@@ -0,0 +1,185 @@
+package zcl
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/GreenLightning/zigbee-conductor/pkg/scf"
+)
+
+var ErrUnknownCommand = errors.New("unknown command")
+
+// A ClusterCommand is a cluster-specific command, which is transmitted in a
+// frame of type FrameTypeLocal.
+//
+// The command ID of a cluster-specific command is only unique in combination
+// with the cluster ID and the direction of the frame.
+type ClusterCommand interface {
+	ClusterID() ClusterID
+	CommandID() CommandID
+	DirectionServerToClient() bool
+	ParsePayload(data []byte) error
+	SerializePayload() []byte
+}
+
+type clusterCommandKey struct {
+	ClusterID               ClusterID
+	DirectionServerToClient bool
+	CommandID               CommandID
+}
+
+var clusterCommandTypes = make(map[clusterCommandKey]reflect.Type)
+
+func registerClusterCommand(prototype ClusterCommand) {
+	commandType := reflect.TypeOf(prototype)
+	if commandType.Kind() != reflect.Ptr || commandType.Elem().Kind() != reflect.Struct {
+		panic("command must be a pointer to a struct")
+	}
+
+	key := clusterCommandKey{
+		ClusterID:               prototype.ClusterID(),
+		DirectionServerToClient: prototype.DirectionServerToClient(),
+		CommandID:               prototype.CommandID(),
+	}
+
+	if old, ok := clusterCommandTypes[key]; ok {
+		panic(fmt.Sprintf("cluster command %v already registered: old=%s, new=%s", key, old.Name(), commandType.Elem().Name()))
+	}
+	clusterCommandTypes[key] = commandType.Elem()
+}
+
+// clusterCommandResponses maps commands which are answered with a specific
+// response command to that response command.
+var clusterCommandResponses = make(map[clusterCommandKey]clusterCommandKey)
+
+func registerClusterCommandResponse(request, response ClusterCommand) {
+	key := clusterCommandKey{
+		ClusterID:               request.ClusterID(),
+		DirectionServerToClient: request.DirectionServerToClient(),
+		CommandID:               request.CommandID(),
+	}
+	clusterCommandResponses[key] = clusterCommandKey{
+		ClusterID:               response.ClusterID(),
+		DirectionServerToClient: response.DirectionServerToClient(),
+		CommandID:               response.CommandID(),
+	}
+}
+
+// HasResponseCommand reports whether the frame contains a cluster-specific
+// command which is answered with a specific response command (e.g. a Query
+// Next Image Request, which is answered with a Query Next Image Response)
+// instead of a Default Response.
+func HasResponseCommand(clusterID ClusterID, frame Frame) bool {
+	if frame.Type != FrameTypeLocal || frame.ManufacturerSpecific {
+		return false
+	}
+	key := clusterCommandKey{
+		ClusterID:               clusterID,
+		DirectionServerToClient: frame.DirectionServerToClient,
+		CommandID:               frame.CommandID,
+	}
+	_, ok := clusterCommandResponses[key]
+	return ok
+}
+
+// NewClusterCommandFrame returns a frame containing the serialized command.
+// The caller is responsible for setting the transaction sequence number.
+func NewClusterCommandFrame(command ClusterCommand) Frame {
+	return Frame{
+		FrameHeader: FrameHeader{
+			Type:                    FrameTypeLocal,
+			DirectionServerToClient: command.DirectionServerToClient(),
+			CommandID:               command.CommandID(),
+		},
+		Data: command.SerializePayload(),
+	}
+}
+
+// ParseClusterCommand parses the payload of a frame of type FrameTypeLocal
+// into the corresponding cluster-specific command.
+// If the command is not known, ErrUnknownCommand is returned.
+func ParseClusterCommand(clusterID ClusterID, frame Frame) (ClusterCommand, error) {
+	if frame.Type != FrameTypeLocal || frame.ManufacturerSpecific {
+		return nil, ErrUnknownCommand
+	}
+
+	key := clusterCommandKey{
+		ClusterID:               clusterID,
+		DirectionServerToClient: frame.DirectionServerToClient,
+		CommandID:               frame.CommandID,
+	}
+
+	commandType, ok := clusterCommandTypes[key]
+	if !ok {
+		return nil, ErrUnknownCommand
+	}
+
+	command := reflect.New(commandType).Interface().(ClusterCommand)
+	err := command.ParsePayload(frame.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	return command, nil
+}
+
+// parsePayload parses fixed-size payloads using package scf.
+// Trailing data is ignored to stay compatible with future revisions of the
+// specification, which may add optional fields at the end of a command.
+func parsePayload(command interface{}, data []byte) error {
+	_, err := scf.Parse(command, data)
+	if err != nil {
+		return ErrNotEnoughData
+	}
+	return nil
+}
+
+func appendUint16(data []byte, value uint16) []byte {
+	return append(data, byte(value), byte(value>>8))
+}
+
+func appendUint32(data []byte, value uint32) []byte {
+	return append(data, byte(value), byte(value>>8), byte(value>>16), byte(value>>24))
+}
+
+func appendUint64(data []byte, value uint64) []byte {
+	data = appendUint32(data, uint32(value))
+	return appendUint32(data, uint32(value>>32))
+}
+
+func appendCharacterString(data []byte, value string) []byte {
+	// The length 0xff is reserved to indicate an invalid string.
+	if len(value) > 0xfe {
+		value = value[:0xfe]
+	}
+	data = append(data, byte(len(value)))
+	return append(data, value...)
+}
+
+func parseCharacterString(data []byte) (string, []byte, error) {
+	value, data, err := ParseValue(DataTypeCharacterString, data)
+	if err != nil {
+		return "", data, err
+	}
+	str, _ := value.(string) // nil for invalid strings
+	return str, data, nil
+}
+
+func appendOctetString(data []byte, value []byte) []byte {
+	// The length 0xff is reserved to indicate an invalid string.
+	if len(value) > 0xfe {
+		value = value[:0xfe]
+	}
+	data = append(data, byte(len(value)))
+	return append(data, value...)
+}
+
+func parseOctetString(data []byte) ([]byte, []byte, error) {
+	value, data, err := ParseValue(DataTypeOctetString, data)
+	if err != nil {
+		return nil, data, err
+	}
+	bytes, _ := value.([]byte) // nil for invalid strings
+	return bytes, data, nil
+}
@@ -0,0 +1,205 @@
+package zcl
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// Note: For testing the transaction sequence number is always 0xAB.
+
+type ClusterCommandTestCase struct {
+	ClusterID ClusterID
+	Data      string
+	Command   ClusterCommand
+}
+
+var clusterCommandTests = []ClusterCommandTestCase{
+	{ClusterGeneralOnOff, "01AB00", &OffCommand{}},
+	{ClusterGeneralOnOff, "01AB01", &OnCommand{}},
+	{ClusterGeneralOnOff, "01AB02", &ToggleCommand{}},
+	{ClusterGeneralOnOff, "01AB400001", &OffWithEffectCommand{EffectIdentifier: EffectDelayedAllOff, EffectVariant: 1}},
+	{ClusterGeneralOnOff, "01AB41", &OnWithRecallGlobalSceneCommand{}},
+	{ClusterGeneralOnOff, "01AB4201E8030A00", &OnWithTimedOffCommand{OnOffControl: OnOffControlAcceptOnlyWhenOn, OnTime: 1000, OffWaitTime: 10}},
+
+	{ClusterGeneralLevelControl, "01AB00801400", &MoveToLevelCommand{Level: 0x80, TransitionTime: 20}},
+	{ClusterGeneralLevelControl, "01AB010132", &MoveCommand{MoveMode: MoveModeDown, Rate: 50}},
+	{ClusterGeneralLevelControl, "01AB02001E0500", &StepCommand{StepMode: MoveModeUp, StepSize: 30, TransitionTime: 5}},
+	{ClusterGeneralLevelControl, "01AB03", &StopCommand{}},
+	{ClusterGeneralLevelControl, "01AB04FE0000", &MoveToLevelWithOnOffCommand{Level: 0xFE}},
+	{ClusterGeneralLevelControl, "01AB050032", &MoveWithOnOffCommand{MoveMode: MoveModeUp, Rate: 50}},
+	{ClusterGeneralLevelControl, "01AB06011E0500", &StepWithOnOffCommand{StepMode: MoveModeDown, StepSize: 30, TransitionTime: 5}},
+	{ClusterGeneralLevelControl, "01AB07", &StopWithOnOffCommand{}},
+
+	{ClusterGeneralIdentify, "01AB003C00", &IdentifyCommand{IdentifyTime: 60}},
+	{ClusterGeneralIdentify, "01AB01", &IdentifyQueryCommand{}},
+	{ClusterGeneralIdentify, "01AB400100", &TriggerEffectCommand{EffectIdentifier: EffectBreathe}},
+	{ClusterGeneralIdentify, "09AB000A00", &IdentifyQueryResponseCommand{Timeout: 10}},
+
+	{ClusterGeneralPollControl, "01AB00017800", &CheckInResponseCommand{StartFastPolling: true, FastPollTimeout: 120}},
+	{ClusterGeneralPollControl, "01AB01", &FastPollStopCommand{}},
+	{ClusterGeneralPollControl, "01AB0260090000", &SetLongPollIntervalCommand{NewLongPollInterval: 2400}},
+	{ClusterGeneralPollControl, "09AB00", &CheckInCommand{}},
+
+	{ClusterGeneralOTAUpgrade, "01AB01007C11012178563412", &QueryNextImageRequestCommand{ManufacturerCode: 0x117C, ImageType: 0x2101, CurrentFileVersion: 0x12345678}},
+	{ClusterGeneralOTAUpgrade, "01AB01017C110121785634120100", &QueryNextImageRequestCommand{ManufacturerCode: 0x117C, ImageType: 0x2101, CurrentFileVersion: 0x12345678, HardwareVersion: uint16Ptr(1)}},
+	{ClusterGeneralOTAUpgrade, "01AB03027C1101217856341240000000406400", &ImageBlockRequestCommand{ManufacturerCode: 0x117C, ImageType: 0x2101, FileVersion: 0x12345678, FileOffset: 0x40, MaximumDataSize: 0x40, MinimumBlockPeriod: uint16Ptr(100)}},
+	{ClusterGeneralOTAUpgrade, "01AB06007C11012178563412", &UpgradeEndRequestCommand{Status: StatusSuccess, ManufacturerCode: 0x117C, ImageType: 0x2101, FileVersion: 0x12345678}},
+	{ClusterGeneralOTAUpgrade, "09AB0003647C11012178563412", &ImageNotifyCommand{PayloadType: ImageNotifyPayloadFileVersion, QueryJitter: 100, ManufacturerCode: 0x117C, ImageType: 0x2101, NewFileVersion: 0x12345678}},
+	{ClusterGeneralOTAUpgrade, "09AB02007C1101217856341200100000", &QueryNextImageResponseCommand{Status: StatusSuccess, ManufacturerCode: 0x117C, ImageType: 0x2101, FileVersion: 0x12345678, ImageSize: 0x1000}},
+	{ClusterGeneralOTAUpgrade, "09AB0298", &QueryNextImageResponseCommand{Status: StatusNoImageAvailable}},
+	{ClusterGeneralOTAUpgrade, "09AB05007C110121785634124000000003010203", &ImageBlockResponseCommand{Status: StatusSuccess, ManufacturerCode: 0x117C, ImageType: 0x2101, FileVersion: 0x12345678, FileOffset: 0x40, ImageData: []byte{1, 2, 3}}},
+	{ClusterGeneralOTAUpgrade, "09AB059700000000010000006400", &ImageBlockResponseCommand{Status: StatusWaitForData, RequestTime: 1, MinimumBlockPeriod: 100}},
+	{ClusterGeneralOTAUpgrade, "09AB077C110121785634120000000000000000", &UpgradeEndResponseCommand{ManufacturerCode: 0x117C, ImageType: 0x2101, FileVersion: 0x12345678}},
+
+	{ClusterGeneralGroups, "01AB00341204486F6C6C", &AddGroupCommand{GroupID: 0x1234, GroupName: "Holl"}},
+	{ClusterGeneralGroups, "01AB010100", &ViewGroupCommand{GroupID: 1}},
+	{ClusterGeneralGroups, "01AB0200", &GetGroupMembershipCommand{GroupList: []uint16{}}},
+	{ClusterGeneralGroups, "01AB030100", &RemoveGroupCommand{GroupID: 1}},
+	{ClusterGeneralGroups, "01AB04", &RemoveAllGroupsCommand{}},
+	{ClusterGeneralGroups, "01AB05010000", &AddGroupIfIdentifyingCommand{GroupID: 1}},
+	{ClusterGeneralGroups, "09AB00000100", &AddGroupResponseCommand{Status: StatusSuccess, GroupID: 1}},
+	{ClusterGeneralGroups, "09AB010001000141", &ViewGroupResponseCommand{Status: StatusSuccess, GroupID: 1, GroupName: "A"}},
+	{ClusterGeneralGroups, "09AB018B0100", &ViewGroupResponseCommand{Status: StatusNotFound, GroupID: 1}},
+	{ClusterGeneralGroups, "09AB02FE0201003412", &GetGroupMembershipResponseCommand{Capacity: 0xFE, GroupList: []uint16{0x0001, 0x1234}}},
+	{ClusterGeneralGroups, "09AB038B0100", &RemoveGroupResponseCommand{Status: StatusNotFound, GroupID: 1}},
+
+	{ClusterGeneralScenes, "01AB0001000203000006000101", &AddSceneCommand{GroupID: 1, SceneID: 2, TransitionTime: 3, ExtensionFieldSets: []ExtensionFieldSet{{ClusterGeneralOnOff, []byte{0x01}}}}},
+	{ClusterGeneralScenes, "01AB01010002", &ViewSceneCommand{GroupID: 1, SceneID: 2}},
+	{ClusterGeneralScenes, "01AB05010002", &RecallSceneCommand{GroupID: 1, SceneID: 2}},
+	{ClusterGeneralScenes, "01AB060100", &GetSceneMembershipCommand{GroupID: 1}},
+	{ClusterGeneralScenes, "01AB400100020A000353756E", &EnhancedAddSceneCommand{GroupID: 1, SceneID: 2, TransitionTime: 10, SceneName: "Sun"}},
+	{ClusterGeneralScenes, "09AB0000010002", &AddSceneResponseCommand{Status: StatusSuccess, GroupID: 1, SceneID: 2}},
+	{ClusterGeneralScenes, "09AB01000100020300000600010108000101", &ViewSceneResponseCommand{Status: StatusSuccess, GroupID: 1, SceneID: 2, TransitionTime: 3, ExtensionFieldSets: []ExtensionFieldSet{{ClusterGeneralOnOff, []byte{0x01}}, {ClusterGeneralLevelControl, []byte{0x01}}}}},
+	{ClusterGeneralScenes, "09AB018B010002", &ViewSceneResponseCommand{Status: StatusNotFound, GroupID: 1, SceneID: 2}},
+	{ClusterGeneralScenes, "09AB06000A0100020102", &GetSceneMembershipResponseCommand{Status: StatusSuccess, Capacity: 10, GroupID: 1, SceneList: []uint8{1, 2}}},
+	{ClusterGeneralScenes, "09AB41000100020A0000", &EnhancedViewSceneResponseCommand{Status: StatusSuccess, GroupID: 1, SceneID: 2, TransitionTime: 10}},
+
+	{ClusterSecurityIASZone, "09AB00090000010000", &ZoneStatusChangeNotificationCommand{ZoneStatus: ZoneStatusAlarm1 | ZoneStatusBattery, ZoneID: 1}},
+	{ClusterSecurityIASZone, "09AB0115005F11", &ZoneEnrollRequestCommand{ZoneType: ZoneTypeContactSwitch, ManufacturerCode: 0x115F}},
+	{ClusterSecurityIASZone, "01AB000005", &ZoneEnrollResponseCommand{EnrollResponseCode: EnrollResponseCodeSuccess, ZoneID: 5}},
+	{ClusterSecurityIASZone, "01AB01", &InitiateNormalOperationModeCommand{}},
+	{ClusterSecurityIASWD, "01AB00161E003201", &StartWarningCommand{WarningMode: WarningModeBurglar, Strobe: true, SirenLevel: SirenLevelHigh, WarningDuration: 30, StrobeDutyCycle: 50, StrobeLevel: SirenLevelMedium}},
+	{ClusterSecurityIASWD, "01AB0118", &SquawkCommand{SquawkMode: SquawkModeDisarmed, Strobe: true, SquawkLevel: SirenLevelLow}},
+
+	{ClusterClosuresDoorLock, "01AB00", &LockDoorCommand{}},
+	{ClusterClosuresDoorLock, "01AB010431323334", &UnlockDoorCommand{PINCode: []byte("1234")}},
+	{ClusterClosuresDoorLock, "01AB031E00", &UnlockWithTimeoutCommand{Timeout: 30}},
+	{ClusterClosuresDoorLock, "01AB05010001000431323334", &SetPINCodeCommand{UserID: 1, UserStatus: UserStatusOccupiedEnabled, UserType: UserTypeUnrestricted, PINCode: []byte("1234")}},
+	{ClusterClosuresDoorLock, "01AB070100", &ClearPINCodeCommand{UserID: 1}},
+	{ClusterClosuresDoorLock, "09AB0000", &LockDoorResponseCommand{Status: StatusSuccess}},
+	{ClusterClosuresDoorLock, "09AB0502", &SetPINCodeResponseCommand{Status: SetCodeStatusMemoryFull}},
+	{ClusterClosuresDoorLock, "09AB06010001000431323334", &GetPINCodeResponseCommand{UserID: 1, UserStatus: UserStatusOccupiedEnabled, UserType: UserTypeUnrestricted, PINCode: []byte("1234")}},
+	{ClusterClosuresDoorLock, "09AB200002030004313233347856341200", &OperationEventNotificationCommand{EventSource: EventSourceKeypad, EventCode: OperationEventUnlock, UserID: 3, PINCode: []byte("1234"), LocalTime: 0x12345678}},
+	{ClusterClosuresWindowCovering, "01AB00", &UpOpenCommand{}},
+	{ClusterClosuresWindowCovering, "01AB02", &WindowCoveringStopCommand{}},
+	{ClusterClosuresWindowCovering, "01AB0532", &GoToLiftPercentageCommand{LiftPercentage: 50}},
+
+	{ClusterHVACThermostat, "01AB0000EC", &SetpointRaiseLowerCommand{Mode: SetpointModeHeat, Amount: -20}},
+	{ClusterHVACThermostat, "01AB01023E01A401D00738043408", &SetWeeklyScheduleCommand{DaysOfWeek: DayWorkdays, Mode: ScheduleModeHeat, Transitions: []ScheduleTransition{{TransitionTime: 420, HeatSetpoint: 2000}, {TransitionTime: 1080, HeatSetpoint: 2100}}}},
+	{ClusterHVACThermostat, "01AB020103", &GetWeeklyScheduleCommand{DaysToReturn: DaySunday, ModeToReturn: ScheduleModeHeat | ScheduleModeCool}},
+	{ClusterHVACThermostat, "01AB03", &ClearWeeklyScheduleCommand{}},
+	{ClusterHVACThermostat, "09AB0001410300003408C409", &GetWeeklyScheduleResponseCommand{DaysOfWeek: DaySunday | DaySaturday, Mode: ScheduleModeHeat | ScheduleModeCool, Transitions: []ScheduleTransition{{TransitionTime: 0, HeatSetpoint: 2100, CoolSetpoint: 2500}}}},
+
+	{ClusterLightingColorControl, "01AB007F000A00", &MoveToHueCommand{Hue: 127, Direction: HueDirectionShortestDistance, TransitionTime: 10}},
+	{ClusterLightingColorControl, "01AB03FE0000", &MoveToSaturationCommand{Saturation: 254}},
+	{ClusterLightingColorControl, "01AB067FFE0A00", &MoveToHueAndSaturationCommand{Hue: 127, Saturation: 254, TransitionTime: 10}},
+	{ClusterLightingColorControl, "01AB07485054540A00", &MoveToColorCommand{ColorX: 0x5048, ColorY: 0x5454, TransitionTime: 10}},
+	{ClusterLightingColorControl, "01AB0A72010000", &MoveToColorTemperatureCommand{ColorTemperatureMireds: 370}},
+	{ClusterLightingColorControl, "01AB400040020A00", &EnhancedMoveToHueCommand{EnhancedHue: 0x4000, Direction: HueDirectionUp, TransitionTime: 10}},
+	{ClusterLightingColorControl, "01AB440F020110000000", &ColorLoopSetCommand{UpdateFlags: 0x0F, Action: ColorLoopActionActivateFromCurrentHue, Direction: ColorLoopDirectionIncrement, Time: 16}},
+	{ClusterLightingColorControl, "01AB47", &StopMoveStepCommand{}},
+}
+
+func uint16Ptr(value uint16) *uint16 {
+	return &value
+}
+
+func (tc ClusterCommandTestCase) Name() string {
+	return fmt.Sprintf("%v/%T", tc.ClusterID, tc.Command)
+}
+
+func TestParseClusterCommand(t *testing.T) {
+	for _, tc := range clusterCommandTests {
+		t.Run(tc.Name(), func(t *testing.T) {
+			data, _ := hex.DecodeString(tc.Data)
+			frame, err := ParseFrame(data)
+			if err != nil {
+				t.Fatal("unexpected err:", err)
+			}
+			if frame.TransSeqNumber != 0xAB {
+				t.Errorf("wrong TSN: expected 0x%02x, actual 0x%02x", 0xAB, frame.TransSeqNumber)
+			}
+			cmd, err := ParseClusterCommand(tc.ClusterID, frame)
+			if err != nil {
+				t.Fatal("unexpected err:", err)
+			}
+			if !reflect.DeepEqual(cmd, tc.Command) {
+				t.Errorf("wrong command:\n\texpected %T%+v\n\tactual   %T%+v", tc.Command, tc.Command, cmd, cmd)
+			}
+		})
+	}
+}
+
+func TestSerializeClusterCommand(t *testing.T) {
+	for _, tc := range clusterCommandTests {
+		t.Run(tc.Name(), func(t *testing.T) {
+			expected, _ := hex.DecodeString(tc.Data)
+			if clusterID := tc.Command.ClusterID(); clusterID != tc.ClusterID {
+				t.Errorf("wrong cluster ID: expected %v, actual %v", tc.ClusterID, clusterID)
+			}
+			frame := NewClusterCommandFrame(tc.Command)
+			frame.TransSeqNumber = 0xAB
+			actual := SerializeFrame(frame)
+			if !bytes.Equal(actual, expected) {
+				t.Errorf("wrong data:\n\texpected %x\n\tactual   %x", expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseClusterCommandUnknown(t *testing.T) {
+	frame := Frame{FrameHeader: FrameHeader{Type: FrameTypeLocal, CommandID: 0x99}}
+	_, err := ParseClusterCommand(ClusterGeneralOnOff, frame)
+	if err != ErrUnknownCommand {
+		t.Fatal("expected ErrUnknownCommand:", err)
+	}
+}
+
+func TestParseClusterCommandNotEnoughData(t *testing.T) {
+	frame := Frame{FrameHeader: FrameHeader{Type: FrameTypeLocal, CommandID: CommandLevelControlMoveToLevel}, Data: []byte{0x80, 0x01}}
+	_, err := ParseClusterCommand(ClusterGeneralLevelControl, frame)
+	if err != ErrNotEnoughData {
+		t.Fatal("expected ErrNotEnoughData:", err)
+	}
+}
+
+func TestHasResponseCommand(t *testing.T) {
+	tests := []struct {
+		clusterID ClusterID
+		command   ClusterCommand
+		expected  bool
+	}{
+		{ClusterGeneralOTAUpgrade, &QueryNextImageRequestCommand{}, true},
+		{ClusterGeneralOTAUpgrade, &ImagePageRequestCommand{}, true},
+		{ClusterSecurityIASZone, &ZoneEnrollRequestCommand{}, true},
+		{ClusterHVACThermostat, &GetWeeklyScheduleCommand{}, true},
+		{ClusterSecurityIASZone, &ZoneStatusChangeNotificationCommand{}, false},
+		{ClusterGeneralOnOff, &ToggleCommand{}, false},
+	}
+	for _, test := range tests {
+		frame := NewClusterCommandFrame(test.command)
+		if actual := HasResponseCommand(test.clusterID, frame); actual != test.expected {
+			t.Errorf("%T: got %v, expected %v", test.command, actual, test.expected)
+		}
+	}
+}
+
+func TestZoneStatusString(t *testing.T) {
+	status := ZoneStatusAlarm1 | ZoneStatusTamper | ZoneStatus(1<<12)
+	if str := status.String(); str != "[Alarm1 Tamper 0x1000]" {
+		t.Errorf("wrong string: %s", str)
+	}
+}
@@ -19,6 +19,7 @@ const (
 	ClusterGeneralLocation            ClusterID = 0x000b
 	ClusterGeneralDiagnostics         ClusterID = 0x0b05
 	ClusterGeneralPollControl         ClusterID = 0x0020
+	ClusterGeneralOTAUpgrade          ClusterID = 0x0019
 	ClusterGeneralPowerProfile        ClusterID = 0x001a
 	ClusterGeneralMeterIdentification ClusterID = 0x0b01
 
@@ -40,6 +41,24 @@ const (
 	ClusterMSRelativeHumidity              ClusterID = 0x0405
 	ClusterMSOccupancySensing              ClusterID = 0x0406
 	ClusterMSElectricalMeasurement         ClusterID = 0x0b04
+
+	ClusterClosuresShadeConfig    ClusterID = 0x0100
+	ClusterClosuresDoorLock       ClusterID = 0x0101
+	ClusterClosuresWindowCovering ClusterID = 0x0102
+
+	ClusterHVACPumpConfigControl       ClusterID = 0x0200
+	ClusterHVACThermostat              ClusterID = 0x0201
+	ClusterHVACFanControl              ClusterID = 0x0202
+	ClusterHVACDehumidificationControl ClusterID = 0x0203
+	ClusterHVACThermostatUIConfig      ClusterID = 0x0204
+
+	ClusterLightingColorControl ClusterID = 0x0300
+
+	ClusterSecurityIASZone ClusterID = 0x0500
+	ClusterSecurityIASACE  ClusterID = 0x0501
+	ClusterSecurityIASWD   ClusterID = 0x0502
+
+	ClusterSEMetering ClusterID = 0x0702
 )
 
 func (id ClusterID) String() string {
@@ -72,6 +91,8 @@ func (id ClusterID) String() string {
 		return "Diagnostics"
 	case ClusterGeneralPollControl:
 		return "PollControl"
+	case ClusterGeneralOTAUpgrade:
+		return "OTAUpgrade"
 	case ClusterGeneralPowerProfile:
 		return "PowerProfile"
 	case ClusterGeneralMeterIdentification:
@@ -113,6 +134,37 @@ func (id ClusterID) String() string {
 	case ClusterMSElectricalMeasurement:
 		return "ElectricalMeasurement"
 
+	case ClusterClosuresShadeConfig:
+		return "ShadeConfig"
+	case ClusterClosuresDoorLock:
+		return "DoorLock"
+	case ClusterClosuresWindowCovering:
+		return "WindowCovering"
+
+	case ClusterHVACPumpConfigControl:
+		return "PumpConfigControl"
+	case ClusterHVACThermostat:
+		return "Thermostat"
+	case ClusterHVACFanControl:
+		return "FanControl"
+	case ClusterHVACDehumidificationControl:
+		return "DehumidificationControl"
+	case ClusterHVACThermostatUIConfig:
+		return "ThermostatUIConfig"
+
+	case ClusterLightingColorControl:
+		return "ColorControl"
+
+	case ClusterSecurityIASZone:
+		return "IASZone"
+	case ClusterSecurityIASACE:
+		return "IASACE"
+	case ClusterSecurityIASWD:
+		return "IASWD"
+
+	case ClusterSEMetering:
+		return "Metering"
+
 	default:
 		return fmt.Sprintf("ClusterID(0x%04x)", uint16(id))
 	}
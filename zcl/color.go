@@ -0,0 +1,118 @@
+package zcl
+
+import "math"
+
+// Helpers for converting common color representations into the ones used by
+// the Color Control cluster.
+
+// RGBToHSV converts an sRGB color into hue (in degrees from 0 to 360),
+// saturation and value (both from 0 to 1).
+func RGBToHSV(r, g, b uint8) (h, s, v float64) {
+	rf, gf, bf := float64(r)/255, float64(g)/255, float64(b)/255
+
+	max := math.Max(rf, math.Max(gf, bf))
+	min := math.Min(rf, math.Min(gf, bf))
+	delta := max - min
+
+	v = max
+	if max > 0 {
+		s = delta / max
+	}
+
+	if delta == 0 {
+		return 0, s, v
+	}
+
+	switch max {
+	case rf:
+		h = 60 * math.Mod((gf-bf)/delta, 6)
+	case gf:
+		h = 60 * ((bf-rf)/delta + 2)
+	default:
+		h = 60 * ((rf-gf)/delta + 4)
+	}
+	if h < 0 {
+		h += 360
+	}
+
+	return h, s, v
+}
+
+// HSVToHueSaturation converts hue (in degrees) and saturation (from 0 to 1)
+// into the values used by the CurrentHue and CurrentSaturation attributes
+// and the Move To Hue / Saturation commands, which range from 0 to 254.
+func HSVToHueSaturation(h, s float64) (hue, saturation uint8) {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	hue = uint8(math.Round(h / 360 * 254))
+	saturation = uint8(math.Round(clamp(s, 0, 1) * 254))
+	return
+}
+
+// HSVToEnhancedHue converts hue (in degrees) into the value used by the
+// EnhancedCurrentHue attribute and the Enhanced Move To Hue command.
+func HSVToEnhancedHue(h float64) uint16 {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	// Hues just below 360 degrees round to 65536, which is the same as zero.
+	return uint16(uint32(math.Round(h/360*65536)) % 65536)
+}
+
+// RGBToXY converts an sRGB color into CIE 1931 chromaticity coordinates as
+// used by the CurrentX and CurrentY attributes and the Move To Color command.
+// The brightness of the color is lost and must be set using the Level Control cluster.
+func RGBToXY(r, g, b uint8) (x, y uint16) {
+	rl, gl, bl := linearize(r), linearize(g), linearize(b)
+
+	// Conversion matrix from linear sRGB to CIE XYZ using the D65 white point.
+	X := 0.4124*rl + 0.3576*gl + 0.1805*bl
+	Y := 0.2126*rl + 0.7152*gl + 0.0722*bl
+	Z := 0.0193*rl + 0.1192*gl + 0.9505*bl
+
+	sum := X + Y + Z
+	if sum == 0 {
+		// Use the D65 white point for black.
+		return scaleChromaticity(0.3127), scaleChromaticity(0.3290)
+	}
+
+	return scaleChromaticity(X / sum), scaleChromaticity(Y / sum)
+}
+
+// linearize removes the sRGB gamma correction.
+func linearize(value uint8) float64 {
+	v := float64(value) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func scaleChromaticity(value float64) uint16 {
+	return uint16(clamp(math.Round(value*65536), 0, 0xfeff))
+}
+
+// KelvinToMireds converts a color temperature in Kelvin into the reciprocal
+// color temperature used by the ColorTemperatureMireds attribute and the Move
+// To Color Temperature command.
+func KelvinToMireds(kelvin uint32) uint16 {
+	if kelvin == 0 {
+		return 0xfeff
+	}
+	return uint16(clamp(math.Round(1e6/float64(kelvin)), 1, 0xfeff))
+}
+
+// MiredsToKelvin converts a reciprocal color temperature into Kelvin.
+func MiredsToKelvin(mireds uint16) uint32 {
+	if mireds == 0 {
+		return 0
+	}
+	return uint32(math.Round(1e6 / float64(mireds)))
+}
+
+func clamp(value, min, max float64) float64 {
+	return math.Max(min, math.Min(max, value))
+}
@@ -0,0 +1,85 @@
+package zcl
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRGBToHSV(t *testing.T) {
+	type TestCase struct {
+		R, G, B uint8
+		H, S, V float64
+	}
+
+	testCases := []TestCase{
+		{0, 0, 0, 0, 0, 0},
+		{255, 255, 255, 0, 0, 1},
+		{255, 0, 0, 0, 1, 1},
+		{0, 255, 0, 120, 1, 1},
+		{0, 0, 255, 240, 1, 1},
+		{255, 0, 255, 300, 1, 1},
+		{128, 64, 64, 0, 0.5, 128.0 / 255},
+	}
+
+	for index, tc := range testCases {
+		h, s, v := RGBToHSV(tc.R, tc.G, tc.B)
+		if math.Abs(h-tc.H) > 1e-9 || math.Abs(s-tc.S) > 1e-9 || math.Abs(v-tc.V) > 1e-9 {
+			t.Errorf("(%d) wrong result: expected (%v, %v, %v), actual (%v, %v, %v)", index, tc.H, tc.S, tc.V, h, s, v)
+		}
+	}
+}
+
+func TestHSVToHueSaturation(t *testing.T) {
+	hue, saturation := HSVToHueSaturation(180, 0.5)
+	if hue != 127 || saturation != 127 {
+		t.Errorf("wrong result: expected (127, 127), actual (%d, %d)", hue, saturation)
+	}
+
+	hue, saturation = HSVToHueSaturation(-90, 2)
+	if hue != 191 || saturation != 254 {
+		t.Errorf("wrong result: expected (191, 254), actual (%d, %d)", hue, saturation)
+	}
+
+	if enhancedHue := HSVToEnhancedHue(90); enhancedHue != 0x4000 {
+		t.Errorf("wrong enhanced hue: expected 0x4000, actual 0x%04x", enhancedHue)
+	}
+	for _, h := range []float64{359.9999, -1e-20, 360} {
+		if enhancedHue := HSVToEnhancedHue(h); enhancedHue != 0 {
+			t.Errorf("wrong enhanced hue for %v: expected 0x0000, actual 0x%04x", h, enhancedHue)
+		}
+	}
+}
+
+func TestRGBToXY(t *testing.T) {
+	type TestCase struct {
+		R, G, B uint8
+		X, Y    float64
+	}
+
+	testCases := []TestCase{
+		{255, 255, 255, 0.3127, 0.3290},
+		{255, 0, 0, 0.6400, 0.3300},
+		{0, 255, 0, 0.3000, 0.6000},
+		{0, 0, 255, 0.1500, 0.0600},
+	}
+
+	for index, tc := range testCases {
+		x, y := RGBToXY(tc.R, tc.G, tc.B)
+		fx, fy := float64(x)/65536, float64(y)/65536
+		if math.Abs(fx-tc.X) > 0.001 || math.Abs(fy-tc.Y) > 0.001 {
+			t.Errorf("(%d) wrong result: expected (%.4f, %.4f), actual (%.4f, %.4f)", index, tc.X, tc.Y, fx, fy)
+		}
+	}
+}
+
+func TestKelvinToMireds(t *testing.T) {
+	if mireds := KelvinToMireds(2700); mireds != 370 {
+		t.Errorf("wrong mireds: expected 370, actual %d", mireds)
+	}
+	if mireds := KelvinToMireds(6500); mireds != 154 {
+		t.Errorf("wrong mireds: expected 154, actual %d", mireds)
+	}
+	if kelvin := MiredsToKelvin(250); kelvin != 4000 {
+		t.Errorf("wrong kelvin: expected 4000, actual %d", kelvin)
+	}
+}
@@ -0,0 +1,334 @@
+package zcl
+
+import "github.com/GreenLightning/zigbee-conductor/pkg/scf"
+
+// Attributes of the Color Control cluster.
+const (
+	AttributeColorControlCurrentHue                 AttributeID = 0x0000
+	AttributeColorControlCurrentSaturation          AttributeID = 0x0001
+	AttributeColorControlRemainingTime              AttributeID = 0x0002
+	AttributeColorControlCurrentX                   AttributeID = 0x0003
+	AttributeColorControlCurrentY                   AttributeID = 0x0004
+	AttributeColorControlColorTemperatureMireds     AttributeID = 0x0007
+	AttributeColorControlColorMode                  AttributeID = 0x0008
+	AttributeColorControlOptions                    AttributeID = 0x000f
+	AttributeColorControlEnhancedCurrentHue         AttributeID = 0x4000
+	AttributeColorControlEnhancedColorMode          AttributeID = 0x4001
+	AttributeColorControlColorLoopActive            AttributeID = 0x4002
+	AttributeColorControlColorLoopDirection         AttributeID = 0x4003
+	AttributeColorControlColorLoopTime              AttributeID = 0x4004
+	AttributeColorControlColorCapabilities          AttributeID = 0x400a
+	AttributeColorControlColorTempPhysicalMinMireds AttributeID = 0x400b
+	AttributeColorControlColorTempPhysicalMaxMireds AttributeID = 0x400c
+)
+
+func init() {
+	registerAttributes(ClusterLightingColorControl,
+		AttributeDefinition{AttributeColorControlCurrentHue, "CurrentHue", DataTypeUint8},
+		AttributeDefinition{AttributeColorControlCurrentSaturation, "CurrentSaturation", DataTypeUint8},
+		AttributeDefinition{AttributeColorControlRemainingTime, "RemainingTime", DataTypeUint16},
+		AttributeDefinition{AttributeColorControlCurrentX, "CurrentX", DataTypeUint16},
+		AttributeDefinition{AttributeColorControlCurrentY, "CurrentY", DataTypeUint16},
+		AttributeDefinition{AttributeColorControlColorTemperatureMireds, "ColorTemperatureMireds", DataTypeUint16},
+		AttributeDefinition{AttributeColorControlColorMode, "ColorMode", DataTypeEnum8},
+		AttributeDefinition{AttributeColorControlOptions, "Options", DataTypeBitmap8},
+		AttributeDefinition{AttributeColorControlEnhancedCurrentHue, "EnhancedCurrentHue", DataTypeUint16},
+		AttributeDefinition{AttributeColorControlEnhancedColorMode, "EnhancedColorMode", DataTypeEnum8},
+		AttributeDefinition{AttributeColorControlColorLoopActive, "ColorLoopActive", DataTypeUint8},
+		AttributeDefinition{AttributeColorControlColorLoopDirection, "ColorLoopDirection", DataTypeUint8},
+		AttributeDefinition{AttributeColorControlColorLoopTime, "ColorLoopTime", DataTypeUint16},
+		AttributeDefinition{AttributeColorControlColorCapabilities, "ColorCapabilities", DataTypeBitmap16},
+		AttributeDefinition{AttributeColorControlColorTempPhysicalMinMireds, "ColorTempPhysicalMinMireds", DataTypeUint16},
+		AttributeDefinition{AttributeColorControlColorTempPhysicalMaxMireds, "ColorTempPhysicalMaxMireds", DataTypeUint16},
+	)
+}
+
+// Values of the ColorMode and EnhancedColorMode attributes.
+const (
+	ColorModeHueAndSaturation         = 0x00
+	ColorModeXY                       = 0x01
+	ColorModeColorTemperature         = 0x02
+	ColorModeEnhancedHueAndSaturation = 0x03 // only used by EnhancedColorMode
+)
+
+// Bits of the ColorCapabilities attribute.
+const (
+	ColorCapabilityHueSaturation    = 1 << 0
+	ColorCapabilityEnhancedHue      = 1 << 1
+	ColorCapabilityColorLoop        = 1 << 2
+	ColorCapabilityXY               = 1 << 3
+	ColorCapabilityColorTemperature = 1 << 4
+)
+
+// Commands received by the server of the Color Control cluster.
+const (
+	CommandColorControlMoveToHue              CommandID = 0x00
+	CommandColorControlMoveToSaturation       CommandID = 0x03
+	CommandColorControlMoveToHueAndSaturation CommandID = 0x06
+	CommandColorControlMoveToColor            CommandID = 0x07
+	CommandColorControlMoveToColorTemperature CommandID = 0x0a
+	CommandColorControlEnhancedMoveToHue      CommandID = 0x40
+	CommandColorControlColorLoopSet           CommandID = 0x44
+	CommandColorControlStopMoveStep           CommandID = 0x47
+)
+
+func init() {
+	registerClusterCommand(new(MoveToHueCommand))
+	registerClusterCommand(new(MoveToSaturationCommand))
+	registerClusterCommand(new(MoveToHueAndSaturationCommand))
+	registerClusterCommand(new(MoveToColorCommand))
+	registerClusterCommand(new(MoveToColorTemperatureCommand))
+	registerClusterCommand(new(EnhancedMoveToHueCommand))
+	registerClusterCommand(new(ColorLoopSetCommand))
+	registerClusterCommand(new(StopMoveStepCommand))
+}
+
+// HueDirection specifies how the hue is changed by the Move To Hue commands.
+type HueDirection uint8
+
+const (
+	HueDirectionShortestDistance HueDirection = 0x00
+	HueDirectionLongestDistance  HueDirection = 0x01
+	HueDirectionUp               HueDirection = 0x02
+	HueDirectionDown             HueDirection = 0x03
+)
+
+// Hue is in the range 0 to 254 (see HSVToHueSaturation).
+// TransitionTime is specified in tenths of a second.
+type MoveToHueCommand struct {
+	Hue            uint8
+	Direction      HueDirection
+	TransitionTime uint16
+}
+
+func (c *MoveToHueCommand) ClusterID() ClusterID {
+	return ClusterLightingColorControl
+}
+
+func (c *MoveToHueCommand) CommandID() CommandID {
+	return CommandColorControlMoveToHue
+}
+
+func (c *MoveToHueCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *MoveToHueCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *MoveToHueCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+// Saturation is in the range 0 to 254 (see HSVToHueSaturation).
+// TransitionTime is specified in tenths of a second.
+type MoveToSaturationCommand struct {
+	Saturation     uint8
+	TransitionTime uint16
+}
+
+func (c *MoveToSaturationCommand) ClusterID() ClusterID {
+	return ClusterLightingColorControl
+}
+
+func (c *MoveToSaturationCommand) CommandID() CommandID {
+	return CommandColorControlMoveToSaturation
+}
+
+func (c *MoveToSaturationCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *MoveToSaturationCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *MoveToSaturationCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+// TransitionTime is specified in tenths of a second.
+type MoveToHueAndSaturationCommand struct {
+	Hue            uint8
+	Saturation     uint8
+	TransitionTime uint16
+}
+
+func (c *MoveToHueAndSaturationCommand) ClusterID() ClusterID {
+	return ClusterLightingColorControl
+}
+
+func (c *MoveToHueAndSaturationCommand) CommandID() CommandID {
+	return CommandColorControlMoveToHueAndSaturation
+}
+
+func (c *MoveToHueAndSaturationCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *MoveToHueAndSaturationCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *MoveToHueAndSaturationCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+// ColorX and ColorY are CIE 1931 chromaticity coordinates scaled by 65536 (see RGBToXY).
+// TransitionTime is specified in tenths of a second.
+type MoveToColorCommand struct {
+	ColorX         uint16
+	ColorY         uint16
+	TransitionTime uint16
+}
+
+func (c *MoveToColorCommand) ClusterID() ClusterID {
+	return ClusterLightingColorControl
+}
+
+func (c *MoveToColorCommand) CommandID() CommandID {
+	return CommandColorControlMoveToColor
+}
+
+func (c *MoveToColorCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *MoveToColorCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *MoveToColorCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+// ColorTemperatureMireds is the reciprocal color temperature (see KelvinToMireds).
+// TransitionTime is specified in tenths of a second.
+type MoveToColorTemperatureCommand struct {
+	ColorTemperatureMireds uint16
+	TransitionTime         uint16
+}
+
+func (c *MoveToColorTemperatureCommand) ClusterID() ClusterID {
+	return ClusterLightingColorControl
+}
+
+func (c *MoveToColorTemperatureCommand) CommandID() CommandID {
+	return CommandColorControlMoveToColorTemperature
+}
+
+func (c *MoveToColorTemperatureCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *MoveToColorTemperatureCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *MoveToColorTemperatureCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+// EnhancedHue is in the range 0 to 65535 (see HSVToEnhancedHue).
+// TransitionTime is specified in tenths of a second.
+type EnhancedMoveToHueCommand struct {
+	EnhancedHue    uint16
+	Direction      HueDirection
+	TransitionTime uint16
+}
+
+func (c *EnhancedMoveToHueCommand) ClusterID() ClusterID {
+	return ClusterLightingColorControl
+}
+
+func (c *EnhancedMoveToHueCommand) CommandID() CommandID {
+	return CommandColorControlEnhancedMoveToHue
+}
+
+func (c *EnhancedMoveToHueCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *EnhancedMoveToHueCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *EnhancedMoveToHueCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+// Bits of the UpdateFlags field of the Color Loop Set command specifying
+// which of the other fields should be applied.
+const (
+	ColorLoopUpdateAction    = 1 << 0
+	ColorLoopUpdateDirection = 1 << 1
+	ColorLoopUpdateTime      = 1 << 2
+	ColorLoopUpdateStartHue  = 1 << 3
+)
+
+type ColorLoopAction uint8
+
+const (
+	ColorLoopActionDeactivate             ColorLoopAction = 0x00
+	ColorLoopActionActivateFromStartHue   ColorLoopAction = 0x01
+	ColorLoopActionActivateFromCurrentHue ColorLoopAction = 0x02
+)
+
+type ColorLoopDirection uint8
+
+const (
+	ColorLoopDirectionDecrement ColorLoopDirection = 0x00
+	ColorLoopDirectionIncrement ColorLoopDirection = 0x01
+)
+
+// Time is the duration of a full color loop in seconds.
+type ColorLoopSetCommand struct {
+	UpdateFlags uint8
+	Action      ColorLoopAction
+	Direction   ColorLoopDirection
+	Time        uint16
+	StartHue    uint16
+}
+
+func (c *ColorLoopSetCommand) ClusterID() ClusterID {
+	return ClusterLightingColorControl
+}
+
+func (c *ColorLoopSetCommand) CommandID() CommandID {
+	return CommandColorControlColorLoopSet
+}
+
+func (c *ColorLoopSetCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *ColorLoopSetCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *ColorLoopSetCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type StopMoveStepCommand struct{}
+
+func (c *StopMoveStepCommand) ClusterID() ClusterID {
+	return ClusterLightingColorControl
+}
+
+func (c *StopMoveStepCommand) CommandID() CommandID {
+	return CommandColorControlStopMoveStep
+}
+
+func (c *StopMoveStepCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *StopMoveStepCommand) ParsePayload(data []byte) error {
+	return nil
+}
+
+func (c *StopMoveStepCommand) SerializePayload() []byte {
+	return nil
+}
@@ -226,6 +226,14 @@ func ParseReadAttributesCommand(data []byte) (ReadAttributesCommand, error) {
 	return command, nil
 }
 
+func SerializeReadAttributesCommand(command ReadAttributesCommand) []byte {
+	data := make([]byte, 0, 2*len(command.Attributes))
+	for _, attribute := range command.Attributes {
+		data = append(data, byte(attribute), byte(attribute>>8))
+	}
+	return data
+}
+
 type ReadAttributesResponseCommand struct {
 	Records []ReadAttributeStatusRecord
 }
@@ -282,6 +290,23 @@ func ParseReadAttributeStatusRecord(data []byte) (ReadAttributeStatusRecord, []b
 	return record, data, nil
 }
 
+func SerializeReadAttributesResponseCommand(command ReadAttributesResponseCommand) ([]byte, error) {
+	var data []byte
+	for _, record := range command.Records {
+		data = append(data, byte(record.AttributeID), byte(record.AttributeID>>8), byte(record.Status))
+		if record.Status != StatusSuccess {
+			continue
+		}
+		value, err := SerializeValue(record.DataType, record.Value)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %v: %w", record.AttributeID, err)
+		}
+		data = append(data, byte(record.DataType))
+		data = append(data, value...)
+	}
+	return data, nil
+}
+
 type ReadReportingConfigurationCommand struct {
 	Records []AttributeRecord
 }
@@ -417,3 +442,393 @@ func ParseAttributeReport(data []byte) (AttributeReport, []byte, error) {
 	report.Value, data, err = ParseValue(report.DataType, data)
 	return report, data, err
 }
+
+type DefaultResponseCommand struct {
+	CommandID CommandID
+	Status    Status
+}
+
+func ParseDefaultResponseCommand(data []byte) (DefaultResponseCommand, error) {
+	var command DefaultResponseCommand
+	if len(data) < 2 {
+		return command, ErrNotEnoughData
+	}
+	command.CommandID = CommandID(data[0])
+	command.Status = Status(data[1])
+	return command, nil
+}
+
+func SerializeDefaultResponseCommand(command DefaultResponseCommand) []byte {
+	return []byte{byte(command.CommandID), byte(command.Status)}
+}
+
+type WriteAttributesCommand struct {
+	Records []WriteAttributeRecord
+}
+
+type WriteAttributeRecord struct {
+	AttributeID AttributeID
+	DataType    DataType
+	Value       interface{}
+}
+
+func ParseWriteAttributesCommand(data []byte) (WriteAttributesCommand, error) {
+	var command WriteAttributesCommand
+	for len(data) != 0 {
+		if len(data) < 3 {
+			return command, ErrNotEnoughData
+		}
+
+		var record WriteAttributeRecord
+		record.AttributeID = AttributeID(binary.LittleEndian.Uint16(data))
+		record.DataType = DataType(data[2])
+		data = data[3:]
+
+		var err error
+		record.Value, data, err = ParseValue(record.DataType, data)
+		if err != nil {
+			return command, err
+		}
+
+		command.Records = append(command.Records, record)
+	}
+	return command, nil
+}
+
+func SerializeWriteAttributesCommand(command WriteAttributesCommand) ([]byte, error) {
+	var data []byte
+	for _, record := range command.Records {
+		value, err := SerializeValue(record.DataType, record.Value)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %v: %w", record.AttributeID, err)
+		}
+		data = append(data, 0, 0, byte(record.DataType))
+		binary.LittleEndian.PutUint16(data[len(data)-3:], uint16(record.AttributeID))
+		data = append(data, value...)
+	}
+	return data, nil
+}
+
+type WriteAttributesResponseCommand struct {
+	Records []WriteAttributeStatusRecord
+}
+
+type WriteAttributeStatusRecord struct {
+	Status      Status
+	AttributeID AttributeID
+}
+
+// ParseWriteAttributesResponseCommand parses the response to a Write Attributes command.
+// If all attributes were written successfully, the response consists of a
+// single record with StatusSuccess (and no attribute ID).
+func ParseWriteAttributesResponseCommand(data []byte) (WriteAttributesResponseCommand, error) {
+	var command WriteAttributesResponseCommand
+
+	if len(data) == 1 {
+		command.Records = append(command.Records, WriteAttributeStatusRecord{Status: Status(data[0])})
+		return command, nil
+	}
+
+	for len(data) != 0 {
+		if len(data) < 3 {
+			return command, ErrNotEnoughData
+		}
+		command.Records = append(command.Records, WriteAttributeStatusRecord{
+			Status:      Status(data[0]),
+			AttributeID: AttributeID(binary.LittleEndian.Uint16(data[1:])),
+		})
+		data = data[3:]
+	}
+
+	return command, nil
+}
+
+// SerializeWriteAttributesResponseCommand only includes records with a status
+// other than StatusSuccess as required by the specification.
+func SerializeWriteAttributesResponseCommand(command WriteAttributesResponseCommand) []byte {
+	var data []byte
+	for _, record := range command.Records {
+		if record.Status == StatusSuccess {
+			continue
+		}
+		data = append(data, byte(record.Status), 0, 0)
+		binary.LittleEndian.PutUint16(data[len(data)-2:], uint16(record.AttributeID))
+	}
+	if len(data) == 0 {
+		data = append(data, byte(StatusSuccess))
+	}
+	return data
+}
+
+func SerializeReportAttributesCommand(command ReportAttributesCommand) ([]byte, error) {
+	var data []byte
+	for _, report := range command.Reports {
+		value, err := SerializeValue(report.DataType, report.Value)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %v: %w", report.AttributeID, err)
+		}
+		data = append(data, byte(report.AttributeID), byte(report.AttributeID>>8), byte(report.DataType))
+		data = append(data, value...)
+	}
+	return data, nil
+}
+
+// Directions of reporting configuration records.
+const (
+	// The receiver of the record sends reports.
+	ReportingDirectionSend byte = 0x00
+	// The receiver of the record receives reports.
+	ReportingDirectionReceive byte = 0x01
+)
+
+type ConfigureReportingCommand struct {
+	Records []ConfigureReportingRecord
+}
+
+// ConfigureReportingRecord configures the reporting of a single attribute.
+// DataType, MinimumReportingInterval, MaximumReportingInterval and
+// ReportableChange are only used for ReportingDirectionSend, TimeoutPeriod is
+// only used for ReportingDirectionReceive. ReportableChange is only present
+// for analog data types.
+type ConfigureReportingRecord struct {
+	Direction                byte
+	AttributeID              AttributeID
+	DataType                 DataType
+	MinimumReportingInterval uint16
+	MaximumReportingInterval uint16
+	ReportableChange         interface{}
+	TimeoutPeriod            uint16
+}
+
+func ParseConfigureReportingCommand(data []byte) (ConfigureReportingCommand, error) {
+	var command ConfigureReportingCommand
+	for len(data) != 0 {
+		if len(data) < 3 {
+			return command, ErrNotEnoughData
+		}
+
+		var record ConfigureReportingRecord
+		record.Direction = data[0]
+		record.AttributeID = AttributeID(binary.LittleEndian.Uint16(data[1:]))
+		data = data[3:]
+
+		switch record.Direction {
+		case ReportingDirectionSend:
+			if len(data) < 5 {
+				return command, ErrNotEnoughData
+			}
+			record.DataType = DataType(data[0])
+			record.MinimumReportingInterval = binary.LittleEndian.Uint16(data[1:])
+			record.MaximumReportingInterval = binary.LittleEndian.Uint16(data[3:])
+			data = data[5:]
+			if record.DataType.IsAnalog() {
+				var err error
+				record.ReportableChange, data, err = ParseValue(record.DataType, data)
+				if err != nil {
+					return command, err
+				}
+			}
+
+		case ReportingDirectionReceive:
+			if len(data) < 2 {
+				return command, ErrNotEnoughData
+			}
+			record.TimeoutPeriod = binary.LittleEndian.Uint16(data)
+			data = data[2:]
+
+		default:
+			return command, fmt.Errorf("%w: invalid direction 0x%02x", ErrInvalidData, record.Direction)
+		}
+
+		command.Records = append(command.Records, record)
+	}
+	return command, nil
+}
+
+func SerializeConfigureReportingCommand(command ConfigureReportingCommand) ([]byte, error) {
+	var data []byte
+	for _, record := range command.Records {
+		data = append(data, record.Direction, byte(record.AttributeID), byte(record.AttributeID>>8))
+		if record.Direction == ReportingDirectionReceive {
+			data = appendUint(data, uint64(record.TimeoutPeriod), 2)
+			continue
+		}
+		data = append(data, byte(record.DataType))
+		data = appendUint(data, uint64(record.MinimumReportingInterval), 2)
+		data = appendUint(data, uint64(record.MaximumReportingInterval), 2)
+		if record.DataType.IsAnalog() {
+			change, err := SerializeValue(record.DataType, record.ReportableChange)
+			if err != nil {
+				return nil, fmt.Errorf("attribute %v: %w", record.AttributeID, err)
+			}
+			data = append(data, change...)
+		}
+	}
+	return data, nil
+}
+
+type ConfigureReportingResponseCommand struct {
+	Records []ConfigureReportingStatusRecord
+}
+
+type ConfigureReportingStatusRecord struct {
+	Status      Status
+	Direction   byte
+	AttributeID AttributeID
+}
+
+// ParseConfigureReportingResponseCommand parses the response to a Configure
+// Reporting command. If all attributes were configured successfully, the
+// response consists of a single record with StatusSuccess.
+func ParseConfigureReportingResponseCommand(data []byte) (ConfigureReportingResponseCommand, error) {
+	var command ConfigureReportingResponseCommand
+
+	if len(data) == 1 {
+		command.Records = append(command.Records, ConfigureReportingStatusRecord{Status: Status(data[0])})
+		return command, nil
+	}
+
+	for len(data) != 0 {
+		if len(data) < 4 {
+			return command, ErrNotEnoughData
+		}
+		command.Records = append(command.Records, ConfigureReportingStatusRecord{
+			Status:      Status(data[0]),
+			Direction:   data[1],
+			AttributeID: AttributeID(binary.LittleEndian.Uint16(data[2:])),
+		})
+		data = data[4:]
+	}
+
+	return command, nil
+}
+
+// SerializeConfigureReportingResponseCommand only includes records with a
+// status other than StatusSuccess as required by the specification.
+func SerializeConfigureReportingResponseCommand(command ConfigureReportingResponseCommand) []byte {
+	var data []byte
+	for _, record := range command.Records {
+		if record.Status == StatusSuccess {
+			continue
+		}
+		data = append(data, byte(record.Status), record.Direction, byte(record.AttributeID), byte(record.AttributeID>>8))
+	}
+	if len(data) == 0 {
+		data = append(data, byte(StatusSuccess))
+	}
+	return data
+}
+
+type DiscoverAttributesCommand struct {
+	StartAttributeID    AttributeID
+	MaximumAttributeIDs uint8
+}
+
+func ParseDiscoverAttributesCommand(data []byte) (DiscoverAttributesCommand, error) {
+	var command DiscoverAttributesCommand
+	if len(data) < 3 {
+		return command, ErrNotEnoughData
+	}
+	command.StartAttributeID = AttributeID(binary.LittleEndian.Uint16(data))
+	command.MaximumAttributeIDs = data[2]
+	return command, nil
+}
+
+func SerializeDiscoverAttributesCommand(command DiscoverAttributesCommand) []byte {
+	return []byte{byte(command.StartAttributeID), byte(command.StartAttributeID >> 8), command.MaximumAttributeIDs}
+}
+
+// DiscoverAttributesResponseCommand lists the attributes of a cluster.
+// Complete is true if there are no more attributes to be discovered.
+type DiscoverAttributesResponseCommand struct {
+	Complete bool
+	Records  []DiscoverAttributeRecord
+}
+
+type DiscoverAttributeRecord struct {
+	AttributeID AttributeID
+	DataType    DataType
+}
+
+func ParseDiscoverAttributesResponseCommand(data []byte) (DiscoverAttributesResponseCommand, error) {
+	var command DiscoverAttributesResponseCommand
+	if len(data) < 1 {
+		return command, ErrNotEnoughData
+	}
+	command.Complete = data[0] != 0
+	data = data[1:]
+	for len(data) != 0 {
+		if len(data) < 3 {
+			return command, ErrNotEnoughData
+		}
+		command.Records = append(command.Records, DiscoverAttributeRecord{
+			AttributeID: AttributeID(binary.LittleEndian.Uint16(data)),
+			DataType:    DataType(data[2]),
+		})
+		data = data[3:]
+	}
+	return command, nil
+}
+
+func SerializeDiscoverAttributesResponseCommand(command DiscoverAttributesResponseCommand) []byte {
+	data := make([]byte, 1, 1+3*len(command.Records))
+	if command.Complete {
+		data[0] = 1
+	}
+	for _, record := range command.Records {
+		data = append(data, byte(record.AttributeID), byte(record.AttributeID>>8), byte(record.DataType))
+	}
+	return data
+}
+
+// DiscoverCommandsCommand is used for both Discover Commands Received and
+// Discover Commands Generated.
+type DiscoverCommandsCommand struct {
+	StartCommandID    CommandID
+	MaximumCommandIDs uint8
+}
+
+func ParseDiscoverCommandsCommand(data []byte) (DiscoverCommandsCommand, error) {
+	var command DiscoverCommandsCommand
+	if len(data) < 2 {
+		return command, ErrNotEnoughData
+	}
+	command.StartCommandID = CommandID(data[0])
+	command.MaximumCommandIDs = data[1]
+	return command, nil
+}
+
+func SerializeDiscoverCommandsCommand(command DiscoverCommandsCommand) []byte {
+	return []byte{byte(command.StartCommandID), command.MaximumCommandIDs}
+}
+
+// DiscoverCommandsResponseCommand is used for both Discover Commands Received
+// Response and Discover Commands Generated Response. Complete is true if there
+// are no more commands to be discovered.
+type DiscoverCommandsResponseCommand struct {
+	Complete bool
+	Commands []CommandID
+}
+
+func ParseDiscoverCommandsResponseCommand(data []byte) (DiscoverCommandsResponseCommand, error) {
+	var command DiscoverCommandsResponseCommand
+	if len(data) < 1 {
+		return command, ErrNotEnoughData
+	}
+	command.Complete = data[0] != 0
+	for _, id := range data[1:] {
+		command.Commands = append(command.Commands, CommandID(id))
+	}
+	return command, nil
+}
+
+func SerializeDiscoverCommandsResponseCommand(command DiscoverCommandsResponseCommand) []byte {
+	data := make([]byte, 1, 1+len(command.Commands))
+	if command.Complete {
+		data[0] = 1
+	}
+	for _, id := range command.Commands {
+		data = append(data, byte(id))
+	}
+	return data
+}
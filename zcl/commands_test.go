@@ -0,0 +1,125 @@
+package zcl
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+func TestGlobalCommands(t *testing.T) {
+	type TestCase struct {
+		Name      string
+		Command   interface{}
+		Hex       string
+		Serialize func(command interface{}) ([]byte, error)
+		Parse     func(data []byte) (interface{}, error)
+	}
+
+	testCases := []TestCase{
+		TestCase{
+			"ReadAttributesResponse",
+			ReadAttributesResponseCommand{Records: []ReadAttributeStatusRecord{
+				{AttributeID: 0x0000, Status: StatusSuccess, DataType: DataTypeUint8, Value: uint8(3)},
+				{AttributeID: 0x0005, Status: StatusUnsupportedAttribute},
+			}},
+			"0000002003050086",
+			func(c interface{}) ([]byte, error) {
+				return SerializeReadAttributesResponseCommand(c.(ReadAttributesResponseCommand))
+			},
+			func(d []byte) (interface{}, error) { return ParseReadAttributesResponseCommand(d) },
+		},
+		TestCase{
+			"ReportAttributes",
+			ReportAttributesCommand{Reports: []AttributeReport{
+				{AttributeID: 0x0000, DataType: DataTypeInt16, Value: int16(2150)},
+			}},
+			"0000296608",
+			func(c interface{}) ([]byte, error) {
+				return SerializeReportAttributesCommand(c.(ReportAttributesCommand))
+			},
+			func(d []byte) (interface{}, error) { return ParseReportAttributesCommand(d) },
+		},
+		TestCase{
+			"ConfigureReporting",
+			ConfigureReportingCommand{Records: []ConfigureReportingRecord{
+				{Direction: ReportingDirectionSend, AttributeID: 0x0000, DataType: DataTypeInt16, MinimumReportingInterval: 10, MaximumReportingInterval: 3600, ReportableChange: int16(50)},
+				{Direction: ReportingDirectionSend, AttributeID: 0x0000, DataType: DataTypeBool, MinimumReportingInterval: 0, MaximumReportingInterval: 300},
+				{Direction: ReportingDirectionReceive, AttributeID: 0x0001, TimeoutPeriod: 600},
+			}},
+			"000000290a00100e32000000001000002c010101005802",
+			func(c interface{}) ([]byte, error) {
+				return SerializeConfigureReportingCommand(c.(ConfigureReportingCommand))
+			},
+			func(d []byte) (interface{}, error) { return ParseConfigureReportingCommand(d) },
+		},
+		TestCase{
+			"ConfigureReportingResponse",
+			ConfigureReportingResponseCommand{Records: []ConfigureReportingStatusRecord{
+				{Status: StatusUnreportableAttribute, Direction: ReportingDirectionSend, AttributeID: 0x0002},
+			}},
+			"8c000200",
+			func(c interface{}) ([]byte, error) {
+				return SerializeConfigureReportingResponseCommand(c.(ConfigureReportingResponseCommand)), nil
+			},
+			func(d []byte) (interface{}, error) { return ParseConfigureReportingResponseCommand(d) },
+		},
+		TestCase{
+			"DiscoverAttributes",
+			DiscoverAttributesCommand{StartAttributeID: 0x0004, MaximumAttributeIDs: 16},
+			"040010",
+			func(c interface{}) ([]byte, error) {
+				return SerializeDiscoverAttributesCommand(c.(DiscoverAttributesCommand)), nil
+			},
+			func(d []byte) (interface{}, error) { return ParseDiscoverAttributesCommand(d) },
+		},
+		TestCase{
+			"DiscoverAttributesResponse",
+			DiscoverAttributesResponseCommand{Complete: true, Records: []DiscoverAttributeRecord{
+				{AttributeID: 0x0000, DataType: DataTypeUint8},
+				{AttributeID: 0x0004, DataType: DataTypeCharacterString},
+			}},
+			"01000020040042",
+			func(c interface{}) ([]byte, error) {
+				return SerializeDiscoverAttributesResponseCommand(c.(DiscoverAttributesResponseCommand)), nil
+			},
+			func(d []byte) (interface{}, error) { return ParseDiscoverAttributesResponseCommand(d) },
+		},
+		TestCase{
+			"DiscoverCommands",
+			DiscoverCommandsCommand{StartCommandID: 0x02, MaximumCommandIDs: 8},
+			"0208",
+			func(c interface{}) ([]byte, error) {
+				return SerializeDiscoverCommandsCommand(c.(DiscoverCommandsCommand)), nil
+			},
+			func(d []byte) (interface{}, error) { return ParseDiscoverCommandsCommand(d) },
+		},
+		TestCase{
+			"DiscoverCommandsResponse",
+			DiscoverCommandsResponseCommand{Complete: false, Commands: []CommandID{0x00, 0x01, 0x02}},
+			"00000102",
+			func(c interface{}) ([]byte, error) {
+				return SerializeDiscoverCommandsResponseCommand(c.(DiscoverCommandsResponseCommand)), nil
+			},
+			func(d []byte) (interface{}, error) { return ParseDiscoverCommandsResponseCommand(d) },
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			data, err := tc.Serialize(tc.Command)
+			if err != nil {
+				t.Fatal("unexpected err:", err)
+			}
+			if actual := hex.EncodeToString(data); actual != tc.Hex {
+				t.Errorf("serialize: got %s, expected %s", actual, tc.Hex)
+			}
+			command, err := tc.Parse(data)
+			if err != nil {
+				t.Fatal("unexpected err:", err)
+			}
+			if !reflect.DeepEqual(command, tc.Command) {
+				t.Errorf("parse: got %+v, expected %+v", command, tc.Command)
+			}
+		})
+	}
+}
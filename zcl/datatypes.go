@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"reflect"
 )
 
 var ErrNotEnoughData = errors.New("not enough data")
@@ -293,6 +294,28 @@ func (typ DataType) SizeInBytes() int {
 	}
 }
 
+// A StructureElement is an element of a value of DataTypeStructure. Structures
+// are represented as []StructureElement.
+type StructureElement struct {
+	DataType DataType
+	Value    interface{}
+}
+
+// IsAnalog reports whether the data type is an analog data type (integer,
+// floating point and time types). Reports of analog attributes can be limited
+// to changes above a threshold, while discrete attributes report every change.
+func (typ DataType) IsAnalog() bool {
+	switch typ {
+	case DataTypeUint8, DataTypeUint16, DataTypeUint24, DataTypeUint32, DataTypeUint40, DataTypeUint48, DataTypeUint56, DataTypeUint64,
+		DataTypeInt8, DataTypeInt16, DataTypeInt24, DataTypeInt32, DataTypeInt40, DataTypeInt48, DataTypeInt56, DataTypeInt64,
+		DataTypeFloat16, DataTypeFloat32, DataTypeFloat64,
+		DataTypeTimeOfDay, DataTypeDate, DataTypeUTCTime:
+		return true
+	default:
+		return false
+	}
+}
+
 func ParseValue(typ DataType, data []byte) (interface{}, []byte, error) {
 	size := typ.SizeInBytes()
 	if size >= 0 && len(data) < size {
@@ -455,8 +478,19 @@ func ParseValue(typ DataType, data []byte) (interface{}, []byte, error) {
 		}
 		return value, data[8:], nil
 
-	case DataTypeEnum8, DataTypeEnum16:
-		return nil, data, fmt.Errorf("%w: %v", ErrNotImplemented, typ)
+	case DataTypeEnum8:
+		value := uint8(data[0])
+		if value == 0xff {
+			return nil, data[1:], nil
+		}
+		return value, data[1:], nil
+
+	case DataTypeEnum16:
+		value := binary.LittleEndian.Uint16(data)
+		if value == 0xffff {
+			return nil, data[2:], nil
+		}
+		return value, data[2:], nil
 
 	case DataTypeFloat16:
 		return nil, data, fmt.Errorf("%w: %v", ErrNotImplemented, typ)
@@ -478,7 +512,18 @@ func ParseValue(typ DataType, data []byte) (interface{}, []byte, error) {
 		return value, data[8:], nil
 
 	case DataTypeOctetString:
-		return nil, data, fmt.Errorf("%w: %v", ErrNotImplemented, typ)
+		if len(data) < 1 {
+			return nil, data, ErrNotEnoughData
+		}
+		length := uint8(data[0])
+		if length == 0xff {
+			return nil, data[1:], nil
+		}
+		if len(data) < int(length)+1 {
+			return nil, data, ErrNotEnoughData
+		}
+		value := append([]byte{}, data[1:length+1]...)
+		return value, data[length+1:], nil
 
 	case DataTypeCharacterString:
 		if len(data) < 1 {
@@ -495,7 +540,18 @@ func ParseValue(typ DataType, data []byte) (interface{}, []byte, error) {
 		return value, data[length+1:], nil
 
 	case DataTypeLongOctetString:
-		return nil, data, fmt.Errorf("%w: %v", ErrNotImplemented, typ)
+		if len(data) < 2 {
+			return nil, data, ErrNotEnoughData
+		}
+		length := binary.LittleEndian.Uint16(data)
+		if length == 0xffff {
+			return nil, data[2:], nil
+		}
+		if len(data) < int(length)+2 {
+			return nil, data, ErrNotEnoughData
+		}
+		value := append([]byte{}, data[2:length+2]...)
+		return value, data[length+2:], nil
 
 	case DataTypeLongCharacterString:
 		if len(data) < 2 {
@@ -511,19 +567,58 @@ func ParseValue(typ DataType, data []byte) (interface{}, []byte, error) {
 		value := string(data[2 : length+2])
 		return value, data[length+2:], nil
 
-	case DataTypeArray, DataTypeStructure:
+	case DataTypeArray:
 		return nil, data, fmt.Errorf("%w: %v", ErrNotImplemented, typ)
 
+	case DataTypeStructure:
+		if len(data) < 2 {
+			return nil, data, ErrNotEnoughData
+		}
+		count := binary.LittleEndian.Uint16(data)
+		data = data[2:]
+		if count == 0xffff {
+			return nil, data, nil
+		}
+		elements := make([]StructureElement, 0, count)
+		for i := 0; i < int(count); i++ {
+			if len(data) < 1 {
+				return nil, data, ErrNotEnoughData
+			}
+			element := StructureElement{DataType: DataType(data[0])}
+			var err error
+			element.Value, data, err = ParseValue(element.DataType, data[1:])
+			if err != nil {
+				return nil, data, err
+			}
+			elements = append(elements, element)
+		}
+		return elements, data, nil
+
 	case DataTypeSet, DataTypeBag:
 		return nil, data, fmt.Errorf("%w: %v", ErrNotImplemented, typ)
 
-	case DataTypeTimeOfDay, DataTypeDate, DataTypeUTCTime:
+	case DataTypeTimeOfDay, DataTypeDate:
 		return nil, data, fmt.Errorf("%w: %v", ErrNotImplemented, typ)
 
+	case DataTypeUTCTime:
+		// Seconds since 2000-01-01 00:00:00 UTC, see UTCToTime.
+		value := binary.LittleEndian.Uint32(data)
+		if value == 0xffff_ffff {
+			return nil, data[4:], nil
+		}
+		return value, data[4:], nil
+
 	case DataTypeClusterID, DataTypeAttributeID, DataTypeBACnetOID:
 		return nil, data, fmt.Errorf("%w: %v", ErrNotImplemented, typ)
 
-	case DataTypeIEEEAddress, DataTypeSecurityKey128:
+	case DataTypeIEEEAddress:
+		value := binary.LittleEndian.Uint64(data)
+		if value == 0xffff_ffff_ffff_ffff {
+			return nil, data[8:], nil
+		}
+		return value, data[8:], nil
+
+	case DataTypeSecurityKey128:
 		return nil, data, fmt.Errorf("%w: %v", ErrNotImplemented, typ)
 
 	case DataTypeUnknown:
@@ -534,6 +629,206 @@ func ParseValue(typ DataType, data []byte) (interface{}, []byte, error) {
 	}
 }
 
+// SerializeValue returns the binary representation of a value of the given
+// data type. It accepts the Go types returned by ParseValue, but integer types
+// are converted as necessary. A nil value is serialized as the invalid value
+// of the data type (if the data type has one).
+func SerializeValue(typ DataType, value interface{}) ([]byte, error) {
+	size := typ.SizeInBytes()
+
+	switch typ {
+	case DataTypeNoData, DataTypeUnknown:
+		return nil, nil
+
+	case DataTypeData8, DataTypeData16, DataTypeData24, DataTypeData32, DataTypeData40, DataTypeData48, DataTypeData56, DataTypeData64:
+		bytes, ok := value.([]byte)
+		if !ok || len(bytes) != size {
+			return nil, fmt.Errorf("%w: cannot serialize %T as %v", ErrInvalidData, value, typ)
+		}
+		return append([]byte(nil), bytes...), nil
+
+	case DataTypeBool:
+		if value == nil {
+			return []byte{0xff}, nil
+		}
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: cannot serialize %T as %v", ErrInvalidData, value, typ)
+		}
+		if b {
+			return []byte{0x01}, nil
+		}
+		return []byte{0x00}, nil
+
+	case DataTypeBitmap8, DataTypeBitmap16, DataTypeBitmap24, DataTypeBitmap32, DataTypeBitmap40, DataTypeBitmap48, DataTypeBitmap56, DataTypeBitmap64,
+		DataTypeClusterID, DataTypeAttributeID, DataTypeBACnetOID:
+		v, ok := toUint64(value)
+		if !ok {
+			return nil, fmt.Errorf("%w: cannot serialize %T as %v", ErrInvalidData, value, typ)
+		}
+		return appendUint(nil, v, size), nil
+
+	case DataTypeUint8, DataTypeUint16, DataTypeUint24, DataTypeUint32, DataTypeUint40, DataTypeUint48, DataTypeUint56, DataTypeUint64,
+		DataTypeEnum8, DataTypeEnum16, DataTypeUTCTime, DataTypeIEEEAddress:
+		if value == nil {
+			return appendUint(nil, math.MaxUint64, size), nil
+		}
+		v, ok := toUint64(value)
+		if !ok {
+			return nil, fmt.Errorf("%w: cannot serialize %T as %v", ErrInvalidData, value, typ)
+		}
+		return appendUint(nil, v, size), nil
+
+	case DataTypeInt8, DataTypeInt16, DataTypeInt24, DataTypeInt32, DataTypeInt40, DataTypeInt48, DataTypeInt56, DataTypeInt64:
+		if value == nil {
+			return appendUint(nil, 1<<(8*size-1), size), nil
+		}
+		v, ok := toInt64(value)
+		if !ok {
+			return nil, fmt.Errorf("%w: cannot serialize %T as %v", ErrInvalidData, value, typ)
+		}
+		return appendUint(nil, uint64(v), size), nil
+
+	case DataTypeFloat32:
+		v := math.NaN()
+		if value != nil {
+			var ok bool
+			v, ok = toFloat64(value)
+			if !ok {
+				return nil, fmt.Errorf("%w: cannot serialize %T as %v", ErrInvalidData, value, typ)
+			}
+		}
+		return appendUint(nil, uint64(math.Float32bits(float32(v))), 4), nil
+
+	case DataTypeFloat64:
+		v := math.NaN()
+		if value != nil {
+			var ok bool
+			v, ok = toFloat64(value)
+			if !ok {
+				return nil, fmt.Errorf("%w: cannot serialize %T as %v", ErrInvalidData, value, typ)
+			}
+		}
+		return appendUint(nil, math.Float64bits(v), 8), nil
+
+	case DataTypeOctetString:
+		if value == nil {
+			return []byte{0xff}, nil
+		}
+		bytes, ok := value.([]byte)
+		if !ok || len(bytes) > 0xfe {
+			return nil, fmt.Errorf("%w: cannot serialize %T as %v", ErrInvalidData, value, typ)
+		}
+		data := []byte{byte(len(bytes))}
+		return append(data, bytes...), nil
+
+	case DataTypeLongOctetString:
+		if value == nil {
+			return []byte{0xff, 0xff}, nil
+		}
+		bytes, ok := value.([]byte)
+		if !ok || len(bytes) > 0xfffe {
+			return nil, fmt.Errorf("%w: cannot serialize %T as %v", ErrInvalidData, value, typ)
+		}
+		data := appendUint(nil, uint64(len(bytes)), 2)
+		return append(data, bytes...), nil
+
+	case DataTypeCharacterString:
+		if value == nil {
+			return []byte{0xff}, nil
+		}
+		str, ok := value.(string)
+		if !ok || len(str) > 0xfe {
+			return nil, fmt.Errorf("%w: cannot serialize %T as %v", ErrInvalidData, value, typ)
+		}
+		data := []byte{byte(len(str))}
+		return append(data, str...), nil
+
+	case DataTypeLongCharacterString:
+		if value == nil {
+			return []byte{0xff, 0xff}, nil
+		}
+		str, ok := value.(string)
+		if !ok || len(str) > 0xfffe {
+			return nil, fmt.Errorf("%w: cannot serialize %T as %v", ErrInvalidData, value, typ)
+		}
+		data := appendUint(nil, uint64(len(str)), 2)
+		return append(data, str...), nil
+
+	case DataTypeStructure:
+		if value == nil {
+			return []byte{0xff, 0xff}, nil
+		}
+		elements, ok := value.([]StructureElement)
+		if !ok || len(elements) > 0xfffe {
+			return nil, fmt.Errorf("%w: cannot serialize %T as %v", ErrInvalidData, value, typ)
+		}
+		data := appendUint(nil, uint64(len(elements)), 2)
+		for _, element := range elements {
+			value, err := SerializeValue(element.DataType, element.Value)
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, byte(element.DataType))
+			data = append(data, value...)
+		}
+		return data, nil
+
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrNotImplemented, typ)
+	}
+}
+
+func appendUint(data []byte, value uint64, size int) []byte {
+	for i := 0; i < size; i++ {
+		data = append(data, byte(value>>(8*i)))
+	}
+	return data
+}
+
+func toUint64(value interface{}) (uint64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() < 0 {
+			return 0, false
+		}
+		return uint64(v.Int()), true
+	default:
+		return 0, false
+	}
+}
+
+func toInt64(value interface{}) (int64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v.Uint()), true
+	default:
+		return 0, false
+	}
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		if i, ok := toInt64(value); ok {
+			return float64(i), true
+		}
+		return 0, false
+	}
+}
+
 func extendSign(buffer []byte, validBytes, totalBytes int) {
 	if buffer[validBytes-1]&0b1000_0000 != 0 {
 		for i := validBytes; i < totalBytes; i++ {
@@ -1,6 +1,7 @@
 package zcl
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -50,6 +51,9 @@ func TestParseValueSuccess(t *testing.T) {
 		TestCase{DataTypeUint56, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 42}, nil},
 		TestCase{DataTypeUint64, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 42}, nil},
 
+		TestCase{DataTypeUTCTime, []byte{0xdd, 0xcc, 0xbb, 0xaa, 42}, uint32(0xaabbccdd)},
+		TestCase{DataTypeUTCTime, []byte{0xff, 0xff, 0xff, 0xff, 42}, nil},
+
 		TestCase{DataTypeInt8, []byte{0x01, 42}, int8(0x01)},
 		TestCase{DataTypeInt16, []byte{0x02, 0x01, 42}, int16(0x0102)},
 		TestCase{DataTypeInt24, []byte{0x03, 0x02, 0x01, 42}, int32(0x010203)},
@@ -77,17 +81,32 @@ func TestParseValueSuccess(t *testing.T) {
 		TestCase{DataTypeInt56, []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x80, 42}, nil},
 		TestCase{DataTypeInt64, []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x80, 42}, nil},
 
+		TestCase{DataTypeEnum8, []byte{0x02, 42}, uint8(0x02)},
+		TestCase{DataTypeEnum16, []byte{0x02, 0x01, 42}, uint16(0x0102)},
+
+		TestCase{DataTypeEnum8, []byte{0xff, 42}, nil},
+		TestCase{DataTypeEnum16, []byte{0xff, 0xff, 42}, nil},
+
 		TestCase{DataTypeFloat32, []byte{0x00, 0x00, 0x00, 0x3e, 42}, float32(0.125)},
 		TestCase{DataTypeFloat64, []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xc0, 0x3f, 42}, float64(0.125)},
 
 		TestCase{DataTypeFloat32, []byte{0x01, 0x00, 0x80, 0x7f, 42}, nil},
 		TestCase{DataTypeFloat64, []byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0xf8, 0x7f, 42}, nil},
 
+		TestCase{DataTypeOctetString, []byte{0x02, 0x12, 0x34, 42}, []byte{0x12, 0x34}},
+		TestCase{DataTypeOctetString, []byte{0xff, 42}, nil},
+
+		TestCase{DataTypeLongOctetString, []byte{0x01, 0x00, 0x12, 42}, []byte{0x12}},
+		TestCase{DataTypeLongOctetString, []byte{0xff, 0xff, 42}, nil},
+
 		TestCase{DataTypeCharacterString, []byte{0x05, 'H', 'e', 'l', 'l', 'o', 42}, "Hello"},
 		TestCase{DataTypeCharacterString, []byte{0xff, 42}, nil},
 
 		TestCase{DataTypeLongCharacterString, []byte{0x05, 0x00, 'H', 'e', 'l', 'l', 'o', 42}, "Hello"},
 		TestCase{DataTypeLongCharacterString, []byte{0xff, 0xff, 42}, nil},
+
+		TestCase{DataTypeStructure, []byte{0x02, 0x00, 0x10, 0x01, 0x21, 0x34, 0x12, 42}, []StructureElement{{DataTypeBool, true}, {DataTypeUint16, uint16(0x1234)}}},
+		TestCase{DataTypeStructure, []byte{0xff, 0xff, 42}, nil},
 	}
 
 	for index, testCase := range testCases {
@@ -177,3 +196,65 @@ func TestParseValueLongCharacterStringNotEnoughData2(t *testing.T) {
 		t.Fatal("expected ErrNotEnoughData:", err)
 	}
 }
+
+func TestSerializeValue(t *testing.T) {
+	type TestCase struct {
+		DataType DataType
+		Input    interface{}
+		Output   []byte
+	}
+
+	testCases := []TestCase{
+		TestCase{DataTypeNoData, nil, nil},
+		TestCase{DataTypeData24, []byte{1, 2, 3}, []byte{1, 2, 3}},
+
+		TestCase{DataTypeBool, false, []byte{0x00}},
+		TestCase{DataTypeBool, true, []byte{0x01}},
+		TestCase{DataTypeBool, nil, []byte{0xff}},
+
+		TestCase{DataTypeBitmap24, uint32(0xaabbcc), []byte{0xcc, 0xbb, 0xaa}},
+		TestCase{DataTypeUint16, uint16(0xaabb), []byte{0xbb, 0xaa}},
+		TestCase{DataTypeUint16, 0xaabb, []byte{0xbb, 0xaa}},
+		TestCase{DataTypeUint40, uint64(0xaabbccddee), []byte{0xee, 0xdd, 0xcc, 0xbb, 0xaa}},
+		TestCase{DataTypeUint8, nil, []byte{0xff}},
+		TestCase{DataTypeUint24, nil, []byte{0xff, 0xff, 0xff}},
+		TestCase{DataTypeEnum8, uint8(2), []byte{0x02}},
+		TestCase{DataTypeIEEEAddress, uint64(0x0102030405060708), []byte{0x08, 0x07, 0x06, 0x05, 0x04, 0x03, 0x02, 0x01}},
+
+		TestCase{DataTypeInt8, int8(-1), []byte{0xff}},
+		TestCase{DataTypeInt24, int32(-2), []byte{0xfe, 0xff, 0xff}},
+		TestCase{DataTypeInt16, nil, []byte{0x00, 0x80}},
+		TestCase{DataTypeInt48, nil, []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x80}},
+
+		TestCase{DataTypeFloat32, float32(0.125), []byte{0x00, 0x00, 0x00, 0x3e}},
+		TestCase{DataTypeFloat64, float64(0.125), []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xc0, 0x3f}},
+
+		TestCase{DataTypeOctetString, []byte{0x12, 0x34}, []byte{0x02, 0x12, 0x34}},
+		TestCase{DataTypeOctetString, nil, []byte{0xff}},
+		TestCase{DataTypeLongOctetString, []byte{0x12}, []byte{0x01, 0x00, 0x12}},
+
+		TestCase{DataTypeCharacterString, "Hello", []byte{0x05, 'H', 'e', 'l', 'l', 'o'}},
+		TestCase{DataTypeCharacterString, nil, []byte{0xff}},
+		TestCase{DataTypeLongCharacterString, "Hello", []byte{0x05, 0x00, 'H', 'e', 'l', 'l', 'o'}},
+		TestCase{DataTypeStructure, []StructureElement{{DataTypeBool, true}, {DataTypeUint16, uint16(0x1234)}}, []byte{0x02, 0x00, 0x10, 0x01, 0x21, 0x34, 0x12}},
+	}
+
+	for index, testCase := range testCases {
+		data, err := SerializeValue(testCase.DataType, testCase.Input)
+		if err != nil {
+			t.Errorf("(%d) unexpected err: %v", index, err)
+			continue
+		}
+		if !reflect.DeepEqual(data, testCase.Output) {
+			t.Errorf("(%d) wrong data: expected %v: %v", index, testCase.Output, data)
+		}
+	}
+}
+
+func TestSerializeValueInvalidData(t *testing.T) {
+	_, err := SerializeValue(DataTypeUint8, "Hello")
+
+	if !errors.Is(err, ErrInvalidData) {
+		t.Fatal("expected ErrInvalidData:", err)
+	}
+}
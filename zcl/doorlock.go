@@ -0,0 +1,1245 @@
+package zcl
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/GreenLightning/zigbee-conductor/pkg/scf"
+)
+
+// Attributes of the Door Lock cluster.
+const (
+	AttributeDoorLockLockState                    AttributeID = 0x0000
+	AttributeDoorLockLockType                     AttributeID = 0x0001
+	AttributeDoorLockActuatorEnabled              AttributeID = 0x0002
+	AttributeDoorLockDoorState                    AttributeID = 0x0003
+	AttributeDoorLockDoorOpenEvents               AttributeID = 0x0004
+	AttributeDoorLockDoorClosedEvents             AttributeID = 0x0005
+	AttributeDoorLockOpenPeriod                   AttributeID = 0x0006
+	AttributeDoorLockNumberOfLogRecordsSupported  AttributeID = 0x0010
+	AttributeDoorLockNumberOfTotalUsersSupported  AttributeID = 0x0011
+	AttributeDoorLockNumberOfPINUsersSupported    AttributeID = 0x0012
+	AttributeDoorLockNumberOfRFIDUsersSupported   AttributeID = 0x0013
+	AttributeDoorLockMaxPINCodeLength             AttributeID = 0x0017
+	AttributeDoorLockMinPINCodeLength             AttributeID = 0x0018
+	AttributeDoorLockMaxRFIDCodeLength            AttributeID = 0x0019
+	AttributeDoorLockMinRFIDCodeLength            AttributeID = 0x001a
+	AttributeDoorLockEnableLogging                AttributeID = 0x0020
+	AttributeDoorLockLanguage                     AttributeID = 0x0021
+	AttributeDoorLockLEDSettings                  AttributeID = 0x0022
+	AttributeDoorLockAutoRelockTime               AttributeID = 0x0023
+	AttributeDoorLockSoundVolume                  AttributeID = 0x0024
+	AttributeDoorLockOperatingMode                AttributeID = 0x0025
+	AttributeDoorLockWrongCodeEntryLimit          AttributeID = 0x0030
+	AttributeDoorLockUserCodeTemporaryDisableTime AttributeID = 0x0031
+	AttributeDoorLockSendPINOverTheAir            AttributeID = 0x0032
+	AttributeDoorLockRequirePINForRFOperation     AttributeID = 0x0033
+	AttributeDoorLockAlarmMask                    AttributeID = 0x0040
+)
+
+func init() {
+	registerAttributes(ClusterClosuresDoorLock,
+		AttributeDefinition{AttributeDoorLockLockState, "LockState", DataTypeEnum8},
+		AttributeDefinition{AttributeDoorLockLockType, "LockType", DataTypeEnum8},
+		AttributeDefinition{AttributeDoorLockActuatorEnabled, "ActuatorEnabled", DataTypeBool},
+		AttributeDefinition{AttributeDoorLockDoorState, "DoorState", DataTypeEnum8},
+		AttributeDefinition{AttributeDoorLockDoorOpenEvents, "DoorOpenEvents", DataTypeUint32},
+		AttributeDefinition{AttributeDoorLockDoorClosedEvents, "DoorClosedEvents", DataTypeUint32},
+		AttributeDefinition{AttributeDoorLockOpenPeriod, "OpenPeriod", DataTypeUint16},
+		AttributeDefinition{AttributeDoorLockNumberOfLogRecordsSupported, "NumberOfLogRecordsSupported", DataTypeUint16},
+		AttributeDefinition{AttributeDoorLockNumberOfTotalUsersSupported, "NumberOfTotalUsersSupported", DataTypeUint16},
+		AttributeDefinition{AttributeDoorLockNumberOfPINUsersSupported, "NumberOfPINUsersSupported", DataTypeUint16},
+		AttributeDefinition{AttributeDoorLockNumberOfRFIDUsersSupported, "NumberOfRFIDUsersSupported", DataTypeUint16},
+		AttributeDefinition{AttributeDoorLockMaxPINCodeLength, "MaxPINCodeLength", DataTypeUint8},
+		AttributeDefinition{AttributeDoorLockMinPINCodeLength, "MinPINCodeLength", DataTypeUint8},
+		AttributeDefinition{AttributeDoorLockMaxRFIDCodeLength, "MaxRFIDCodeLength", DataTypeUint8},
+		AttributeDefinition{AttributeDoorLockMinRFIDCodeLength, "MinRFIDCodeLength", DataTypeUint8},
+		AttributeDefinition{AttributeDoorLockEnableLogging, "EnableLogging", DataTypeBool},
+		AttributeDefinition{AttributeDoorLockLanguage, "Language", DataTypeCharacterString},
+		AttributeDefinition{AttributeDoorLockLEDSettings, "LEDSettings", DataTypeUint8},
+		AttributeDefinition{AttributeDoorLockAutoRelockTime, "AutoRelockTime", DataTypeUint32},
+		AttributeDefinition{AttributeDoorLockSoundVolume, "SoundVolume", DataTypeUint8},
+		AttributeDefinition{AttributeDoorLockOperatingMode, "OperatingMode", DataTypeEnum8},
+		AttributeDefinition{AttributeDoorLockWrongCodeEntryLimit, "WrongCodeEntryLimit", DataTypeUint8},
+		AttributeDefinition{AttributeDoorLockUserCodeTemporaryDisableTime, "UserCodeTemporaryDisableTime", DataTypeUint8},
+		AttributeDefinition{AttributeDoorLockSendPINOverTheAir, "SendPINOverTheAir", DataTypeBool},
+		AttributeDefinition{AttributeDoorLockRequirePINForRFOperation, "RequirePINForRFOperation", DataTypeBool},
+		AttributeDefinition{AttributeDoorLockAlarmMask, "AlarmMask", DataTypeBitmap16},
+	)
+	registerUnits(ClusterClosuresDoorLock, map[AttributeID]Unit{
+		AttributeDoorLockOpenPeriod:                   UnitMinutes,
+		AttributeDoorLockAutoRelockTime:               UnitSeconds,
+		AttributeDoorLockUserCodeTemporaryDisableTime: UnitSeconds,
+	})
+}
+
+// Values of the LockState attribute.
+const (
+	LockStateNotFullyLocked = 0x00
+	LockStateLocked         = 0x01
+	LockStateUnlocked       = 0x02
+)
+
+// Values of the DoorState attribute.
+const (
+	DoorStateOpen             = 0x00
+	DoorStateClosed           = 0x01
+	DoorStateErrorJammed      = 0x02
+	DoorStateErrorForcedOpen  = 0x03
+	DoorStateErrorUnspecified = 0x04
+)
+
+// Commands received by the server of the Door Lock cluster.
+const (
+	CommandDoorLockLockDoor          CommandID = 0x00
+	CommandDoorLockUnlockDoor        CommandID = 0x01
+	CommandDoorLockToggle            CommandID = 0x02
+	CommandDoorLockUnlockWithTimeout CommandID = 0x03
+	CommandDoorLockSetPINCode        CommandID = 0x05
+	CommandDoorLockGetPINCode        CommandID = 0x06
+	CommandDoorLockClearPINCode      CommandID = 0x07
+	CommandDoorLockClearAllPINCodes  CommandID = 0x08
+	CommandDoorLockSetUserStatus     CommandID = 0x09
+	CommandDoorLockGetUserStatus     CommandID = 0x0a
+	CommandDoorLockSetUserType       CommandID = 0x14
+	CommandDoorLockGetUserType       CommandID = 0x15
+	CommandDoorLockSetRFIDCode       CommandID = 0x16
+	CommandDoorLockGetRFIDCode       CommandID = 0x17
+	CommandDoorLockClearRFIDCode     CommandID = 0x18
+	CommandDoorLockClearAllRFIDCodes CommandID = 0x19
+)
+
+// Commands generated by the server of the Door Lock cluster.
+const (
+	CommandDoorLockLockDoorResponse             CommandID = 0x00
+	CommandDoorLockUnlockDoorResponse           CommandID = 0x01
+	CommandDoorLockToggleResponse               CommandID = 0x02
+	CommandDoorLockUnlockWithTimeoutResponse    CommandID = 0x03
+	CommandDoorLockSetPINCodeResponse           CommandID = 0x05
+	CommandDoorLockGetPINCodeResponse           CommandID = 0x06
+	CommandDoorLockClearPINCodeResponse         CommandID = 0x07
+	CommandDoorLockClearAllPINCodesResponse     CommandID = 0x08
+	CommandDoorLockSetUserStatusResponse        CommandID = 0x09
+	CommandDoorLockGetUserStatusResponse        CommandID = 0x0a
+	CommandDoorLockSetUserTypeResponse          CommandID = 0x14
+	CommandDoorLockGetUserTypeResponse          CommandID = 0x15
+	CommandDoorLockSetRFIDCodeResponse          CommandID = 0x16
+	CommandDoorLockGetRFIDCodeResponse          CommandID = 0x17
+	CommandDoorLockClearRFIDCodeResponse        CommandID = 0x18
+	CommandDoorLockClearAllRFIDCodesResponse    CommandID = 0x19
+	CommandDoorLockOperationEventNotification   CommandID = 0x20
+	CommandDoorLockProgrammingEventNotification CommandID = 0x21
+)
+
+func init() {
+	registerClusterCommand(new(LockDoorCommand))
+	registerClusterCommand(new(UnlockDoorCommand))
+	registerClusterCommand(new(DoorLockToggleCommand))
+	registerClusterCommand(new(UnlockWithTimeoutCommand))
+	registerClusterCommand(new(SetPINCodeCommand))
+	registerClusterCommand(new(GetPINCodeCommand))
+	registerClusterCommand(new(ClearPINCodeCommand))
+	registerClusterCommand(new(ClearAllPINCodesCommand))
+	registerClusterCommand(new(SetUserStatusCommand))
+	registerClusterCommand(new(GetUserStatusCommand))
+	registerClusterCommand(new(SetUserTypeCommand))
+	registerClusterCommand(new(GetUserTypeCommand))
+	registerClusterCommand(new(SetRFIDCodeCommand))
+	registerClusterCommand(new(GetRFIDCodeCommand))
+	registerClusterCommand(new(ClearRFIDCodeCommand))
+	registerClusterCommand(new(ClearAllRFIDCodesCommand))
+
+	registerClusterCommand(new(LockDoorResponseCommand))
+	registerClusterCommand(new(UnlockDoorResponseCommand))
+	registerClusterCommand(new(DoorLockToggleResponseCommand))
+	registerClusterCommand(new(UnlockWithTimeoutResponseCommand))
+	registerClusterCommand(new(SetPINCodeResponseCommand))
+	registerClusterCommand(new(GetPINCodeResponseCommand))
+	registerClusterCommand(new(ClearPINCodeResponseCommand))
+	registerClusterCommand(new(ClearAllPINCodesResponseCommand))
+	registerClusterCommand(new(SetUserStatusResponseCommand))
+	registerClusterCommand(new(GetUserStatusResponseCommand))
+	registerClusterCommand(new(SetUserTypeResponseCommand))
+	registerClusterCommand(new(GetUserTypeResponseCommand))
+	registerClusterCommand(new(SetRFIDCodeResponseCommand))
+	registerClusterCommand(new(GetRFIDCodeResponseCommand))
+	registerClusterCommand(new(ClearRFIDCodeResponseCommand))
+	registerClusterCommand(new(ClearAllRFIDCodesResponseCommand))
+	registerClusterCommand(new(OperationEventNotificationCommand))
+	registerClusterCommand(new(ProgrammingEventNotificationCommand))
+
+	registerClusterCommandResponse(new(LockDoorCommand), new(LockDoorResponseCommand))
+	registerClusterCommandResponse(new(UnlockDoorCommand), new(UnlockDoorResponseCommand))
+	registerClusterCommandResponse(new(DoorLockToggleCommand), new(DoorLockToggleResponseCommand))
+	registerClusterCommandResponse(new(UnlockWithTimeoutCommand), new(UnlockWithTimeoutResponseCommand))
+	registerClusterCommandResponse(new(SetPINCodeCommand), new(SetPINCodeResponseCommand))
+	registerClusterCommandResponse(new(GetPINCodeCommand), new(GetPINCodeResponseCommand))
+	registerClusterCommandResponse(new(ClearPINCodeCommand), new(ClearPINCodeResponseCommand))
+	registerClusterCommandResponse(new(ClearAllPINCodesCommand), new(ClearAllPINCodesResponseCommand))
+	registerClusterCommandResponse(new(SetUserStatusCommand), new(SetUserStatusResponseCommand))
+	registerClusterCommandResponse(new(GetUserStatusCommand), new(GetUserStatusResponseCommand))
+	registerClusterCommandResponse(new(SetUserTypeCommand), new(SetUserTypeResponseCommand))
+	registerClusterCommandResponse(new(GetUserTypeCommand), new(GetUserTypeResponseCommand))
+	registerClusterCommandResponse(new(SetRFIDCodeCommand), new(SetRFIDCodeResponseCommand))
+	registerClusterCommandResponse(new(GetRFIDCodeCommand), new(GetRFIDCodeResponseCommand))
+	registerClusterCommandResponse(new(ClearRFIDCodeCommand), new(ClearRFIDCodeResponseCommand))
+	registerClusterCommandResponse(new(ClearAllRFIDCodesCommand), new(ClearAllRFIDCodesResponseCommand))
+}
+
+type UserStatus uint8
+
+const (
+	UserStatusAvailable        UserStatus = 0x00
+	UserStatusOccupiedEnabled  UserStatus = 0x01
+	UserStatusOccupiedDisabled UserStatus = 0x03
+	UserStatusNotSupported     UserStatus = 0xff
+)
+
+type UserType uint8
+
+const (
+	UserTypeUnrestricted    UserType = 0x00
+	UserTypeYearDaySchedule UserType = 0x01
+	UserTypeWeekDaySchedule UserType = 0x02
+	UserTypeMaster          UserType = 0x03
+	UserTypeNonAccess       UserType = 0x04
+	UserTypeNotSupported    UserType = 0xff
+)
+
+// SetCodeStatus is returned by the Set PIN Code and Set RFID Code commands.
+type SetCodeStatus uint8
+
+const (
+	SetCodeStatusSuccess        SetCodeStatus = 0x00
+	SetCodeStatusGeneralFailure SetCodeStatus = 0x01
+	SetCodeStatusMemoryFull     SetCodeStatus = 0x02
+	SetCodeStatusDuplicateCode  SetCodeStatus = 0x03
+)
+
+// EventSource is the source of an operation or programming event.
+type EventSource uint8
+
+const (
+	EventSourceKeypad        EventSource = 0x00
+	EventSourceRF            EventSource = 0x01
+	EventSourceManual        EventSource = 0x02
+	EventSourceRFID          EventSource = 0x03
+	EventSourceIndeterminate EventSource = 0xff
+)
+
+func (source EventSource) String() string {
+	switch source {
+	case EventSourceKeypad:
+		return "Keypad"
+	case EventSourceRF:
+		return "RF"
+	case EventSourceManual:
+		return "Manual"
+	case EventSourceRFID:
+		return "RFID"
+	case EventSourceIndeterminate:
+		return "Indeterminate"
+	default:
+		return fmt.Sprintf("EventSource(0x%02x)", uint8(source))
+	}
+}
+
+type OperationEventCode uint8
+
+const (
+	OperationEventUnknown                      OperationEventCode = 0x00
+	OperationEventLock                         OperationEventCode = 0x01
+	OperationEventUnlock                       OperationEventCode = 0x02
+	OperationEventLockFailureInvalidCode       OperationEventCode = 0x03
+	OperationEventLockFailureInvalidSchedule   OperationEventCode = 0x04
+	OperationEventUnlockFailureInvalidCode     OperationEventCode = 0x05
+	OperationEventUnlockFailureInvalidSchedule OperationEventCode = 0x06
+	OperationEventOneTouchLock                 OperationEventCode = 0x07
+	OperationEventKeyLock                      OperationEventCode = 0x08
+	OperationEventKeyUnlock                    OperationEventCode = 0x09
+	OperationEventAutoLock                     OperationEventCode = 0x0a
+	OperationEventScheduleLock                 OperationEventCode = 0x0b
+	OperationEventScheduleUnlock               OperationEventCode = 0x0c
+	OperationEventManualLock                   OperationEventCode = 0x0d
+	OperationEventManualUnlock                 OperationEventCode = 0x0e
+	OperationEventNonAccessUser                OperationEventCode = 0x0f
+)
+
+var operationEventNames = []string{
+	"Unknown",
+	"Lock",
+	"Unlock",
+	"LockFailureInvalidCode",
+	"LockFailureInvalidSchedule",
+	"UnlockFailureInvalidCode",
+	"UnlockFailureInvalidSchedule",
+	"OneTouchLock",
+	"KeyLock",
+	"KeyUnlock",
+	"AutoLock",
+	"ScheduleLock",
+	"ScheduleUnlock",
+	"ManualLock",
+	"ManualUnlock",
+	"NonAccessUser",
+}
+
+func (code OperationEventCode) String() string {
+	if int(code) < len(operationEventNames) {
+		return operationEventNames[code]
+	}
+	return fmt.Sprintf("OperationEventCode(0x%02x)", uint8(code))
+}
+
+type ProgrammingEventCode uint8
+
+const (
+	ProgrammingEventUnknown           ProgrammingEventCode = 0x00
+	ProgrammingEventMasterCodeChanged ProgrammingEventCode = 0x01
+	ProgrammingEventPINCodeAdded      ProgrammingEventCode = 0x02
+	ProgrammingEventPINCodeDeleted    ProgrammingEventCode = 0x03
+	ProgrammingEventPINCodeChanged    ProgrammingEventCode = 0x04
+	ProgrammingEventRFIDCodeAdded     ProgrammingEventCode = 0x05
+	ProgrammingEventRFIDCodeDeleted   ProgrammingEventCode = 0x06
+)
+
+// PINCode is optional and only sent if it is not nil.
+type LockDoorCommand struct {
+	PINCode []byte
+}
+
+func (c *LockDoorCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *LockDoorCommand) CommandID() CommandID {
+	return CommandDoorLockLockDoor
+}
+
+func (c *LockDoorCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *LockDoorCommand) ParsePayload(data []byte) error {
+	var err error
+	c.PINCode, err = parseOptionalPINCode(data)
+	return err
+}
+
+func (c *LockDoorCommand) SerializePayload() []byte {
+	return appendOptionalPINCode(nil, c.PINCode)
+}
+
+// PINCode is optional and only sent if it is not nil.
+type UnlockDoorCommand struct {
+	PINCode []byte
+}
+
+func (c *UnlockDoorCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *UnlockDoorCommand) CommandID() CommandID {
+	return CommandDoorLockUnlockDoor
+}
+
+func (c *UnlockDoorCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *UnlockDoorCommand) ParsePayload(data []byte) error {
+	var err error
+	c.PINCode, err = parseOptionalPINCode(data)
+	return err
+}
+
+func (c *UnlockDoorCommand) SerializePayload() []byte {
+	return appendOptionalPINCode(nil, c.PINCode)
+}
+
+// PINCode is optional and only sent if it is not nil.
+type DoorLockToggleCommand struct {
+	PINCode []byte
+}
+
+func (c *DoorLockToggleCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *DoorLockToggleCommand) CommandID() CommandID {
+	return CommandDoorLockToggle
+}
+
+func (c *DoorLockToggleCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *DoorLockToggleCommand) ParsePayload(data []byte) error {
+	var err error
+	c.PINCode, err = parseOptionalPINCode(data)
+	return err
+}
+
+func (c *DoorLockToggleCommand) SerializePayload() []byte {
+	return appendOptionalPINCode(nil, c.PINCode)
+}
+
+// Timeout is specified in seconds.
+// PINCode is optional and only sent if it is not nil.
+type UnlockWithTimeoutCommand struct {
+	Timeout uint16
+	PINCode []byte
+}
+
+func (c *UnlockWithTimeoutCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *UnlockWithTimeoutCommand) CommandID() CommandID {
+	return CommandDoorLockUnlockWithTimeout
+}
+
+func (c *UnlockWithTimeoutCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *UnlockWithTimeoutCommand) ParsePayload(data []byte) error {
+	if len(data) < 2 {
+		return ErrNotEnoughData
+	}
+	c.Timeout = binary.LittleEndian.Uint16(data)
+	var err error
+	c.PINCode, err = parseOptionalPINCode(data[2:])
+	return err
+}
+
+func (c *UnlockWithTimeoutCommand) SerializePayload() []byte {
+	data := appendUint16(nil, c.Timeout)
+	return appendOptionalPINCode(data, c.PINCode)
+}
+
+type SetPINCodeCommand struct {
+	UserID     uint16
+	UserStatus UserStatus
+	UserType   UserType
+	PINCode    []byte
+}
+
+func (c *SetPINCodeCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *SetPINCodeCommand) CommandID() CommandID {
+	return CommandDoorLockSetPINCode
+}
+
+func (c *SetPINCodeCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *SetPINCodeCommand) ParsePayload(data []byte) error {
+	if len(data) < 4 {
+		return ErrNotEnoughData
+	}
+	c.UserID = binary.LittleEndian.Uint16(data)
+	c.UserStatus = UserStatus(data[2])
+	c.UserType = UserType(data[3])
+	var err error
+	c.PINCode, _, err = parseOctetString(data[4:])
+	return err
+}
+
+func (c *SetPINCodeCommand) SerializePayload() []byte {
+	data := appendUint16(nil, c.UserID)
+	data = append(data, byte(c.UserStatus), byte(c.UserType))
+	return appendOctetString(data, c.PINCode)
+}
+
+type GetPINCodeCommand struct {
+	UserID uint16
+}
+
+func (c *GetPINCodeCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *GetPINCodeCommand) CommandID() CommandID {
+	return CommandDoorLockGetPINCode
+}
+
+func (c *GetPINCodeCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *GetPINCodeCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *GetPINCodeCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type ClearPINCodeCommand struct {
+	UserID uint16
+}
+
+func (c *ClearPINCodeCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *ClearPINCodeCommand) CommandID() CommandID {
+	return CommandDoorLockClearPINCode
+}
+
+func (c *ClearPINCodeCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *ClearPINCodeCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *ClearPINCodeCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type ClearAllPINCodesCommand struct{}
+
+func (c *ClearAllPINCodesCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *ClearAllPINCodesCommand) CommandID() CommandID {
+	return CommandDoorLockClearAllPINCodes
+}
+
+func (c *ClearAllPINCodesCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *ClearAllPINCodesCommand) ParsePayload(data []byte) error {
+	return nil
+}
+
+func (c *ClearAllPINCodesCommand) SerializePayload() []byte {
+	return nil
+}
+
+type SetUserStatusCommand struct {
+	UserID     uint16
+	UserStatus UserStatus
+}
+
+func (c *SetUserStatusCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *SetUserStatusCommand) CommandID() CommandID {
+	return CommandDoorLockSetUserStatus
+}
+
+func (c *SetUserStatusCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *SetUserStatusCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *SetUserStatusCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type GetUserStatusCommand struct {
+	UserID uint16
+}
+
+func (c *GetUserStatusCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *GetUserStatusCommand) CommandID() CommandID {
+	return CommandDoorLockGetUserStatus
+}
+
+func (c *GetUserStatusCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *GetUserStatusCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *GetUserStatusCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type SetUserTypeCommand struct {
+	UserID   uint16
+	UserType UserType
+}
+
+func (c *SetUserTypeCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *SetUserTypeCommand) CommandID() CommandID {
+	return CommandDoorLockSetUserType
+}
+
+func (c *SetUserTypeCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *SetUserTypeCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *SetUserTypeCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type GetUserTypeCommand struct {
+	UserID uint16
+}
+
+func (c *GetUserTypeCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *GetUserTypeCommand) CommandID() CommandID {
+	return CommandDoorLockGetUserType
+}
+
+func (c *GetUserTypeCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *GetUserTypeCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *GetUserTypeCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type SetRFIDCodeCommand struct {
+	UserID     uint16
+	UserStatus UserStatus
+	UserType   UserType
+	RFIDCode   []byte
+}
+
+func (c *SetRFIDCodeCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *SetRFIDCodeCommand) CommandID() CommandID {
+	return CommandDoorLockSetRFIDCode
+}
+
+func (c *SetRFIDCodeCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *SetRFIDCodeCommand) ParsePayload(data []byte) error {
+	if len(data) < 4 {
+		return ErrNotEnoughData
+	}
+	c.UserID = binary.LittleEndian.Uint16(data)
+	c.UserStatus = UserStatus(data[2])
+	c.UserType = UserType(data[3])
+	var err error
+	c.RFIDCode, _, err = parseOctetString(data[4:])
+	return err
+}
+
+func (c *SetRFIDCodeCommand) SerializePayload() []byte {
+	data := appendUint16(nil, c.UserID)
+	data = append(data, byte(c.UserStatus), byte(c.UserType))
+	return appendOctetString(data, c.RFIDCode)
+}
+
+type GetRFIDCodeCommand struct {
+	UserID uint16
+}
+
+func (c *GetRFIDCodeCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *GetRFIDCodeCommand) CommandID() CommandID {
+	return CommandDoorLockGetRFIDCode
+}
+
+func (c *GetRFIDCodeCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *GetRFIDCodeCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *GetRFIDCodeCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type ClearRFIDCodeCommand struct {
+	UserID uint16
+}
+
+func (c *ClearRFIDCodeCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *ClearRFIDCodeCommand) CommandID() CommandID {
+	return CommandDoorLockClearRFIDCode
+}
+
+func (c *ClearRFIDCodeCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *ClearRFIDCodeCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *ClearRFIDCodeCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type ClearAllRFIDCodesCommand struct{}
+
+func (c *ClearAllRFIDCodesCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *ClearAllRFIDCodesCommand) CommandID() CommandID {
+	return CommandDoorLockClearAllRFIDCodes
+}
+
+func (c *ClearAllRFIDCodesCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *ClearAllRFIDCodesCommand) ParsePayload(data []byte) error {
+	return nil
+}
+
+func (c *ClearAllRFIDCodesCommand) SerializePayload() []byte {
+	return nil
+}
+
+type LockDoorResponseCommand struct {
+	Status Status
+}
+
+func (c *LockDoorResponseCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *LockDoorResponseCommand) CommandID() CommandID {
+	return CommandDoorLockLockDoorResponse
+}
+
+func (c *LockDoorResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *LockDoorResponseCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *LockDoorResponseCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type UnlockDoorResponseCommand struct {
+	Status Status
+}
+
+func (c *UnlockDoorResponseCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *UnlockDoorResponseCommand) CommandID() CommandID {
+	return CommandDoorLockUnlockDoorResponse
+}
+
+func (c *UnlockDoorResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *UnlockDoorResponseCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *UnlockDoorResponseCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type DoorLockToggleResponseCommand struct {
+	Status Status
+}
+
+func (c *DoorLockToggleResponseCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *DoorLockToggleResponseCommand) CommandID() CommandID {
+	return CommandDoorLockToggleResponse
+}
+
+func (c *DoorLockToggleResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *DoorLockToggleResponseCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *DoorLockToggleResponseCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type UnlockWithTimeoutResponseCommand struct {
+	Status Status
+}
+
+func (c *UnlockWithTimeoutResponseCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *UnlockWithTimeoutResponseCommand) CommandID() CommandID {
+	return CommandDoorLockUnlockWithTimeoutResponse
+}
+
+func (c *UnlockWithTimeoutResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *UnlockWithTimeoutResponseCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *UnlockWithTimeoutResponseCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type SetPINCodeResponseCommand struct {
+	Status SetCodeStatus
+}
+
+func (c *SetPINCodeResponseCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *SetPINCodeResponseCommand) CommandID() CommandID {
+	return CommandDoorLockSetPINCodeResponse
+}
+
+func (c *SetPINCodeResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *SetPINCodeResponseCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *SetPINCodeResponseCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+// Same format as SetPINCodeCommand.
+type GetPINCodeResponseCommand SetPINCodeCommand
+
+func (c *GetPINCodeResponseCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *GetPINCodeResponseCommand) CommandID() CommandID {
+	return CommandDoorLockGetPINCodeResponse
+}
+
+func (c *GetPINCodeResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *GetPINCodeResponseCommand) ParsePayload(data []byte) error {
+	return (*SetPINCodeCommand)(c).ParsePayload(data)
+}
+
+func (c *GetPINCodeResponseCommand) SerializePayload() []byte {
+	return (*SetPINCodeCommand)(c).SerializePayload()
+}
+
+type ClearPINCodeResponseCommand struct {
+	Status Status
+}
+
+func (c *ClearPINCodeResponseCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *ClearPINCodeResponseCommand) CommandID() CommandID {
+	return CommandDoorLockClearPINCodeResponse
+}
+
+func (c *ClearPINCodeResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *ClearPINCodeResponseCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *ClearPINCodeResponseCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type ClearAllPINCodesResponseCommand struct {
+	Status Status
+}
+
+func (c *ClearAllPINCodesResponseCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *ClearAllPINCodesResponseCommand) CommandID() CommandID {
+	return CommandDoorLockClearAllPINCodesResponse
+}
+
+func (c *ClearAllPINCodesResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *ClearAllPINCodesResponseCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *ClearAllPINCodesResponseCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type SetUserStatusResponseCommand struct {
+	Status Status
+}
+
+func (c *SetUserStatusResponseCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *SetUserStatusResponseCommand) CommandID() CommandID {
+	return CommandDoorLockSetUserStatusResponse
+}
+
+func (c *SetUserStatusResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *SetUserStatusResponseCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *SetUserStatusResponseCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type GetUserStatusResponseCommand struct {
+	UserID     uint16
+	UserStatus UserStatus
+}
+
+func (c *GetUserStatusResponseCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *GetUserStatusResponseCommand) CommandID() CommandID {
+	return CommandDoorLockGetUserStatusResponse
+}
+
+func (c *GetUserStatusResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *GetUserStatusResponseCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *GetUserStatusResponseCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type SetUserTypeResponseCommand struct {
+	Status Status
+}
+
+func (c *SetUserTypeResponseCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *SetUserTypeResponseCommand) CommandID() CommandID {
+	return CommandDoorLockSetUserTypeResponse
+}
+
+func (c *SetUserTypeResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *SetUserTypeResponseCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *SetUserTypeResponseCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type GetUserTypeResponseCommand struct {
+	UserID   uint16
+	UserType UserType
+}
+
+func (c *GetUserTypeResponseCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *GetUserTypeResponseCommand) CommandID() CommandID {
+	return CommandDoorLockGetUserTypeResponse
+}
+
+func (c *GetUserTypeResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *GetUserTypeResponseCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *GetUserTypeResponseCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type SetRFIDCodeResponseCommand struct {
+	Status SetCodeStatus
+}
+
+func (c *SetRFIDCodeResponseCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *SetRFIDCodeResponseCommand) CommandID() CommandID {
+	return CommandDoorLockSetRFIDCodeResponse
+}
+
+func (c *SetRFIDCodeResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *SetRFIDCodeResponseCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *SetRFIDCodeResponseCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+// Same format as SetRFIDCodeCommand.
+type GetRFIDCodeResponseCommand SetRFIDCodeCommand
+
+func (c *GetRFIDCodeResponseCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *GetRFIDCodeResponseCommand) CommandID() CommandID {
+	return CommandDoorLockGetRFIDCodeResponse
+}
+
+func (c *GetRFIDCodeResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *GetRFIDCodeResponseCommand) ParsePayload(data []byte) error {
+	return (*SetRFIDCodeCommand)(c).ParsePayload(data)
+}
+
+func (c *GetRFIDCodeResponseCommand) SerializePayload() []byte {
+	return (*SetRFIDCodeCommand)(c).SerializePayload()
+}
+
+type ClearRFIDCodeResponseCommand struct {
+	Status Status
+}
+
+func (c *ClearRFIDCodeResponseCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *ClearRFIDCodeResponseCommand) CommandID() CommandID {
+	return CommandDoorLockClearRFIDCodeResponse
+}
+
+func (c *ClearRFIDCodeResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *ClearRFIDCodeResponseCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *ClearRFIDCodeResponseCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type ClearAllRFIDCodesResponseCommand struct {
+	Status Status
+}
+
+func (c *ClearAllRFIDCodesResponseCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *ClearAllRFIDCodesResponseCommand) CommandID() CommandID {
+	return CommandDoorLockClearAllRFIDCodesResponse
+}
+
+func (c *ClearAllRFIDCodesResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *ClearAllRFIDCodesResponseCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *ClearAllRFIDCodesResponseCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+// LocalTime is specified in seconds since 2000-01-01 00:00:00 in local time.
+// Data is optional and may contain manufacturer-specific information.
+type OperationEventNotificationCommand struct {
+	EventSource EventSource
+	EventCode   OperationEventCode
+	UserID      uint16
+	PINCode     []byte
+	LocalTime   uint32
+	Data        string
+}
+
+func (c *OperationEventNotificationCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *OperationEventNotificationCommand) CommandID() CommandID {
+	return CommandDoorLockOperationEventNotification
+}
+
+func (c *OperationEventNotificationCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *OperationEventNotificationCommand) ParsePayload(data []byte) error {
+	if len(data) < 4 {
+		return ErrNotEnoughData
+	}
+	c.EventSource = EventSource(data[0])
+	c.EventCode = OperationEventCode(data[1])
+	c.UserID = binary.LittleEndian.Uint16(data[2:])
+	var err error
+	c.PINCode, data, err = parseOctetString(data[4:])
+	if err != nil {
+		return err
+	}
+	if len(data) < 4 {
+		return ErrNotEnoughData
+	}
+	c.LocalTime = binary.LittleEndian.Uint32(data)
+	c.Data = ""
+	if len(data) > 4 {
+		c.Data, _, err = parseCharacterString(data[4:])
+	}
+	return err
+}
+
+func (c *OperationEventNotificationCommand) SerializePayload() []byte {
+	data := []byte{byte(c.EventSource), byte(c.EventCode)}
+	data = appendUint16(data, c.UserID)
+	data = appendOctetString(data, c.PINCode)
+	data = appendUint32(data, c.LocalTime)
+	return appendCharacterString(data, c.Data)
+}
+
+// LocalTime is specified in seconds since 2000-01-01 00:00:00 in local time.
+// Data is optional and may contain manufacturer-specific information.
+type ProgrammingEventNotificationCommand struct {
+	EventSource EventSource
+	EventCode   ProgrammingEventCode
+	UserID      uint16
+	PINCode     []byte
+	UserType    UserType
+	UserStatus  UserStatus
+	LocalTime   uint32
+	Data        string
+}
+
+func (c *ProgrammingEventNotificationCommand) ClusterID() ClusterID {
+	return ClusterClosuresDoorLock
+}
+
+func (c *ProgrammingEventNotificationCommand) CommandID() CommandID {
+	return CommandDoorLockProgrammingEventNotification
+}
+
+func (c *ProgrammingEventNotificationCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *ProgrammingEventNotificationCommand) ParsePayload(data []byte) error {
+	if len(data) < 4 {
+		return ErrNotEnoughData
+	}
+	c.EventSource = EventSource(data[0])
+	c.EventCode = ProgrammingEventCode(data[1])
+	c.UserID = binary.LittleEndian.Uint16(data[2:])
+	var err error
+	c.PINCode, data, err = parseOctetString(data[4:])
+	if err != nil {
+		return err
+	}
+	if len(data) < 6 {
+		return ErrNotEnoughData
+	}
+	c.UserType = UserType(data[0])
+	c.UserStatus = UserStatus(data[1])
+	c.LocalTime = binary.LittleEndian.Uint32(data[2:])
+	c.Data = ""
+	if len(data) > 6 {
+		c.Data, _, err = parseCharacterString(data[6:])
+	}
+	return err
+}
+
+func (c *ProgrammingEventNotificationCommand) SerializePayload() []byte {
+	data := []byte{byte(c.EventSource), byte(c.EventCode)}
+	data = appendUint16(data, c.UserID)
+	data = appendOctetString(data, c.PINCode)
+	data = append(data, byte(c.UserType), byte(c.UserStatus))
+	data = appendUint32(data, c.LocalTime)
+	return appendCharacterString(data, c.Data)
+}
+
+// The PIN code of the lock and unlock commands is optional and only required
+// if the RequirePINForRFOperation attribute is set.
+func parseOptionalPINCode(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	code, _, err := parseOctetString(data)
+	return code, err
+}
+
+func appendOptionalPINCode(data []byte, code []byte) []byte {
+	if code == nil {
+		return data
+	}
+	return appendOctetString(data, code)
+}
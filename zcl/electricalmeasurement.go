@@ -0,0 +1,84 @@
+package zcl
+
+// Attributes of the Electrical Measurement cluster.
+const (
+	AttributeElectricalMeasurementMeasurementType       AttributeID = 0x0000
+	AttributeElectricalMeasurementDCVoltage             AttributeID = 0x0100
+	AttributeElectricalMeasurementDCCurrent             AttributeID = 0x0103
+	AttributeElectricalMeasurementDCPower               AttributeID = 0x0106
+	AttributeElectricalMeasurementDCVoltageMultiplier   AttributeID = 0x0200
+	AttributeElectricalMeasurementDCVoltageDivisor      AttributeID = 0x0201
+	AttributeElectricalMeasurementDCCurrentMultiplier   AttributeID = 0x0202
+	AttributeElectricalMeasurementDCCurrentDivisor      AttributeID = 0x0203
+	AttributeElectricalMeasurementDCPowerMultiplier     AttributeID = 0x0204
+	AttributeElectricalMeasurementDCPowerDivisor        AttributeID = 0x0205
+	AttributeElectricalMeasurementACFrequency           AttributeID = 0x0300
+	AttributeElectricalMeasurementTotalActivePower      AttributeID = 0x0304
+	AttributeElectricalMeasurementTotalReactivePower    AttributeID = 0x0305
+	AttributeElectricalMeasurementTotalApparentPower    AttributeID = 0x0306
+	AttributeElectricalMeasurementACFrequencyMultiplier AttributeID = 0x0400
+	AttributeElectricalMeasurementACFrequencyDivisor    AttributeID = 0x0401
+	AttributeElectricalMeasurementPowerMultiplier       AttributeID = 0x0402
+	AttributeElectricalMeasurementPowerDivisor          AttributeID = 0x0403
+	AttributeElectricalMeasurementLineCurrent           AttributeID = 0x0501
+	AttributeElectricalMeasurementRMSVoltage            AttributeID = 0x0505
+	AttributeElectricalMeasurementRMSVoltageMin         AttributeID = 0x0506
+	AttributeElectricalMeasurementRMSVoltageMax         AttributeID = 0x0507
+	AttributeElectricalMeasurementRMSCurrent            AttributeID = 0x0508
+	AttributeElectricalMeasurementRMSCurrentMin         AttributeID = 0x0509
+	AttributeElectricalMeasurementRMSCurrentMax         AttributeID = 0x050a
+	AttributeElectricalMeasurementActivePower           AttributeID = 0x050b
+	AttributeElectricalMeasurementActivePowerMin        AttributeID = 0x050c
+	AttributeElectricalMeasurementActivePowerMax        AttributeID = 0x050d
+	AttributeElectricalMeasurementReactivePower         AttributeID = 0x050e
+	AttributeElectricalMeasurementApparentPower         AttributeID = 0x050f
+	AttributeElectricalMeasurementPowerFactor           AttributeID = 0x0510
+	AttributeElectricalMeasurementACVoltageMultiplier   AttributeID = 0x0600
+	AttributeElectricalMeasurementACVoltageDivisor      AttributeID = 0x0601
+	AttributeElectricalMeasurementACCurrentMultiplier   AttributeID = 0x0602
+	AttributeElectricalMeasurementACCurrentDivisor      AttributeID = 0x0603
+	AttributeElectricalMeasurementACPowerMultiplier     AttributeID = 0x0604
+	AttributeElectricalMeasurementACPowerDivisor        AttributeID = 0x0605
+)
+
+func init() {
+	registerAttributes(ClusterMSElectricalMeasurement,
+		AttributeDefinition{AttributeElectricalMeasurementMeasurementType, "MeasurementType", DataTypeBitmap32},
+		AttributeDefinition{AttributeElectricalMeasurementDCVoltage, "DCVoltage", DataTypeInt16},
+		AttributeDefinition{AttributeElectricalMeasurementDCCurrent, "DCCurrent", DataTypeInt16},
+		AttributeDefinition{AttributeElectricalMeasurementDCPower, "DCPower", DataTypeInt16},
+		AttributeDefinition{AttributeElectricalMeasurementDCVoltageMultiplier, "DCVoltageMultiplier", DataTypeUint16},
+		AttributeDefinition{AttributeElectricalMeasurementDCVoltageDivisor, "DCVoltageDivisor", DataTypeUint16},
+		AttributeDefinition{AttributeElectricalMeasurementDCCurrentMultiplier, "DCCurrentMultiplier", DataTypeUint16},
+		AttributeDefinition{AttributeElectricalMeasurementDCCurrentDivisor, "DCCurrentDivisor", DataTypeUint16},
+		AttributeDefinition{AttributeElectricalMeasurementDCPowerMultiplier, "DCPowerMultiplier", DataTypeUint16},
+		AttributeDefinition{AttributeElectricalMeasurementDCPowerDivisor, "DCPowerDivisor", DataTypeUint16},
+		AttributeDefinition{AttributeElectricalMeasurementACFrequency, "ACFrequency", DataTypeUint16},
+		AttributeDefinition{AttributeElectricalMeasurementTotalActivePower, "TotalActivePower", DataTypeInt32},
+		AttributeDefinition{AttributeElectricalMeasurementTotalReactivePower, "TotalReactivePower", DataTypeInt32},
+		AttributeDefinition{AttributeElectricalMeasurementTotalApparentPower, "TotalApparentPower", DataTypeUint32},
+		AttributeDefinition{AttributeElectricalMeasurementACFrequencyMultiplier, "ACFrequencyMultiplier", DataTypeUint16},
+		AttributeDefinition{AttributeElectricalMeasurementACFrequencyDivisor, "ACFrequencyDivisor", DataTypeUint16},
+		AttributeDefinition{AttributeElectricalMeasurementPowerMultiplier, "PowerMultiplier", DataTypeUint32},
+		AttributeDefinition{AttributeElectricalMeasurementPowerDivisor, "PowerDivisor", DataTypeUint32},
+		AttributeDefinition{AttributeElectricalMeasurementLineCurrent, "LineCurrent", DataTypeUint16},
+		AttributeDefinition{AttributeElectricalMeasurementRMSVoltage, "RMSVoltage", DataTypeUint16},
+		AttributeDefinition{AttributeElectricalMeasurementRMSVoltageMin, "RMSVoltageMin", DataTypeUint16},
+		AttributeDefinition{AttributeElectricalMeasurementRMSVoltageMax, "RMSVoltageMax", DataTypeUint16},
+		AttributeDefinition{AttributeElectricalMeasurementRMSCurrent, "RMSCurrent", DataTypeUint16},
+		AttributeDefinition{AttributeElectricalMeasurementRMSCurrentMin, "RMSCurrentMin", DataTypeUint16},
+		AttributeDefinition{AttributeElectricalMeasurementRMSCurrentMax, "RMSCurrentMax", DataTypeUint16},
+		AttributeDefinition{AttributeElectricalMeasurementActivePower, "ActivePower", DataTypeInt16},
+		AttributeDefinition{AttributeElectricalMeasurementActivePowerMin, "ActivePowerMin", DataTypeInt16},
+		AttributeDefinition{AttributeElectricalMeasurementActivePowerMax, "ActivePowerMax", DataTypeInt16},
+		AttributeDefinition{AttributeElectricalMeasurementReactivePower, "ReactivePower", DataTypeInt16},
+		AttributeDefinition{AttributeElectricalMeasurementApparentPower, "ApparentPower", DataTypeUint16},
+		AttributeDefinition{AttributeElectricalMeasurementPowerFactor, "PowerFactor", DataTypeInt8},
+		AttributeDefinition{AttributeElectricalMeasurementACVoltageMultiplier, "ACVoltageMultiplier", DataTypeUint16},
+		AttributeDefinition{AttributeElectricalMeasurementACVoltageDivisor, "ACVoltageDivisor", DataTypeUint16},
+		AttributeDefinition{AttributeElectricalMeasurementACCurrentMultiplier, "ACCurrentMultiplier", DataTypeUint16},
+		AttributeDefinition{AttributeElectricalMeasurementACCurrentDivisor, "ACCurrentDivisor", DataTypeUint16},
+		AttributeDefinition{AttributeElectricalMeasurementACPowerMultiplier, "ACPowerMultiplier", DataTypeUint16},
+		AttributeDefinition{AttributeElectricalMeasurementACPowerDivisor, "ACPowerDivisor", DataTypeUint16},
+	)
+}
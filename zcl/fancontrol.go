@@ -0,0 +1,34 @@
+package zcl
+
+// Attributes of the Fan Control cluster.
+const (
+	AttributeFanControlFanMode         AttributeID = 0x0000
+	AttributeFanControlFanModeSequence AttributeID = 0x0001
+)
+
+func init() {
+	registerAttributes(ClusterHVACFanControl,
+		AttributeDefinition{AttributeFanControlFanMode, "FanMode", DataTypeEnum8},
+		AttributeDefinition{AttributeFanControlFanModeSequence, "FanModeSequence", DataTypeEnum8},
+	)
+}
+
+// Values of the FanMode attribute.
+const (
+	FanModeOff    = 0x00
+	FanModeLow    = 0x01
+	FanModeMedium = 0x02
+	FanModeHigh   = 0x03
+	FanModeOn     = 0x04
+	FanModeAuto   = 0x05
+	FanModeSmart  = 0x06
+)
+
+// Values of the FanModeSequence attribute.
+const (
+	FanModeSequenceLowMedHigh     = 0x00
+	FanModeSequenceLowHigh        = 0x01
+	FanModeSequenceLowMedHighAuto = 0x02
+	FanModeSequenceLowHighAuto    = 0x03
+	FanModeSequenceOnAuto         = 0x04
+)
@@ -83,15 +83,15 @@ func SerializeFrame(frame Frame) []byte {
 	data := make([]byte, 0, length)
 
 	var control byte
-	control &= byte(frame.Type) & 0b00011
+	control |= byte(frame.Type) & 0b00011
 	if frame.ManufacturerSpecific {
-		control &= 0b00100
+		control |= 0b00100
 	}
 	if frame.DirectionServerToClient {
-		control &= 0b01000
+		control |= 0b01000
 	}
 	if frame.DisableDefaultResponse {
-		control &= 0b10000
+		control |= 0b10000
 	}
 	data = append(data, control)
 
@@ -0,0 +1,340 @@
+package zcl
+
+import (
+	"encoding/binary"
+
+	"github.com/GreenLightning/zigbee-conductor/pkg/scf"
+)
+
+// Attributes of the Groups cluster.
+const (
+	AttributeGroupsNameSupport AttributeID = 0x0000
+)
+
+func init() {
+	registerAttributes(ClusterGeneralGroups,
+		AttributeDefinition{AttributeGroupsNameSupport, "NameSupport", DataTypeBitmap8},
+	)
+}
+
+// Commands received by the server of the Groups cluster.
+const (
+	CommandGroupsAddGroup              CommandID = 0x00
+	CommandGroupsViewGroup             CommandID = 0x01
+	CommandGroupsGetGroupMembership    CommandID = 0x02
+	CommandGroupsRemoveGroup           CommandID = 0x03
+	CommandGroupsRemoveAllGroups       CommandID = 0x04
+	CommandGroupsAddGroupIfIdentifying CommandID = 0x05
+)
+
+// Commands generated by the server of the Groups cluster.
+const (
+	CommandGroupsAddGroupResponse           CommandID = 0x00
+	CommandGroupsViewGroupResponse          CommandID = 0x01
+	CommandGroupsGetGroupMembershipResponse CommandID = 0x02
+	CommandGroupsRemoveGroupResponse        CommandID = 0x03
+)
+
+func init() {
+	registerClusterCommand(new(AddGroupCommand))
+	registerClusterCommand(new(ViewGroupCommand))
+	registerClusterCommand(new(GetGroupMembershipCommand))
+	registerClusterCommand(new(RemoveGroupCommand))
+	registerClusterCommand(new(RemoveAllGroupsCommand))
+	registerClusterCommand(new(AddGroupIfIdentifyingCommand))
+
+	registerClusterCommand(new(AddGroupResponseCommand))
+	registerClusterCommand(new(ViewGroupResponseCommand))
+	registerClusterCommand(new(GetGroupMembershipResponseCommand))
+	registerClusterCommand(new(RemoveGroupResponseCommand))
+
+	registerClusterCommandResponse(new(AddGroupCommand), new(AddGroupResponseCommand))
+	registerClusterCommandResponse(new(ViewGroupCommand), new(ViewGroupResponseCommand))
+	registerClusterCommandResponse(new(GetGroupMembershipCommand), new(GetGroupMembershipResponseCommand))
+	registerClusterCommandResponse(new(RemoveGroupCommand), new(RemoveGroupResponseCommand))
+}
+
+type AddGroupCommand struct {
+	GroupID   uint16
+	GroupName string
+}
+
+func (c *AddGroupCommand) ClusterID() ClusterID {
+	return ClusterGeneralGroups
+}
+
+func (c *AddGroupCommand) CommandID() CommandID {
+	return CommandGroupsAddGroup
+}
+
+func (c *AddGroupCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *AddGroupCommand) ParsePayload(data []byte) error {
+	var err error
+	c.GroupID, c.GroupName, err = parseGroupIDAndName(data)
+	return err
+}
+
+func (c *AddGroupCommand) SerializePayload() []byte {
+	data := appendUint16(nil, c.GroupID)
+	return appendCharacterString(data, c.GroupName)
+}
+
+type ViewGroupCommand struct {
+	GroupID uint16
+}
+
+func (c *ViewGroupCommand) ClusterID() ClusterID {
+	return ClusterGeneralGroups
+}
+
+func (c *ViewGroupCommand) CommandID() CommandID {
+	return CommandGroupsViewGroup
+}
+
+func (c *ViewGroupCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *ViewGroupCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *ViewGroupCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+// If GroupList is empty, the device responds with all groups it is a member of.
+// Otherwise it responds with the groups from GroupList that it is a member of.
+type GetGroupMembershipCommand struct {
+	GroupList []uint16
+}
+
+func (c *GetGroupMembershipCommand) ClusterID() ClusterID {
+	return ClusterGeneralGroups
+}
+
+func (c *GetGroupMembershipCommand) CommandID() CommandID {
+	return CommandGroupsGetGroupMembership
+}
+
+func (c *GetGroupMembershipCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *GetGroupMembershipCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *GetGroupMembershipCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type RemoveGroupCommand struct {
+	GroupID uint16
+}
+
+func (c *RemoveGroupCommand) ClusterID() ClusterID {
+	return ClusterGeneralGroups
+}
+
+func (c *RemoveGroupCommand) CommandID() CommandID {
+	return CommandGroupsRemoveGroup
+}
+
+func (c *RemoveGroupCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *RemoveGroupCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *RemoveGroupCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type RemoveAllGroupsCommand struct{}
+
+func (c *RemoveAllGroupsCommand) ClusterID() ClusterID {
+	return ClusterGeneralGroups
+}
+
+func (c *RemoveAllGroupsCommand) CommandID() CommandID {
+	return CommandGroupsRemoveAllGroups
+}
+
+func (c *RemoveAllGroupsCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *RemoveAllGroupsCommand) ParsePayload(data []byte) error {
+	return nil
+}
+
+func (c *RemoveAllGroupsCommand) SerializePayload() []byte {
+	return nil
+}
+
+// Same as AddGroupCommand, but only processed by devices that are currently identifying.
+// This command is usually broadcast and the devices do not send a response.
+type AddGroupIfIdentifyingCommand struct {
+	GroupID   uint16
+	GroupName string
+}
+
+func (c *AddGroupIfIdentifyingCommand) ClusterID() ClusterID {
+	return ClusterGeneralGroups
+}
+
+func (c *AddGroupIfIdentifyingCommand) CommandID() CommandID {
+	return CommandGroupsAddGroupIfIdentifying
+}
+
+func (c *AddGroupIfIdentifyingCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *AddGroupIfIdentifyingCommand) ParsePayload(data []byte) error {
+	var err error
+	c.GroupID, c.GroupName, err = parseGroupIDAndName(data)
+	return err
+}
+
+func (c *AddGroupIfIdentifyingCommand) SerializePayload() []byte {
+	data := appendUint16(nil, c.GroupID)
+	return appendCharacterString(data, c.GroupName)
+}
+
+type AddGroupResponseCommand struct {
+	Status  Status
+	GroupID uint16
+}
+
+func (c *AddGroupResponseCommand) ClusterID() ClusterID {
+	return ClusterGeneralGroups
+}
+
+func (c *AddGroupResponseCommand) CommandID() CommandID {
+	return CommandGroupsAddGroupResponse
+}
+
+func (c *AddGroupResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *AddGroupResponseCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *AddGroupResponseCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+// GroupName is only present if the status is StatusSuccess.
+type ViewGroupResponseCommand struct {
+	Status    Status
+	GroupID   uint16
+	GroupName string
+}
+
+func (c *ViewGroupResponseCommand) ClusterID() ClusterID {
+	return ClusterGeneralGroups
+}
+
+func (c *ViewGroupResponseCommand) CommandID() CommandID {
+	return CommandGroupsViewGroupResponse
+}
+
+func (c *ViewGroupResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *ViewGroupResponseCommand) ParsePayload(data []byte) error {
+	if len(data) < 1 {
+		return ErrNotEnoughData
+	}
+	c.Status = Status(data[0])
+	if c.Status != StatusSuccess {
+		if len(data) < 3 {
+			return ErrNotEnoughData
+		}
+		c.GroupID = binary.LittleEndian.Uint16(data[1:])
+		return nil
+	}
+	var err error
+	c.GroupID, c.GroupName, err = parseGroupIDAndName(data[1:])
+	return err
+}
+
+func (c *ViewGroupResponseCommand) SerializePayload() []byte {
+	data := []byte{byte(c.Status)}
+	data = appendUint16(data, c.GroupID)
+	if c.Status == StatusSuccess {
+		data = appendCharacterString(data, c.GroupName)
+	}
+	return data
+}
+
+// Capacity is the number of groups that can be added to the device
+// (0xfe means at least one, 0xff means unknown).
+type GetGroupMembershipResponseCommand struct {
+	Capacity  uint8
+	GroupList []uint16
+}
+
+func (c *GetGroupMembershipResponseCommand) ClusterID() ClusterID {
+	return ClusterGeneralGroups
+}
+
+func (c *GetGroupMembershipResponseCommand) CommandID() CommandID {
+	return CommandGroupsGetGroupMembershipResponse
+}
+
+func (c *GetGroupMembershipResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *GetGroupMembershipResponseCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *GetGroupMembershipResponseCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type RemoveGroupResponseCommand struct {
+	Status  Status
+	GroupID uint16
+}
+
+func (c *RemoveGroupResponseCommand) ClusterID() ClusterID {
+	return ClusterGeneralGroups
+}
+
+func (c *RemoveGroupResponseCommand) CommandID() CommandID {
+	return CommandGroupsRemoveGroupResponse
+}
+
+func (c *RemoveGroupResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *RemoveGroupResponseCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *RemoveGroupResponseCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+func parseGroupIDAndName(data []byte) (uint16, string, error) {
+	if len(data) < 2 {
+		return 0, "", ErrNotEnoughData
+	}
+	groupID := binary.LittleEndian.Uint16(data)
+	name, _, err := parseCharacterString(data[2:])
+	return groupID, name, err
+}
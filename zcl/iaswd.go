@@ -0,0 +1,137 @@
+package zcl
+
+import "encoding/binary"
+
+// Attributes of the IAS WD (warning device) cluster.
+const (
+	AttributeIASWDMaxDuration AttributeID = 0x0000
+)
+
+func init() {
+	registerAttributes(ClusterSecurityIASWD,
+		AttributeDefinition{AttributeIASWDMaxDuration, "MaxDuration", DataTypeUint16},
+	)
+}
+
+// Commands received by the server of the IAS WD cluster.
+const (
+	CommandIASWDStartWarning CommandID = 0x00
+	CommandIASWDSquawk       CommandID = 0x01
+)
+
+func init() {
+	registerClusterCommand(new(StartWarningCommand))
+	registerClusterCommand(new(SquawkCommand))
+}
+
+type WarningMode uint8
+
+const (
+	WarningModeStop           WarningMode = 0x0
+	WarningModeBurglar        WarningMode = 0x1
+	WarningModeFire           WarningMode = 0x2
+	WarningModeEmergency      WarningMode = 0x3
+	WarningModePolicePanic    WarningMode = 0x4
+	WarningModeFirePanic      WarningMode = 0x5
+	WarningModeEmergencyPanic WarningMode = 0x6
+)
+
+// SirenLevel is used for the siren level of the Start Warning command, the
+// squawk level of the Squawk command and the strobe level of the Start Warning command.
+type SirenLevel uint8
+
+const (
+	SirenLevelLow      SirenLevel = 0x0
+	SirenLevelMedium   SirenLevel = 0x1
+	SirenLevelHigh     SirenLevel = 0x2
+	SirenLevelVeryHigh SirenLevel = 0x3
+)
+
+// WarningDuration is specified in seconds.
+// StrobeDutyCycle is specified in multiples of 10 percent (from 0 to 100).
+type StartWarningCommand struct {
+	WarningMode     WarningMode
+	Strobe          bool
+	SirenLevel      SirenLevel
+	WarningDuration uint16
+	StrobeDutyCycle uint8
+	StrobeLevel     SirenLevel
+}
+
+func (c *StartWarningCommand) ClusterID() ClusterID {
+	return ClusterSecurityIASWD
+}
+
+func (c *StartWarningCommand) CommandID() CommandID {
+	return CommandIASWDStartWarning
+}
+
+func (c *StartWarningCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *StartWarningCommand) ParsePayload(data []byte) error {
+	if len(data) < 5 {
+		return ErrNotEnoughData
+	}
+	c.WarningMode = WarningMode(data[0] >> 4)
+	c.Strobe = (data[0]>>2)&0b11 != 0
+	c.SirenLevel = SirenLevel(data[0] & 0b11)
+	c.WarningDuration = binary.LittleEndian.Uint16(data[1:])
+	c.StrobeDutyCycle = data[3]
+	c.StrobeLevel = SirenLevel(data[4])
+	return nil
+}
+
+func (c *StartWarningCommand) SerializePayload() []byte {
+	info := byte(c.WarningMode&0b1111)<<4 | byte(c.SirenLevel&0b11)
+	if c.Strobe {
+		info |= 1 << 2
+	}
+	data := []byte{info}
+	data = appendUint16(data, c.WarningDuration)
+	return append(data, c.StrobeDutyCycle, byte(c.StrobeLevel))
+}
+
+type SquawkMode uint8
+
+const (
+	SquawkModeArmed    SquawkMode = 0x0
+	SquawkModeDisarmed SquawkMode = 0x1
+)
+
+type SquawkCommand struct {
+	SquawkMode  SquawkMode
+	Strobe      bool
+	SquawkLevel SirenLevel
+}
+
+func (c *SquawkCommand) ClusterID() ClusterID {
+	return ClusterSecurityIASWD
+}
+
+func (c *SquawkCommand) CommandID() CommandID {
+	return CommandIASWDSquawk
+}
+
+func (c *SquawkCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *SquawkCommand) ParsePayload(data []byte) error {
+	if len(data) < 1 {
+		return ErrNotEnoughData
+	}
+	c.SquawkMode = SquawkMode(data[0] >> 4)
+	c.Strobe = data[0]&(1<<3) != 0
+	c.SquawkLevel = SirenLevel(data[0] & 0b11)
+	return nil
+}
+
+func (c *SquawkCommand) SerializePayload() []byte {
+	info := byte(c.SquawkMode&0b1111)<<4 | byte(c.SquawkLevel&0b11)
+	if c.Strobe {
+		info |= 1 << 3
+	}
+	return []byte{info}
+}
@@ -0,0 +1,307 @@
+package zcl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GreenLightning/zigbee-conductor/pkg/scf"
+)
+
+// Attributes of the IAS Zone cluster.
+const (
+	AttributeIASZoneZoneState                              AttributeID = 0x0000
+	AttributeIASZoneZoneType                               AttributeID = 0x0001
+	AttributeIASZoneZoneStatus                             AttributeID = 0x0002
+	AttributeIASZoneIASCIEAddress                          AttributeID = 0x0010
+	AttributeIASZoneZoneID                                 AttributeID = 0x0011
+	AttributeIASZoneNumberOfZoneSensitivityLevelsSupported AttributeID = 0x0012
+	AttributeIASZoneCurrentZoneSensitivityLevel            AttributeID = 0x0013
+)
+
+func init() {
+	registerAttributes(ClusterSecurityIASZone,
+		AttributeDefinition{AttributeIASZoneZoneState, "ZoneState", DataTypeEnum8},
+		AttributeDefinition{AttributeIASZoneZoneType, "ZoneType", DataTypeEnum16},
+		AttributeDefinition{AttributeIASZoneZoneStatus, "ZoneStatus", DataTypeBitmap16},
+		AttributeDefinition{AttributeIASZoneIASCIEAddress, "IAS_CIE_Address", DataTypeIEEEAddress},
+		AttributeDefinition{AttributeIASZoneZoneID, "ZoneID", DataTypeUint8},
+		AttributeDefinition{AttributeIASZoneNumberOfZoneSensitivityLevelsSupported, "NumberOfZoneSensitivityLevelsSupported", DataTypeUint8},
+		AttributeDefinition{AttributeIASZoneCurrentZoneSensitivityLevel, "CurrentZoneSensitivityLevel", DataTypeUint8},
+	)
+}
+
+// Values of the ZoneState attribute.
+const (
+	ZoneStateNotEnrolled = 0x00
+	ZoneStateEnrolled    = 0x01
+)
+
+type ZoneType uint16
+
+const (
+	ZoneTypeStandardCIE             ZoneType = 0x0000
+	ZoneTypeMotionSensor            ZoneType = 0x000d
+	ZoneTypeContactSwitch           ZoneType = 0x0015
+	ZoneTypeFireSensor              ZoneType = 0x0028
+	ZoneTypeWaterSensor             ZoneType = 0x002a
+	ZoneTypeCarbonMonoxideSensor    ZoneType = 0x002b
+	ZoneTypePersonalEmergencyDevice ZoneType = 0x002c
+	ZoneTypeVibrationMovementSensor ZoneType = 0x002d
+	ZoneTypeRemoteControl           ZoneType = 0x010f
+	ZoneTypeKeyFob                  ZoneType = 0x0115
+	ZoneTypeKeypad                  ZoneType = 0x021d
+	ZoneTypeStandardWarningDevice   ZoneType = 0x0225
+	ZoneTypeGlassBreakSensor        ZoneType = 0x0226
+	ZoneTypeSecurityRepeater        ZoneType = 0x0229
+	ZoneTypeInvalid                 ZoneType = 0xffff
+)
+
+func (t ZoneType) String() string {
+	switch t {
+	case ZoneTypeStandardCIE:
+		return "StandardCIE"
+	case ZoneTypeMotionSensor:
+		return "MotionSensor"
+	case ZoneTypeContactSwitch:
+		return "ContactSwitch"
+	case ZoneTypeFireSensor:
+		return "FireSensor"
+	case ZoneTypeWaterSensor:
+		return "WaterSensor"
+	case ZoneTypeCarbonMonoxideSensor:
+		return "CarbonMonoxideSensor"
+	case ZoneTypePersonalEmergencyDevice:
+		return "PersonalEmergencyDevice"
+	case ZoneTypeVibrationMovementSensor:
+		return "VibrationMovementSensor"
+	case ZoneTypeRemoteControl:
+		return "RemoteControl"
+	case ZoneTypeKeyFob:
+		return "KeyFob"
+	case ZoneTypeKeypad:
+		return "Keypad"
+	case ZoneTypeStandardWarningDevice:
+		return "StandardWarningDevice"
+	case ZoneTypeGlassBreakSensor:
+		return "GlassBreakSensor"
+	case ZoneTypeSecurityRepeater:
+		return "SecurityRepeater"
+	case ZoneTypeInvalid:
+		return "Invalid"
+	default:
+		return fmt.Sprintf("ZoneType(0x%04x)", uint16(t))
+	}
+}
+
+// Commands received by the server of the IAS Zone cluster.
+const (
+	CommandIASZoneZoneEnrollResponse          CommandID = 0x00
+	CommandIASZoneInitiateNormalOperationMode CommandID = 0x01
+	CommandIASZoneInitiateTestMode            CommandID = 0x02
+)
+
+// Commands generated by the server of the IAS Zone cluster.
+const (
+	CommandIASZoneZoneStatusChangeNotification CommandID = 0x00
+	CommandIASZoneZoneEnrollRequest            CommandID = 0x01
+)
+
+func init() {
+	registerClusterCommand(new(ZoneEnrollResponseCommand))
+	registerClusterCommand(new(InitiateNormalOperationModeCommand))
+	registerClusterCommand(new(InitiateTestModeCommand))
+
+	registerClusterCommand(new(ZoneStatusChangeNotificationCommand))
+	registerClusterCommand(new(ZoneEnrollRequestCommand))
+
+	registerClusterCommandResponse(new(ZoneEnrollRequestCommand), new(ZoneEnrollResponseCommand))
+}
+
+// ZoneStatus is the bitmap reported by the ZoneStatus attribute and the Zone
+// Status Change Notification command.
+type ZoneStatus uint16
+
+const (
+	ZoneStatusAlarm1             ZoneStatus = 1 << 0
+	ZoneStatusAlarm2             ZoneStatus = 1 << 1
+	ZoneStatusTamper             ZoneStatus = 1 << 2
+	ZoneStatusBattery            ZoneStatus = 1 << 3 // low battery
+	ZoneStatusSupervisionReports ZoneStatus = 1 << 4
+	ZoneStatusRestoreReports     ZoneStatus = 1 << 5
+	ZoneStatusTrouble            ZoneStatus = 1 << 6
+	ZoneStatusACMains            ZoneStatus = 1 << 7 // AC (mains) fault
+	ZoneStatusTest               ZoneStatus = 1 << 8
+	ZoneStatusBatteryDefect      ZoneStatus = 1 << 9
+)
+
+var zoneStatusNames = []string{
+	"Alarm1",
+	"Alarm2",
+	"Tamper",
+	"Battery",
+	"SupervisionReports",
+	"RestoreReports",
+	"Trouble",
+	"ACMains",
+	"Test",
+	"BatteryDefect",
+}
+
+// The meaning of the alarm bits depends on the zone type (e.g. for a contact
+// switch Alarm1 means opened and for a fire sensor it means fire detected).
+func (s ZoneStatus) Alarm1() bool        { return s&ZoneStatusAlarm1 != 0 }
+func (s ZoneStatus) Alarm2() bool        { return s&ZoneStatusAlarm2 != 0 }
+func (s ZoneStatus) Tamper() bool        { return s&ZoneStatusTamper != 0 }
+func (s ZoneStatus) BatteryLow() bool    { return s&ZoneStatusBattery != 0 }
+func (s ZoneStatus) Trouble() bool       { return s&ZoneStatusTrouble != 0 }
+func (s ZoneStatus) ACMainsFault() bool  { return s&ZoneStatusACMains != 0 }
+func (s ZoneStatus) Test() bool          { return s&ZoneStatusTest != 0 }
+func (s ZoneStatus) BatteryDefect() bool { return s&ZoneStatusBatteryDefect != 0 }
+
+func (s ZoneStatus) String() string {
+	var names []string
+	for bit, name := range zoneStatusNames {
+		if s&(1<<bit) != 0 {
+			names = append(names, name)
+		}
+	}
+	if rest := s &^ (1<<len(zoneStatusNames) - 1); rest != 0 {
+		names = append(names, fmt.Sprintf("0x%04x", uint16(rest)))
+	}
+	return "[" + strings.Join(names, " ") + "]"
+}
+
+// Delay is the time in quarter-seconds since the status changed.
+type ZoneStatusChangeNotificationCommand struct {
+	ZoneStatus     ZoneStatus
+	ExtendedStatus uint8
+	ZoneID         uint8
+	Delay          uint16
+}
+
+func (c *ZoneStatusChangeNotificationCommand) ClusterID() ClusterID {
+	return ClusterSecurityIASZone
+}
+
+func (c *ZoneStatusChangeNotificationCommand) CommandID() CommandID {
+	return CommandIASZoneZoneStatusChangeNotification
+}
+
+func (c *ZoneStatusChangeNotificationCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *ZoneStatusChangeNotificationCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *ZoneStatusChangeNotificationCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type ZoneEnrollRequestCommand struct {
+	ZoneType         ZoneType
+	ManufacturerCode uint16
+}
+
+func (c *ZoneEnrollRequestCommand) ClusterID() ClusterID {
+	return ClusterSecurityIASZone
+}
+
+func (c *ZoneEnrollRequestCommand) CommandID() CommandID {
+	return CommandIASZoneZoneEnrollRequest
+}
+
+func (c *ZoneEnrollRequestCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *ZoneEnrollRequestCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *ZoneEnrollRequestCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type EnrollResponseCode uint8
+
+const (
+	EnrollResponseCodeSuccess        EnrollResponseCode = 0x00
+	EnrollResponseCodeNotSupported   EnrollResponseCode = 0x01
+	EnrollResponseCodeNoEnrollPermit EnrollResponseCode = 0x02
+	EnrollResponseCodeTooManyZones   EnrollResponseCode = 0x03
+)
+
+type ZoneEnrollResponseCommand struct {
+	EnrollResponseCode EnrollResponseCode
+	ZoneID             uint8
+}
+
+func (c *ZoneEnrollResponseCommand) ClusterID() ClusterID {
+	return ClusterSecurityIASZone
+}
+
+func (c *ZoneEnrollResponseCommand) CommandID() CommandID {
+	return CommandIASZoneZoneEnrollResponse
+}
+
+func (c *ZoneEnrollResponseCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *ZoneEnrollResponseCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *ZoneEnrollResponseCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type InitiateNormalOperationModeCommand struct{}
+
+func (c *InitiateNormalOperationModeCommand) ClusterID() ClusterID {
+	return ClusterSecurityIASZone
+}
+
+func (c *InitiateNormalOperationModeCommand) CommandID() CommandID {
+	return CommandIASZoneInitiateNormalOperationMode
+}
+
+func (c *InitiateNormalOperationModeCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *InitiateNormalOperationModeCommand) ParsePayload(data []byte) error {
+	return nil
+}
+
+func (c *InitiateNormalOperationModeCommand) SerializePayload() []byte {
+	return nil
+}
+
+// TestModeDuration is specified in seconds.
+type InitiateTestModeCommand struct {
+	TestModeDuration            uint8
+	CurrentZoneSensitivityLevel uint8
+}
+
+func (c *InitiateTestModeCommand) ClusterID() ClusterID {
+	return ClusterSecurityIASZone
+}
+
+func (c *InitiateTestModeCommand) CommandID() CommandID {
+	return CommandIASZoneInitiateTestMode
+}
+
+func (c *InitiateTestModeCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *InitiateTestModeCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *InitiateTestModeCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
@@ -0,0 +1,131 @@
+package zcl
+
+import "github.com/GreenLightning/zigbee-conductor/pkg/scf"
+
+// Commands received by the server of the Identify cluster.
+const (
+	CommandIdentifyIdentify      CommandID = 0x00
+	CommandIdentifyIdentifyQuery CommandID = 0x01
+	CommandIdentifyTriggerEffect CommandID = 0x40
+)
+
+// Commands generated by the server of the Identify cluster.
+const (
+	CommandIdentifyIdentifyQueryResponse CommandID = 0x00
+)
+
+func init() {
+	registerClusterCommand(new(IdentifyCommand))
+	registerClusterCommand(new(IdentifyQueryCommand))
+	registerClusterCommand(new(TriggerEffectCommand))
+	registerClusterCommand(new(IdentifyQueryResponseCommand))
+
+	registerClusterCommandResponse(new(IdentifyQueryCommand), new(IdentifyQueryResponseCommand))
+}
+
+// IdentifyTime is specified in seconds. A value of zero stops identifying.
+type IdentifyCommand struct {
+	IdentifyTime uint16
+}
+
+func (c *IdentifyCommand) ClusterID() ClusterID {
+	return ClusterGeneralIdentify
+}
+
+func (c *IdentifyCommand) CommandID() CommandID {
+	return CommandIdentifyIdentify
+}
+
+func (c *IdentifyCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *IdentifyCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *IdentifyCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type IdentifyQueryCommand struct{}
+
+func (c *IdentifyQueryCommand) ClusterID() ClusterID {
+	return ClusterGeneralIdentify
+}
+
+func (c *IdentifyQueryCommand) CommandID() CommandID {
+	return CommandIdentifyIdentifyQuery
+}
+
+func (c *IdentifyQueryCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *IdentifyQueryCommand) ParsePayload(data []byte) error {
+	return nil
+}
+
+func (c *IdentifyQueryCommand) SerializePayload() []byte {
+	return nil
+}
+
+// Effect identifiers for the Trigger Effect command.
+const (
+	EffectBlink         = 0x00
+	EffectBreathe       = 0x01
+	EffectOkay          = 0x02
+	EffectChannelChange = 0x0b
+	EffectFinishEffect  = 0xfe
+	EffectStopEffect    = 0xff
+)
+
+type TriggerEffectCommand struct {
+	EffectIdentifier uint8
+	EffectVariant    uint8
+}
+
+func (c *TriggerEffectCommand) ClusterID() ClusterID {
+	return ClusterGeneralIdentify
+}
+
+func (c *TriggerEffectCommand) CommandID() CommandID {
+	return CommandIdentifyTriggerEffect
+}
+
+func (c *TriggerEffectCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *TriggerEffectCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *TriggerEffectCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+// Timeout is the remaining identify time in seconds.
+type IdentifyQueryResponseCommand struct {
+	Timeout uint16
+}
+
+func (c *IdentifyQueryResponseCommand) ClusterID() ClusterID {
+	return ClusterGeneralIdentify
+}
+
+func (c *IdentifyQueryResponseCommand) CommandID() CommandID {
+	return CommandIdentifyIdentifyQueryResponse
+}
+
+func (c *IdentifyQueryResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *IdentifyQueryResponseCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *IdentifyQueryResponseCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
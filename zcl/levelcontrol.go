@@ -0,0 +1,236 @@
+package zcl
+
+import "github.com/GreenLightning/zigbee-conductor/pkg/scf"
+
+// Commands received by the server of the Level Control cluster.
+const (
+	CommandLevelControlMoveToLevel          CommandID = 0x00
+	CommandLevelControlMove                 CommandID = 0x01
+	CommandLevelControlStep                 CommandID = 0x02
+	CommandLevelControlStop                 CommandID = 0x03
+	CommandLevelControlMoveToLevelWithOnOff CommandID = 0x04
+	CommandLevelControlMoveWithOnOff        CommandID = 0x05
+	CommandLevelControlStepWithOnOff        CommandID = 0x06
+	CommandLevelControlStopWithOnOff        CommandID = 0x07
+)
+
+func init() {
+	registerClusterCommand(new(MoveToLevelCommand))
+	registerClusterCommand(new(MoveCommand))
+	registerClusterCommand(new(StepCommand))
+	registerClusterCommand(new(StopCommand))
+	registerClusterCommand(new(MoveToLevelWithOnOffCommand))
+	registerClusterCommand(new(MoveWithOnOffCommand))
+	registerClusterCommand(new(StepWithOnOffCommand))
+	registerClusterCommand(new(StopWithOnOffCommand))
+}
+
+// MoveMode specifies the direction of the Move and Step commands.
+type MoveMode uint8
+
+const (
+	MoveModeUp   MoveMode = 0x00
+	MoveModeDown MoveMode = 0x01
+)
+
+// TransitionTime is specified in tenths of a second.
+type MoveToLevelCommand struct {
+	Level          uint8
+	TransitionTime uint16
+}
+
+func (c *MoveToLevelCommand) ClusterID() ClusterID {
+	return ClusterGeneralLevelControl
+}
+
+func (c *MoveToLevelCommand) CommandID() CommandID {
+	return CommandLevelControlMoveToLevel
+}
+
+func (c *MoveToLevelCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *MoveToLevelCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *MoveToLevelCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+// Rate is specified in units per second.
+type MoveCommand struct {
+	MoveMode MoveMode
+	Rate     uint8
+}
+
+func (c *MoveCommand) ClusterID() ClusterID {
+	return ClusterGeneralLevelControl
+}
+
+func (c *MoveCommand) CommandID() CommandID {
+	return CommandLevelControlMove
+}
+
+func (c *MoveCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *MoveCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *MoveCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+// TransitionTime is specified in tenths of a second.
+type StepCommand struct {
+	StepMode       MoveMode
+	StepSize       uint8
+	TransitionTime uint16
+}
+
+func (c *StepCommand) ClusterID() ClusterID {
+	return ClusterGeneralLevelControl
+}
+
+func (c *StepCommand) CommandID() CommandID {
+	return CommandLevelControlStep
+}
+
+func (c *StepCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *StepCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *StepCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type StopCommand struct{}
+
+func (c *StopCommand) ClusterID() ClusterID {
+	return ClusterGeneralLevelControl
+}
+
+func (c *StopCommand) CommandID() CommandID {
+	return CommandLevelControlStop
+}
+
+func (c *StopCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *StopCommand) ParsePayload(data []byte) error {
+	return nil
+}
+
+func (c *StopCommand) SerializePayload() []byte {
+	return nil
+}
+
+// Same as MoveToLevelCommand, but also turns the device on or off.
+type MoveToLevelWithOnOffCommand struct {
+	Level          uint8
+	TransitionTime uint16
+}
+
+func (c *MoveToLevelWithOnOffCommand) ClusterID() ClusterID {
+	return ClusterGeneralLevelControl
+}
+
+func (c *MoveToLevelWithOnOffCommand) CommandID() CommandID {
+	return CommandLevelControlMoveToLevelWithOnOff
+}
+
+func (c *MoveToLevelWithOnOffCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *MoveToLevelWithOnOffCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *MoveToLevelWithOnOffCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+// Same as MoveCommand, but also turns the device on or off.
+type MoveWithOnOffCommand struct {
+	MoveMode MoveMode
+	Rate     uint8
+}
+
+func (c *MoveWithOnOffCommand) ClusterID() ClusterID {
+	return ClusterGeneralLevelControl
+}
+
+func (c *MoveWithOnOffCommand) CommandID() CommandID {
+	return CommandLevelControlMoveWithOnOff
+}
+
+func (c *MoveWithOnOffCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *MoveWithOnOffCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *MoveWithOnOffCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+// Same as StepCommand, but also turns the device on or off.
+type StepWithOnOffCommand struct {
+	StepMode       MoveMode
+	StepSize       uint8
+	TransitionTime uint16
+}
+
+func (c *StepWithOnOffCommand) ClusterID() ClusterID {
+	return ClusterGeneralLevelControl
+}
+
+func (c *StepWithOnOffCommand) CommandID() CommandID {
+	return CommandLevelControlStepWithOnOff
+}
+
+func (c *StepWithOnOffCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *StepWithOnOffCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *StepWithOnOffCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type StopWithOnOffCommand struct{}
+
+func (c *StopWithOnOffCommand) ClusterID() ClusterID {
+	return ClusterGeneralLevelControl
+}
+
+func (c *StopWithOnOffCommand) CommandID() CommandID {
+	return CommandLevelControlStopWithOnOff
+}
+
+func (c *StopWithOnOffCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *StopWithOnOffCommand) ParsePayload(data []byte) error {
+	return nil
+}
+
+func (c *StopWithOnOffCommand) SerializePayload() []byte {
+	return nil
+}
@@ -0,0 +1,186 @@
+// Package hue decodes the manufacturer-specific extensions used by Philips
+// Hue devices.
+//
+// Hue dimmer switches and the Hue smart button report button events using a
+// cluster-specific command of the manufacturer-specific cluster 0xfc00. Hue
+// motion sensors have additional manufacturer-specific attributes in the
+// standard clusters, for example the motion sensitivity in the Occupancy
+// Sensing cluster.
+package hue
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+)
+
+// ManufacturerCode is the manufacturer code used by Philips Hue devices
+// (registered as Philips, now Signify).
+const ManufacturerCode = 0x100b
+
+// ClusterID is the manufacturer-specific cluster of Hue switches.
+const ClusterID zcl.ClusterID = 0xfc00
+
+// Commands generated by the server of the Hue cluster.
+const (
+	CommandButtonEvent zcl.CommandID = 0x00
+)
+
+// Manufacturer-specific attributes of the standard clusters.
+const (
+	AttributeBasicLEDIndication             zcl.AttributeID = 0x0033 // Basic cluster, bool
+	AttributeOccupancySensingSensitivity    zcl.AttributeID = 0x0030 // Occupancy Sensing cluster, uint8
+	AttributeOccupancySensingSensitivityMax zcl.AttributeID = 0x0031 // Occupancy Sensing cluster, uint8, read-only
+)
+
+// Buttons of the Hue dimmer switch. The Hue smart button only has ButtonOn.
+const (
+	ButtonOn   uint8 = 1
+	ButtonUp   uint8 = 2
+	ButtonDown uint8 = 3
+	ButtonOff  uint8 = 4
+)
+
+// ButtonAction is the type of a button event as reported by the device.
+type ButtonAction uint8
+
+const (
+	ButtonActionPress        ButtonAction = 0x00
+	ButtonActionHold         ButtonAction = 0x01 // repeated while the button is held
+	ButtonActionShortRelease ButtonAction = 0x02 // released after a press
+	ButtonActionLongRelease  ButtonAction = 0x03 // released after a hold
+)
+
+func (a ButtonAction) String() string {
+	switch a {
+	case ButtonActionPress:
+		return "press"
+	case ButtonActionHold:
+		return "hold"
+	case ButtonActionShortRelease:
+		return "short release"
+	case ButtonActionLongRelease:
+		return "long release"
+	default:
+		return fmt.Sprintf("ButtonAction(0x%02x)", uint8(a))
+	}
+}
+
+// IsRelease reports whether the action is one of the release actions.
+func (a ButtonAction) IsRelease() bool {
+	return a == ButtonActionShortRelease || a == ButtonActionLongRelease
+}
+
+// ButtonEventCommand is sent by Hue switches whenever a button is pressed,
+// held or released.
+type ButtonEventCommand struct {
+	Button uint8
+	Action ButtonAction
+
+	// Duration is the time since the button was pressed in multiples of
+	// 1/8 second for hold and release actions.
+	Duration uint16
+}
+
+func (c *ButtonEventCommand) ClusterID() zcl.ClusterID {
+	return ClusterID
+}
+
+func (c *ButtonEventCommand) CommandID() zcl.CommandID {
+	return CommandButtonEvent
+}
+
+func (c *ButtonEventCommand) DirectionServerToClient() bool {
+	return true
+}
+
+// Payload format: button (uint8), unknown (3 bytes, 00 00 30 on the dimmer
+// switch), action (uint8), unknown (uint8, 0x21), duration (uint16).
+func (c *ButtonEventCommand) ParsePayload(data []byte) error {
+	if len(data) < 5 {
+		return zcl.ErrNotEnoughData
+	}
+	c.Button = data[0]
+	c.Action = ButtonAction(data[4])
+	c.Duration = 0
+	if len(data) >= 8 {
+		c.Duration = binary.LittleEndian.Uint16(data[6:])
+	}
+	return nil
+}
+
+func (c *ButtonEventCommand) SerializePayload() []byte {
+	data := []byte{c.Button, 0x00, 0x00, 0x30, byte(c.Action), 0x21, 0, 0}
+	binary.LittleEndian.PutUint16(data[6:], c.Duration)
+	return data
+}
+
+// ParseButtonEvent parses a button event frame of the Hue cluster. If the
+// frame does not contain a button event, zcl.ErrUnknownCommand is returned.
+func ParseButtonEvent(frame zcl.Frame) (ButtonEventCommand, error) {
+	var cmd ButtonEventCommand
+	if frame.Type != zcl.FrameTypeLocal || !frame.ManufacturerSpecific || frame.ManufacturerCode != ManufacturerCode ||
+		!frame.DirectionServerToClient || frame.CommandID != CommandButtonEvent {
+		return cmd, zcl.ErrUnknownCommand
+	}
+	err := cmd.ParsePayload(frame.Data)
+	return cmd, err
+}
+
+// Motion sensitivity levels of Hue motion sensors. Newer firmware supports
+// additional levels up to the value of AttributeOccupancySensingSensitivityMax.
+const (
+	SensitivityLow    uint8 = 0
+	SensitivityMedium uint8 = 1
+	SensitivityHigh   uint8 = 2
+)
+
+// MotionSensitivityFrame returns the manufacturer-specific Write Attributes
+// frame to change the motion sensitivity of a Hue motion sensor. The frame must
+// be sent to the Occupancy Sensing cluster on endpoint 2 of the sensor.
+func MotionSensitivityFrame(sensitivity uint8) zcl.Frame {
+	return writeAttributeFrame(AttributeOccupancySensingSensitivity, zcl.DataTypeUint8, sensitivity)
+}
+
+// LEDIndicationFrame returns the manufacturer-specific Write Attributes frame
+// to enable or disable the LED which flashes when a Hue motion sensor detects
+// motion. The frame must be sent to the Basic cluster on endpoint 2 of the
+// sensor.
+func LEDIndicationFrame(enabled bool) zcl.Frame {
+	return writeAttributeFrame(AttributeBasicLEDIndication, zcl.DataTypeBool, enabled)
+}
+
+// ReadAttributesFrame returns a manufacturer-specific Read Attributes frame for
+// the given attributes.
+func ReadAttributesFrame(attributes ...zcl.AttributeID) zcl.Frame {
+	return zcl.Frame{
+		FrameHeader: zcl.FrameHeader{
+			Type:                 zcl.FrameTypeGlobal,
+			ManufacturerSpecific: true,
+			ManufacturerCode:     ManufacturerCode,
+			CommandID:            zcl.CommandReadAttributes,
+		},
+		Data: zcl.SerializeReadAttributesCommand(zcl.ReadAttributesCommand{Attributes: attributes}),
+	}
+}
+
+func writeAttributeFrame(id zcl.AttributeID, typ zcl.DataType, value interface{}) zcl.Frame {
+	data, _ := zcl.SerializeWriteAttributesCommand(zcl.WriteAttributesCommand{
+		Records: []zcl.WriteAttributeRecord{{
+			AttributeID: id,
+			DataType:    typ,
+			Value:       value,
+		}},
+	})
+
+	return zcl.Frame{
+		FrameHeader: zcl.FrameHeader{
+			Type:                 zcl.FrameTypeGlobal,
+			ManufacturerSpecific: true,
+			ManufacturerCode:     ManufacturerCode,
+			CommandID:            zcl.CommandWriteAttributes,
+		},
+		Data: data,
+	}
+}
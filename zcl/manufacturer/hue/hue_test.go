@@ -0,0 +1,67 @@
+package hue
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+)
+
+func TestParseButtonEvent(t *testing.T) {
+	type TestCase struct {
+		Frame    string
+		Expected ButtonEventCommand
+	}
+
+	testCases := []TestCase{
+		// Dimmer switch, "on" pressed.
+		TestCase{"1d0b101200" + "0100003000210000", ButtonEventCommand{Button: ButtonOn, Action: ButtonActionPress}},
+		// Dimmer switch, "up" held for one second.
+		TestCase{"1d0b101300" + "0200003001210800", ButtonEventCommand{Button: ButtonUp, Action: ButtonActionHold, Duration: 8}},
+		// Dimmer switch, "off" released after a hold.
+		TestCase{"1d0b101400" + "0400003003210e00", ButtonEventCommand{Button: ButtonOff, Action: ButtonActionLongRelease, Duration: 14}},
+	}
+
+	for _, tc := range testCases {
+		data, err := hex.DecodeString(tc.Frame)
+		if err != nil {
+			t.Fatalf("%s: invalid hex: %v", tc.Frame, err)
+		}
+		frame, err := zcl.ParseFrame(data)
+		if err != nil {
+			t.Fatalf("%s: unexpected err: %v", tc.Frame, err)
+		}
+		cmd, err := ParseButtonEvent(frame)
+		if err != nil {
+			t.Fatalf("%s: unexpected err: %v", tc.Frame, err)
+		}
+		if cmd != tc.Expected {
+			t.Errorf("%s: got %+v, expected %+v", tc.Frame, cmd, tc.Expected)
+		}
+		if actual := hex.EncodeToString(cmd.SerializePayload()); actual != tc.Frame[10:] {
+			t.Errorf("%s: wrong payload: %s", tc.Frame, actual)
+		}
+	}
+
+	// Frames of other manufacturers are rejected.
+	frame := zcl.Frame{
+		FrameHeader: zcl.FrameHeader{
+			Type:                    zcl.FrameTypeLocal,
+			ManufacturerSpecific:    true,
+			DirectionServerToClient: true,
+			ManufacturerCode:        0x115f,
+			CommandID:               CommandButtonEvent,
+		},
+		Data: []byte{0x01, 0x00, 0x00, 0x30, 0x00},
+	}
+	if _, err := ParseButtonEvent(frame); err != zcl.ErrUnknownCommand {
+		t.Errorf("unexpected err: %v", err)
+	}
+}
+
+func TestMotionSensitivityFrame(t *testing.T) {
+	frame := MotionSensitivityFrame(SensitivityHigh)
+	if actual := hex.EncodeToString(zcl.SerializeFrame(frame)); actual != "040b1000023000"+"2002" {
+		t.Errorf("wrong frame: %s", actual)
+	}
+}
@@ -0,0 +1,127 @@
+// Package ikea decodes the non-standard commands sent by IKEA TRADFRI remotes.
+//
+// The five-button TRADFRI remote sends standard On/Off and Level Control
+// commands for the center and up/down buttons, but reports the left and right
+// arrow buttons using non-standard commands of the Scenes cluster.
+package ikea
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+)
+
+// ManufacturerCode is the manufacturer code used by IKEA devices.
+const ManufacturerCode = 0x117c
+
+// Non-standard commands received by the server of the Scenes cluster.
+const (
+	CommandScenesArrowClick   zcl.CommandID = 0x07
+	CommandScenesArrowHold    zcl.CommandID = 0x08
+	CommandScenesArrowRelease zcl.CommandID = 0x09
+)
+
+// Direction identifies an arrow button.
+type Direction uint8
+
+const (
+	DirectionRight Direction = 0x00
+	DirectionLeft  Direction = 0x01
+)
+
+func (d Direction) String() string {
+	switch d {
+	case DirectionRight:
+		return "right"
+	case DirectionLeft:
+		return "left"
+	default:
+		return fmt.Sprintf("Direction(0x%02x)", uint8(d))
+	}
+}
+
+// EventType is the type of a button event.
+type EventType uint8
+
+const (
+	EventPress EventType = iota
+	EventHold
+	EventRelease
+)
+
+func (e EventType) String() string {
+	switch e {
+	case EventPress:
+		return "press"
+	case EventHold:
+		return "hold"
+	case EventRelease:
+		return "release"
+	default:
+		return fmt.Sprintf("EventType(%d)", uint8(e))
+	}
+}
+
+// An ArrowEvent is a decoded arrow button event. The remote sends a press
+// event when an arrow button is clicked, or a hold event followed by a release
+// event when it is held.
+type ArrowEvent struct {
+	Type EventType
+
+	// Direction is unknown for release events (the remote only reports the
+	// duration). It is set to DirectionRight in this case.
+	Direction Direction
+
+	// Duration is the time the button was held (release events only).
+	Duration time.Duration
+}
+
+// ParseArrowEvent decodes an arrow button command sent to the Scenes cluster.
+// The frame may or may not be manufacturer-specific depending on the firmware
+// version of the remote. If the frame is not an arrow button command,
+// zcl.ErrUnknownCommand is returned.
+//
+// When the center button is held, the remote sends an arrow click command with
+// the value 2 in addition to the Level Control commands. This command is also
+// reported as zcl.ErrUnknownCommand.
+func ParseArrowEvent(frame zcl.Frame) (ArrowEvent, error) {
+	var event ArrowEvent
+	if frame.Type != zcl.FrameTypeLocal || frame.DirectionServerToClient ||
+		(frame.ManufacturerSpecific && frame.ManufacturerCode != ManufacturerCode) {
+		return event, zcl.ErrUnknownCommand
+	}
+
+	if len(frame.Data) < 2 {
+		switch frame.CommandID {
+		case CommandScenesArrowClick, CommandScenesArrowHold, CommandScenesArrowRelease:
+			return event, zcl.ErrNotEnoughData
+		default:
+			return event, zcl.ErrUnknownCommand
+		}
+	}
+
+	// The low byte of the first value is the direction, the high byte is
+	// 0x01 for clicks and 0x0d for holds.
+	value := binary.LittleEndian.Uint16(frame.Data)
+
+	switch frame.CommandID {
+	case CommandScenesArrowClick:
+		if value&0xff > uint16(DirectionLeft) {
+			return event, zcl.ErrUnknownCommand
+		}
+		event.Type = EventPress
+		event.Direction = Direction(value & 0xff)
+	case CommandScenesArrowHold:
+		event.Type = EventHold
+		event.Direction = Direction(value & 0xff)
+	case CommandScenesArrowRelease:
+		event.Type = EventRelease
+		event.Duration = time.Duration(value) * time.Millisecond
+	default:
+		return event, zcl.ErrUnknownCommand
+	}
+
+	return event, nil
+}
@@ -0,0 +1,54 @@
+package ikea
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+)
+
+func TestParseArrowEvent(t *testing.T) {
+	type TestCase struct {
+		Frame    string
+		Expected ArrowEvent
+	}
+
+	testCases := []TestCase{
+		TestCase{"0101070101" + "0d00", ArrowEvent{Type: EventPress, Direction: DirectionLeft}},
+		TestCase{"0102070001" + "0d00", ArrowEvent{Type: EventPress, Direction: DirectionRight}},
+		TestCase{"010308010d", ArrowEvent{Type: EventHold, Direction: DirectionLeft}},
+		TestCase{"010408000d", ArrowEvent{Type: EventHold, Direction: DirectionRight}},
+		TestCase{"057c110109" + "1105", ArrowEvent{Type: EventRelease, Duration: 1297 * time.Millisecond}},
+	}
+
+	for _, tc := range testCases {
+		data, err := hex.DecodeString(tc.Frame)
+		if err != nil {
+			t.Fatalf("%s: invalid hex: %v", tc.Frame, err)
+		}
+		frame, err := zcl.ParseFrame(data)
+		if err != nil {
+			t.Fatalf("%s: unexpected err: %v", tc.Frame, err)
+		}
+		event, err := ParseArrowEvent(frame)
+		if err != nil {
+			t.Fatalf("%s: unexpected err: %v", tc.Frame, err)
+		}
+		if event != tc.Expected {
+			t.Errorf("%s: got %+v, expected %+v", tc.Frame, event, tc.Expected)
+		}
+	}
+
+	// Toggle hold and standard Scenes commands are not arrow events.
+	for _, s := range []string{"0105070200" + "0000", "010605010002"} {
+		data, _ := hex.DecodeString(s)
+		frame, err := zcl.ParseFrame(data)
+		if err != nil {
+			t.Fatalf("%s: unexpected err: %v", s, err)
+		}
+		if _, err := ParseArrowEvent(frame); err != zcl.ErrUnknownCommand {
+			t.Errorf("%s: unexpected err: %v", s, err)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package tuya
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+)
+
+var ErrUnknownDatapoint = errors.New("unknown datapoint")
+
+// A Mapping describes the meaning of a datapoint for a specific device model.
+type Mapping struct {
+	ID   uint8
+	Name string
+	Type DataType
+
+	// Scale divides DataTypeValue datapoints (for example 10 if the device
+	// reports tenths of a degree). If zero, the value is returned as int32.
+	Scale float64
+
+	// Enum contains the names of the values of DataTypeEnum datapoints. If
+	// empty, the value is returned as uint8.
+	Enum []string
+}
+
+// A Model maps the datapoints of a device model to named values.
+type Model struct {
+	Name     string
+	Mappings []Mapping
+}
+
+func (m *Model) byID(id uint8) (Mapping, bool) {
+	for _, mapping := range m.Mappings {
+		if mapping.ID == id {
+			return mapping, true
+		}
+	}
+	return Mapping{}, false
+}
+
+func (m *Model) byName(name string) (Mapping, bool) {
+	for _, mapping := range m.Mappings {
+		if mapping.Name == name {
+			return mapping, true
+		}
+	}
+	return Mapping{}, false
+}
+
+// Decode returns the name and semantic value of a datapoint. Values are
+// float64 for scaled values and string for named enums; otherwise the value is
+// returned unchanged. If the datapoint is unknown or its type does not match
+// the mapping, ok is false.
+func (m *Model) Decode(dp Datapoint) (name string, value interface{}, ok bool) {
+	mapping, ok := m.byID(dp.ID)
+	if !ok || mapping.Type != dp.Type {
+		return "", nil, false
+	}
+
+	value = dp.Value
+	switch v := dp.Value.(type) {
+	case int32:
+		if mapping.Scale != 0 {
+			value = float64(v) / mapping.Scale
+		}
+	case uint8:
+		if mapping.Type == DataTypeEnum && len(mapping.Enum) != 0 {
+			if int(v) >= len(mapping.Enum) {
+				return "", nil, false
+			}
+			value = mapping.Enum[v]
+		}
+	}
+	return mapping.Name, value, true
+}
+
+// Encode creates a datapoint from a name and semantic value. It accepts the
+// values returned by Decode as well as the raw Go types of the data type.
+// Numeric values are converted as necessary.
+func (m *Model) Encode(name string, value interface{}) (Datapoint, error) {
+	mapping, ok := m.byName(name)
+	if !ok {
+		return Datapoint{}, fmt.Errorf("%w: %s", ErrUnknownDatapoint, name)
+	}
+
+	dp := Datapoint{ID: mapping.ID, Type: mapping.Type}
+	invalid := fmt.Errorf("datapoint %s: invalid value %v (%T)", name, value, value)
+
+	switch mapping.Type {
+	case DataTypeValue:
+		var f float64
+		switch v := value.(type) {
+		case int32:
+			dp.Value = v
+			return dp, nil
+		case int:
+			f = float64(v)
+		case float64:
+			f = v
+		default:
+			return dp, invalid
+		}
+		if mapping.Scale != 0 {
+			f *= mapping.Scale
+		}
+		f = math.Round(f)
+		if f < math.MinInt32 || f > math.MaxInt32 {
+			return dp, invalid
+		}
+		dp.Value = int32(f)
+
+	case DataTypeEnum:
+		switch v := value.(type) {
+		case uint8:
+			dp.Value = v
+		case int:
+			if v < 0 || v > math.MaxUint8 {
+				return dp, invalid
+			}
+			dp.Value = uint8(v)
+		case string:
+			for index, s := range mapping.Enum {
+				if s == v {
+					dp.Value = uint8(index)
+				}
+			}
+			if dp.Value == nil {
+				return dp, invalid
+			}
+		default:
+			return dp, invalid
+		}
+
+	default:
+		dp.Value = value
+	}
+
+	// Let AppendDatapoint check the remaining types.
+	if _, err := AppendDatapoint(nil, dp); err != nil {
+		return dp, err
+	}
+	return dp, nil
+}
+
+var (
+	modelsMutex sync.RWMutex
+	models      = make(map[string]*Model)
+)
+
+// RegisterModel registers the datapoint mapping for devices with the given
+// manufacturer name (the ManufacturerName attribute of the Basic cluster, for
+// example "_TZE200_ckud7u2l"). Tuya devices share the same model identifier,
+// so the manufacturer name identifies the device model.
+func RegisterModel(manufacturerName string, model *Model) {
+	modelsMutex.Lock()
+	defer modelsMutex.Unlock()
+	models[manufacturerName] = model
+}
+
+// LookupModel returns the model registered for the manufacturer name or nil.
+func LookupModel(manufacturerName string) *Model {
+	modelsMutex.RLock()
+	defer modelsMutex.RUnlock()
+	return models[manufacturerName]
+}
@@ -0,0 +1,97 @@
+package tuya
+
+import (
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/dispatch"
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+// TimeSyncHandler answers time synchronization requests of Tuya devices.
+// Devices with a clock (thermostats, displays) send a request after joining
+// and then periodically.
+type TimeSyncHandler struct {
+	dispatcher *dispatch.Dispatcher
+
+	// Location determines the local time sent to the devices. Defaults to time.Local.
+	Location *time.Location
+
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewTimeSyncHandler creates a TimeSyncHandler and registers it as a handler
+// with the dispatcher.
+func NewTimeSyncHandler(dispatcher *dispatch.Dispatcher) *TimeSyncHandler {
+	handler := &TimeSyncHandler{
+		dispatcher: dispatcher,
+		Location:   time.Local,
+		Now:        time.Now,
+	}
+	dispatcher.AddHandler(handler)
+	return handler
+}
+
+// Response returns the time synchronization response for the given time.
+func (h *TimeSyncHandler) Response(sequence uint16, now time.Time) TimeSyncResponseCommand {
+	location := h.Location
+	if location == nil {
+		location = time.Local
+	}
+	_, offset := now.In(location).Zone()
+	return TimeSyncResponseCommand{
+		Sequence:  sequence,
+		UTCTime:   uint32(now.Unix()),
+		LocalTime: uint32(now.Unix() + int64(offset)),
+	}
+}
+
+// HandleMessage answers time synchronization requests.
+func (h *TimeSyncHandler) HandleMessage(message zigbee.IncomingMessage) bool {
+	if message.ClusterID != uint16(ClusterID) {
+		return false
+	}
+
+	frame, err := zcl.ParseFrame(message.Data)
+	if err != nil {
+		return false
+	}
+
+	if frame.Type != zcl.FrameTypeLocal || !frame.DirectionServerToClient || frame.CommandID != CommandTimeSyncRequest {
+		return false
+	}
+
+	var request TimeSyncRequestCommand
+	if err := request.ParsePayload(frame.Data); err != nil {
+		return false
+	}
+
+	now := time.Now
+	if h.Now != nil {
+		now = h.Now
+	}
+	response := h.Response(request.Sequence, now())
+
+	outgoing := zigbee.OutgoingMessage{
+		Destination:         zigbee.Address{Mode: zigbee.AddressModeNWK, Short: message.Source.Short},
+		DestinationEndpoint: message.SourceEndpoint,
+		SourceEndpoint:      message.DestinationEndpoint,
+		ClusterID:           uint16(ClusterID),
+		Radius:              zigbee.DefaultRadius,
+		Data: zcl.SerializeFrame(zcl.Frame{
+			FrameHeader: zcl.FrameHeader{
+				Type:                   zcl.FrameTypeLocal,
+				DisableDefaultResponse: true,
+				TransSeqNumber:         frame.TransSeqNumber,
+				CommandID:              CommandTimeSyncResponse,
+			},
+			Data: response.SerializePayload(),
+		}),
+	}
+
+	// Must not block the dispatcher.
+	go h.dispatcher.Send(outgoing)
+
+	return true
+}
@@ -0,0 +1,386 @@
+// Package tuya implements the datapoint protocol used by Tuya devices.
+//
+// Many Tuya devices (identified by the model TS0601) do not use the standard
+// clusters. Instead, all values are transferred as datapoints using
+// cluster-specific commands of the manufacturer-specific cluster 0xef00.
+// A datapoint consists of an ID (whose meaning depends on the device model),
+// a type and a big-endian value.
+package tuya
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+)
+
+// ClusterID is the manufacturer-specific cluster used for datapoints.
+const ClusterID zcl.ClusterID = 0xef00
+
+// Commands received by the device (server) on the Tuya cluster.
+const (
+	CommandSetData          zcl.CommandID = 0x00
+	CommandQueryData        zcl.CommandID = 0x03
+	CommandTimeSyncResponse zcl.CommandID = 0x24
+)
+
+// Commands generated by the device (server) on the Tuya cluster.
+const (
+	CommandDataResponse    zcl.CommandID = 0x01
+	CommandDataReport      zcl.CommandID = 0x02
+	CommandTimeSyncRequest zcl.CommandID = 0x24
+)
+
+// DataType is the type of a datapoint.
+type DataType uint8
+
+const (
+	DataTypeRaw    DataType = 0x00 // []byte
+	DataTypeBool   DataType = 0x01 // bool
+	DataTypeValue  DataType = 0x02 // int32
+	DataTypeString DataType = 0x03 // string
+	DataTypeEnum   DataType = 0x04 // uint8
+	DataTypeBitmap DataType = 0x05 // uint8, uint16 or uint32
+)
+
+func (typ DataType) String() string {
+	switch typ {
+	case DataTypeRaw:
+		return "raw"
+	case DataTypeBool:
+		return "bool"
+	case DataTypeValue:
+		return "value"
+	case DataTypeString:
+		return "string"
+	case DataTypeEnum:
+		return "enum"
+	case DataTypeBitmap:
+		return "bitmap"
+	default:
+		return fmt.Sprintf("DataType(0x%02x)", uint8(typ))
+	}
+}
+
+// A Datapoint is a single value of a device. The Go type of Value depends on
+// the data type (see the DataType constants).
+type Datapoint struct {
+	ID    uint8
+	Type  DataType
+	Value interface{}
+}
+
+// ParseDatapoints parses a sequence of datapoints.
+func ParseDatapoints(data []byte) ([]Datapoint, error) {
+	var datapoints []Datapoint
+	for len(data) != 0 {
+		if len(data) < 4 {
+			return datapoints, zcl.ErrNotEnoughData
+		}
+		dp := Datapoint{ID: data[0], Type: DataType(data[1])}
+		length := int(binary.BigEndian.Uint16(data[2:]))
+		data = data[4:]
+		if len(data) < length {
+			return datapoints, zcl.ErrNotEnoughData
+		}
+		value := data[:length]
+		data = data[length:]
+
+		switch dp.Type {
+		case DataTypeRaw:
+			dp.Value = append([]byte(nil), value...)
+		case DataTypeBool:
+			if length != 1 {
+				return datapoints, fmt.Errorf("%w: datapoint %d: bool of length %d", zcl.ErrInvalidData, dp.ID, length)
+			}
+			dp.Value = value[0] != 0
+		case DataTypeValue:
+			if length != 4 {
+				return datapoints, fmt.Errorf("%w: datapoint %d: value of length %d", zcl.ErrInvalidData, dp.ID, length)
+			}
+			dp.Value = int32(binary.BigEndian.Uint32(value))
+		case DataTypeString:
+			dp.Value = string(value)
+		case DataTypeEnum:
+			if length != 1 {
+				return datapoints, fmt.Errorf("%w: datapoint %d: enum of length %d", zcl.ErrInvalidData, dp.ID, length)
+			}
+			dp.Value = value[0]
+		case DataTypeBitmap:
+			switch length {
+			case 1:
+				dp.Value = value[0]
+			case 2:
+				dp.Value = binary.BigEndian.Uint16(value)
+			case 4:
+				dp.Value = binary.BigEndian.Uint32(value)
+			default:
+				return datapoints, fmt.Errorf("%w: datapoint %d: bitmap of length %d", zcl.ErrInvalidData, dp.ID, length)
+			}
+		default:
+			return datapoints, fmt.Errorf("%w: datapoint %d: unknown type %v", zcl.ErrInvalidData, dp.ID, dp.Type)
+		}
+
+		datapoints = append(datapoints, dp)
+	}
+	return datapoints, nil
+}
+
+// AppendDatapoint appends the binary representation of a datapoint.
+func AppendDatapoint(data []byte, dp Datapoint) ([]byte, error) {
+	var value []byte
+	ok := false
+
+	switch dp.Type {
+	case DataTypeRaw:
+		value, ok = dp.Value.([]byte)
+	case DataTypeBool:
+		var b bool
+		if b, ok = dp.Value.(bool); ok && b {
+			value = []byte{1}
+		} else {
+			value = []byte{0}
+		}
+	case DataTypeValue:
+		var v int32
+		if v, ok = dp.Value.(int32); ok {
+			value = make([]byte, 4)
+			binary.BigEndian.PutUint32(value, uint32(v))
+		}
+	case DataTypeString:
+		var s string
+		s, ok = dp.Value.(string)
+		value = []byte(s)
+	case DataTypeEnum:
+		var v uint8
+		v, ok = dp.Value.(uint8)
+		value = []byte{v}
+	case DataTypeBitmap:
+		switch v := dp.Value.(type) {
+		case uint8:
+			value, ok = []byte{v}, true
+		case uint16:
+			value, ok = make([]byte, 2), true
+			binary.BigEndian.PutUint16(value, v)
+		case uint32:
+			value, ok = make([]byte, 4), true
+			binary.BigEndian.PutUint32(value, v)
+		}
+	}
+
+	if !ok || len(value) > 0xffff {
+		return data, fmt.Errorf("%w: datapoint %d: cannot serialize %T as %v", zcl.ErrInvalidData, dp.ID, dp.Value, dp.Type)
+	}
+
+	data = append(data, dp.ID, byte(dp.Type), byte(len(value)>>8), byte(len(value)))
+	return append(data, value...), nil
+}
+
+// ParseCommand parses the payload of a frame of the Tuya cluster. If the
+// command is not known, zcl.ErrUnknownCommand is returned.
+func ParseCommand(frame zcl.Frame) (zcl.ClusterCommand, error) {
+	if frame.Type != zcl.FrameTypeLocal {
+		return nil, zcl.ErrUnknownCommand
+	}
+
+	var command zcl.ClusterCommand
+	switch {
+	case !frame.DirectionServerToClient && frame.CommandID == CommandSetData:
+		command = new(SetDataCommand)
+	case !frame.DirectionServerToClient && frame.CommandID == CommandQueryData:
+		command = new(QueryDataCommand)
+	case !frame.DirectionServerToClient && frame.CommandID == CommandTimeSyncResponse:
+		command = new(TimeSyncResponseCommand)
+	case frame.DirectionServerToClient && frame.CommandID == CommandDataResponse:
+		command = new(DataResponseCommand)
+	case frame.DirectionServerToClient && frame.CommandID == CommandDataReport:
+		command = new(DataReportCommand)
+	case frame.DirectionServerToClient && frame.CommandID == CommandTimeSyncRequest:
+		command = new(TimeSyncRequestCommand)
+	default:
+		return nil, zcl.ErrUnknownCommand
+	}
+
+	if err := command.ParsePayload(frame.Data); err != nil {
+		return nil, err
+	}
+	return command, nil
+}
+
+// SetDataCommand changes datapoints of the device. The sequence number is
+// echoed by the device in the following DataResponseCommand.
+type SetDataCommand struct {
+	Sequence   uint16
+	Datapoints []Datapoint
+}
+
+func (c *SetDataCommand) ClusterID() zcl.ClusterID {
+	return ClusterID
+}
+
+func (c *SetDataCommand) CommandID() zcl.CommandID {
+	return CommandSetData
+}
+
+func (c *SetDataCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *SetDataCommand) ParsePayload(data []byte) error {
+	if len(data) < 2 {
+		return zcl.ErrNotEnoughData
+	}
+	c.Sequence = binary.BigEndian.Uint16(data)
+	var err error
+	c.Datapoints, err = ParseDatapoints(data[2:])
+	return err
+}
+
+// SerializePayload skips datapoints which cannot be serialized. Use
+// AppendDatapoint to check the datapoints beforehand.
+func (c *SetDataCommand) SerializePayload() []byte {
+	data := []byte{byte(c.Sequence >> 8), byte(c.Sequence)}
+	for _, dp := range c.Datapoints {
+		data, _ = AppendDatapoint(data, dp)
+	}
+	return data
+}
+
+// DataResponseCommand is sent by the device in response to a SetDataCommand
+// or QueryDataCommand.
+type DataResponseCommand SetDataCommand
+
+func (c *DataResponseCommand) ClusterID() zcl.ClusterID {
+	return ClusterID
+}
+
+func (c *DataResponseCommand) CommandID() zcl.CommandID {
+	return CommandDataResponse
+}
+
+func (c *DataResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *DataResponseCommand) ParsePayload(data []byte) error {
+	return (*SetDataCommand)(c).ParsePayload(data)
+}
+
+func (c *DataResponseCommand) SerializePayload() []byte {
+	return (*SetDataCommand)(c).SerializePayload()
+}
+
+// DataReportCommand is sent by the device when datapoints change.
+type DataReportCommand SetDataCommand
+
+func (c *DataReportCommand) ClusterID() zcl.ClusterID {
+	return ClusterID
+}
+
+func (c *DataReportCommand) CommandID() zcl.CommandID {
+	return CommandDataReport
+}
+
+func (c *DataReportCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *DataReportCommand) ParsePayload(data []byte) error {
+	return (*SetDataCommand)(c).ParsePayload(data)
+}
+
+func (c *DataReportCommand) SerializePayload() []byte {
+	return (*SetDataCommand)(c).SerializePayload()
+}
+
+// QueryDataCommand asks the device to report all datapoints.
+type QueryDataCommand struct{}
+
+func (c *QueryDataCommand) ClusterID() zcl.ClusterID {
+	return ClusterID
+}
+
+func (c *QueryDataCommand) CommandID() zcl.CommandID {
+	return CommandQueryData
+}
+
+func (c *QueryDataCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *QueryDataCommand) ParsePayload(data []byte) error {
+	return nil
+}
+
+func (c *QueryDataCommand) SerializePayload() []byte {
+	return nil
+}
+
+// TimeSyncRequestCommand is sent by the device to request the current time.
+// Some devices omit the sequence number.
+type TimeSyncRequestCommand struct {
+	Sequence uint16
+}
+
+func (c *TimeSyncRequestCommand) ClusterID() zcl.ClusterID {
+	return ClusterID
+}
+
+func (c *TimeSyncRequestCommand) CommandID() zcl.CommandID {
+	return CommandTimeSyncRequest
+}
+
+func (c *TimeSyncRequestCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *TimeSyncRequestCommand) ParsePayload(data []byte) error {
+	c.Sequence = 0
+	if len(data) >= 2 {
+		c.Sequence = binary.BigEndian.Uint16(data)
+	}
+	return nil
+}
+
+func (c *TimeSyncRequestCommand) SerializePayload() []byte {
+	return []byte{byte(c.Sequence >> 8), byte(c.Sequence)}
+}
+
+// TimeSyncResponseCommand contains the current time as seconds since the
+// Unix epoch, both in UTC and in local time (UTC plus the offset of the time
+// zone of the device).
+type TimeSyncResponseCommand struct {
+	Sequence  uint16
+	UTCTime   uint32
+	LocalTime uint32
+}
+
+func (c *TimeSyncResponseCommand) ClusterID() zcl.ClusterID {
+	return ClusterID
+}
+
+func (c *TimeSyncResponseCommand) CommandID() zcl.CommandID {
+	return CommandTimeSyncResponse
+}
+
+func (c *TimeSyncResponseCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *TimeSyncResponseCommand) ParsePayload(data []byte) error {
+	if len(data) < 10 {
+		return zcl.ErrNotEnoughData
+	}
+	c.Sequence = binary.BigEndian.Uint16(data)
+	c.UTCTime = binary.BigEndian.Uint32(data[2:])
+	c.LocalTime = binary.BigEndian.Uint32(data[6:])
+	return nil
+}
+
+func (c *TimeSyncResponseCommand) SerializePayload() []byte {
+	data := make([]byte, 10)
+	binary.BigEndian.PutUint16(data, c.Sequence)
+	binary.BigEndian.PutUint32(data[2:], c.UTCTime)
+	binary.BigEndian.PutUint32(data[6:], c.LocalTime)
+	return data
+}
@@ -0,0 +1,167 @@
+package tuya
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/dispatch"
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+func TestParseDataReport(t *testing.T) {
+	// Data report of a thermostat: current temperature 21.5 °C, window open,
+	// preset mode 2 and a child lock bitmap.
+	data, _ := hex.DecodeString("090002" + "0003" + "02020004000000d7" + "0801000101" + "0404000102" + "0d0500020102")
+
+	frame, err := zcl.ParseFrame(data)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	command, err := ParseCommand(frame)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	report, ok := command.(*DataReportCommand)
+	if !ok {
+		t.Fatalf("unexpected command: %T", command)
+	}
+
+	expected := &DataReportCommand{
+		Sequence: 0x0003,
+		Datapoints: []Datapoint{
+			{ID: 0x02, Type: DataTypeValue, Value: int32(215)},
+			{ID: 0x08, Type: DataTypeBool, Value: true},
+			{ID: 0x04, Type: DataTypeEnum, Value: uint8(2)},
+			{ID: 0x0d, Type: DataTypeBitmap, Value: uint16(0x0102)},
+		},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("got %+v, expected %+v", report, expected)
+	}
+
+	if actual := hex.EncodeToString(report.SerializePayload()); actual != hex.EncodeToString(data[3:]) {
+		t.Errorf("wrong payload: %s", actual)
+	}
+}
+
+func TestSetData(t *testing.T) {
+	frame := zcl.NewClusterCommandFrame(&SetDataCommand{
+		Sequence: 0x0102,
+		Datapoints: []Datapoint{
+			{ID: 0x01, Type: DataTypeBool, Value: false},
+			{ID: 0x10, Type: DataTypeString, Value: "ab"},
+		},
+	})
+	if actual := hex.EncodeToString(zcl.SerializeFrame(frame)); actual != "01000001020101000100"+"100300026162" {
+		t.Errorf("wrong frame: %s", actual)
+	}
+}
+
+func TestModel(t *testing.T) {
+	model := &Model{
+		Name: "Thermostat",
+		Mappings: []Mapping{
+			{ID: 0x02, Name: "temperature", Type: DataTypeValue, Scale: 10},
+			{ID: 0x04, Name: "preset", Type: DataTypeEnum, Enum: []string{"auto", "manual", "holiday"}},
+			{ID: 0x08, Name: "window_open", Type: DataTypeBool},
+		},
+	}
+	RegisterModel("_TZE200_test", model)
+	if LookupModel("_TZE200_test") != model {
+		t.Fatal("model not registered")
+	}
+
+	name, value, ok := model.Decode(Datapoint{ID: 0x02, Type: DataTypeValue, Value: int32(215)})
+	if !ok || name != "temperature" || value != 21.5 {
+		t.Errorf("wrong decoding: %v %v %v", name, value, ok)
+	}
+	name, value, ok = model.Decode(Datapoint{ID: 0x04, Type: DataTypeEnum, Value: uint8(2)})
+	if !ok || name != "preset" || value != "holiday" {
+		t.Errorf("wrong decoding: %v %v %v", name, value, ok)
+	}
+	if _, _, ok := model.Decode(Datapoint{ID: 0x08, Type: DataTypeEnum, Value: uint8(1)}); ok {
+		t.Error("decoded datapoint with wrong type")
+	}
+
+	dp, err := model.Encode("temperature", 22.5)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if dp != (Datapoint{ID: 0x02, Type: DataTypeValue, Value: int32(225)}) {
+		t.Errorf("wrong encoding: %+v", dp)
+	}
+	dp, err = model.Encode("preset", "manual")
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if dp != (Datapoint{ID: 0x04, Type: DataTypeEnum, Value: uint8(1)}) {
+		t.Errorf("wrong encoding: %+v", dp)
+	}
+	if _, err := model.Encode("preset", "party"); err == nil {
+		t.Error("expected error for unknown enum value")
+	}
+	if _, err := model.Encode("window_open", 1); err == nil {
+		t.Error("expected error for wrong type")
+	}
+	if _, err := model.Encode("unknown", 1); err == nil {
+		t.Error("expected error for unknown datapoint")
+	}
+}
+
+// testDevice sends time sync requests to the coordinator and records the responses.
+type testDevice struct {
+	incoming chan zigbee.IncomingMessage
+	sent     chan zigbee.OutgoingMessage
+}
+
+func (d *testDevice) Start() (chan zigbee.IncomingMessage, error) { return d.incoming, nil }
+func (d *testDevice) Close() error                                { close(d.incoming); return nil }
+func (d *testDevice) PermitJoining(enabled bool) error            { return nil }
+
+func (d *testDevice) Send(message zigbee.OutgoingMessage) error {
+	d.sent <- message
+	return nil
+}
+
+func TestTimeSync(t *testing.T) {
+	device := &testDevice{
+		incoming: make(chan zigbee.IncomingMessage, 16),
+		sent:     make(chan zigbee.OutgoingMessage, 16),
+	}
+	dispatcher := dispatch.New(device)
+	_, err := dispatcher.Start()
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	defer dispatcher.Close()
+
+	handler := NewTimeSyncHandler(dispatcher)
+	handler.Location = time.FixedZone("UTC+2", 2*60*60)
+	handler.Now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	device.incoming <- zigbee.IncomingMessage{
+		Source:              zigbee.Address{Mode: zigbee.AddressModeNWK, Short: 0x1234},
+		SourceEndpoint:      1,
+		DestinationEndpoint: 1,
+		ClusterID:           uint16(ClusterID),
+		Data:                []byte{0x09, 0x05, 0x24, 0x00, 0x07},
+	}
+
+	var message zigbee.OutgoingMessage
+	select {
+	case message = <-device.sent:
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+
+	if message.Destination.Short != 0x1234 || message.ClusterID != uint16(ClusterID) {
+		t.Errorf("wrong addressing: %+v", message)
+	}
+	// 1700000000 = 0x6553f100, 1700007200 = 0x65540d20
+	if actual := hex.EncodeToString(message.Data); actual != "1105240007"+"6553f100"+"65540d20" {
+		t.Errorf("wrong response: %s", actual)
+	}
+}
@@ -0,0 +1,197 @@
+// Package xiaomi decodes the manufacturer-specific extensions used by Xiaomi
+// and Aqara devices.
+//
+// Aqara sensors periodically report their battery voltage and other status
+// values in a manufacturer-specific attribute of the Basic cluster. Depending
+// on the device, the report is either a character string containing
+// tag-type-value entries (attribute 0xff01) or a ZCL structure (attribute
+// 0xff02). Newer devices send the same tag-type-value format in attribute
+// 0x00f7 of the manufacturer-specific cluster 0xfcc0.
+package xiaomi
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+)
+
+// ManufacturerCode is the manufacturer code used by Xiaomi and Aqara devices
+// (registered as LUMI).
+const ManufacturerCode = 0x115f
+
+// ClusterOpple is the manufacturer-specific cluster of Aqara devices.
+const ClusterOpple zcl.ClusterID = 0xfcc0
+
+// Manufacturer-specific attributes.
+const (
+	AttributeBasicReport       zcl.AttributeID = 0xff01 // Basic cluster, tag-type-value entries
+	AttributeBasicReportStruct zcl.AttributeID = 0xff02 // Basic cluster, structure
+	AttributeOppleReport       zcl.AttributeID = 0x00f7 // Opple cluster, tag-type-value entries
+	AttributeOppleMode         zcl.AttributeID = 0x0009 // Opple cluster, see OppleModeFrame
+)
+
+// Tags of the entries of a report.
+const (
+	TagBatteryVoltage    uint8 = 0x01
+	TagDeviceTemperature uint8 = 0x03
+	TagPowerOutageCount  uint8 = 0x05
+	TagTemperature       uint8 = 0x64
+	TagHumidity          uint8 = 0x65
+	TagPressure          uint8 = 0x66
+)
+
+var ErrUnknownAttribute = errors.New("not a Xiaomi report attribute")
+
+// An Entry is a single value of a report. For structure reports, the tag is
+// the index of the element.
+type Entry struct {
+	Tag      uint8
+	DataType zcl.DataType
+	Value    interface{}
+}
+
+// A Report contains the decoded values of a status report. Fields are nil if
+// the value is not present in the report. All entries (including unknown
+// ones) are available in Entries.
+//
+// The meaning of some tags depends on the device model. Temperature, Humidity
+// and Pressure are only decoded if the data type of the entry matches the one
+// used by the temperature and humidity sensors (on other devices, for example
+// wall switches, these tags contain the state of the relays).
+type Report struct {
+	Entries []Entry
+
+	BatteryVoltage    *uint16  // mV
+	DeviceTemperature *int8    // °C
+	PowerOutageCount  *uint16  // as reported by the device
+	Temperature       *float64 // °C
+	Humidity          *float64 // %
+	Pressure          *float64 // hPa
+}
+
+// ParseReport decodes the value of a report attribute as returned by
+// zcl.ParseValue (a string or []byte for AttributeBasicReport and
+// AttributeOppleReport, a []zcl.StructureElement for AttributeBasicReportStruct).
+func ParseReport(attributeID zcl.AttributeID, value interface{}) (Report, error) {
+	var report Report
+
+	switch attributeID {
+	case AttributeBasicReport, AttributeOppleReport:
+		var data []byte
+		switch v := value.(type) {
+		case string:
+			data = []byte(v)
+		case []byte:
+			data = v
+		default:
+			return report, fmt.Errorf("%w: unexpected value %T", zcl.ErrInvalidData, value)
+		}
+		entries, err := ParseEntries(data)
+		if err != nil {
+			return report, err
+		}
+		report.Entries = entries
+
+	case AttributeBasicReportStruct:
+		elements, ok := value.([]zcl.StructureElement)
+		if !ok {
+			return report, fmt.Errorf("%w: unexpected value %T", zcl.ErrInvalidData, value)
+		}
+		for index, element := range elements {
+			report.Entries = append(report.Entries, Entry{Tag: uint8(index), DataType: element.DataType, Value: element.Value})
+		}
+		// The battery voltage is the second element of the structure.
+		if len(elements) > 1 {
+			if voltage, ok := elements[1].Value.(uint16); ok {
+				report.BatteryVoltage = &voltage
+			}
+		}
+		return report, nil
+
+	default:
+		return report, ErrUnknownAttribute
+	}
+
+	for _, entry := range report.Entries {
+		switch v := entry.Value.(type) {
+		case uint16:
+			switch entry.Tag {
+			case TagBatteryVoltage:
+				report.BatteryVoltage = &v
+			case TagPowerOutageCount:
+				report.PowerOutageCount = &v
+			case TagHumidity:
+				humidity := float64(v) / 100
+				report.Humidity = &humidity
+			}
+		case int8:
+			if entry.Tag == TagDeviceTemperature {
+				report.DeviceTemperature = &v
+			}
+		case int16:
+			if entry.Tag == TagTemperature {
+				temperature := float64(v) / 100
+				report.Temperature = &temperature
+			}
+		case int32:
+			if entry.Tag == TagPressure {
+				pressure := float64(v) / 100
+				report.Pressure = &pressure
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// ParseEntries parses a sequence of tag-type-value entries.
+func ParseEntries(data []byte) ([]Entry, error) {
+	var entries []Entry
+	for len(data) != 0 {
+		if len(data) < 2 {
+			return entries, zcl.ErrNotEnoughData
+		}
+		entry := Entry{Tag: data[0], DataType: zcl.DataType(data[1])}
+		var err error
+		entry.Value, data, err = zcl.ParseValue(entry.DataType, data[2:])
+		if err != nil {
+			return entries, fmt.Errorf("tag 0x%02x: %w", entry.Tag, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Operation modes of Aqara Opple wireless switches.
+const (
+	// The switch sends standard On/Off, Level Control and Color Control commands.
+	OppleModeCommands uint8 = 0x00
+	// The switch reports button events (single, double, triple click,
+	// hold and release) via the Multistate Input cluster.
+	OppleModeEvents uint8 = 0x01
+)
+
+// OppleModeFrame returns the manufacturer-specific Write Attributes frame to
+// change the operation mode of an Aqara Opple switch. The frame must be sent
+// to ClusterOpple on endpoint 1 of the switch while it is awake (for example
+// right after joining or after pressing a button).
+func OppleModeFrame(mode uint8) zcl.Frame {
+	data, _ := zcl.SerializeWriteAttributesCommand(zcl.WriteAttributesCommand{
+		Records: []zcl.WriteAttributeRecord{{
+			AttributeID: AttributeOppleMode,
+			DataType:    zcl.DataTypeUint8,
+			Value:       mode,
+		}},
+	})
+
+	return zcl.Frame{
+		FrameHeader: zcl.FrameHeader{
+			Type:                 zcl.FrameTypeGlobal,
+			ManufacturerSpecific: true,
+			ManufacturerCode:     ManufacturerCode,
+			CommandID:            zcl.CommandWriteAttributes,
+		},
+		Data: data,
+	}
+}
@@ -0,0 +1,94 @@
+package xiaomi
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+)
+
+func TestParseReport(t *testing.T) {
+	// Report Attributes payload of a temperature and humidity sensor.
+	data, _ := hex.DecodeString("01ff4219" + "0121e40b" + "032815" + "05210500" + "6429fc07" + "65218f0d" + "662b8c870100")
+
+	cmd, err := zcl.ParseReportAttributesCommand(data)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if len(cmd.Reports) != 1 {
+		t.Fatalf("unexpected reports: %+v", cmd.Reports)
+	}
+
+	report, err := ParseReport(cmd.Reports[0].AttributeID, cmd.Reports[0].Value)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	if len(report.Entries) != 6 {
+		t.Errorf("wrong number of entries: %+v", report.Entries)
+	}
+	if report.BatteryVoltage == nil || *report.BatteryVoltage != 3044 {
+		t.Errorf("wrong battery voltage: %v", report.BatteryVoltage)
+	}
+	if report.DeviceTemperature == nil || *report.DeviceTemperature != 21 {
+		t.Errorf("wrong device temperature: %v", report.DeviceTemperature)
+	}
+	if report.PowerOutageCount == nil || *report.PowerOutageCount != 5 {
+		t.Errorf("wrong power outage count: %v", report.PowerOutageCount)
+	}
+	if report.Temperature == nil || *report.Temperature != 20.44 {
+		t.Errorf("wrong temperature: %v", report.Temperature)
+	}
+	if report.Humidity == nil || *report.Humidity != 34.71 {
+		t.Errorf("wrong humidity: %v", report.Humidity)
+	}
+	if report.Pressure == nil || *report.Pressure != 1002.36 {
+		t.Errorf("wrong pressure: %v", report.Pressure)
+	}
+}
+
+func TestParseReportSwitch(t *testing.T) {
+	// Tag 0x64 contains the relay state on wall switches.
+	data, _ := hex.DecodeString("0121d00b" + "641001")
+
+	report, err := ParseReport(AttributeBasicReport, data)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if report.BatteryVoltage == nil || *report.BatteryVoltage != 3024 {
+		t.Errorf("wrong battery voltage: %v", report.BatteryVoltage)
+	}
+	if report.Temperature != nil {
+		t.Errorf("unexpected temperature: %v", *report.Temperature)
+	}
+	if len(report.Entries) != 2 || report.Entries[1].Value != true {
+		t.Errorf("wrong entries: %+v", report.Entries)
+	}
+}
+
+func TestParseReportStruct(t *testing.T) {
+	data, _ := hex.DecodeString("02ff4c0400" + "1001" + "21e40b" + "21a813" + "240000000000")
+
+	cmd, err := zcl.ParseReportAttributesCommand(data)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	report, err := ParseReport(cmd.Reports[0].AttributeID, cmd.Reports[0].Value)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if report.BatteryVoltage == nil || *report.BatteryVoltage != 3044 {
+		t.Errorf("wrong battery voltage: %v", report.BatteryVoltage)
+	}
+	if len(report.Entries) != 4 {
+		t.Errorf("wrong number of entries: %+v", report.Entries)
+	}
+}
+
+func TestOppleModeFrame(t *testing.T) {
+	frame := OppleModeFrame(OppleModeEvents)
+	if actual := hex.EncodeToString(zcl.SerializeFrame(frame)); actual != "045f11000209002001" {
+		t.Errorf("wrong frame: %s", actual)
+	}
+}
@@ -0,0 +1,64 @@
+package zcl
+
+// Attributes of the Metering cluster.
+const (
+	AttributeMeteringCurrentSummationDelivered       AttributeID = 0x0000
+	AttributeMeteringCurrentSummationReceived        AttributeID = 0x0001
+	AttributeMeteringCurrentMaxDemandDelivered       AttributeID = 0x0002
+	AttributeMeteringCurrentMaxDemandReceived        AttributeID = 0x0003
+	AttributeMeteringPowerFactor                     AttributeID = 0x0006
+	AttributeMeteringStatus                          AttributeID = 0x0200
+	AttributeMeteringUnitOfMeasure                   AttributeID = 0x0300
+	AttributeMeteringMultiplier                      AttributeID = 0x0301
+	AttributeMeteringDivisor                         AttributeID = 0x0302
+	AttributeMeteringSummationFormatting             AttributeID = 0x0303
+	AttributeMeteringDemandFormatting                AttributeID = 0x0304
+	AttributeMeteringHistoricalConsumptionFormatting AttributeID = 0x0305
+	AttributeMeteringMeteringDeviceType              AttributeID = 0x0306
+	AttributeMeteringInstantaneousDemand             AttributeID = 0x0400
+	AttributeMeteringCurrentDayConsumptionDelivered  AttributeID = 0x0401
+	AttributeMeteringCurrentDayConsumptionReceived   AttributeID = 0x0402
+	AttributeMeteringPreviousDayConsumptionDelivered AttributeID = 0x0403
+	AttributeMeteringPreviousDayConsumptionReceived  AttributeID = 0x0404
+)
+
+func init() {
+	registerAttributes(ClusterSEMetering,
+		AttributeDefinition{AttributeMeteringCurrentSummationDelivered, "CurrentSummationDelivered", DataTypeUint48},
+		AttributeDefinition{AttributeMeteringCurrentSummationReceived, "CurrentSummationReceived", DataTypeUint48},
+		AttributeDefinition{AttributeMeteringCurrentMaxDemandDelivered, "CurrentMaxDemandDelivered", DataTypeUint48},
+		AttributeDefinition{AttributeMeteringCurrentMaxDemandReceived, "CurrentMaxDemandReceived", DataTypeUint48},
+		AttributeDefinition{AttributeMeteringPowerFactor, "PowerFactor", DataTypeInt8},
+		AttributeDefinition{AttributeMeteringStatus, "Status", DataTypeBitmap8},
+		AttributeDefinition{AttributeMeteringUnitOfMeasure, "UnitOfMeasure", DataTypeEnum8},
+		AttributeDefinition{AttributeMeteringMultiplier, "Multiplier", DataTypeUint24},
+		AttributeDefinition{AttributeMeteringDivisor, "Divisor", DataTypeUint24},
+		AttributeDefinition{AttributeMeteringSummationFormatting, "SummationFormatting", DataTypeBitmap8},
+		AttributeDefinition{AttributeMeteringDemandFormatting, "DemandFormatting", DataTypeBitmap8},
+		AttributeDefinition{AttributeMeteringHistoricalConsumptionFormatting, "HistoricalConsumptionFormatting", DataTypeBitmap8},
+		AttributeDefinition{AttributeMeteringMeteringDeviceType, "MeteringDeviceType", DataTypeBitmap8},
+		AttributeDefinition{AttributeMeteringInstantaneousDemand, "InstantaneousDemand", DataTypeInt24},
+		AttributeDefinition{AttributeMeteringCurrentDayConsumptionDelivered, "CurrentDayConsumptionDelivered", DataTypeUint24},
+		AttributeDefinition{AttributeMeteringCurrentDayConsumptionReceived, "CurrentDayConsumptionReceived", DataTypeUint24},
+		AttributeDefinition{AttributeMeteringPreviousDayConsumptionDelivered, "PreviousDayConsumptionDelivered", DataTypeUint24},
+		AttributeDefinition{AttributeMeteringPreviousDayConsumptionReceived, "PreviousDayConsumptionReceived", DataTypeUint24},
+	)
+}
+
+// Values of the UnitOfMeasure attribute. Values with the high bit set use
+// BCD formatting on the device display, but the attribute values are still binary.
+const (
+	UnitOfMeasureKilowattHours      = 0x00
+	UnitOfMeasureCubicMeters        = 0x01
+	UnitOfMeasureCubicFeet          = 0x02
+	UnitOfMeasureCentumCubicFeet    = 0x03
+	UnitOfMeasureUSGallons          = 0x04
+	UnitOfMeasureImperialGallons    = 0x05
+	UnitOfMeasureBTUs               = 0x06
+	UnitOfMeasureLiters             = 0x07
+	UnitOfMeasureKilopascalGauge    = 0x08
+	UnitOfMeasureKilopascalAbsolute = 0x09
+	UnitOfMeasureMegaCubicFeet      = 0x0a
+	UnitOfMeasureUnitless           = 0x0b
+	UnitOfMeasureMegajoule          = 0x0c
+)
@@ -0,0 +1,172 @@
+package zcl
+
+import "github.com/GreenLightning/zigbee-conductor/pkg/scf"
+
+// Commands received by the server of the On/Off cluster.
+const (
+	CommandOnOffOff                     CommandID = 0x00
+	CommandOnOffOn                      CommandID = 0x01
+	CommandOnOffToggle                  CommandID = 0x02
+	CommandOnOffOffWithEffect           CommandID = 0x40
+	CommandOnOffOnWithRecallGlobalScene CommandID = 0x41
+	CommandOnOffOnWithTimedOff          CommandID = 0x42
+)
+
+func init() {
+	registerClusterCommand(new(OffCommand))
+	registerClusterCommand(new(OnCommand))
+	registerClusterCommand(new(ToggleCommand))
+	registerClusterCommand(new(OffWithEffectCommand))
+	registerClusterCommand(new(OnWithRecallGlobalSceneCommand))
+	registerClusterCommand(new(OnWithTimedOffCommand))
+}
+
+type OffCommand struct{}
+
+func (c *OffCommand) ClusterID() ClusterID {
+	return ClusterGeneralOnOff
+}
+
+func (c *OffCommand) CommandID() CommandID {
+	return CommandOnOffOff
+}
+
+func (c *OffCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *OffCommand) ParsePayload(data []byte) error {
+	return nil
+}
+
+func (c *OffCommand) SerializePayload() []byte {
+	return nil
+}
+
+type OnCommand struct{}
+
+func (c *OnCommand) ClusterID() ClusterID {
+	return ClusterGeneralOnOff
+}
+
+func (c *OnCommand) CommandID() CommandID {
+	return CommandOnOffOn
+}
+
+func (c *OnCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *OnCommand) ParsePayload(data []byte) error {
+	return nil
+}
+
+func (c *OnCommand) SerializePayload() []byte {
+	return nil
+}
+
+type ToggleCommand struct{}
+
+func (c *ToggleCommand) ClusterID() ClusterID {
+	return ClusterGeneralOnOff
+}
+
+func (c *ToggleCommand) CommandID() CommandID {
+	return CommandOnOffToggle
+}
+
+func (c *ToggleCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *ToggleCommand) ParsePayload(data []byte) error {
+	return nil
+}
+
+func (c *ToggleCommand) SerializePayload() []byte {
+	return nil
+}
+
+// Effect identifiers for the Off With Effect command.
+const (
+	EffectDelayedAllOff = 0x00
+	EffectDyingLight    = 0x01
+)
+
+type OffWithEffectCommand struct {
+	EffectIdentifier uint8
+	EffectVariant    uint8
+}
+
+func (c *OffWithEffectCommand) ClusterID() ClusterID {
+	return ClusterGeneralOnOff
+}
+
+func (c *OffWithEffectCommand) CommandID() CommandID {
+	return CommandOnOffOffWithEffect
+}
+
+func (c *OffWithEffectCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *OffWithEffectCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *OffWithEffectCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type OnWithRecallGlobalSceneCommand struct{}
+
+func (c *OnWithRecallGlobalSceneCommand) ClusterID() ClusterID {
+	return ClusterGeneralOnOff
+}
+
+func (c *OnWithRecallGlobalSceneCommand) CommandID() CommandID {
+	return CommandOnOffOnWithRecallGlobalScene
+}
+
+func (c *OnWithRecallGlobalSceneCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *OnWithRecallGlobalSceneCommand) ParsePayload(data []byte) error {
+	return nil
+}
+
+func (c *OnWithRecallGlobalSceneCommand) SerializePayload() []byte {
+	return nil
+}
+
+// If this bit is set in OnOffControl, the command is only accepted if the
+// device is currently on.
+const OnOffControlAcceptOnlyWhenOn = 1 << 0
+
+// OnTime and OffWaitTime are specified in tenths of a second.
+type OnWithTimedOffCommand struct {
+	OnOffControl uint8
+	OnTime       uint16
+	OffWaitTime  uint16
+}
+
+func (c *OnWithTimedOffCommand) ClusterID() ClusterID {
+	return ClusterGeneralOnOff
+}
+
+func (c *OnWithTimedOffCommand) CommandID() CommandID {
+	return CommandOnOffOnWithTimedOff
+}
+
+func (c *OnWithTimedOffCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *OnWithTimedOffCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *OnWithTimedOffCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
@@ -0,0 +1,549 @@
+package zcl
+
+import (
+	"encoding/binary"
+
+	"github.com/GreenLightning/zigbee-conductor/pkg/scf"
+)
+
+// Attributes of the OTA Upgrade cluster (implemented by the client).
+const (
+	AttributeOTAUpgradeUpgradeServerID              AttributeID = 0x0000
+	AttributeOTAUpgradeFileOffset                   AttributeID = 0x0001
+	AttributeOTAUpgradeCurrentFileVersion           AttributeID = 0x0002
+	AttributeOTAUpgradeCurrentZigBeeStackVersion    AttributeID = 0x0003
+	AttributeOTAUpgradeDownloadedFileVersion        AttributeID = 0x0004
+	AttributeOTAUpgradeDownloadedZigBeeStackVersion AttributeID = 0x0005
+	AttributeOTAUpgradeImageUpgradeStatus           AttributeID = 0x0006
+	AttributeOTAUpgradeManufacturerID               AttributeID = 0x0007
+	AttributeOTAUpgradeImageTypeID                  AttributeID = 0x0008
+	AttributeOTAUpgradeMinimumBlockPeriod           AttributeID = 0x0009
+	AttributeOTAUpgradeImageStamp                   AttributeID = 0x000a
+)
+
+func init() {
+	registerAttributes(ClusterGeneralOTAUpgrade,
+		AttributeDefinition{AttributeOTAUpgradeUpgradeServerID, "UpgradeServerID", DataTypeIEEEAddress},
+		AttributeDefinition{AttributeOTAUpgradeFileOffset, "FileOffset", DataTypeUint32},
+		AttributeDefinition{AttributeOTAUpgradeCurrentFileVersion, "CurrentFileVersion", DataTypeUint32},
+		AttributeDefinition{AttributeOTAUpgradeCurrentZigBeeStackVersion, "CurrentZigBeeStackVersion", DataTypeUint16},
+		AttributeDefinition{AttributeOTAUpgradeDownloadedFileVersion, "DownloadedFileVersion", DataTypeUint32},
+		AttributeDefinition{AttributeOTAUpgradeDownloadedZigBeeStackVersion, "DownloadedZigBeeStackVersion", DataTypeUint16},
+		AttributeDefinition{AttributeOTAUpgradeImageUpgradeStatus, "ImageUpgradeStatus", DataTypeEnum8},
+		AttributeDefinition{AttributeOTAUpgradeManufacturerID, "ManufacturerID", DataTypeUint16},
+		AttributeDefinition{AttributeOTAUpgradeImageTypeID, "ImageTypeID", DataTypeUint16},
+		AttributeDefinition{AttributeOTAUpgradeMinimumBlockPeriod, "MinimumBlockPeriod", DataTypeUint16},
+		AttributeDefinition{AttributeOTAUpgradeImageStamp, "ImageStamp", DataTypeUint32},
+	)
+}
+
+// Values of the ImageUpgradeStatus attribute.
+const (
+	ImageUpgradeStatusNormal                           = 0x00
+	ImageUpgradeStatusDownloadInProgress               = 0x01
+	ImageUpgradeStatusDownloadComplete                 = 0x02
+	ImageUpgradeStatusWaitingToUpgrade                 = 0x03
+	ImageUpgradeStatusCountDown                        = 0x04
+	ImageUpgradeStatusWaitForMore                      = 0x05
+	ImageUpgradeStatusWaitingToUpgradeViaExternalEvent = 0x06
+)
+
+// Commands received by the server of the OTA Upgrade cluster.
+const (
+	CommandOTAUpgradeQueryNextImageRequest CommandID = 0x01
+	CommandOTAUpgradeImageBlockRequest     CommandID = 0x03
+	CommandOTAUpgradeImagePageRequest      CommandID = 0x04
+	CommandOTAUpgradeUpgradeEndRequest     CommandID = 0x06
+)
+
+// Commands generated by the server of the OTA Upgrade cluster.
+const (
+	CommandOTAUpgradeImageNotify            CommandID = 0x00
+	CommandOTAUpgradeQueryNextImageResponse CommandID = 0x02
+	CommandOTAUpgradeImageBlockResponse     CommandID = 0x05
+	CommandOTAUpgradeUpgradeEndResponse     CommandID = 0x07
+)
+
+func init() {
+	registerClusterCommand(new(QueryNextImageRequestCommand))
+	registerClusterCommand(new(ImageBlockRequestCommand))
+	registerClusterCommand(new(ImagePageRequestCommand))
+	registerClusterCommand(new(UpgradeEndRequestCommand))
+
+	registerClusterCommand(new(ImageNotifyCommand))
+	registerClusterCommand(new(QueryNextImageResponseCommand))
+	registerClusterCommand(new(ImageBlockResponseCommand))
+	registerClusterCommand(new(UpgradeEndResponseCommand))
+
+	registerClusterCommandResponse(new(QueryNextImageRequestCommand), new(QueryNextImageResponseCommand))
+	registerClusterCommandResponse(new(ImageBlockRequestCommand), new(ImageBlockResponseCommand))
+	registerClusterCommandResponse(new(ImagePageRequestCommand), new(ImageBlockResponseCommand))
+	registerClusterCommandResponse(new(UpgradeEndRequestCommand), new(UpgradeEndResponseCommand))
+}
+
+// ImageNotifyPayloadType determines which fields of the Image Notify command are present.
+type ImageNotifyPayloadType uint8
+
+const (
+	ImageNotifyPayloadQueryJitter      ImageNotifyPayloadType = 0x00
+	ImageNotifyPayloadManufacturerCode ImageNotifyPayloadType = 0x01
+	ImageNotifyPayloadImageType        ImageNotifyPayloadType = 0x02
+	ImageNotifyPayloadFileVersion      ImageNotifyPayloadType = 0x03
+)
+
+// HardwareVersion is optional.
+type QueryNextImageRequestCommand struct {
+	ManufacturerCode   uint16
+	ImageType          uint16
+	CurrentFileVersion uint32
+	HardwareVersion    *uint16
+}
+
+func (c *QueryNextImageRequestCommand) ClusterID() ClusterID {
+	return ClusterGeneralOTAUpgrade
+}
+
+func (c *QueryNextImageRequestCommand) CommandID() CommandID {
+	return CommandOTAUpgradeQueryNextImageRequest
+}
+
+func (c *QueryNextImageRequestCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *QueryNextImageRequestCommand) ParsePayload(data []byte) error {
+	if len(data) < 9 {
+		return ErrNotEnoughData
+	}
+	fieldControl := data[0]
+	c.ManufacturerCode = binary.LittleEndian.Uint16(data[1:])
+	c.ImageType = binary.LittleEndian.Uint16(data[3:])
+	c.CurrentFileVersion = binary.LittleEndian.Uint32(data[5:])
+	c.HardwareVersion = nil
+	if fieldControl&0x01 != 0 {
+		if len(data) < 11 {
+			return ErrNotEnoughData
+		}
+		version := binary.LittleEndian.Uint16(data[9:])
+		c.HardwareVersion = &version
+	}
+	return nil
+}
+
+func (c *QueryNextImageRequestCommand) SerializePayload() []byte {
+	var fieldControl uint8
+	if c.HardwareVersion != nil {
+		fieldControl |= 0x01
+	}
+	data := []byte{fieldControl}
+	data = appendUint16(data, c.ManufacturerCode)
+	data = appendUint16(data, c.ImageType)
+	data = appendUint32(data, c.CurrentFileVersion)
+	if c.HardwareVersion != nil {
+		data = appendUint16(data, *c.HardwareVersion)
+	}
+	return data
+}
+
+// RequestNodeAddress and MinimumBlockPeriod are optional. MinimumBlockPeriod is
+// only sent by clients supporting rate limiting and is specified in milliseconds.
+type ImageBlockRequestCommand struct {
+	ManufacturerCode   uint16
+	ImageType          uint16
+	FileVersion        uint32
+	FileOffset         uint32
+	MaximumDataSize    uint8
+	RequestNodeAddress *uint64
+	MinimumBlockPeriod *uint16
+}
+
+func (c *ImageBlockRequestCommand) ClusterID() ClusterID {
+	return ClusterGeneralOTAUpgrade
+}
+
+func (c *ImageBlockRequestCommand) CommandID() CommandID {
+	return CommandOTAUpgradeImageBlockRequest
+}
+
+func (c *ImageBlockRequestCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *ImageBlockRequestCommand) ParsePayload(data []byte) error {
+	if len(data) < 14 {
+		return ErrNotEnoughData
+	}
+	fieldControl := data[0]
+	c.ManufacturerCode = binary.LittleEndian.Uint16(data[1:])
+	c.ImageType = binary.LittleEndian.Uint16(data[3:])
+	c.FileVersion = binary.LittleEndian.Uint32(data[5:])
+	c.FileOffset = binary.LittleEndian.Uint32(data[9:])
+	c.MaximumDataSize = data[13]
+	data = data[14:]
+	c.RequestNodeAddress = nil
+	if fieldControl&0x01 != 0 {
+		if len(data) < 8 {
+			return ErrNotEnoughData
+		}
+		address := binary.LittleEndian.Uint64(data)
+		c.RequestNodeAddress = &address
+		data = data[8:]
+	}
+	c.MinimumBlockPeriod = nil
+	if fieldControl&0x02 != 0 {
+		if len(data) < 2 {
+			return ErrNotEnoughData
+		}
+		period := binary.LittleEndian.Uint16(data)
+		c.MinimumBlockPeriod = &period
+	}
+	return nil
+}
+
+func (c *ImageBlockRequestCommand) SerializePayload() []byte {
+	var fieldControl uint8
+	if c.RequestNodeAddress != nil {
+		fieldControl |= 0x01
+	}
+	if c.MinimumBlockPeriod != nil {
+		fieldControl |= 0x02
+	}
+	data := []byte{fieldControl}
+	data = appendUint16(data, c.ManufacturerCode)
+	data = appendUint16(data, c.ImageType)
+	data = appendUint32(data, c.FileVersion)
+	data = appendUint32(data, c.FileOffset)
+	data = append(data, c.MaximumDataSize)
+	if c.RequestNodeAddress != nil {
+		data = appendUint64(data, *c.RequestNodeAddress)
+	}
+	if c.MinimumBlockPeriod != nil {
+		data = appendUint16(data, *c.MinimumBlockPeriod)
+	}
+	return data
+}
+
+// ResponseSpacing is specified in milliseconds.
+// RequestNodeAddress is optional.
+type ImagePageRequestCommand struct {
+	ManufacturerCode   uint16
+	ImageType          uint16
+	FileVersion        uint32
+	FileOffset         uint32
+	MaximumDataSize    uint8
+	PageSize           uint16
+	ResponseSpacing    uint16
+	RequestNodeAddress *uint64
+}
+
+func (c *ImagePageRequestCommand) ClusterID() ClusterID {
+	return ClusterGeneralOTAUpgrade
+}
+
+func (c *ImagePageRequestCommand) CommandID() CommandID {
+	return CommandOTAUpgradeImagePageRequest
+}
+
+func (c *ImagePageRequestCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *ImagePageRequestCommand) ParsePayload(data []byte) error {
+	if len(data) < 18 {
+		return ErrNotEnoughData
+	}
+	fieldControl := data[0]
+	c.ManufacturerCode = binary.LittleEndian.Uint16(data[1:])
+	c.ImageType = binary.LittleEndian.Uint16(data[3:])
+	c.FileVersion = binary.LittleEndian.Uint32(data[5:])
+	c.FileOffset = binary.LittleEndian.Uint32(data[9:])
+	c.MaximumDataSize = data[13]
+	c.PageSize = binary.LittleEndian.Uint16(data[14:])
+	c.ResponseSpacing = binary.LittleEndian.Uint16(data[16:])
+	c.RequestNodeAddress = nil
+	if fieldControl&0x01 != 0 {
+		if len(data) < 26 {
+			return ErrNotEnoughData
+		}
+		address := binary.LittleEndian.Uint64(data[18:])
+		c.RequestNodeAddress = &address
+	}
+	return nil
+}
+
+func (c *ImagePageRequestCommand) SerializePayload() []byte {
+	var fieldControl uint8
+	if c.RequestNodeAddress != nil {
+		fieldControl |= 0x01
+	}
+	data := []byte{fieldControl}
+	data = appendUint16(data, c.ManufacturerCode)
+	data = appendUint16(data, c.ImageType)
+	data = appendUint32(data, c.FileVersion)
+	data = appendUint32(data, c.FileOffset)
+	data = append(data, c.MaximumDataSize)
+	data = appendUint16(data, c.PageSize)
+	data = appendUint16(data, c.ResponseSpacing)
+	if c.RequestNodeAddress != nil {
+		data = appendUint64(data, *c.RequestNodeAddress)
+	}
+	return data
+}
+
+type UpgradeEndRequestCommand struct {
+	Status           Status
+	ManufacturerCode uint16
+	ImageType        uint16
+	FileVersion      uint32
+}
+
+func (c *UpgradeEndRequestCommand) ClusterID() ClusterID {
+	return ClusterGeneralOTAUpgrade
+}
+
+func (c *UpgradeEndRequestCommand) CommandID() CommandID {
+	return CommandOTAUpgradeUpgradeEndRequest
+}
+
+func (c *UpgradeEndRequestCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *UpgradeEndRequestCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *UpgradeEndRequestCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+// PayloadType determines which of ManufacturerCode, ImageType and
+// NewFileVersion are present. QueryJitter is a value between 1 and 100; a
+// client only queries the server if its random value is below the jitter.
+type ImageNotifyCommand struct {
+	PayloadType      ImageNotifyPayloadType
+	QueryJitter      uint8
+	ManufacturerCode uint16
+	ImageType        uint16
+	NewFileVersion   uint32
+}
+
+func (c *ImageNotifyCommand) ClusterID() ClusterID {
+	return ClusterGeneralOTAUpgrade
+}
+
+func (c *ImageNotifyCommand) CommandID() CommandID {
+	return CommandOTAUpgradeImageNotify
+}
+
+func (c *ImageNotifyCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *ImageNotifyCommand) ParsePayload(data []byte) error {
+	if len(data) < 2 {
+		return ErrNotEnoughData
+	}
+	c.PayloadType = ImageNotifyPayloadType(data[0])
+	c.QueryJitter = data[1]
+	data = data[2:]
+	c.ManufacturerCode, c.ImageType, c.NewFileVersion = 0, 0, 0
+	if c.PayloadType >= ImageNotifyPayloadManufacturerCode {
+		if len(data) < 2 {
+			return ErrNotEnoughData
+		}
+		c.ManufacturerCode = binary.LittleEndian.Uint16(data)
+		data = data[2:]
+	}
+	if c.PayloadType >= ImageNotifyPayloadImageType {
+		if len(data) < 2 {
+			return ErrNotEnoughData
+		}
+		c.ImageType = binary.LittleEndian.Uint16(data)
+		data = data[2:]
+	}
+	if c.PayloadType >= ImageNotifyPayloadFileVersion {
+		if len(data) < 4 {
+			return ErrNotEnoughData
+		}
+		c.NewFileVersion = binary.LittleEndian.Uint32(data)
+	}
+	return nil
+}
+
+func (c *ImageNotifyCommand) SerializePayload() []byte {
+	data := []byte{byte(c.PayloadType), c.QueryJitter}
+	if c.PayloadType >= ImageNotifyPayloadManufacturerCode {
+		data = appendUint16(data, c.ManufacturerCode)
+	}
+	if c.PayloadType >= ImageNotifyPayloadImageType {
+		data = appendUint16(data, c.ImageType)
+	}
+	if c.PayloadType >= ImageNotifyPayloadFileVersion {
+		data = appendUint32(data, c.NewFileVersion)
+	}
+	return data
+}
+
+// The remaining fields are only present if Status is StatusSuccess.
+type QueryNextImageResponseCommand struct {
+	Status           Status
+	ManufacturerCode uint16
+	ImageType        uint16
+	FileVersion      uint32
+	ImageSize        uint32
+}
+
+func (c *QueryNextImageResponseCommand) ClusterID() ClusterID {
+	return ClusterGeneralOTAUpgrade
+}
+
+func (c *QueryNextImageResponseCommand) CommandID() CommandID {
+	return CommandOTAUpgradeQueryNextImageResponse
+}
+
+func (c *QueryNextImageResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *QueryNextImageResponseCommand) ParsePayload(data []byte) error {
+	if len(data) < 1 {
+		return ErrNotEnoughData
+	}
+	*c = QueryNextImageResponseCommand{Status: Status(data[0])}
+	if c.Status != StatusSuccess {
+		return nil
+	}
+	if len(data) < 13 {
+		return ErrNotEnoughData
+	}
+	c.ManufacturerCode = binary.LittleEndian.Uint16(data[1:])
+	c.ImageType = binary.LittleEndian.Uint16(data[3:])
+	c.FileVersion = binary.LittleEndian.Uint32(data[5:])
+	c.ImageSize = binary.LittleEndian.Uint32(data[9:])
+	return nil
+}
+
+func (c *QueryNextImageResponseCommand) SerializePayload() []byte {
+	data := []byte{byte(c.Status)}
+	if c.Status != StatusSuccess {
+		return data
+	}
+	data = appendUint16(data, c.ManufacturerCode)
+	data = appendUint16(data, c.ImageType)
+	data = appendUint32(data, c.FileVersion)
+	return appendUint32(data, c.ImageSize)
+}
+
+// ManufacturerCode, ImageType, FileVersion, FileOffset and ImageData are only
+// present if Status is StatusSuccess. CurrentTime, RequestTime and
+// MinimumBlockPeriod are only present if Status is StatusWaitForData. If
+// CurrentTime is zero, RequestTime is the number of seconds the client should
+// wait before requesting the block again. MinimumBlockPeriod is specified in milliseconds.
+type ImageBlockResponseCommand struct {
+	Status             Status
+	ManufacturerCode   uint16
+	ImageType          uint16
+	FileVersion        uint32
+	FileOffset         uint32
+	ImageData          []byte
+	CurrentTime        uint32
+	RequestTime        uint32
+	MinimumBlockPeriod uint16
+}
+
+func (c *ImageBlockResponseCommand) ClusterID() ClusterID {
+	return ClusterGeneralOTAUpgrade
+}
+
+func (c *ImageBlockResponseCommand) CommandID() CommandID {
+	return CommandOTAUpgradeImageBlockResponse
+}
+
+func (c *ImageBlockResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *ImageBlockResponseCommand) ParsePayload(data []byte) error {
+	if len(data) < 1 {
+		return ErrNotEnoughData
+	}
+	*c = ImageBlockResponseCommand{Status: Status(data[0])}
+	data = data[1:]
+	switch c.Status {
+	case StatusSuccess:
+		if len(data) < 13 {
+			return ErrNotEnoughData
+		}
+		c.ManufacturerCode = binary.LittleEndian.Uint16(data)
+		c.ImageType = binary.LittleEndian.Uint16(data[2:])
+		c.FileVersion = binary.LittleEndian.Uint32(data[4:])
+		c.FileOffset = binary.LittleEndian.Uint32(data[8:])
+		size := int(data[12])
+		data = data[13:]
+		if len(data) < size {
+			return ErrNotEnoughData
+		}
+		c.ImageData = append([]byte{}, data[:size]...)
+	case StatusWaitForData:
+		if len(data) < 8 {
+			return ErrNotEnoughData
+		}
+		c.CurrentTime = binary.LittleEndian.Uint32(data)
+		c.RequestTime = binary.LittleEndian.Uint32(data[4:])
+		// MinimumBlockPeriod was added in a later revision of the specification.
+		if len(data) >= 10 {
+			c.MinimumBlockPeriod = binary.LittleEndian.Uint16(data[8:])
+		}
+	}
+	return nil
+}
+
+func (c *ImageBlockResponseCommand) SerializePayload() []byte {
+	data := []byte{byte(c.Status)}
+	switch c.Status {
+	case StatusSuccess:
+		data = appendUint16(data, c.ManufacturerCode)
+		data = appendUint16(data, c.ImageType)
+		data = appendUint32(data, c.FileVersion)
+		data = appendUint32(data, c.FileOffset)
+		data = append(data, byte(len(c.ImageData)))
+		data = append(data, c.ImageData...)
+	case StatusWaitForData:
+		data = appendUint32(data, c.CurrentTime)
+		data = appendUint32(data, c.RequestTime)
+		data = appendUint16(data, c.MinimumBlockPeriod)
+	}
+	return data
+}
+
+// If CurrentTime is zero, UpgradeTime is the number of seconds the client
+// should wait before applying the new image. An UpgradeTime of 0xffffffff
+// instructs the client to wait for another Upgrade End Response.
+type UpgradeEndResponseCommand struct {
+	ManufacturerCode uint16
+	ImageType        uint16
+	FileVersion      uint32
+	CurrentTime      uint32
+	UpgradeTime      uint32
+}
+
+func (c *UpgradeEndResponseCommand) ClusterID() ClusterID {
+	return ClusterGeneralOTAUpgrade
+}
+
+func (c *UpgradeEndResponseCommand) CommandID() CommandID {
+	return CommandOTAUpgradeUpgradeEndResponse
+}
+
+func (c *UpgradeEndResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *UpgradeEndResponseCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *UpgradeEndResponseCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
@@ -0,0 +1,195 @@
+package zcl
+
+import (
+	"encoding/binary"
+
+	"github.com/GreenLightning/zigbee-conductor/pkg/scf"
+)
+
+// Attributes of the Poll Control cluster. All intervals are specified in quarter seconds.
+const (
+	AttributePollControlCheckInInterval     AttributeID = 0x0000
+	AttributePollControlLongPollInterval    AttributeID = 0x0001
+	AttributePollControlShortPollInterval   AttributeID = 0x0002
+	AttributePollControlFastPollTimeout     AttributeID = 0x0003
+	AttributePollControlCheckInIntervalMin  AttributeID = 0x0004
+	AttributePollControlLongPollIntervalMin AttributeID = 0x0005
+	AttributePollControlFastPollTimeoutMax  AttributeID = 0x0006
+)
+
+func init() {
+	registerAttributes(ClusterGeneralPollControl,
+		AttributeDefinition{AttributePollControlCheckInInterval, "CheckInInterval", DataTypeUint32},
+		AttributeDefinition{AttributePollControlLongPollInterval, "LongPollInterval", DataTypeUint32},
+		AttributeDefinition{AttributePollControlShortPollInterval, "ShortPollInterval", DataTypeUint16},
+		AttributeDefinition{AttributePollControlFastPollTimeout, "FastPollTimeout", DataTypeUint16},
+		AttributeDefinition{AttributePollControlCheckInIntervalMin, "CheckInIntervalMin", DataTypeUint32},
+		AttributeDefinition{AttributePollControlLongPollIntervalMin, "LongPollIntervalMin", DataTypeUint32},
+		AttributeDefinition{AttributePollControlFastPollTimeoutMax, "FastPollTimeoutMax", DataTypeUint16},
+	)
+	registerUnits(ClusterGeneralPollControl, map[AttributeID]Unit{
+		AttributePollControlCheckInInterval:     UnitQuarterSeconds,
+		AttributePollControlLongPollInterval:    UnitQuarterSeconds,
+		AttributePollControlShortPollInterval:   UnitQuarterSeconds,
+		AttributePollControlFastPollTimeout:     UnitQuarterSeconds,
+		AttributePollControlCheckInIntervalMin:  UnitQuarterSeconds,
+		AttributePollControlLongPollIntervalMin: UnitQuarterSeconds,
+		AttributePollControlFastPollTimeoutMax:  UnitQuarterSeconds,
+	})
+}
+
+// Commands received by the server of the Poll Control cluster.
+const (
+	CommandPollControlCheckInResponse      CommandID = 0x00
+	CommandPollControlFastPollStop         CommandID = 0x01
+	CommandPollControlSetLongPollInterval  CommandID = 0x02
+	CommandPollControlSetShortPollInterval CommandID = 0x03
+)
+
+// Commands generated by the server of the Poll Control cluster.
+const (
+	CommandPollControlCheckIn CommandID = 0x00
+)
+
+func init() {
+	registerClusterCommand(new(CheckInResponseCommand))
+	registerClusterCommand(new(FastPollStopCommand))
+	registerClusterCommand(new(SetLongPollIntervalCommand))
+	registerClusterCommand(new(SetShortPollIntervalCommand))
+
+	registerClusterCommand(new(CheckInCommand))
+
+	registerClusterCommandResponse(new(CheckInCommand), new(CheckInResponseCommand))
+}
+
+// FastPollTimeout is specified in quarter seconds. If it is zero, the device
+// uses the value of its FastPollTimeout attribute.
+type CheckInResponseCommand struct {
+	StartFastPolling bool
+	FastPollTimeout  uint16
+}
+
+func (c *CheckInResponseCommand) ClusterID() ClusterID {
+	return ClusterGeneralPollControl
+}
+
+func (c *CheckInResponseCommand) CommandID() CommandID {
+	return CommandPollControlCheckInResponse
+}
+
+func (c *CheckInResponseCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *CheckInResponseCommand) ParsePayload(data []byte) error {
+	if len(data) < 3 {
+		return ErrNotEnoughData
+	}
+	c.StartFastPolling = data[0] != 0
+	c.FastPollTimeout = binary.LittleEndian.Uint16(data[1:])
+	return nil
+}
+
+func (c *CheckInResponseCommand) SerializePayload() []byte {
+	var data []byte
+	if c.StartFastPolling {
+		data = append(data, 1)
+	} else {
+		data = append(data, 0)
+	}
+	return appendUint16(data, c.FastPollTimeout)
+}
+
+type FastPollStopCommand struct{}
+
+func (c *FastPollStopCommand) ClusterID() ClusterID {
+	return ClusterGeneralPollControl
+}
+
+func (c *FastPollStopCommand) CommandID() CommandID {
+	return CommandPollControlFastPollStop
+}
+
+func (c *FastPollStopCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *FastPollStopCommand) ParsePayload(data []byte) error {
+	return nil
+}
+
+func (c *FastPollStopCommand) SerializePayload() []byte {
+	return nil
+}
+
+// NewLongPollInterval is specified in quarter seconds.
+type SetLongPollIntervalCommand struct {
+	NewLongPollInterval uint32
+}
+
+func (c *SetLongPollIntervalCommand) ClusterID() ClusterID {
+	return ClusterGeneralPollControl
+}
+
+func (c *SetLongPollIntervalCommand) CommandID() CommandID {
+	return CommandPollControlSetLongPollInterval
+}
+
+func (c *SetLongPollIntervalCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *SetLongPollIntervalCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *SetLongPollIntervalCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+// NewShortPollInterval is specified in quarter seconds.
+type SetShortPollIntervalCommand struct {
+	NewShortPollInterval uint16
+}
+
+func (c *SetShortPollIntervalCommand) ClusterID() ClusterID {
+	return ClusterGeneralPollControl
+}
+
+func (c *SetShortPollIntervalCommand) CommandID() CommandID {
+	return CommandPollControlSetShortPollInterval
+}
+
+func (c *SetShortPollIntervalCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *SetShortPollIntervalCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *SetShortPollIntervalCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type CheckInCommand struct{}
+
+func (c *CheckInCommand) ClusterID() ClusterID {
+	return ClusterGeneralPollControl
+}
+
+func (c *CheckInCommand) CommandID() CommandID {
+	return CommandPollControlCheckIn
+}
+
+func (c *CheckInCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *CheckInCommand) ParsePayload(data []byte) error {
+	return nil
+}
+
+func (c *CheckInCommand) SerializePayload() []byte {
+	return nil
+}
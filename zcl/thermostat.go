@@ -0,0 +1,481 @@
+package zcl
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/pkg/scf"
+)
+
+// Attributes of the Thermostat cluster.
+const (
+	AttributeThermostatLocalTemperature                AttributeID = 0x0000
+	AttributeThermostatOutdoorTemperature              AttributeID = 0x0001
+	AttributeThermostatOccupancy                       AttributeID = 0x0002
+	AttributeThermostatAbsMinHeatSetpointLimit         AttributeID = 0x0003
+	AttributeThermostatAbsMaxHeatSetpointLimit         AttributeID = 0x0004
+	AttributeThermostatAbsMinCoolSetpointLimit         AttributeID = 0x0005
+	AttributeThermostatAbsMaxCoolSetpointLimit         AttributeID = 0x0006
+	AttributeThermostatPICoolingDemand                 AttributeID = 0x0007
+	AttributeThermostatPIHeatingDemand                 AttributeID = 0x0008
+	AttributeThermostatLocalTemperatureCalibration     AttributeID = 0x0010
+	AttributeThermostatOccupiedCoolingSetpoint         AttributeID = 0x0011
+	AttributeThermostatOccupiedHeatingSetpoint         AttributeID = 0x0012
+	AttributeThermostatUnoccupiedCoolingSetpoint       AttributeID = 0x0013
+	AttributeThermostatUnoccupiedHeatingSetpoint       AttributeID = 0x0014
+	AttributeThermostatMinHeatSetpointLimit            AttributeID = 0x0015
+	AttributeThermostatMaxHeatSetpointLimit            AttributeID = 0x0016
+	AttributeThermostatMinCoolSetpointLimit            AttributeID = 0x0017
+	AttributeThermostatMaxCoolSetpointLimit            AttributeID = 0x0018
+	AttributeThermostatMinSetpointDeadBand             AttributeID = 0x0019
+	AttributeThermostatRemoteSensing                   AttributeID = 0x001a
+	AttributeThermostatControlSequenceOfOperation      AttributeID = 0x001b
+	AttributeThermostatSystemMode                      AttributeID = 0x001c
+	AttributeThermostatAlarmMask                       AttributeID = 0x001d
+	AttributeThermostatRunningMode                     AttributeID = 0x001e
+	AttributeThermostatStartOfWeek                     AttributeID = 0x0020
+	AttributeThermostatNumberOfWeeklyTransitions       AttributeID = 0x0021
+	AttributeThermostatNumberOfDailyTransitions        AttributeID = 0x0022
+	AttributeThermostatTemperatureSetpointHold         AttributeID = 0x0023
+	AttributeThermostatTemperatureSetpointHoldDuration AttributeID = 0x0024
+	AttributeThermostatProgrammingOperationMode        AttributeID = 0x0025
+	AttributeThermostatRunningState                    AttributeID = 0x0029
+	AttributeThermostatSetpointChangeSource            AttributeID = 0x0030
+	AttributeThermostatSetpointChangeAmount            AttributeID = 0x0031
+	AttributeThermostatSetpointChangeSourceTimestamp   AttributeID = 0x0032
+)
+
+func init() {
+	registerAttributes(ClusterHVACThermostat,
+		AttributeDefinition{AttributeThermostatLocalTemperature, "LocalTemperature", DataTypeInt16},
+		AttributeDefinition{AttributeThermostatOutdoorTemperature, "OutdoorTemperature", DataTypeInt16},
+		AttributeDefinition{AttributeThermostatOccupancy, "Occupancy", DataTypeBitmap8},
+		AttributeDefinition{AttributeThermostatAbsMinHeatSetpointLimit, "AbsMinHeatSetpointLimit", DataTypeInt16},
+		AttributeDefinition{AttributeThermostatAbsMaxHeatSetpointLimit, "AbsMaxHeatSetpointLimit", DataTypeInt16},
+		AttributeDefinition{AttributeThermostatAbsMinCoolSetpointLimit, "AbsMinCoolSetpointLimit", DataTypeInt16},
+		AttributeDefinition{AttributeThermostatAbsMaxCoolSetpointLimit, "AbsMaxCoolSetpointLimit", DataTypeInt16},
+		AttributeDefinition{AttributeThermostatPICoolingDemand, "PICoolingDemand", DataTypeUint8},
+		AttributeDefinition{AttributeThermostatPIHeatingDemand, "PIHeatingDemand", DataTypeUint8},
+		AttributeDefinition{AttributeThermostatLocalTemperatureCalibration, "LocalTemperatureCalibration", DataTypeInt8},
+		AttributeDefinition{AttributeThermostatOccupiedCoolingSetpoint, "OccupiedCoolingSetpoint", DataTypeInt16},
+		AttributeDefinition{AttributeThermostatOccupiedHeatingSetpoint, "OccupiedHeatingSetpoint", DataTypeInt16},
+		AttributeDefinition{AttributeThermostatUnoccupiedCoolingSetpoint, "UnoccupiedCoolingSetpoint", DataTypeInt16},
+		AttributeDefinition{AttributeThermostatUnoccupiedHeatingSetpoint, "UnoccupiedHeatingSetpoint", DataTypeInt16},
+		AttributeDefinition{AttributeThermostatMinHeatSetpointLimit, "MinHeatSetpointLimit", DataTypeInt16},
+		AttributeDefinition{AttributeThermostatMaxHeatSetpointLimit, "MaxHeatSetpointLimit", DataTypeInt16},
+		AttributeDefinition{AttributeThermostatMinCoolSetpointLimit, "MinCoolSetpointLimit", DataTypeInt16},
+		AttributeDefinition{AttributeThermostatMaxCoolSetpointLimit, "MaxCoolSetpointLimit", DataTypeInt16},
+		AttributeDefinition{AttributeThermostatMinSetpointDeadBand, "MinSetpointDeadBand", DataTypeInt8},
+		AttributeDefinition{AttributeThermostatRemoteSensing, "RemoteSensing", DataTypeBitmap8},
+		AttributeDefinition{AttributeThermostatControlSequenceOfOperation, "ControlSequenceOfOperation", DataTypeEnum8},
+		AttributeDefinition{AttributeThermostatSystemMode, "SystemMode", DataTypeEnum8},
+		AttributeDefinition{AttributeThermostatAlarmMask, "AlarmMask", DataTypeBitmap8},
+		AttributeDefinition{AttributeThermostatRunningMode, "RunningMode", DataTypeEnum8},
+		AttributeDefinition{AttributeThermostatStartOfWeek, "StartOfWeek", DataTypeEnum8},
+		AttributeDefinition{AttributeThermostatNumberOfWeeklyTransitions, "NumberOfWeeklyTransitions", DataTypeUint8},
+		AttributeDefinition{AttributeThermostatNumberOfDailyTransitions, "NumberOfDailyTransitions", DataTypeUint8},
+		AttributeDefinition{AttributeThermostatTemperatureSetpointHold, "TemperatureSetpointHold", DataTypeEnum8},
+		AttributeDefinition{AttributeThermostatTemperatureSetpointHoldDuration, "TemperatureSetpointHoldDuration", DataTypeUint16},
+		AttributeDefinition{AttributeThermostatProgrammingOperationMode, "ProgrammingOperationMode", DataTypeBitmap8},
+		AttributeDefinition{AttributeThermostatRunningState, "RunningState", DataTypeBitmap16},
+		AttributeDefinition{AttributeThermostatSetpointChangeSource, "SetpointChangeSource", DataTypeEnum8},
+		AttributeDefinition{AttributeThermostatSetpointChangeAmount, "SetpointChangeAmount", DataTypeInt16},
+		AttributeDefinition{AttributeThermostatSetpointChangeSourceTimestamp, "SetpointChangeSourceTimestamp", DataTypeUTCTime},
+	)
+	registerUnits(ClusterHVACThermostat, map[AttributeID]Unit{
+		AttributeThermostatLocalTemperature:                UnitCentiCelsius,
+		AttributeThermostatOutdoorTemperature:              UnitCentiCelsius,
+		AttributeThermostatAbsMinHeatSetpointLimit:         UnitCentiCelsius,
+		AttributeThermostatAbsMaxHeatSetpointLimit:         UnitCentiCelsius,
+		AttributeThermostatAbsMinCoolSetpointLimit:         UnitCentiCelsius,
+		AttributeThermostatAbsMaxCoolSetpointLimit:         UnitCentiCelsius,
+		AttributeThermostatPICoolingDemand:                 UnitPercent,
+		AttributeThermostatPIHeatingDemand:                 UnitPercent,
+		AttributeThermostatLocalTemperatureCalibration:     UnitDeciCelsius,
+		AttributeThermostatOccupiedCoolingSetpoint:         UnitCentiCelsius,
+		AttributeThermostatOccupiedHeatingSetpoint:         UnitCentiCelsius,
+		AttributeThermostatUnoccupiedCoolingSetpoint:       UnitCentiCelsius,
+		AttributeThermostatUnoccupiedHeatingSetpoint:       UnitCentiCelsius,
+		AttributeThermostatMinHeatSetpointLimit:            UnitCentiCelsius,
+		AttributeThermostatMaxHeatSetpointLimit:            UnitCentiCelsius,
+		AttributeThermostatMinCoolSetpointLimit:            UnitCentiCelsius,
+		AttributeThermostatMaxCoolSetpointLimit:            UnitCentiCelsius,
+		AttributeThermostatMinSetpointDeadBand:             UnitDeciCelsius,
+		AttributeThermostatTemperatureSetpointHoldDuration: UnitMinutes,
+		AttributeThermostatSetpointChangeAmount:            UnitCentiCelsius,
+	})
+}
+
+// Values of the SystemMode attribute.
+const (
+	SystemModeOff              = 0x00
+	SystemModeAuto             = 0x01
+	SystemModeCool             = 0x03
+	SystemModeHeat             = 0x04
+	SystemModeEmergencyHeating = 0x05
+	SystemModePrecooling       = 0x06
+	SystemModeFanOnly          = 0x07
+	SystemModeDry              = 0x08
+	SystemModeSleep            = 0x09
+)
+
+// Values of the ControlSequenceOfOperation attribute.
+const (
+	ControlSequenceCoolingOnly                 = 0x00
+	ControlSequenceCoolingWithReheat           = 0x01
+	ControlSequenceHeatingOnly                 = 0x02
+	ControlSequenceHeatingWithReheat           = 0x03
+	ControlSequenceCoolingAndHeating           = 0x04
+	ControlSequenceCoolingAndHeatingWithReheat = 0x05
+)
+
+// Bits of the RunningState attribute.
+const (
+	RunningStateHeat            = 1 << 0
+	RunningStateCool            = 1 << 1
+	RunningStateFan             = 1 << 2
+	RunningStateHeatSecondStage = 1 << 3
+	RunningStateCoolSecondStage = 1 << 4
+	RunningStateFanSecondStage  = 1 << 5
+	RunningStateFanThirdStage   = 1 << 6
+)
+
+// TemperatureToCelsius converts a temperature as used by the attributes and
+// commands of the Thermostat cluster (in hundredths of a degree Celsius).
+func TemperatureToCelsius(temperature int16) float64 {
+	return float64(temperature) / 100
+}
+
+// CelsiusToTemperature converts a temperature in degrees Celsius to the
+// representation used by the Thermostat cluster (in hundredths of a degree Celsius).
+func CelsiusToTemperature(celsius float64) int16 {
+	value := math.Round(celsius * 100)
+	if value < math.MinInt16 {
+		return math.MinInt16
+	}
+	if value > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	return int16(value)
+}
+
+// Commands received by the server of the Thermostat cluster.
+const (
+	CommandThermostatSetpointRaiseLower  CommandID = 0x00
+	CommandThermostatSetWeeklySchedule   CommandID = 0x01
+	CommandThermostatGetWeeklySchedule   CommandID = 0x02
+	CommandThermostatClearWeeklySchedule CommandID = 0x03
+	CommandThermostatGetRelayStatusLog   CommandID = 0x04
+)
+
+// Commands generated by the server of the Thermostat cluster.
+const (
+	CommandThermostatGetWeeklyScheduleResponse CommandID = 0x00
+	CommandThermostatGetRelayStatusLogResponse CommandID = 0x01
+)
+
+func init() {
+	registerClusterCommand(new(SetpointRaiseLowerCommand))
+	registerClusterCommand(new(SetWeeklyScheduleCommand))
+	registerClusterCommand(new(GetWeeklyScheduleCommand))
+	registerClusterCommand(new(ClearWeeklyScheduleCommand))
+	registerClusterCommand(new(GetRelayStatusLogCommand))
+
+	registerClusterCommand(new(GetWeeklyScheduleResponseCommand))
+	registerClusterCommand(new(GetRelayStatusLogResponseCommand))
+
+	registerClusterCommandResponse(new(GetWeeklyScheduleCommand), new(GetWeeklyScheduleResponseCommand))
+	registerClusterCommandResponse(new(GetRelayStatusLogCommand), new(GetRelayStatusLogResponseCommand))
+}
+
+type SetpointMode uint8
+
+const (
+	SetpointModeHeat SetpointMode = 0x00
+	SetpointModeCool SetpointMode = 0x01
+	SetpointModeBoth SetpointMode = 0x02
+)
+
+// DayOfWeek is a bitmap of the days a weekly schedule applies to.
+type DayOfWeek uint8
+
+const (
+	DaySunday         DayOfWeek = 1 << 0
+	DayMonday         DayOfWeek = 1 << 1
+	DayTuesday        DayOfWeek = 1 << 2
+	DayWednesday      DayOfWeek = 1 << 3
+	DayThursday       DayOfWeek = 1 << 4
+	DayFriday         DayOfWeek = 1 << 5
+	DaySaturday       DayOfWeek = 1 << 6
+	DayAwayOrVacation DayOfWeek = 1 << 7
+
+	DayWorkdays = DayMonday | DayTuesday | DayWednesday | DayThursday | DayFriday
+	DayWeekend  = DaySaturday | DaySunday
+)
+
+// Weekdays returns the bitmap containing the given days.
+func Weekdays(days ...time.Weekday) DayOfWeek {
+	var result DayOfWeek
+	for _, day := range days {
+		result |= 1 << uint(day)
+	}
+	return result
+}
+
+// Contains reports whether the bitmap contains the given day.
+func (d DayOfWeek) Contains(day time.Weekday) bool {
+	return d&(1<<uint(day)) != 0
+}
+
+// ScheduleMode is a bitmap of the setpoints contained in a weekly schedule.
+type ScheduleMode uint8
+
+const (
+	ScheduleModeHeat ScheduleMode = 1 << 0
+	ScheduleModeCool ScheduleMode = 1 << 1
+)
+
+// A ScheduleTransition changes the setpoints at a certain time of day.
+// TransitionTime is specified in minutes after midnight. The setpoints are
+// specified in hundredths of a degree Celsius and are only used if the
+// corresponding bit is set in the mode of the schedule.
+type ScheduleTransition struct {
+	TransitionTime uint16
+	HeatSetpoint   int16
+	CoolSetpoint   int16
+}
+
+// NewScheduleTransition returns a transition at the given time of day.
+func NewScheduleTransition(hour, minute int, heatSetpoint, coolSetpoint float64) ScheduleTransition {
+	return ScheduleTransition{
+		TransitionTime: uint16(hour*60 + minute),
+		HeatSetpoint:   CelsiusToTemperature(heatSetpoint),
+		CoolSetpoint:   CelsiusToTemperature(coolSetpoint),
+	}
+}
+
+// TimeOfDay returns the transition time as a duration since midnight.
+func (t ScheduleTransition) TimeOfDay() time.Duration {
+	return time.Duration(t.TransitionTime) * time.Minute
+}
+
+// Amount is specified in tenths of a degree Celsius.
+type SetpointRaiseLowerCommand struct {
+	Mode   SetpointMode
+	Amount int8
+}
+
+func (c *SetpointRaiseLowerCommand) ClusterID() ClusterID {
+	return ClusterHVACThermostat
+}
+
+func (c *SetpointRaiseLowerCommand) CommandID() CommandID {
+	return CommandThermostatSetpointRaiseLower
+}
+
+func (c *SetpointRaiseLowerCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *SetpointRaiseLowerCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *SetpointRaiseLowerCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+// The specification allows at most 10 transitions per command.
+type SetWeeklyScheduleCommand struct {
+	DaysOfWeek  DayOfWeek
+	Mode        ScheduleMode
+	Transitions []ScheduleTransition
+}
+
+func (c *SetWeeklyScheduleCommand) ClusterID() ClusterID {
+	return ClusterHVACThermostat
+}
+
+func (c *SetWeeklyScheduleCommand) CommandID() CommandID {
+	return CommandThermostatSetWeeklySchedule
+}
+
+func (c *SetWeeklyScheduleCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *SetWeeklyScheduleCommand) ParsePayload(data []byte) error {
+	if len(data) < 3 {
+		return ErrNotEnoughData
+	}
+	count := int(data[0])
+	c.DaysOfWeek = DayOfWeek(data[1])
+	c.Mode = ScheduleMode(data[2])
+	data = data[3:]
+
+	size := 2
+	if c.Mode&ScheduleModeHeat != 0 {
+		size += 2
+	}
+	if c.Mode&ScheduleModeCool != 0 {
+		size += 2
+	}
+	if len(data) < count*size {
+		return ErrNotEnoughData
+	}
+
+	c.Transitions = make([]ScheduleTransition, count)
+	for i := range c.Transitions {
+		transition := &c.Transitions[i]
+		transition.TransitionTime = binary.LittleEndian.Uint16(data)
+		data = data[2:]
+		if c.Mode&ScheduleModeHeat != 0 {
+			transition.HeatSetpoint = int16(binary.LittleEndian.Uint16(data))
+			data = data[2:]
+		}
+		if c.Mode&ScheduleModeCool != 0 {
+			transition.CoolSetpoint = int16(binary.LittleEndian.Uint16(data))
+			data = data[2:]
+		}
+	}
+	return nil
+}
+
+func (c *SetWeeklyScheduleCommand) SerializePayload() []byte {
+	data := []byte{byte(len(c.Transitions)), byte(c.DaysOfWeek), byte(c.Mode)}
+	for _, transition := range c.Transitions {
+		data = appendUint16(data, transition.TransitionTime)
+		if c.Mode&ScheduleModeHeat != 0 {
+			data = appendUint16(data, uint16(transition.HeatSetpoint))
+		}
+		if c.Mode&ScheduleModeCool != 0 {
+			data = appendUint16(data, uint16(transition.CoolSetpoint))
+		}
+	}
+	return data
+}
+
+type GetWeeklyScheduleCommand struct {
+	DaysToReturn DayOfWeek
+	ModeToReturn ScheduleMode
+}
+
+func (c *GetWeeklyScheduleCommand) ClusterID() ClusterID {
+	return ClusterHVACThermostat
+}
+
+func (c *GetWeeklyScheduleCommand) CommandID() CommandID {
+	return CommandThermostatGetWeeklySchedule
+}
+
+func (c *GetWeeklyScheduleCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *GetWeeklyScheduleCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *GetWeeklyScheduleCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type ClearWeeklyScheduleCommand struct{}
+
+func (c *ClearWeeklyScheduleCommand) ClusterID() ClusterID {
+	return ClusterHVACThermostat
+}
+
+func (c *ClearWeeklyScheduleCommand) CommandID() CommandID {
+	return CommandThermostatClearWeeklySchedule
+}
+
+func (c *ClearWeeklyScheduleCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *ClearWeeklyScheduleCommand) ParsePayload(data []byte) error {
+	return nil
+}
+
+func (c *ClearWeeklyScheduleCommand) SerializePayload() []byte {
+	return nil
+}
+
+type GetRelayStatusLogCommand struct{}
+
+func (c *GetRelayStatusLogCommand) ClusterID() ClusterID {
+	return ClusterHVACThermostat
+}
+
+func (c *GetRelayStatusLogCommand) CommandID() CommandID {
+	return CommandThermostatGetRelayStatusLog
+}
+
+func (c *GetRelayStatusLogCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *GetRelayStatusLogCommand) ParsePayload(data []byte) error {
+	return nil
+}
+
+func (c *GetRelayStatusLogCommand) SerializePayload() []byte {
+	return nil
+}
+
+// Same format as SetWeeklyScheduleCommand.
+type GetWeeklyScheduleResponseCommand SetWeeklyScheduleCommand
+
+func (c *GetWeeklyScheduleResponseCommand) ClusterID() ClusterID {
+	return ClusterHVACThermostat
+}
+
+func (c *GetWeeklyScheduleResponseCommand) CommandID() CommandID {
+	return CommandThermostatGetWeeklyScheduleResponse
+}
+
+func (c *GetWeeklyScheduleResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *GetWeeklyScheduleResponseCommand) ParsePayload(data []byte) error {
+	return (*SetWeeklyScheduleCommand)(c).ParsePayload(data)
+}
+
+func (c *GetWeeklyScheduleResponseCommand) SerializePayload() []byte {
+	return (*SetWeeklyScheduleCommand)(c).SerializePayload()
+}
+
+// TimeOfDay is specified in minutes after midnight.
+// RelayStatus uses the bits of the RunningState attribute.
+// LocalTemperature and Setpoint are specified in hundredths of a degree Celsius.
+type GetRelayStatusLogResponseCommand struct {
+	TimeOfDay          uint16
+	RelayStatus        uint16
+	LocalTemperature   int16
+	HumidityPercentage uint8
+	Setpoint           int16
+	UnreadEntries      uint16
+}
+
+func (c *GetRelayStatusLogResponseCommand) ClusterID() ClusterID {
+	return ClusterHVACThermostat
+}
+
+func (c *GetRelayStatusLogResponseCommand) CommandID() CommandID {
+	return CommandThermostatGetRelayStatusLogResponse
+}
+
+func (c *GetRelayStatusLogResponseCommand) DirectionServerToClient() bool {
+	return true
+}
+
+func (c *GetRelayStatusLogResponseCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *GetRelayStatusLogResponseCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
@@ -0,0 +1,38 @@
+package zcl
+
+// Attributes of the Thermostat User Interface Configuration cluster.
+const (
+	AttributeThermostatUIConfigTemperatureDisplayMode        AttributeID = 0x0000
+	AttributeThermostatUIConfigKeypadLockout                 AttributeID = 0x0001
+	AttributeThermostatUIConfigScheduleProgrammingVisibility AttributeID = 0x0002
+)
+
+func init() {
+	registerAttributes(ClusterHVACThermostatUIConfig,
+		AttributeDefinition{AttributeThermostatUIConfigTemperatureDisplayMode, "TemperatureDisplayMode", DataTypeEnum8},
+		AttributeDefinition{AttributeThermostatUIConfigKeypadLockout, "KeypadLockout", DataTypeEnum8},
+		AttributeDefinition{AttributeThermostatUIConfigScheduleProgrammingVisibility, "ScheduleProgrammingVisibility", DataTypeEnum8},
+	)
+}
+
+// Values of the TemperatureDisplayMode attribute.
+const (
+	TemperatureDisplayModeCelsius    = 0x00
+	TemperatureDisplayModeFahrenheit = 0x01
+)
+
+// Values of the KeypadLockout attribute. Higher levels restrict more functions.
+const (
+	KeypadLockoutNone   = 0x00
+	KeypadLockoutLevel1 = 0x01
+	KeypadLockoutLevel2 = 0x02
+	KeypadLockoutLevel3 = 0x03
+	KeypadLockoutLevel4 = 0x04
+	KeypadLockoutLevel5 = 0x05
+)
+
+// Values of the ScheduleProgrammingVisibility attribute.
+const (
+	ScheduleProgrammingVisible = 0x00
+	ScheduleProgrammingHidden  = 0x01
+)
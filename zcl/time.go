@@ -0,0 +1,66 @@
+package zcl
+
+import "time"
+
+// Attributes of the Time cluster. All times are specified in seconds since
+// the ZCL epoch (see UTCEpoch), all offsets in seconds.
+const (
+	AttributeTimeTime           AttributeID = 0x0000
+	AttributeTimeTimeStatus     AttributeID = 0x0001
+	AttributeTimeTimeZone       AttributeID = 0x0002
+	AttributeTimeDstStart       AttributeID = 0x0003
+	AttributeTimeDstEnd         AttributeID = 0x0004
+	AttributeTimeDstShift       AttributeID = 0x0005
+	AttributeTimeStandardTime   AttributeID = 0x0006
+	AttributeTimeLocalTime      AttributeID = 0x0007
+	AttributeTimeLastSetTime    AttributeID = 0x0008
+	AttributeTimeValidUntilTime AttributeID = 0x0009
+)
+
+func init() {
+	registerAttributes(ClusterGeneralTime,
+		AttributeDefinition{AttributeTimeTime, "Time", DataTypeUTCTime},
+		AttributeDefinition{AttributeTimeTimeStatus, "TimeStatus", DataTypeBitmap8},
+		AttributeDefinition{AttributeTimeTimeZone, "TimeZone", DataTypeInt32},
+		AttributeDefinition{AttributeTimeDstStart, "DstStart", DataTypeUint32},
+		AttributeDefinition{AttributeTimeDstEnd, "DstEnd", DataTypeUint32},
+		AttributeDefinition{AttributeTimeDstShift, "DstShift", DataTypeInt32},
+		AttributeDefinition{AttributeTimeStandardTime, "StandardTime", DataTypeUint32},
+		AttributeDefinition{AttributeTimeLocalTime, "LocalTime", DataTypeUint32},
+		AttributeDefinition{AttributeTimeLastSetTime, "LastSetTime", DataTypeUTCTime},
+		AttributeDefinition{AttributeTimeValidUntilTime, "ValidUntilTime", DataTypeUTCTime},
+	)
+	registerUnits(ClusterGeneralTime, map[AttributeID]Unit{
+		AttributeTimeTimeZone: UnitSeconds,
+		AttributeTimeDstShift: UnitSeconds,
+	})
+}
+
+// Bits of the TimeStatus attribute.
+const (
+	TimeStatusMaster        = 1 << 0
+	TimeStatusSynchronized  = 1 << 1
+	TimeStatusMasterZoneDst = 1 << 2
+	TimeStatusSuperseding   = 1 << 3
+)
+
+// UTCEpoch is the reference point of the UTCTime data type.
+var UTCEpoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// TimeToUTC returns the number of seconds between the ZCL epoch and t. Times
+// before the epoch are clamped to zero.
+func TimeToUTC(t time.Time) uint32 {
+	seconds := t.Unix() - UTCEpoch.Unix()
+	if seconds < 0 {
+		return 0
+	}
+	if seconds > 0xfffffffe {
+		return 0xfffffffe
+	}
+	return uint32(seconds)
+}
+
+// UTCToTime returns the time the given number of seconds after the ZCL epoch.
+func UTCToTime(value uint32) time.Time {
+	return UTCEpoch.Add(time.Duration(value) * time.Second)
+}
@@ -0,0 +1,245 @@
+package zcl
+
+import "github.com/GreenLightning/zigbee-conductor/pkg/scf"
+
+// Attributes of the Window Covering cluster.
+const (
+	AttributeWindowCoveringWindowCoveringType            AttributeID = 0x0000
+	AttributeWindowCoveringPhysicalClosedLimitLift       AttributeID = 0x0001
+	AttributeWindowCoveringPhysicalClosedLimitTilt       AttributeID = 0x0002
+	AttributeWindowCoveringCurrentPositionLift           AttributeID = 0x0003
+	AttributeWindowCoveringCurrentPositionTilt           AttributeID = 0x0004
+	AttributeWindowCoveringNumberOfActuationsLift        AttributeID = 0x0005
+	AttributeWindowCoveringNumberOfActuationsTilt        AttributeID = 0x0006
+	AttributeWindowCoveringConfigStatus                  AttributeID = 0x0007
+	AttributeWindowCoveringCurrentPositionLiftPercentage AttributeID = 0x0008
+	AttributeWindowCoveringCurrentPositionTiltPercentage AttributeID = 0x0009
+	AttributeWindowCoveringInstalledOpenLimitLift        AttributeID = 0x0010
+	AttributeWindowCoveringInstalledClosedLimitLift      AttributeID = 0x0011
+	AttributeWindowCoveringInstalledOpenLimitTilt        AttributeID = 0x0012
+	AttributeWindowCoveringInstalledClosedLimitTilt      AttributeID = 0x0013
+	AttributeWindowCoveringMode                          AttributeID = 0x0017
+)
+
+func init() {
+	registerAttributes(ClusterClosuresWindowCovering,
+		AttributeDefinition{AttributeWindowCoveringWindowCoveringType, "WindowCoveringType", DataTypeEnum8},
+		AttributeDefinition{AttributeWindowCoveringPhysicalClosedLimitLift, "PhysicalClosedLimitLift", DataTypeUint16},
+		AttributeDefinition{AttributeWindowCoveringPhysicalClosedLimitTilt, "PhysicalClosedLimitTilt", DataTypeUint16},
+		AttributeDefinition{AttributeWindowCoveringCurrentPositionLift, "CurrentPositionLift", DataTypeUint16},
+		AttributeDefinition{AttributeWindowCoveringCurrentPositionTilt, "CurrentPositionTilt", DataTypeUint16},
+		AttributeDefinition{AttributeWindowCoveringNumberOfActuationsLift, "NumberOfActuationsLift", DataTypeUint16},
+		AttributeDefinition{AttributeWindowCoveringNumberOfActuationsTilt, "NumberOfActuationsTilt", DataTypeUint16},
+		AttributeDefinition{AttributeWindowCoveringConfigStatus, "ConfigStatus", DataTypeBitmap8},
+		AttributeDefinition{AttributeWindowCoveringCurrentPositionLiftPercentage, "CurrentPositionLiftPercentage", DataTypeUint8},
+		AttributeDefinition{AttributeWindowCoveringCurrentPositionTiltPercentage, "CurrentPositionTiltPercentage", DataTypeUint8},
+		AttributeDefinition{AttributeWindowCoveringInstalledOpenLimitLift, "InstalledOpenLimitLift", DataTypeUint16},
+		AttributeDefinition{AttributeWindowCoveringInstalledClosedLimitLift, "InstalledClosedLimitLift", DataTypeUint16},
+		AttributeDefinition{AttributeWindowCoveringInstalledOpenLimitTilt, "InstalledOpenLimitTilt", DataTypeUint16},
+		AttributeDefinition{AttributeWindowCoveringInstalledClosedLimitTilt, "InstalledClosedLimitTilt", DataTypeUint16},
+		AttributeDefinition{AttributeWindowCoveringMode, "Mode", DataTypeBitmap8},
+	)
+	registerUnits(ClusterClosuresWindowCovering, map[AttributeID]Unit{
+		AttributeWindowCoveringCurrentPositionLiftPercentage: UnitPercent,
+		AttributeWindowCoveringCurrentPositionTiltPercentage: UnitPercent,
+	})
+}
+
+// Values of the WindowCoveringType attribute.
+const (
+	WindowCoveringTypeRollershade               = 0x00
+	WindowCoveringTypeRollershade2Motor         = 0x01
+	WindowCoveringTypeRollershadeExterior       = 0x02
+	WindowCoveringTypeRollershadeExterior2Motor = 0x03
+	WindowCoveringTypeDrapery                   = 0x04
+	WindowCoveringTypeAwning                    = 0x05
+	WindowCoveringTypeShutter                   = 0x06
+	WindowCoveringTypeTiltBlindTiltOnly         = 0x07
+	WindowCoveringTypeTiltBlindLiftAndTilt      = 0x08
+	WindowCoveringTypeProjectorScreen           = 0x09
+)
+
+// Commands received by the server of the Window Covering cluster.
+const (
+	CommandWindowCoveringUpOpen             CommandID = 0x00
+	CommandWindowCoveringDownClose          CommandID = 0x01
+	CommandWindowCoveringStop               CommandID = 0x02
+	CommandWindowCoveringGoToLiftValue      CommandID = 0x04
+	CommandWindowCoveringGoToLiftPercentage CommandID = 0x05
+	CommandWindowCoveringGoToTiltValue      CommandID = 0x07
+	CommandWindowCoveringGoToTiltPercentage CommandID = 0x08
+)
+
+func init() {
+	registerClusterCommand(new(UpOpenCommand))
+	registerClusterCommand(new(DownCloseCommand))
+	registerClusterCommand(new(WindowCoveringStopCommand))
+	registerClusterCommand(new(GoToLiftValueCommand))
+	registerClusterCommand(new(GoToLiftPercentageCommand))
+	registerClusterCommand(new(GoToTiltValueCommand))
+	registerClusterCommand(new(GoToTiltPercentageCommand))
+}
+
+type UpOpenCommand struct{}
+
+func (c *UpOpenCommand) ClusterID() ClusterID {
+	return ClusterClosuresWindowCovering
+}
+
+func (c *UpOpenCommand) CommandID() CommandID {
+	return CommandWindowCoveringUpOpen
+}
+
+func (c *UpOpenCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *UpOpenCommand) ParsePayload(data []byte) error {
+	return nil
+}
+
+func (c *UpOpenCommand) SerializePayload() []byte {
+	return nil
+}
+
+type DownCloseCommand struct{}
+
+func (c *DownCloseCommand) ClusterID() ClusterID {
+	return ClusterClosuresWindowCovering
+}
+
+func (c *DownCloseCommand) CommandID() CommandID {
+	return CommandWindowCoveringDownClose
+}
+
+func (c *DownCloseCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *DownCloseCommand) ParsePayload(data []byte) error {
+	return nil
+}
+
+func (c *DownCloseCommand) SerializePayload() []byte {
+	return nil
+}
+
+type WindowCoveringStopCommand struct{}
+
+func (c *WindowCoveringStopCommand) ClusterID() ClusterID {
+	return ClusterClosuresWindowCovering
+}
+
+func (c *WindowCoveringStopCommand) CommandID() CommandID {
+	return CommandWindowCoveringStop
+}
+
+func (c *WindowCoveringStopCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *WindowCoveringStopCommand) ParsePayload(data []byte) error {
+	return nil
+}
+
+func (c *WindowCoveringStopCommand) SerializePayload() []byte {
+	return nil
+}
+
+type GoToLiftValueCommand struct {
+	LiftValue uint16
+}
+
+func (c *GoToLiftValueCommand) ClusterID() ClusterID {
+	return ClusterClosuresWindowCovering
+}
+
+func (c *GoToLiftValueCommand) CommandID() CommandID {
+	return CommandWindowCoveringGoToLiftValue
+}
+
+func (c *GoToLiftValueCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *GoToLiftValueCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *GoToLiftValueCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+// LiftPercentage is 0 for fully open and 100 for fully closed.
+type GoToLiftPercentageCommand struct {
+	LiftPercentage uint8
+}
+
+func (c *GoToLiftPercentageCommand) ClusterID() ClusterID {
+	return ClusterClosuresWindowCovering
+}
+
+func (c *GoToLiftPercentageCommand) CommandID() CommandID {
+	return CommandWindowCoveringGoToLiftPercentage
+}
+
+func (c *GoToLiftPercentageCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *GoToLiftPercentageCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *GoToLiftPercentageCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+type GoToTiltValueCommand struct {
+	TiltValue uint16
+}
+
+func (c *GoToTiltValueCommand) ClusterID() ClusterID {
+	return ClusterClosuresWindowCovering
+}
+
+func (c *GoToTiltValueCommand) CommandID() CommandID {
+	return CommandWindowCoveringGoToTiltValue
+}
+
+func (c *GoToTiltValueCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *GoToTiltValueCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *GoToTiltValueCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
+
+// TiltPercentage is 0 for fully open and 100 for fully closed.
+type GoToTiltPercentageCommand struct {
+	TiltPercentage uint8
+}
+
+func (c *GoToTiltPercentageCommand) ClusterID() ClusterID {
+	return ClusterClosuresWindowCovering
+}
+
+func (c *GoToTiltPercentageCommand) CommandID() CommandID {
+	return CommandWindowCoveringGoToTiltPercentage
+}
+
+func (c *GoToTiltPercentageCommand) DirectionServerToClient() bool {
+	return false
+}
+
+func (c *GoToTiltPercentageCommand) ParsePayload(data []byte) error {
+	return parsePayload(c, data)
+}
+
+func (c *GoToTiltPercentageCommand) SerializePayload() []byte {
+	return scf.Serialize(*c)
+}
@@ -0,0 +1,722 @@
+// Package zclserver answers ZCL requests addressed to the local endpoints of
+// the coordinator.
+//
+// The application registers the server side of clusters on its endpoints
+// together with their attributes. The Server then answers Read Attributes,
+// Write Attributes, Discover Attributes, Discover Commands and Configure
+// Reporting requests for these clusters, sends attribute reports as configured
+// by remote devices and generates Default Responses as required by the
+// specification. Cluster-specific commands are passed to a callback of the
+// cluster.
+//
+// Only frames sent from the client to the server side of a registered cluster
+// are handled. All other messages (including commands sent to client clusters
+// of the coordinator, like button presses) are passed on unchanged. However,
+// unicast requests for a cluster which is not registered on an endpoint with
+// other registered clusters are answered with a Default Response with
+// zcl.StatusUnsupportedCluster before they are passed on, so the application
+// must not answer them itself.
+package zclserver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/defaultresponse"
+	"github.com/GreenLightning/zigbee-conductor/dispatch"
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+var (
+	ErrClusterExists    = errors.New("cluster already registered")
+	ErrUnknownCluster   = errors.New("unknown cluster")
+	ErrUnknownAttribute = errors.New("unknown attribute")
+)
+
+// An Attribute is an attribute of a local cluster.
+type Attribute struct {
+	ID       zcl.AttributeID
+	DataType zcl.DataType
+
+	// Value is the initial value. It must be of a Go type accepted by
+	// zcl.SerializeValue for DataType.
+	Value interface{}
+
+	// Writable attributes can be written by remote devices.
+	Writable bool
+
+	// Reportable attributes can be configured for reporting by remote devices.
+	Reportable bool
+
+	// OnRead, if set, is called to obtain the current value instead of using
+	// the stored value. It is called from the dispatcher goroutine and must
+	// not block.
+	OnRead func() interface{}
+
+	// OnWrite, if set, is called before a write from a remote device is
+	// applied. Returning a status other than zcl.StatusSuccess rejects the
+	// write. It is called from the dispatcher goroutine and must not block.
+	//
+	// OnWrite is only called if the write passed all other checks, for an
+	// undivided write those of all records. However, a later record can still
+	// be rejected by its own OnWrite, which discards the whole undivided
+	// write, so OnWrite should only validate the value and not have side
+	// effects.
+	OnWrite func(value interface{}) zcl.Status
+}
+
+// A Cluster is the server side of a cluster on a local endpoint.
+type Cluster struct {
+	ID         zcl.ClusterID
+	Attributes []Attribute
+
+	// CommandsReceived and CommandsGenerated list the cluster-specific
+	// commands for the Discover Commands requests. Commands which are not
+	// listed in CommandsReceived are rejected with
+	// zcl.StatusUnsupportedClusterCommand.
+	CommandsReceived  []zcl.CommandID
+	CommandsGenerated []zcl.CommandID
+
+	// HandleCommand is called for cluster-specific commands. If it returns a
+	// command, the command is sent as the response, otherwise a Default
+	// Response with the returned status is sent (if required). It is called
+	// from the dispatcher goroutine and must not block.
+	HandleCommand func(message zigbee.IncomingMessage, frame zcl.Frame) (zcl.ClusterCommand, zcl.Status)
+}
+
+type cluster struct {
+	Cluster
+	endpoint   uint8
+	attributes map[zcl.AttributeID]*attribute
+	sorted     []zcl.AttributeID
+}
+
+type attribute struct {
+	Attribute
+	reporting *reporting
+}
+
+// reporting is the reporting configuration of an attribute. Reports are sent
+// to the device which configured the reporting.
+type reporting struct {
+	destination zigbee.Address
+	endpoint    uint8
+	minimum     time.Duration
+	maximum     time.Duration
+	change      interface{}
+
+	reported interface{} // last reported value
+	last     time.Time   // time of the last report
+	timer    *time.Timer
+}
+
+// Server answers ZCL requests for the registered clusters.
+type Server struct {
+	dispatcher *dispatch.Dispatcher
+
+	mutex     sync.Mutex
+	endpoints map[uint8]map[zcl.ClusterID]*cluster
+	closed    bool
+}
+
+// NewServer creates a Server and registers it as a handler with the dispatcher.
+func NewServer(dispatcher *dispatch.Dispatcher) *Server {
+	server := &Server{
+		dispatcher: dispatcher,
+		endpoints:  make(map[uint8]map[zcl.ClusterID]*cluster),
+	}
+	dispatcher.AddHandler(server)
+	return server
+}
+
+// AddCluster registers the server side of a cluster on a local endpoint.
+func (s *Server) AddCluster(endpoint uint8, definition Cluster) error {
+	c := &cluster{
+		Cluster:    definition,
+		endpoint:   endpoint,
+		attributes: make(map[zcl.AttributeID]*attribute),
+	}
+	for _, a := range definition.Attributes {
+		if _, ok := c.attributes[a.ID]; ok {
+			return fmt.Errorf("attribute %v registered twice", a.ID)
+		}
+		if _, err := zcl.SerializeValue(a.DataType, a.Value); err != nil {
+			return fmt.Errorf("attribute %v: %w", a.ID, err)
+		}
+		c.attributes[a.ID] = &attribute{Attribute: a}
+		c.sorted = append(c.sorted, a.ID)
+	}
+	sort.Slice(c.sorted, func(i, j int) bool { return c.sorted[i] < c.sorted[j] })
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	clusters, ok := s.endpoints[endpoint]
+	if !ok {
+		clusters = make(map[zcl.ClusterID]*cluster)
+		s.endpoints[endpoint] = clusters
+	}
+	if _, ok := clusters[definition.ID]; ok {
+		return fmt.Errorf("%w: %v on endpoint %d", ErrClusterExists, definition.ID, endpoint)
+	}
+	clusters[definition.ID] = c
+	return nil
+}
+
+// Get returns the stored value of an attribute.
+func (s *Server) Get(endpoint uint8, clusterID zcl.ClusterID, attributeID zcl.AttributeID) (interface{}, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	a, err := s.lookup(endpoint, clusterID, attributeID)
+	if err != nil {
+		return nil, err
+	}
+	return a.Value, nil
+}
+
+// Set changes the stored value of an attribute. If reporting has been
+// configured for the attribute and the change is large enough, a report is
+// sent (after the minimum reporting interval has passed).
+func (s *Server) Set(endpoint uint8, clusterID zcl.ClusterID, attributeID zcl.AttributeID, value interface{}) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	a, err := s.lookup(endpoint, clusterID, attributeID)
+	if err != nil {
+		return err
+	}
+	if _, err := zcl.SerializeValue(a.DataType, value); err != nil {
+		return fmt.Errorf("attribute %v: %w", attributeID, err)
+	}
+
+	a.Value = value
+	s.update(s.endpoints[endpoint][clusterID], a)
+	return nil
+}
+
+// Close stops sending periodic attribute reports.
+func (s *Server) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.closed = true
+	for _, clusters := range s.endpoints {
+		for _, c := range clusters {
+			for _, a := range c.attributes {
+				if a.reporting != nil && a.reporting.timer != nil {
+					a.reporting.timer.Stop()
+				}
+			}
+		}
+	}
+}
+
+// lookup must be called with the mutex held.
+func (s *Server) lookup(endpoint uint8, clusterID zcl.ClusterID, attributeID zcl.AttributeID) (*attribute, error) {
+	c, ok := s.endpoints[endpoint][clusterID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %v on endpoint %d", ErrUnknownCluster, clusterID, endpoint)
+	}
+	a, ok := c.attributes[attributeID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %v/%v", ErrUnknownAttribute, clusterID, attributeID)
+	}
+	return a, nil
+}
+
+// HandleMessage answers requests for the registered clusters.
+func (s *Server) HandleMessage(message zigbee.IncomingMessage) bool {
+	frame, err := zcl.ParseFrame(message.Data)
+	if err != nil || frame.DirectionServerToClient {
+		return false
+	}
+
+	s.mutex.Lock()
+	clusters, registered := s.endpoints[message.DestinationEndpoint]
+	c, ok := clusters[zcl.ClusterID(message.ClusterID)]
+	s.mutex.Unlock()
+	if !ok {
+		if registered && !message.Broadcast && !frame.DisableDefaultResponse && expectsResponse(frame) {
+			s.reply(message, defaultresponse.Frame(frame, zcl.StatusUnsupportedCluster))
+		}
+		return false
+	}
+
+	var response *zcl.Frame
+	var status zcl.Status
+
+	switch {
+	case frame.Type == zcl.FrameTypeGlobal && frame.ManufacturerSpecific:
+		status = zcl.StatusUnsupportedManufacturerGeneralCommand
+	case frame.Type == zcl.FrameTypeGlobal:
+		response, status = s.handleGlobal(message, c, frame)
+	case frame.ManufacturerSpecific:
+		status = zcl.StatusUnsupportedManufacturerClusterCommand
+	default:
+		response, status = s.handleCommand(message, c, frame)
+	}
+
+	if response == nil {
+		if frame.Type == zcl.FrameTypeGlobal && frame.CommandID == zcl.CommandWriteAttributesNoResponse {
+			return true
+		}
+		if status == zcl.StatusSuccess && frame.DisableDefaultResponse {
+			return true
+		}
+		defaultResponse := defaultresponse.Frame(frame, status)
+		response = &defaultResponse
+	}
+
+	response.DirectionServerToClient = true
+	response.DisableDefaultResponse = true
+	response.TransSeqNumber = frame.TransSeqNumber
+	s.reply(message, *response)
+
+	return true
+}
+
+// reply sends the response to the source of the message.
+func (s *Server) reply(message zigbee.IncomingMessage, response zcl.Frame) {
+	outgoing := zigbee.OutgoingMessage{
+		Destination:         zigbee.Address{Mode: zigbee.AddressModeNWK, Short: message.Source.Short},
+		DestinationEndpoint: message.SourceEndpoint,
+		SourceEndpoint:      message.DestinationEndpoint,
+		ClusterID:           message.ClusterID,
+		Radius:              zigbee.DefaultRadius,
+		Data:                zcl.SerializeFrame(response),
+	}
+
+	// Must not block the dispatcher.
+	go s.dispatcher.Send(outgoing)
+}
+
+// expectsResponse reports whether the frame is a request, i.e. neither a
+// response itself nor a Write Attributes No Response command.
+func expectsResponse(frame zcl.Frame) bool {
+	if frame.Type != zcl.FrameTypeGlobal {
+		return true
+	}
+	switch frame.CommandID {
+	case zcl.CommandReadAttributesResponse,
+		zcl.CommandWriteAttributesResponse,
+		zcl.CommandWriteAttributesNoResponse,
+		zcl.CommandConfigureReportingResponse,
+		zcl.CommandReadReportingConfigurationResponse,
+		zcl.CommandDefaultResponse,
+		zcl.CommandDiscoverAttributesResponse,
+		zcl.CommandWriteAttributesStructuredResponse,
+		zcl.CommandDiscoverCommandsReceivedResponse,
+		zcl.CommandDiscoverCommandsGeneratedResponse,
+		zcl.CommandDiscoverAttributesExtendedResponse:
+		return false
+	default:
+		return true
+	}
+}
+
+func (s *Server) handleCommand(message zigbee.IncomingMessage, c *cluster, frame zcl.Frame) (*zcl.Frame, zcl.Status) {
+	if c.HandleCommand == nil || !containsCommand(c.CommandsReceived, frame.CommandID) {
+		return nil, zcl.StatusUnsupportedClusterCommand
+	}
+	command, status := c.HandleCommand(message, frame)
+	if command == nil {
+		return nil, status
+	}
+	response := zcl.NewClusterCommandFrame(command)
+	return &response, zcl.StatusSuccess
+}
+
+func (s *Server) handleGlobal(message zigbee.IncomingMessage, c *cluster, frame zcl.Frame) (*zcl.Frame, zcl.Status) {
+	var commandID zcl.CommandID
+	var data []byte
+	var err error
+
+	switch frame.CommandID {
+	case zcl.CommandReadAttributes:
+		var cmd zcl.ReadAttributesCommand
+		if cmd, err = zcl.ParseReadAttributesCommand(frame.Data); err != nil {
+			return nil, zcl.StatusMalformedCommand
+		}
+		commandID = zcl.CommandReadAttributesResponse
+		data, err = zcl.SerializeReadAttributesResponseCommand(s.readAttributes(c, cmd))
+
+	case zcl.CommandWriteAttributes, zcl.CommandWriteAttributesUndivided, zcl.CommandWriteAttributesNoResponse:
+		var cmd zcl.WriteAttributesCommand
+		if cmd, err = zcl.ParseWriteAttributesCommand(frame.Data); err != nil {
+			return nil, zcl.StatusMalformedCommand
+		}
+		response := s.writeAttributes(c, cmd, frame.CommandID == zcl.CommandWriteAttributesUndivided)
+		if frame.CommandID == zcl.CommandWriteAttributesNoResponse {
+			return nil, zcl.StatusSuccess
+		}
+		commandID = zcl.CommandWriteAttributesResponse
+		data = zcl.SerializeWriteAttributesResponseCommand(response)
+
+	case zcl.CommandDiscoverAttributes:
+		var cmd zcl.DiscoverAttributesCommand
+		if cmd, err = zcl.ParseDiscoverAttributesCommand(frame.Data); err != nil {
+			return nil, zcl.StatusMalformedCommand
+		}
+		commandID = zcl.CommandDiscoverAttributesResponse
+		data = zcl.SerializeDiscoverAttributesResponseCommand(discoverAttributes(c, cmd))
+
+	case zcl.CommandDiscoverCommandsReceived, zcl.CommandDiscoverCommandsGenerated:
+		var cmd zcl.DiscoverCommandsCommand
+		if cmd, err = zcl.ParseDiscoverCommandsCommand(frame.Data); err != nil {
+			return nil, zcl.StatusMalformedCommand
+		}
+		commands := c.CommandsReceived
+		commandID = zcl.CommandDiscoverCommandsReceivedResponse
+		if frame.CommandID == zcl.CommandDiscoverCommandsGenerated {
+			commands = c.CommandsGenerated
+			commandID = zcl.CommandDiscoverCommandsGeneratedResponse
+		}
+		data = zcl.SerializeDiscoverCommandsResponseCommand(discoverCommands(commands, cmd))
+
+	case zcl.CommandConfigureReporting:
+		var cmd zcl.ConfigureReportingCommand
+		if cmd, err = zcl.ParseConfigureReportingCommand(frame.Data); err != nil {
+			return nil, zcl.StatusMalformedCommand
+		}
+		commandID = zcl.CommandConfigureReportingResponse
+		data = zcl.SerializeConfigureReportingResponseCommand(s.configureReporting(message, c, cmd))
+
+	default:
+		return nil, zcl.StatusUnsupportedGeneralCommand
+	}
+
+	if err != nil {
+		return nil, zcl.StatusFailure
+	}
+
+	return &zcl.Frame{
+		FrameHeader: zcl.FrameHeader{
+			Type:      zcl.FrameTypeGlobal,
+			CommandID: commandID,
+		},
+		Data: data,
+	}, zcl.StatusSuccess
+}
+
+func (s *Server) readAttributes(c *cluster, cmd zcl.ReadAttributesCommand) zcl.ReadAttributesResponseCommand {
+	var response zcl.ReadAttributesResponseCommand
+	for _, id := range cmd.Attributes {
+		s.mutex.Lock()
+		a, ok := c.attributes[id]
+		var definition Attribute
+		if ok {
+			definition = a.Attribute
+		}
+		s.mutex.Unlock()
+
+		if !ok {
+			response.Records = append(response.Records, zcl.ReadAttributeStatusRecord{AttributeID: id, Status: zcl.StatusUnsupportedAttribute})
+			continue
+		}
+
+		value := definition.Value
+		if definition.OnRead != nil {
+			value = definition.OnRead()
+		}
+		response.Records = append(response.Records, zcl.ReadAttributeStatusRecord{
+			AttributeID: id,
+			Status:      zcl.StatusSuccess,
+			DataType:    definition.DataType,
+			Value:       value,
+		})
+	}
+	return response
+}
+
+func (s *Server) writeAttributes(c *cluster, cmd zcl.WriteAttributesCommand, undivided bool) zcl.WriteAttributesResponseCommand {
+	statuses := make([]zcl.Status, len(cmd.Records))
+	onWrites := make([]func(interface{}) zcl.Status, len(cmd.Records))
+	rejected := false
+	for i, record := range cmd.Records {
+		statuses[i], onWrites[i] = s.checkWrite(c, record)
+		if statuses[i] != zcl.StatusSuccess {
+			rejected = true
+		}
+	}
+
+	// OnWrite is only called once the static checks have passed, for an
+	// undivided write those of all records.
+	for i, record := range cmd.Records {
+		if undivided && rejected {
+			break
+		}
+		if statuses[i] == zcl.StatusSuccess && onWrites[i] != nil {
+			statuses[i] = onWrites[i](record.Value)
+			if statuses[i] != zcl.StatusSuccess {
+				rejected = true
+			}
+		}
+	}
+
+	var response zcl.WriteAttributesResponseCommand
+	for i, record := range cmd.Records {
+		response.Records = append(response.Records, zcl.WriteAttributeStatusRecord{Status: statuses[i], AttributeID: record.AttributeID})
+	}
+
+	if undivided && rejected {
+		return response
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i, record := range cmd.Records {
+		if statuses[i] == zcl.StatusSuccess {
+			a := c.attributes[record.AttributeID]
+			a.Value = record.Value
+			s.update(c, a)
+		}
+	}
+
+	return response
+}
+
+// checkWrite checks whether the record may be written and returns the OnWrite
+// function of the attribute, which has not been called yet.
+func (s *Server) checkWrite(c *cluster, record zcl.WriteAttributeRecord) (zcl.Status, func(interface{}) zcl.Status) {
+	s.mutex.Lock()
+	a, ok := c.attributes[record.AttributeID]
+	var definition Attribute
+	if ok {
+		definition = a.Attribute
+	}
+	s.mutex.Unlock()
+
+	switch {
+	case !ok:
+		return zcl.StatusUnsupportedAttribute, nil
+	case !definition.Writable:
+		return zcl.StatusReadOnly, nil
+	case record.DataType != definition.DataType:
+		return zcl.StatusInvalidDataType, nil
+	default:
+		return zcl.StatusSuccess, definition.OnWrite
+	}
+}
+
+func discoverAttributes(c *cluster, cmd zcl.DiscoverAttributesCommand) zcl.DiscoverAttributesResponseCommand {
+	response := zcl.DiscoverAttributesResponseCommand{Complete: true}
+	for _, id := range c.sorted {
+		if id < cmd.StartAttributeID {
+			continue
+		}
+		if len(response.Records) == int(cmd.MaximumAttributeIDs) {
+			response.Complete = false
+			break
+		}
+		response.Records = append(response.Records, zcl.DiscoverAttributeRecord{AttributeID: id, DataType: c.attributes[id].DataType})
+	}
+	return response
+}
+
+func discoverCommands(commands []zcl.CommandID, cmd zcl.DiscoverCommandsCommand) zcl.DiscoverCommandsResponseCommand {
+	sorted := append([]zcl.CommandID(nil), commands...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	response := zcl.DiscoverCommandsResponseCommand{Complete: true}
+	for _, id := range sorted {
+		if id < cmd.StartCommandID {
+			continue
+		}
+		if len(response.Commands) == int(cmd.MaximumCommandIDs) {
+			response.Complete = false
+			break
+		}
+		response.Commands = append(response.Commands, id)
+	}
+	return response
+}
+
+func containsCommand(commands []zcl.CommandID, id zcl.CommandID) bool {
+	for _, command := range commands {
+		if command == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) configureReporting(message zigbee.IncomingMessage, c *cluster, cmd zcl.ConfigureReportingCommand) zcl.ConfigureReportingResponseCommand {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var response zcl.ConfigureReportingResponseCommand
+	for _, record := range cmd.Records {
+		status := zcl.StatusSuccess
+		a, ok := c.attributes[record.AttributeID]
+		switch {
+		case !ok || record.Direction != zcl.ReportingDirectionSend:
+			// Receiving reports is not supported.
+			status = zcl.StatusUnsupportedAttribute
+		case !a.Reportable:
+			status = zcl.StatusUnreportableAttribute
+		case record.DataType != a.DataType:
+			status = zcl.StatusInvalidDataType
+		default:
+			s.configure(c, a, message, record)
+		}
+		response.Records = append(response.Records, zcl.ConfigureReportingStatusRecord{
+			Status:      status,
+			Direction:   record.Direction,
+			AttributeID: record.AttributeID,
+		})
+	}
+	return response
+}
+
+// configure must be called with the mutex held.
+func (s *Server) configure(c *cluster, a *attribute, message zigbee.IncomingMessage, record zcl.ConfigureReportingRecord) {
+	if a.reporting != nil && a.reporting.timer != nil {
+		a.reporting.timer.Stop()
+	}
+	a.reporting = nil
+
+	// A maximum reporting interval of 0xffff disables reporting.
+	if record.MaximumReportingInterval == 0xffff {
+		return
+	}
+
+	r := &reporting{
+		destination: zigbee.Address{Mode: zigbee.AddressModeNWK, Short: message.Source.Short},
+		endpoint:    message.SourceEndpoint,
+		minimum:     time.Duration(record.MinimumReportingInterval) * time.Second,
+		maximum:     time.Duration(record.MaximumReportingInterval) * time.Second,
+		change:      record.ReportableChange,
+		reported:    a.Value,
+		last:        time.Now(),
+	}
+	a.reporting = r
+	s.schedule(c, a, r.maximum)
+}
+
+// update sends a report if the value of the attribute has changed enough since
+// the last report. If the minimum reporting interval has not passed yet, the
+// report is delayed. update must be called with the mutex held.
+func (s *Server) update(c *cluster, a *attribute) {
+	r := a.reporting
+	if r == nil || !changed(a.DataType, r.reported, a.Value, r.change) {
+		return
+	}
+	if wait := r.minimum - time.Since(r.last); wait > 0 {
+		s.schedule(c, a, wait)
+		return
+	}
+	s.report(c, a)
+}
+
+// schedule sends a report after the delay (if the reporting configuration has
+// not been changed in the meantime). A delay of zero does not schedule a
+// report. schedule must be called with the mutex held.
+func (s *Server) schedule(c *cluster, a *attribute, delay time.Duration) {
+	r := a.reporting
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+	if delay <= 0 || s.closed {
+		return
+	}
+	r.timer = time.AfterFunc(delay, func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		if a.reporting == r && !s.closed {
+			s.report(c, a)
+		}
+	})
+}
+
+// report must be called with the mutex held.
+func (s *Server) report(c *cluster, a *attribute) {
+	r := a.reporting
+	r.reported = a.Value
+	r.last = time.Now()
+	s.schedule(c, a, r.maximum)
+
+	data, err := zcl.SerializeReportAttributesCommand(zcl.ReportAttributesCommand{
+		Reports: []zcl.AttributeReport{{AttributeID: a.ID, DataType: a.DataType, Value: a.Value}},
+	})
+	if err != nil {
+		return
+	}
+
+	message := zigbee.OutgoingMessage{
+		Destination:         r.destination,
+		DestinationEndpoint: r.endpoint,
+		SourceEndpoint:      c.endpoint,
+		ClusterID:           uint16(c.ID),
+		Radius:              zigbee.DefaultRadius,
+		Data: zcl.SerializeFrame(zcl.Frame{
+			FrameHeader: zcl.FrameHeader{
+				Type:                    zcl.FrameTypeGlobal,
+				DirectionServerToClient: true,
+				DisableDefaultResponse:  true,
+				TransSeqNumber:          s.dispatcher.NextTransactionSequenceNumber(),
+				CommandID:               zcl.CommandReportAttributes,
+			},
+			Data: data,
+		}),
+	}
+
+	go s.dispatcher.Send(message)
+}
+
+// changed reports whether a report has to be sent for the new value. Analog
+// values must change by at least the reportable change, discrete values are
+// reported on every change.
+func changed(typ zcl.DataType, previous, current, change interface{}) bool {
+	if !typ.IsAnalog() {
+		return !reflect.DeepEqual(previous, current)
+	}
+	a, okA := toFloat64(previous)
+	b, okB := toFloat64(current)
+	if !okA || !okB {
+		return !reflect.DeepEqual(previous, current)
+	}
+	threshold, _ := toFloat64(change)
+	if threshold == 0 {
+		return a != b
+	}
+	return math.Abs(b-a) >= threshold
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,368 @@
+package zclserver
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/GreenLightning/zigbee-conductor/dispatch"
+	"github.com/GreenLightning/zigbee-conductor/zcl"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+// testDevice sends requests to the coordinator and records the responses.
+type testDevice struct {
+	incoming chan zigbee.IncomingMessage
+	sent     chan zigbee.OutgoingMessage
+}
+
+func newTestDevice() *testDevice {
+	return &testDevice{
+		incoming: make(chan zigbee.IncomingMessage, 16),
+		sent:     make(chan zigbee.OutgoingMessage, 16),
+	}
+}
+
+func (d *testDevice) Start() (chan zigbee.IncomingMessage, error) { return d.incoming, nil }
+func (d *testDevice) Close() error                                { close(d.incoming); return nil }
+func (d *testDevice) PermitJoining(enabled bool) error            { return nil }
+
+func (d *testDevice) Send(message zigbee.OutgoingMessage) error {
+	d.sent <- message
+	return nil
+}
+
+func (d *testDevice) request(clusterID zcl.ClusterID, frame zcl.Frame) {
+	d.incoming <- zigbee.IncomingMessage{
+		Source:              zigbee.Address{Mode: zigbee.AddressModeNWK, Short: 0x1234},
+		SourceEndpoint:      2,
+		DestinationEndpoint: 1,
+		ClusterID:           uint16(clusterID),
+		Data:                zcl.SerializeFrame(frame),
+	}
+}
+
+func (d *testDevice) global(clusterID zcl.ClusterID, tsn uint8, commandID zcl.CommandID, data []byte) {
+	d.request(clusterID, zcl.Frame{
+		FrameHeader: zcl.FrameHeader{Type: zcl.FrameTypeGlobal, TransSeqNumber: tsn, CommandID: commandID},
+		Data:        data,
+	})
+}
+
+func (d *testDevice) response(t *testing.T, tsn uint8, commandID zcl.CommandID) []byte {
+	t.Helper()
+	select {
+	case message := <-d.sent:
+		if message.Destination.Short != 0x1234 || message.DestinationEndpoint != 2 || message.SourceEndpoint != 1 {
+			t.Errorf("wrong addressing: %+v", message)
+		}
+		frame, err := zcl.ParseFrame(message.Data)
+		if err != nil {
+			t.Fatal("unexpected err:", err)
+		}
+		if frame.TransSeqNumber != tsn || frame.CommandID != commandID || !frame.DirectionServerToClient {
+			t.Fatalf("unexpected frame: %+v", frame)
+		}
+		return frame.Data
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+		return nil
+	}
+}
+
+// setup returns a device and a server with the Basic cluster on endpoint 1.
+// The returned function closes the server and the dispatcher.
+func setup(t *testing.T) (*testDevice, *Server, chan zigbee.IncomingMessage, func()) {
+	device := newTestDevice()
+	dispatcher := dispatch.New(device)
+	output, err := dispatcher.Start()
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	server := NewServer(dispatcher)
+	closeAll := func() {
+		server.Close()
+		dispatcher.Close()
+	}
+
+	err = server.AddCluster(1, Cluster{
+		ID: zcl.ClusterGeneralBasic,
+		Attributes: []Attribute{
+			{ID: 0x0000, DataType: zcl.DataTypeUint8, Value: uint8(3)},
+			{ID: 0x0005, DataType: zcl.DataTypeCharacterString, Value: "Conductor"},
+			{ID: 0x0010, DataType: zcl.DataTypeCharacterString, Value: "", Writable: true},
+		},
+	})
+	if err != nil {
+		closeAll()
+		t.Fatal("unexpected err:", err)
+	}
+
+	return device, server, output, closeAll
+}
+
+func TestReadAttributes(t *testing.T) {
+	device, _, _, closeAll := setup(t)
+	defer closeAll()
+
+	device.global(zcl.ClusterGeneralBasic, 1, zcl.CommandReadAttributes,
+		zcl.SerializeReadAttributesCommand(zcl.ReadAttributesCommand{Attributes: []zcl.AttributeID{0x0005, 0x0004}}))
+
+	cmd, err := zcl.ParseReadAttributesResponseCommand(device.response(t, 1, zcl.CommandReadAttributesResponse))
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	expected := []zcl.ReadAttributeStatusRecord{
+		{AttributeID: 0x0005, Status: zcl.StatusSuccess, DataType: zcl.DataTypeCharacterString, Value: "Conductor"},
+		{AttributeID: 0x0004, Status: zcl.StatusUnsupportedAttribute},
+	}
+	if !reflect.DeepEqual(cmd.Records, expected) {
+		t.Errorf("got %+v, expected %+v", cmd.Records, expected)
+	}
+}
+
+func TestWriteAttributes(t *testing.T) {
+	device, server, _, closeAll := setup(t)
+	defer closeAll()
+
+	data, err := zcl.SerializeWriteAttributesCommand(zcl.WriteAttributesCommand{Records: []zcl.WriteAttributeRecord{
+		{AttributeID: 0x0010, DataType: zcl.DataTypeCharacterString, Value: "Hallway"},
+		{AttributeID: 0x0005, DataType: zcl.DataTypeCharacterString, Value: "Other"},
+		{AttributeID: 0x0004, DataType: zcl.DataTypeCharacterString, Value: "Other"},
+	}})
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	device.global(zcl.ClusterGeneralBasic, 2, zcl.CommandWriteAttributes, data)
+
+	cmd, err := zcl.ParseWriteAttributesResponseCommand(device.response(t, 2, zcl.CommandWriteAttributesResponse))
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	expected := []zcl.WriteAttributeStatusRecord{
+		{Status: zcl.StatusReadOnly, AttributeID: 0x0005},
+		{Status: zcl.StatusUnsupportedAttribute, AttributeID: 0x0004},
+	}
+	if !reflect.DeepEqual(cmd.Records, expected) {
+		t.Errorf("got %+v, expected %+v", cmd.Records, expected)
+	}
+
+	if value, _ := server.Get(1, zcl.ClusterGeneralBasic, 0x0010); value != "Hallway" {
+		t.Errorf("value not written: %v", value)
+	}
+	if value, _ := server.Get(1, zcl.ClusterGeneralBasic, 0x0005); value != "Conductor" {
+		t.Errorf("read-only value written: %v", value)
+	}
+}
+
+func TestWriteAttributesUndivided(t *testing.T) {
+	device, server, _, closeAll := setup(t)
+	defer closeAll()
+
+	var written []interface{}
+	err := server.AddCluster(1, Cluster{
+		ID: zcl.ClusterGeneralIdentify,
+		Attributes: []Attribute{
+			{ID: 0x0000, DataType: zcl.DataTypeUint16, Value: uint16(0), Writable: true, OnWrite: func(value interface{}) zcl.Status {
+				written = append(written, value)
+				return zcl.StatusSuccess
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	// OnWrite is not called if another record is rejected.
+	data, err := zcl.SerializeWriteAttributesCommand(zcl.WriteAttributesCommand{Records: []zcl.WriteAttributeRecord{
+		{AttributeID: 0x0000, DataType: zcl.DataTypeUint16, Value: uint16(10)},
+		{AttributeID: 0x0001, DataType: zcl.DataTypeUint8, Value: uint8(1)},
+	}})
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	device.global(zcl.ClusterGeneralIdentify, 3, zcl.CommandWriteAttributesUndivided, data)
+
+	cmd, err := zcl.ParseWriteAttributesResponseCommand(device.response(t, 3, zcl.CommandWriteAttributesResponse))
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	expected := []zcl.WriteAttributeStatusRecord{{Status: zcl.StatusUnsupportedAttribute, AttributeID: 0x0001}}
+	if !reflect.DeepEqual(cmd.Records, expected) {
+		t.Errorf("got %+v, expected %+v", cmd.Records, expected)
+	}
+	if len(written) != 0 {
+		t.Errorf("OnWrite called: %v", written)
+	}
+	if value, _ := server.Get(1, zcl.ClusterGeneralIdentify, 0x0000); value != uint16(0) {
+		t.Errorf("value written: %v", value)
+	}
+
+	data, err = zcl.SerializeWriteAttributesCommand(zcl.WriteAttributesCommand{Records: []zcl.WriteAttributeRecord{
+		{AttributeID: 0x0000, DataType: zcl.DataTypeUint16, Value: uint16(10)},
+	}})
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	device.global(zcl.ClusterGeneralIdentify, 4, zcl.CommandWriteAttributesUndivided, data)
+	device.response(t, 4, zcl.CommandWriteAttributesResponse)
+
+	if !reflect.DeepEqual(written, []interface{}{uint16(10)}) {
+		t.Errorf("wrong OnWrite calls: %v", written)
+	}
+	if value, _ := server.Get(1, zcl.ClusterGeneralIdentify, 0x0000); value != uint16(10) {
+		t.Errorf("value not written: %v", value)
+	}
+}
+
+func TestDiscoverAttributes(t *testing.T) {
+	device, _, _, closeAll := setup(t)
+	defer closeAll()
+
+	device.global(zcl.ClusterGeneralBasic, 3, zcl.CommandDiscoverAttributes,
+		zcl.SerializeDiscoverAttributesCommand(zcl.DiscoverAttributesCommand{StartAttributeID: 0x0001, MaximumAttributeIDs: 1}))
+
+	cmd, err := zcl.ParseDiscoverAttributesResponseCommand(device.response(t, 3, zcl.CommandDiscoverAttributesResponse))
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	expected := zcl.DiscoverAttributesResponseCommand{
+		Complete: false,
+		Records:  []zcl.DiscoverAttributeRecord{{AttributeID: 0x0005, DataType: zcl.DataTypeCharacterString}},
+	}
+	if !reflect.DeepEqual(cmd, expected) {
+		t.Errorf("got %+v, expected %+v", cmd, expected)
+	}
+}
+
+func TestDefaultResponses(t *testing.T) {
+	device, _, output, closeAll := setup(t)
+	defer closeAll()
+
+	// Cluster-specific commands are not supported by the Basic cluster.
+	device.request(zcl.ClusterGeneralBasic, zcl.Frame{
+		FrameHeader: zcl.FrameHeader{Type: zcl.FrameTypeLocal, TransSeqNumber: 4, CommandID: 0x00},
+	})
+	cmd, err := zcl.ParseDefaultResponseCommand(device.response(t, 4, zcl.CommandDefaultResponse))
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if cmd.CommandID != 0x00 || cmd.Status != zcl.StatusUnsupportedClusterCommand {
+		t.Errorf("unexpected default response: %+v", cmd)
+	}
+
+	device.global(zcl.ClusterGeneralBasic, 5, zcl.CommandReadReportingConfiguration, nil)
+	cmd, err = zcl.ParseDefaultResponseCommand(device.response(t, 5, zcl.CommandDefaultResponse))
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if cmd.Status != zcl.StatusUnsupportedGeneralCommand {
+		t.Errorf("unexpected default response: %+v", cmd)
+	}
+
+	// Commands for other clusters are rejected, but still passed on to the
+	// application.
+	toggle := zcl.NewClusterCommandFrame(&zcl.ToggleCommand{})
+	toggle.TransSeqNumber = 6
+	device.request(zcl.ClusterGeneralOnOff, toggle)
+	select {
+	case message := <-output:
+		if message.ClusterID != uint16(zcl.ClusterGeneralOnOff) {
+			t.Errorf("unexpected message: %+v", message)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+	cmd, err = zcl.ParseDefaultResponseCommand(device.response(t, 6, zcl.CommandDefaultResponse))
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if cmd.CommandID != zcl.CommandOnOffToggle || cmd.Status != zcl.StatusUnsupportedCluster {
+		t.Errorf("unexpected default response: %+v", cmd)
+	}
+
+	// Broadcasts, responses and frames with DisableDefaultResponse are not
+	// answered.
+	device.incoming <- zigbee.IncomingMessage{
+		Source:              zigbee.Address{Mode: zigbee.AddressModeNWK, Short: 0x1234},
+		SourceEndpoint:      2,
+		DestinationEndpoint: 1,
+		ClusterID:           uint16(zcl.ClusterGeneralOnOff),
+		Broadcast:           true,
+		Data:                zcl.SerializeFrame(toggle),
+	}
+	device.global(zcl.ClusterGeneralOnOff, 7, zcl.CommandDefaultResponse, zcl.SerializeDefaultResponseCommand(zcl.DefaultResponseCommand{}))
+	toggle.DisableDefaultResponse = true
+	device.request(zcl.ClusterGeneralOnOff, toggle)
+	for i := 0; i < 3; i++ {
+		select {
+		case <-output:
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	}
+	select {
+	case message := <-device.sent:
+		t.Errorf("unexpected message: %+v", message)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestReporting(t *testing.T) {
+	device, server, _, closeAll := setup(t)
+	defer closeAll()
+
+	err := server.AddCluster(1, Cluster{
+		ID: zcl.ClusterMSTemperatureMeasurement,
+		Attributes: []Attribute{
+			{ID: 0x0000, DataType: zcl.DataTypeInt16, Value: int16(2000), Reportable: true},
+		},
+	})
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	data, err := zcl.SerializeConfigureReportingCommand(zcl.ConfigureReportingCommand{Records: []zcl.ConfigureReportingRecord{
+		{Direction: zcl.ReportingDirectionSend, AttributeID: 0x0000, DataType: zcl.DataTypeInt16, MaximumReportingInterval: 3600, ReportableChange: int16(50)},
+	}})
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	device.global(zcl.ClusterMSTemperatureMeasurement, 6, zcl.CommandConfigureReporting, data)
+
+	cmd, err := zcl.ParseConfigureReportingResponseCommand(device.response(t, 6, zcl.CommandConfigureReportingResponse))
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if len(cmd.Records) != 1 || cmd.Records[0].Status != zcl.StatusSuccess {
+		t.Fatalf("unexpected response: %+v", cmd)
+	}
+
+	// Below the reportable change.
+	server.Set(1, zcl.ClusterMSTemperatureMeasurement, 0x0000, int16(2020))
+	server.Set(1, zcl.ClusterMSTemperatureMeasurement, 0x0000, int16(2100))
+
+	select {
+	case message := <-device.sent:
+		frame, err := zcl.ParseFrame(message.Data)
+		if err != nil {
+			t.Fatal("unexpected err:", err)
+		}
+		report, err := zcl.ParseReportAttributesCommand(frame.Data)
+		if err != nil {
+			t.Fatal("unexpected err:", err)
+		}
+		if frame.CommandID != zcl.CommandReportAttributes || len(report.Reports) != 1 || report.Reports[0].Value != int16(2100) {
+			t.Errorf("unexpected report: %+v %+v", frame, report)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+
+	select {
+	case message := <-device.sent:
+		t.Errorf("unexpected message: %+v", message)
+	default:
+	}
+}
@@ -52,6 +52,10 @@ const (
 	ClusterMgmtNWKIEEEJoiningListReq = 0x003a
 )
 
+// ClusterResponseFlag is set in the cluster ID of all responses. The cluster ID
+// of a response is the cluster ID of the request with this flag set.
+const ClusterResponseFlag = 0x8000
+
 const (
 	ClusterNWKAddrRsp               = 0x8000
 	ClusterIEEEAddrRsp              = 0x8001
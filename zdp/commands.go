@@ -16,6 +16,19 @@ type IEEEAddrReq struct {
 	StartIndex        uint8
 }
 
+type NodeDescReq struct {
+	NWKAddrOfInterest uint16
+}
+
+type PowerDescReq struct {
+	NWKAddrOfInterest uint16
+}
+
+type SimpleDescReq struct {
+	NWKAddrOfInterest uint16
+	Endpoint          uint8
+}
+
 type ActiveEPReq struct {
 	NWKAddrOfInterest uint16
 }
@@ -27,6 +40,14 @@ type MatchDescReq struct {
 	OutClusters       []uint16
 }
 
+type ComplexDescReq struct {
+	NWKAddrOfInterest uint16
+}
+
+type UserDescReq struct {
+	NWKAddrOfInterest uint16
+}
+
 type DeviceAnnce struct {
 	NWKAddr    uint16
 	IEEEAddr   zigbee.MACAddress
@@ -73,6 +94,45 @@ type ReplaceDeviceReq struct {
 	NewEndpoint uint8
 }
 
+type MgmtNWKDiscReq struct {
+	ScanChannels uint32
+	ScanDuration uint8
+	StartIndex   uint8
+}
+
+type MgmtLqiReq struct {
+	StartIndex uint8
+}
+
+type MgmtRtgReq struct {
+	StartIndex uint8
+}
+
+type MgmtBindReq struct {
+	StartIndex uint8
+}
+
+// MgmtLeaveReq requests the remote device to leave the network. If
+// DeviceAddress is zero, the remote device leaves itself, otherwise it asks
+// the given child to leave.
+type MgmtLeaveReq struct {
+	DeviceAddress  zigbee.MACAddress
+	RemoveChildren bool
+	Rejoin         bool
+}
+
+// MgmtNWKUpdateReq requests an energy scan (ScanDuration 0x00 to 0x05, repeated
+// ScanCount times), a channel change (ScanDurationChannelChange) or a change of
+// the channel mask and network manager (ScanDurationChangeManager). ScanCount,
+// NWKUpdateID and NWKManagerAddr are only present if required by ScanDuration.
+type MgmtNWKUpdateReq struct {
+	ScanChannels   uint32
+	ScanDuration   uint8
+	ScanCount      uint8  `scf:"if=ScanDuration<=0x05"`
+	NWKUpdateID    uint8  `scf:"if=ScanDuration>=0xfe"`
+	NWKManagerAddr uint16 `scf:"if=ScanDuration==0xff"`
+}
+
 type NWKAddrRsp struct {
 	Status            Status
 	IEEEAddrRemoteDev zigbee.MACAddress
@@ -89,6 +149,27 @@ type IEEEAddrRsp struct {
 	NWKAddrAssocDevs  []uint16
 }
 
+// The descriptor of a NodeDescRsp, PowerDescRsp or SimpleDescRsp is only
+// present if the status is success.
+
+type NodeDescRsp struct {
+	Status            Status
+	NWKAddrOfInterest uint16
+	NodeDescriptor    NodeDescriptor
+}
+
+type PowerDescRsp struct {
+	Status            Status
+	NWKAddrOfInterest uint16
+	PowerDescriptor   PowerDescriptor
+}
+
+type SimpleDescRsp struct {
+	Status            Status
+	NWKAddrOfInterest uint16
+	SimpleDescriptor  SimpleDescriptor
+}
+
 type ActiveEPRsp struct {
 	Status            Status
 	NWKAddrOfInterest uint16
@@ -101,6 +182,19 @@ type MatchDescRsp struct {
 	Matches           []uint8
 }
 
+// ComplexDescriptor contains the compressed XML tags of the complex descriptor.
+type ComplexDescRsp struct {
+	Status            Status
+	NWKAddrOfInterest uint16
+	ComplexDescriptor []uint8
+}
+
+type UserDescRsp struct {
+	Status            Status
+	NWKAddrOfInterest uint16
+	UserDescriptor    string
+}
+
 type ParentAnnceRsp struct {
 	Status    Status
 	ChildInfo []zigbee.MACAddress
@@ -122,9 +216,72 @@ type BindRegisterRsp struct {
 	Status              Status
 	BindingTableEntries uint16
 	BindingTableCount   uint16
-	BindingTable        []uint8 // ?
+	BindingTable        []BindingTableEntry
+}
+
+// BindingTableEntry is the destination of a binding. DstAddress is either a
+// group address (AddressModeGroup) or an IEEE address (AddressModeIEEE). In the
+// latter case DstEndpoint is the destination endpoint.
+type BindingTableEntry struct {
+	SrcAddress  zigbee.MACAddress
+	SrcEndpoint uint8
+	ClusterID   uint16
+	DstAddress  zigbee.Address
+	DstEndpoint uint8
 }
 
 type ReplaceDeviceRsp struct {
 	Status Status
 }
+
+// MgmtNWKDiscRsp contains a part of the list of networks discovered by the
+// remote device, starting at StartIndex. NetworkCount is the total number of
+// networks.
+type MgmtNWKDiscRsp struct {
+	Status       Status
+	NetworkCount uint8
+	StartIndex   uint8
+	NetworkList  []NetworkDescriptor
+}
+
+// MgmtLqiRsp contains a part of the neighbor table of the remote device,
+// starting at StartIndex. NeighborTableEntries is the total number of entries.
+type MgmtLqiRsp struct {
+	Status               Status
+	NeighborTableEntries uint8
+	StartIndex           uint8
+	NeighborTableList    []NeighborTableEntry
+}
+
+// MgmtRtgRsp contains a part of the routing table of the remote device,
+// starting at StartIndex. RoutingTableEntries is the total number of entries.
+type MgmtRtgRsp struct {
+	Status              Status
+	RoutingTableEntries uint8
+	StartIndex          uint8
+	RoutingTableList    []RoutingTableEntry
+}
+
+// MgmtBindRsp contains a part of the binding table of the remote device,
+// starting at StartIndex. BindingTableEntries is the total number of entries.
+type MgmtBindRsp struct {
+	Status              Status
+	BindingTableEntries uint8
+	StartIndex          uint8
+	BindingTableList    []BindingTableEntry
+}
+
+type MgmtLeaveRsp struct {
+	Status Status
+}
+
+// MgmtNWKUpdateNotify contains the result of an energy scan (one energy value
+// per scanned channel). It is also sent unsolicited by devices detecting
+// interference.
+type MgmtNWKUpdateNotify struct {
+	Status               Status
+	ScannedChannels      uint32
+	TotalTransmissions   uint16
+	TransmissionFailures uint16
+	EnergyValues         []uint8
+}
@@ -0,0 +1,131 @@
+package zdp
+
+import (
+	"fmt"
+
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+type LogicalType uint8
+
+const (
+	LogicalTypeCoordinator LogicalType = 0
+	LogicalTypeRouter      LogicalType = 1
+	LogicalTypeEndDevice   LogicalType = 2
+)
+
+func (t LogicalType) String() string {
+	switch t {
+	case LogicalTypeCoordinator:
+		return "Coordinator"
+	case LogicalTypeRouter:
+		return "Router"
+	case LogicalTypeEndDevice:
+		return "EndDevice"
+	default:
+		return fmt.Sprintf("LogicalType(%d)", uint8(t))
+	}
+}
+
+// Bits of the frequency band field of the node descriptor.
+const (
+	FrequencyBand868MHz       uint8 = 1 << 0
+	FrequencyBand902MHz       uint8 = 1 << 2
+	FrequencyBand2400MHz      uint8 = 1 << 3
+	FrequencyBandEuropeSubGHz uint8 = 1 << 4
+)
+
+// MACCapabilities are the capability flags of a device, as found in the node
+// descriptor and in the DeviceAnnce command.
+type MACCapabilities uint8
+
+const (
+	MACCapabilityAlternatePANCoordinator MACCapabilities = 1 << 0
+	MACCapabilityFullFunctionDevice      MACCapabilities = 1 << 1
+	MACCapabilityMainsPowered            MACCapabilities = 1 << 2
+	MACCapabilityReceiverOnWhenIdle      MACCapabilities = 1 << 3
+	MACCapabilitySecurity                MACCapabilities = 1 << 6
+	MACCapabilityAllocateAddress         MACCapabilities = 1 << 7
+)
+
+// ServerMask indicates the system server capabilities of a device.
+type ServerMask uint16
+
+const (
+	ServerMaskPrimaryTrustCenter       ServerMask = 1 << 0
+	ServerMaskBackupTrustCenter        ServerMask = 1 << 1
+	ServerMaskPrimaryBindingTableCache ServerMask = 1 << 2
+	ServerMaskBackupBindingTableCache  ServerMask = 1 << 3
+	ServerMaskPrimaryDiscoveryCache    ServerMask = 1 << 4
+	ServerMaskBackupDiscoveryCache     ServerMask = 1 << 5
+	ServerMaskNetworkManager           ServerMask = 1 << 6
+)
+
+// StackComplianceRevision returns the revision of the ZigBee specification
+// implemented by the device (0 for devices implementing revisions before 21).
+func (m ServerMask) StackComplianceRevision() uint8 {
+	return uint8(m >> 9)
+}
+
+// Bits of the descriptor capability field of the node descriptor.
+const (
+	DescriptorCapabilityExtendedActiveEndpointList   uint8 = 1 << 0
+	DescriptorCapabilityExtendedSimpleDescriptorList uint8 = 1 << 1
+)
+
+type NodeDescriptor struct {
+	LogicalType                LogicalType `scf:"bits=3"`
+	ComplexDescriptorAvailable bool        `scf:"bits=1"`
+	UserDescriptorAvailable    bool        `scf:"bits=1"`
+	_                          uint8       `scf:"bits=3"`
+	APSFlags                   uint8       `scf:"bits=3"`
+	FrequencyBand              uint8       `scf:"bits=5"`
+	MACCapabilities            MACCapabilities
+	ManufacturerCode           uint16
+	MaxBufferSize              uint8
+	MaxIncomingTransferSize    uint16
+	ServerMask                 ServerMask
+	MaxOutgoingTransferSize    uint16
+	DescriptorCapabilities     uint8
+}
+
+type PowerMode uint8
+
+const (
+	PowerModeReceiverOnWhenIdle PowerMode = 0 // synchronized with the receiver on when idle setting of the node descriptor
+	PowerModePeriodic           PowerMode = 1 // receiver comes on periodically
+	PowerModeStimulated         PowerMode = 2 // receiver comes on when stimulated, e.g. by a button press
+)
+
+// Bits of the available and current power source fields of the power descriptor.
+const (
+	PowerSourceMains               uint8 = 1 << 0
+	PowerSourceRechargeableBattery uint8 = 1 << 1
+	PowerSourceDisposableBattery   uint8 = 1 << 2
+)
+
+type PowerLevel uint8
+
+const (
+	PowerLevelCritical PowerLevel = 0x0
+	PowerLevel33       PowerLevel = 0x4
+	PowerLevel66       PowerLevel = 0x8
+	PowerLevel100      PowerLevel = 0xc
+)
+
+type PowerDescriptor struct {
+	CurrentPowerMode        PowerMode  `scf:"bits=4"`
+	AvailablePowerSources   uint8      `scf:"bits=4"`
+	CurrentPowerSource      uint8      `scf:"bits=4"`
+	CurrentPowerSourceLevel PowerLevel `scf:"bits=4"`
+}
+
+type SimpleDescriptor struct {
+	Endpoint      uint8
+	ProfileID     zigbee.ProfileID
+	DeviceID      uint16
+	DeviceVersion uint8 `scf:"bits=4"`
+	_             uint8 `scf:"bits=4"`
+	InClusters    []uint16
+	OutClusters   []uint16
+}
@@ -0,0 +1,583 @@
+package zdp
+
+import (
+	"encoding/binary"
+
+	"github.com/GreenLightning/zigbee-conductor/pkg/scf"
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+// Most commands are parsed and serialized using package scf. The responses in
+// this file omit fields if the status is not success or contain lists of
+// structures whose count is not stored directly in front of them, so they are
+// encoded manually. The structures themselves are still declared with scf
+// tags where possible.
+
+// reader reads little endian values from a byte slice. After the first read
+// past the end of the data, all reads return zero and err is set.
+type reader struct {
+	data []byte
+	err  error
+}
+
+func (r *reader) next(n int) []byte {
+	if r.err != nil || len(r.data) < n {
+		r.err = ErrInvalidData
+		return make([]byte, n)
+	}
+	result := r.data[:n]
+	r.data = r.data[n:]
+	return result
+}
+
+func (r *reader) empty() bool {
+	return r.err == nil && len(r.data) == 0
+}
+
+func (r *reader) uint8() uint8 {
+	return r.next(1)[0]
+}
+
+func (r *reader) uint16() uint16 {
+	return binary.LittleEndian.Uint16(r.next(2))
+}
+
+func (r *reader) uint32() uint32 {
+	return binary.LittleEndian.Uint32(r.next(4))
+}
+
+func (r *reader) macAddress() zigbee.MACAddress {
+	return zigbee.MACAddress(binary.LittleEndian.Uint64(r.next(8)))
+}
+
+func (r *reader) macAddresses(count int) []zigbee.MACAddress {
+	addresses := make([]zigbee.MACAddress, 0, count)
+	for i := 0; i < count && r.err == nil; i++ {
+		addresses = append(addresses, r.macAddress())
+	}
+	return addresses
+}
+
+func (r *reader) uint16s(count int) []uint16 {
+	values := make([]uint16, 0, count)
+	for i := 0; i < count && r.err == nil; i++ {
+		values = append(values, r.uint16())
+	}
+	return values
+}
+
+// scf reads a value declared with scf tags. value must be a pointer.
+func (r *reader) scf(value interface{}) {
+	if r.err != nil {
+		return
+	}
+	data, err := scf.Parse(value, r.data)
+	if err != nil {
+		r.err = ErrInvalidData
+		return
+	}
+	r.data = data
+}
+
+func appendUint16(data []byte, value uint16) []byte {
+	return append(data, byte(value), byte(value>>8))
+}
+
+func appendUint32(data []byte, value uint32) []byte {
+	return append(data, byte(value), byte(value>>8), byte(value>>16), byte(value>>24))
+}
+
+func appendMACAddress(data []byte, address zigbee.MACAddress) []byte {
+	start := len(data)
+	data = append(data, 0, 0, 0, 0, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint64(data[start:], uint64(address))
+	return data
+}
+
+// binding is the binary representation of BindReq, UnbindReq and
+// BindingTableEntry. The destination is either a group address or an IEEE
+// address and an endpoint.
+type binding struct {
+	SrcAddress  zigbee.MACAddress
+	SrcEndpoint uint8
+	ClusterID   uint16
+	DstAddrMode zigbee.AddressMode
+	DstGroup    uint16            `scf:"if=DstAddrMode==1"`
+	DstAddress  zigbee.MACAddress `scf:"if=DstAddrMode==3"`
+	DstEndpoint uint8             `scf:"if=DstAddrMode==3"`
+}
+
+func (r *reader) bindingTableEntry() (entry BindingTableEntry) {
+	var b binding
+	r.scf(&b)
+	if r.err != nil {
+		return
+	}
+	entry = BindingTableEntry{
+		SrcAddress:  b.SrcAddress,
+		SrcEndpoint: b.SrcEndpoint,
+		ClusterID:   b.ClusterID,
+		DstAddress:  zigbee.Address{Mode: b.DstAddrMode},
+		DstEndpoint: b.DstEndpoint,
+	}
+	switch b.DstAddrMode {
+	case zigbee.AddressModeGroup:
+		entry.DstAddress.Short = b.DstGroup
+	case zigbee.AddressModeIEEE:
+		entry.DstAddress.Extended = b.DstAddress
+	default:
+		r.err = ErrInvalidData
+	}
+	return
+}
+
+func appendBindingTableEntry(data []byte, entry BindingTableEntry) ([]byte, error) {
+	b := binding{
+		SrcAddress:  entry.SrcAddress,
+		SrcEndpoint: entry.SrcEndpoint,
+		ClusterID:   entry.ClusterID,
+		DstAddrMode: entry.DstAddress.Mode,
+	}
+	switch entry.DstAddress.Mode {
+	case zigbee.AddressModeGroup:
+		b.DstGroup = entry.DstAddress.Short
+	case zigbee.AddressModeIEEE:
+		b.DstAddress = entry.DstAddress.Extended
+		b.DstEndpoint = entry.DstEndpoint
+	default:
+		return nil, ErrInvalidData
+	}
+	return append(data, scf.Serialize(b)...), nil
+}
+
+func (c *ParentAnnce) parse(data []byte) error {
+	r := reader{data: data}
+	c.ChildInfo = r.macAddresses(int(r.uint8()))
+	return r.err
+}
+
+func (c *ParentAnnce) serialize() ([]byte, error) {
+	if len(c.ChildInfo) > 0xff {
+		return nil, ErrInvalidData
+	}
+	data := []byte{byte(len(c.ChildInfo))}
+	for _, address := range c.ChildInfo {
+		data = appendMACAddress(data, address)
+	}
+	return data, nil
+}
+
+func (c *ParentAnnceRsp) parse(data []byte) error {
+	r := reader{data: data}
+	c.Status = Status(r.uint8())
+	if r.empty() {
+		return nil
+	}
+	c.ChildInfo = r.macAddresses(int(r.uint8()))
+	return r.err
+}
+
+func (c *ParentAnnceRsp) serialize() ([]byte, error) {
+	data, err := (&ParentAnnce{ChildInfo: c.ChildInfo}).serialize()
+	return append([]byte{byte(c.Status)}, data...), err
+}
+
+func (c *BindReq) parse(data []byte) error {
+	r := reader{data: data}
+	*c = BindReq(r.bindingTableEntry())
+	return r.err
+}
+
+func (c *BindReq) serialize() ([]byte, error) {
+	return appendBindingTableEntry(nil, BindingTableEntry(*c))
+}
+
+// The IEEE and NWK address are omitted by some devices if the status is not
+// success. The list of associated devices is only present if the request type
+// was extended. In this case NWKAddrAssocDevs is non-nil (but may be empty),
+// otherwise it is nil.
+
+func (c *NWKAddrRsp) parse(data []byte) error {
+	r := reader{data: data}
+	c.Status = Status(r.uint8())
+	if r.empty() {
+		return nil
+	}
+	c.IEEEAddrRemoteDev = r.macAddress()
+	c.NWKAddrRemoteDev = r.uint16()
+	if r.empty() {
+		return r.err
+	}
+	count := int(r.uint8())
+	// The start index is omitted if there are no associated devices.
+	if count != 0 {
+		c.StartIndex = r.uint8()
+	}
+	c.NWKAddrAssocDevs = r.uint16s(count)
+	return r.err
+}
+
+func (c *NWKAddrRsp) serialize() ([]byte, error) {
+	data := []byte{byte(c.Status)}
+	data = appendMACAddress(data, c.IEEEAddrRemoteDev)
+	data = appendUint16(data, c.NWKAddrRemoteDev)
+	if c.NWKAddrAssocDevs != nil {
+		if len(c.NWKAddrAssocDevs) > 0xff {
+			return nil, ErrInvalidData
+		}
+		data = append(data, byte(len(c.NWKAddrAssocDevs)))
+		if len(c.NWKAddrAssocDevs) != 0 {
+			data = append(data, c.StartIndex)
+		}
+		for _, address := range c.NWKAddrAssocDevs {
+			data = appendUint16(data, address)
+		}
+	}
+	return data, nil
+}
+
+func (c *BindRegisterRsp) parse(data []byte) error {
+	r := reader{data: data}
+	c.Status = Status(r.uint8())
+	if r.empty() {
+		return nil
+	}
+	c.BindingTableEntries = r.uint16()
+	c.BindingTableCount = r.uint16()
+	c.BindingTable = make([]BindingTableEntry, 0, c.BindingTableCount)
+	for i := 0; i < int(c.BindingTableCount) && r.err == nil; i++ {
+		c.BindingTable = append(c.BindingTable, r.bindingTableEntry())
+	}
+	return r.err
+}
+
+func (c *BindRegisterRsp) serialize() ([]byte, error) {
+	data := []byte{byte(c.Status)}
+	data = appendUint16(data, c.BindingTableEntries)
+	data = appendUint16(data, c.BindingTableCount)
+	var err error
+	for _, entry := range c.BindingTable {
+		if data, err = appendBindingTableEntry(data, entry); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func (c *NodeDescRsp) parse(data []byte) error {
+	r := reader{data: data}
+	c.Status = Status(r.uint8())
+	if r.empty() {
+		return nil
+	}
+	c.NWKAddrOfInterest = r.uint16()
+	if c.Status == StatusSuccess {
+		r.scf(&c.NodeDescriptor)
+	}
+	return r.err
+}
+
+func (c *NodeDescRsp) serialize() ([]byte, error) {
+	data := []byte{byte(c.Status)}
+	data = appendUint16(data, c.NWKAddrOfInterest)
+	if c.Status == StatusSuccess {
+		data = append(data, scf.Serialize(c.NodeDescriptor)...)
+	}
+	return data, nil
+}
+
+func (c *PowerDescRsp) parse(data []byte) error {
+	r := reader{data: data}
+	c.Status = Status(r.uint8())
+	if r.empty() {
+		return nil
+	}
+	c.NWKAddrOfInterest = r.uint16()
+	if c.Status == StatusSuccess {
+		r.scf(&c.PowerDescriptor)
+	}
+	return r.err
+}
+
+func (c *PowerDescRsp) serialize() ([]byte, error) {
+	data := []byte{byte(c.Status)}
+	data = appendUint16(data, c.NWKAddrOfInterest)
+	if c.Status == StatusSuccess {
+		data = append(data, scf.Serialize(c.PowerDescriptor)...)
+	}
+	return data, nil
+}
+
+// The simple, complex and user descriptors are prefixed with their length,
+// which is zero if the status is not success.
+
+func (c *SimpleDescRsp) parse(data []byte) error {
+	r := reader{data: data}
+	c.Status = Status(r.uint8())
+	if r.empty() {
+		return nil
+	}
+	c.NWKAddrOfInterest = r.uint16()
+	length := int(r.uint8())
+	if length != 0 {
+		descriptor := reader{data: r.next(length)}
+		descriptor.scf(&c.SimpleDescriptor)
+		if descriptor.err != nil {
+			return descriptor.err
+		}
+	}
+	return r.err
+}
+
+func (c *SimpleDescRsp) serialize() ([]byte, error) {
+	data := []byte{byte(c.Status)}
+	data = appendUint16(data, c.NWKAddrOfInterest)
+	if c.Status != StatusSuccess {
+		return append(data, 0), nil
+	}
+	if len(c.SimpleDescriptor.InClusters) > 0xff || len(c.SimpleDescriptor.OutClusters) > 0xff {
+		return nil, ErrInvalidData
+	}
+	descriptor := scf.Serialize(c.SimpleDescriptor)
+	if len(descriptor) > 0xff {
+		return nil, ErrInvalidData
+	}
+	data = append(data, byte(len(descriptor)))
+	return append(data, descriptor...), nil
+}
+
+func (c *ComplexDescRsp) parse(data []byte) error {
+	r := reader{data: data}
+	c.Status = Status(r.uint8())
+	if r.empty() {
+		return nil
+	}
+	c.NWKAddrOfInterest = r.uint16()
+	c.ComplexDescriptor = append([]uint8(nil), r.next(int(r.uint8()))...)
+	return r.err
+}
+
+func (c *ComplexDescRsp) serialize() ([]byte, error) {
+	if len(c.ComplexDescriptor) > 0xff {
+		return nil, ErrInvalidData
+	}
+	data := []byte{byte(c.Status)}
+	data = appendUint16(data, c.NWKAddrOfInterest)
+	data = append(data, byte(len(c.ComplexDescriptor)))
+	return append(data, c.ComplexDescriptor...), nil
+}
+
+func (c *UserDescRsp) parse(data []byte) error {
+	r := reader{data: data}
+	c.Status = Status(r.uint8())
+	if r.empty() {
+		return nil
+	}
+	c.NWKAddrOfInterest = r.uint16()
+	c.UserDescriptor = string(r.next(int(r.uint8())))
+	return r.err
+}
+
+func (c *UserDescRsp) serialize() ([]byte, error) {
+	if len(c.UserDescriptor) > 0xff {
+		return nil, ErrInvalidData
+	}
+	data := []byte{byte(c.Status)}
+	data = appendUint16(data, c.NWKAddrOfInterest)
+	data = append(data, byte(len(c.UserDescriptor)))
+	return append(data, c.UserDescriptor...), nil
+}
+
+func (c *MgmtBindRsp) parse(data []byte) error {
+	r := reader{data: data}
+	c.Status = Status(r.uint8())
+	if r.empty() {
+		return nil
+	}
+	c.BindingTableEntries = r.uint8()
+	c.StartIndex = r.uint8()
+	count := int(r.uint8())
+	c.BindingTableList = make([]BindingTableEntry, 0, count)
+	for i := 0; i < count && r.err == nil; i++ {
+		c.BindingTableList = append(c.BindingTableList, r.bindingTableEntry())
+	}
+	return r.err
+}
+
+func (c *MgmtBindRsp) serialize() ([]byte, error) {
+	if c.Status != StatusSuccess {
+		return []byte{byte(c.Status)}, nil
+	}
+	if len(c.BindingTableList) > 0xff {
+		return nil, ErrInvalidData
+	}
+	data := []byte{byte(c.Status), c.BindingTableEntries, c.StartIndex, byte(len(c.BindingTableList))}
+	var err error
+	for _, entry := range c.BindingTableList {
+		if data, err = appendBindingTableEntry(data, entry); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func (c *MgmtLeaveReq) parse(data []byte) error {
+	r := reader{data: data}
+	c.DeviceAddress = r.macAddress()
+	flags := r.uint8()
+	c.RemoveChildren = flags&0x40 != 0
+	c.Rejoin = flags&0x80 != 0
+	return r.err
+}
+
+func (c *MgmtLeaveReq) serialize() ([]byte, error) {
+	var flags uint8
+	if c.RemoveChildren {
+		flags |= 0x40
+	}
+	if c.Rejoin {
+		flags |= 0x80
+	}
+	return append(appendMACAddress(nil, c.DeviceAddress), flags), nil
+}
+
+// validScanDuration reports whether duration is an energy scan duration or
+// one of the special values ScanDurationChannelChange and
+// ScanDurationChangeManager.
+func validScanDuration(duration uint8) bool {
+	return duration <= 0x05 || duration >= ScanDurationChannelChange
+}
+
+func (c *MgmtNWKUpdateReq) parse(data []byte) error {
+	if _, err := scf.Parse(c, data); err != nil || !validScanDuration(c.ScanDuration) {
+		return ErrInvalidData
+	}
+	return nil
+}
+
+func (c *MgmtNWKUpdateReq) serialize() ([]byte, error) {
+	if !validScanDuration(c.ScanDuration) {
+		return nil, ErrInvalidData
+	}
+	return scf.Serialize(*c), nil
+}
+
+// The management responses only contain the status if it is not success.
+
+func (c *MgmtNWKDiscRsp) parse(data []byte) error {
+	r := reader{data: data}
+	c.Status = Status(r.uint8())
+	if r.empty() {
+		return nil
+	}
+	c.NetworkCount = r.uint8()
+	c.StartIndex = r.uint8()
+	count := int(r.uint8())
+	c.NetworkList = make([]NetworkDescriptor, 0, count)
+	for i := 0; i < count && r.err == nil; i++ {
+		c.NetworkList = append(c.NetworkList, r.networkDescriptor())
+	}
+	return r.err
+}
+
+func (c *MgmtNWKDiscRsp) serialize() ([]byte, error) {
+	if c.Status != StatusSuccess {
+		return []byte{byte(c.Status)}, nil
+	}
+	if len(c.NetworkList) > 0xff {
+		return nil, ErrInvalidData
+	}
+	data := []byte{byte(c.Status), c.NetworkCount, c.StartIndex, byte(len(c.NetworkList))}
+	for _, network := range c.NetworkList {
+		data = appendNetworkDescriptor(data, network)
+	}
+	return data, nil
+}
+
+func (c *MgmtLqiRsp) parse(data []byte) error {
+	r := reader{data: data}
+	c.Status = Status(r.uint8())
+	if r.empty() {
+		return nil
+	}
+	c.NeighborTableEntries = r.uint8()
+	c.StartIndex = r.uint8()
+	count := int(r.uint8())
+	c.NeighborTableList = make([]NeighborTableEntry, 0, count)
+	for i := 0; i < count && r.err == nil; i++ {
+		c.NeighborTableList = append(c.NeighborTableList, r.neighborTableEntry())
+	}
+	return r.err
+}
+
+func (c *MgmtLqiRsp) serialize() ([]byte, error) {
+	if c.Status != StatusSuccess {
+		return []byte{byte(c.Status)}, nil
+	}
+	if len(c.NeighborTableList) > 0xff {
+		return nil, ErrInvalidData
+	}
+	data := []byte{byte(c.Status), c.NeighborTableEntries, c.StartIndex, byte(len(c.NeighborTableList))}
+	for _, entry := range c.NeighborTableList {
+		data = appendNeighborTableEntry(data, entry)
+	}
+	return data, nil
+}
+
+func (c *MgmtRtgRsp) parse(data []byte) error {
+	r := reader{data: data}
+	c.Status = Status(r.uint8())
+	if r.empty() {
+		return nil
+	}
+	c.RoutingTableEntries = r.uint8()
+	c.StartIndex = r.uint8()
+	count := int(r.uint8())
+	c.RoutingTableList = make([]RoutingTableEntry, 0, count)
+	for i := 0; i < count && r.err == nil; i++ {
+		c.RoutingTableList = append(c.RoutingTableList, r.routingTableEntry())
+	}
+	return r.err
+}
+
+func (c *MgmtRtgRsp) serialize() ([]byte, error) {
+	if c.Status != StatusSuccess {
+		return []byte{byte(c.Status)}, nil
+	}
+	if len(c.RoutingTableList) > 0xff {
+		return nil, ErrInvalidData
+	}
+	data := []byte{byte(c.Status), c.RoutingTableEntries, c.StartIndex, byte(len(c.RoutingTableList))}
+	for _, entry := range c.RoutingTableList {
+		data = appendRoutingTableEntry(data, entry)
+	}
+	return data, nil
+}
+
+func (c *MgmtNWKUpdateNotify) parse(data []byte) error {
+	r := reader{data: data}
+	c.Status = Status(r.uint8())
+	if r.empty() {
+		return nil
+	}
+	c.ScannedChannels = r.uint32()
+	c.TotalTransmissions = r.uint16()
+	c.TransmissionFailures = r.uint16()
+	c.EnergyValues = append([]uint8{}, r.next(int(r.uint8()))...)
+	return r.err
+}
+
+func (c *MgmtNWKUpdateNotify) serialize() ([]byte, error) {
+	if c.Status != StatusSuccess {
+		return []byte{byte(c.Status)}, nil
+	}
+	if len(c.EnergyValues) > 0xff {
+		return nil, ErrInvalidData
+	}
+	data := []byte{byte(c.Status)}
+	data = appendUint32(data, c.ScannedChannels)
+	data = appendUint16(data, c.TotalTransmissions)
+	data = appendUint16(data, c.TransmissionFailures)
+	data = append(data, byte(len(c.EnergyValues)))
+	return append(data, c.EnergyValues...), nil
+}
@@ -22,34 +22,111 @@ func ParseFrame(clusterID uint16, data []byte) (transactionSequenceNumber uint8,
 
 	switch clusterID {
 	case ClusterNWKAddrReq:
-		command = new(NWKAddrReq)
-		_, err = scf.Parse(command, data)
-
-	// case ClusterIEEEAddrReq:
-	// case ClusterActiveEPReq:
-	// case ClusterMatchDescReq:
-
+		command, err = parseSCF(new(NWKAddrReq), data)
+	case ClusterIEEEAddrReq:
+		command, err = parseSCF(new(IEEEAddrReq), data)
+	case ClusterNodeDescReq:
+		command, err = parseSCF(new(NodeDescReq), data)
+	case ClusterPowerDescReq:
+		command, err = parseSCF(new(PowerDescReq), data)
+	case ClusterSimpleDescReq:
+		command, err = parseSCF(new(SimpleDescReq), data)
+	case ClusterActiveEPReq:
+		command, err = parseSCF(new(ActiveEPReq), data)
+	case ClusterMatchDescReq:
+		command, err = parseSCF(new(MatchDescReq), data)
+	case ClusterComplexDescReq:
+		command, err = parseSCF(new(ComplexDescReq), data)
+	case ClusterUserDescReq:
+		command, err = parseSCF(new(UserDescReq), data)
 	case ClusterDeviceAnnce:
-		command = new(DeviceAnnce)
-		_, err = scf.Parse(command, data)
+		command, err = parseSCF(new(DeviceAnnce), data)
+	case ClusterParentAnnce:
+		cmd := new(ParentAnnce)
+		command, err = cmd, cmd.parse(data)
+	case ClusterEndDeviceBindReq:
+		command, err = parseSCF(new(EndDeviceBindReq), data)
+	case ClusterBindReq:
+		cmd := new(BindReq)
+		command, err = cmd, cmd.parse(data)
+	case ClusterUnbindReq:
+		cmd := new(UnbindReq)
+		command, err = cmd, (*BindReq)(cmd).parse(data)
+	case ClusterBindRegisterReq:
+		command, err = parseSCF(new(BindRegisterReq), data)
+	case ClusterReplaceDeviceReq:
+		command, err = parseSCF(new(ReplaceDeviceReq), data)
+	case ClusterMgmtNWKDiscReq:
+		command, err = parseSCF(new(MgmtNWKDiscReq), data)
+	case ClusterMgmtLqiReq:
+		command, err = parseSCF(new(MgmtLqiReq), data)
+	case ClusterMgmtRtgReq:
+		command, err = parseSCF(new(MgmtRtgReq), data)
+	case ClusterMgmtBindReq:
+		command, err = parseSCF(new(MgmtBindReq), data)
+	case ClusterMgmtLeaveReq:
+		cmd := new(MgmtLeaveReq)
+		command, err = cmd, cmd.parse(data)
+	case ClusterMgmtNWKUpdateReq:
+		cmd := new(MgmtNWKUpdateReq)
+		command, err = cmd, cmd.parse(data)
 
-	// case ClusterParentAnnce:
-	// case ClusterEndDeviceBindReq:
-	// case ClusterBindReq:
-	// case ClusterUnbindReq:
-	// case ClusterBindRegisterReq:
-	// case ClusterReplaceDeviceReq:
-
-	// case ClusterNWKAddrRsp:
-	// case ClusterIEEEAddrRsp:
-	// case ClusterActiveEPRsp:
-	// case ClusterMatchDescRsp:
-	// case ClusterParentAnnceRsp:
-	// case ClusterEndDeviceBindRsp:
-	// case ClusterBindRsp:
-	// case ClusterUnbindRsp:
-	// case ClusterBindRegisterRsp:
-	// case ClusterReplaceDeviceRsp:
+	case ClusterNWKAddrRsp:
+		cmd := new(NWKAddrRsp)
+		command, err = cmd, cmd.parse(data)
+	case ClusterIEEEAddrRsp:
+		cmd := new(IEEEAddrRsp)
+		command, err = cmd, (*NWKAddrRsp)(cmd).parse(data)
+	case ClusterNodeDescRsp:
+		cmd := new(NodeDescRsp)
+		command, err = cmd, cmd.parse(data)
+	case ClusterPowerDescRsp:
+		cmd := new(PowerDescRsp)
+		command, err = cmd, cmd.parse(data)
+	case ClusterSimpleDescRsp:
+		cmd := new(SimpleDescRsp)
+		command, err = cmd, cmd.parse(data)
+	case ClusterActiveEPRsp:
+		command, err = parseSCF(new(ActiveEPRsp), data)
+	case ClusterMatchDescRsp:
+		command, err = parseSCF(new(MatchDescRsp), data)
+	case ClusterComplexDescRsp:
+		cmd := new(ComplexDescRsp)
+		command, err = cmd, cmd.parse(data)
+	case ClusterUserDescRsp:
+		cmd := new(UserDescRsp)
+		command, err = cmd, cmd.parse(data)
+	case ClusterParentAnnceRsp:
+		cmd := new(ParentAnnceRsp)
+		command, err = cmd, cmd.parse(data)
+	case ClusterEndDeviceBindRsp:
+		command, err = parseSCF(new(EndDeviceBindRsp), data)
+	case ClusterBindRsp:
+		command, err = parseSCF(new(BindRsp), data)
+	case ClusterUnbindRsp:
+		command, err = parseSCF(new(UnbindRsp), data)
+	case ClusterBindRegisterRsp:
+		cmd := new(BindRegisterRsp)
+		command, err = cmd, cmd.parse(data)
+	case ClusterReplaceDeviceRsp:
+		command, err = parseSCF(new(ReplaceDeviceRsp), data)
+	case ClusterMgmtNWKDiscRsp:
+		cmd := new(MgmtNWKDiscRsp)
+		command, err = cmd, cmd.parse(data)
+	case ClusterMgmtLqiRsp:
+		cmd := new(MgmtLqiRsp)
+		command, err = cmd, cmd.parse(data)
+	case ClusterMgmtRtgRsp:
+		cmd := new(MgmtRtgRsp)
+		command, err = cmd, cmd.parse(data)
+	case ClusterMgmtBindRsp:
+		cmd := new(MgmtBindRsp)
+		command, err = cmd, cmd.parse(data)
+	case ClusterMgmtLeaveRsp:
+		command, err = parseSCF(new(MgmtLeaveRsp), data)
+	case ClusterMgmtNWKUpdateNotify:
+		cmd := new(MgmtNWKUpdateNotify)
+		command, err = cmd, cmd.parse(data)
 
 	default:
 		err = ErrNotImplemented
@@ -57,36 +134,130 @@ func ParseFrame(clusterID uint16, data []byte) (transactionSequenceNumber uint8,
 	return
 }
 
+func parseSCF(command interface{}, data []byte) (interface{}, error) {
+	if _, err := scf.Parse(command, data); err != nil {
+		return command, ErrInvalidData
+	}
+	return command, nil
+}
+
 func SerializeFrame(transactionSequenceNumber uint8, command interface{}) (clusterID uint16, data []byte, err error) {
-	data = append(data, transactionSequenceNumber)
-	switch  command := command.(type) {
+	var payload []byte
+	switch command := command.(type) {
 	case *NWKAddrReq:
-		clusterID = ClusterNWKAddrReq
-		data = append(data, scf.Serialize(*command)...)
+		clusterID, payload = ClusterNWKAddrReq, scf.Serialize(*command)
+	case *IEEEAddrReq:
+		clusterID, payload = ClusterIEEEAddrReq, scf.Serialize(*command)
+	case *NodeDescReq:
+		clusterID, payload = ClusterNodeDescReq, scf.Serialize(*command)
+	case *PowerDescReq:
+		clusterID, payload = ClusterPowerDescReq, scf.Serialize(*command)
+	case *SimpleDescReq:
+		clusterID, payload = ClusterSimpleDescReq, scf.Serialize(*command)
+	case *ActiveEPReq:
+		clusterID, payload = ClusterActiveEPReq, scf.Serialize(*command)
+	case *MatchDescReq:
+		clusterID, payload = ClusterMatchDescReq, scf.Serialize(*command)
+	case *ComplexDescReq:
+		clusterID, payload = ClusterComplexDescReq, scf.Serialize(*command)
+	case *UserDescReq:
+		clusterID, payload = ClusterUserDescReq, scf.Serialize(*command)
+	case *DeviceAnnce:
+		clusterID, payload = ClusterDeviceAnnce, scf.Serialize(*command)
+	case *ParentAnnce:
+		clusterID = ClusterParentAnnce
+		payload, err = command.serialize()
+	case *EndDeviceBindReq:
+		clusterID, payload = ClusterEndDeviceBindReq, scf.Serialize(*command)
+	case *BindReq:
+		clusterID = ClusterBindReq
+		payload, err = command.serialize()
+	case *UnbindReq:
+		clusterID = ClusterUnbindReq
+		payload, err = (*BindReq)(command).serialize()
+	case *BindRegisterReq:
+		clusterID, payload = ClusterBindRegisterReq, scf.Serialize(*command)
+	case *ReplaceDeviceReq:
+		clusterID, payload = ClusterReplaceDeviceReq, scf.Serialize(*command)
+	case *MgmtNWKDiscReq:
+		clusterID, payload = ClusterMgmtNWKDiscReq, scf.Serialize(*command)
+	case *MgmtLqiReq:
+		clusterID, payload = ClusterMgmtLqiReq, scf.Serialize(*command)
+	case *MgmtRtgReq:
+		clusterID, payload = ClusterMgmtRtgReq, scf.Serialize(*command)
+	case *MgmtBindReq:
+		clusterID, payload = ClusterMgmtBindReq, scf.Serialize(*command)
+	case *MgmtLeaveReq:
+		clusterID = ClusterMgmtLeaveReq
+		payload, err = command.serialize()
+	case *MgmtNWKUpdateReq:
+		clusterID = ClusterMgmtNWKUpdateReq
+		payload, err = command.serialize()
 
-	// case *IEEEAddrReq:
-	// case *ActiveEPReq:
-	// case *MatchDescReq:
-	// case *DeviceAnnce:
-	// case *ParentAnnce:
-	// case *EndDeviceBindReq:
-	// case *BindReq:
-	// case *UnbindReq:
-	// case *BindRegisterReq:
-	// case *ReplaceDeviceReq:
-	// case *NWKAddrRsp:
-	// case *IEEEAddrRsp:
-	// case *ActiveEPRsp:
-	// case *MatchDescRsp:
-	// case *ParentAnnceRsp:
-	// case *EndDeviceBindRsp:
-	// case *BindRsp:
-	// case *UnbindRsp:
-	// case *BindRegisterRsp:
-	// case *ReplaceDeviceRsp:
+	case *NWKAddrRsp:
+		clusterID = ClusterNWKAddrRsp
+		payload, err = command.serialize()
+	case *IEEEAddrRsp:
+		clusterID = ClusterIEEEAddrRsp
+		payload, err = (*NWKAddrRsp)(command).serialize()
+	case *NodeDescRsp:
+		clusterID = ClusterNodeDescRsp
+		payload, err = command.serialize()
+	case *PowerDescRsp:
+		clusterID = ClusterPowerDescRsp
+		payload, err = command.serialize()
+	case *SimpleDescRsp:
+		clusterID = ClusterSimpleDescRsp
+		payload, err = command.serialize()
+	case *ActiveEPRsp:
+		clusterID, payload = ClusterActiveEPRsp, scf.Serialize(*command)
+	case *MatchDescRsp:
+		clusterID, payload = ClusterMatchDescRsp, scf.Serialize(*command)
+	case *ComplexDescRsp:
+		clusterID = ClusterComplexDescRsp
+		payload, err = command.serialize()
+	case *UserDescRsp:
+		clusterID = ClusterUserDescRsp
+		payload, err = command.serialize()
+	case *ParentAnnceRsp:
+		clusterID = ClusterParentAnnceRsp
+		payload, err = command.serialize()
+	case *EndDeviceBindRsp:
+		clusterID, payload = ClusterEndDeviceBindRsp, scf.Serialize(*command)
+	case *BindRsp:
+		clusterID, payload = ClusterBindRsp, scf.Serialize(*command)
+	case *UnbindRsp:
+		clusterID, payload = ClusterUnbindRsp, scf.Serialize(*command)
+	case *BindRegisterRsp:
+		clusterID = ClusterBindRegisterRsp
+		payload, err = command.serialize()
+	case *ReplaceDeviceRsp:
+		clusterID, payload = ClusterReplaceDeviceRsp, scf.Serialize(*command)
+	case *MgmtNWKDiscRsp:
+		clusterID = ClusterMgmtNWKDiscRsp
+		payload, err = command.serialize()
+	case *MgmtLqiRsp:
+		clusterID = ClusterMgmtLqiRsp
+		payload, err = command.serialize()
+	case *MgmtRtgRsp:
+		clusterID = ClusterMgmtRtgRsp
+		payload, err = command.serialize()
+	case *MgmtBindRsp:
+		clusterID = ClusterMgmtBindRsp
+		payload, err = command.serialize()
+	case *MgmtLeaveRsp:
+		clusterID, payload = ClusterMgmtLeaveRsp, scf.Serialize(*command)
+	case *MgmtNWKUpdateNotify:
+		clusterID = ClusterMgmtNWKUpdateNotify
+		payload, err = command.serialize()
 
 	default:
 		err = ErrNotImplemented
 	}
+
+	if err != nil {
+		return 0, nil, err
+	}
+	data = append([]byte{transactionSequenceNumber}, payload...)
 	return
 }
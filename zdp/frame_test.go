@@ -5,6 +5,8 @@ import (
 	"encoding/hex"
 	"reflect"
 	"testing"
+
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
 )
 
 // Note: For testing the transaction sequence number is always 0xAB.
@@ -17,6 +19,89 @@ type TestCase struct {
 
 var tests = []TestCase{
 	TestCase{ClusterID: 0x0000, Data: "AB77665544330200AA0001", Command: &NWKAddrReq{IEEEAddress: 0xAA00023344556677, RequestType: 0, StartIndex: 1}},
+	TestCase{ClusterID: 0x0001, Data: "AB34120100", Command: &IEEEAddrReq{NWKAddrOfInterest: 0x1234, RequestType: 1, StartIndex: 0}},
+	TestCase{ClusterID: 0x0002, Data: "AB3412", Command: &NodeDescReq{NWKAddrOfInterest: 0x1234}},
+	TestCase{ClusterID: 0x0003, Data: "AB3412", Command: &PowerDescReq{NWKAddrOfInterest: 0x1234}},
+	TestCase{ClusterID: 0x0004, Data: "AB341201", Command: &SimpleDescReq{NWKAddrOfInterest: 0x1234, Endpoint: 1}},
+	TestCase{ClusterID: 0x0005, Data: "AB3412", Command: &ActiveEPReq{NWKAddrOfInterest: 0x1234}},
+	TestCase{ClusterID: 0x0010, Data: "AB3412", Command: &ComplexDescReq{NWKAddrOfInterest: 0x1234}},
+	TestCase{ClusterID: 0x0011, Data: "AB3412", Command: &UserDescReq{NWKAddrOfInterest: 0x1234}},
+	TestCase{ClusterID: 0x0006, Data: "ABFDFF04010106000203000800", Command: &MatchDescReq{NWKAddrOfInterest: 0xFFFD, ProfileID: 0x0104, InClusters: []uint16{0x0006}, OutClusters: []uint16{0x0003, 0x0008}}},
+	TestCase{ClusterID: 0x0013, Data: "AB341277665544330200AA8E", Command: &DeviceAnnce{NWKAddr: 0x1234, IEEEAddr: 0xAA00023344556677, Capability: 0x8E}},
+	TestCase{ClusterID: 0x001F, Data: "AB02" + "77665544330200AA" + "8877665544332211", Command: &ParentAnnce{ChildInfo: []zigbee.MACAddress{0xAA00023344556677, 0x1122334455667788}}},
+	TestCase{ClusterID: 0x0020, Data: "AB000077665544330200AA010401" + "01060000", Command: &EndDeviceBindReq{BindingTarget: 0x0000, SrcIEEEAddress: 0xAA00023344556677, SrcEndpoint: 1, ProfileID: 0x0104, InClusters: []uint16{0x0006}, OutClusters: []uint16{}}},
+	TestCase{ClusterID: 0x0021, Data: "AB77665544330200AA01060003" + "8877665544332211" + "0B", Command: &BindReq{SrcAddress: 0xAA00023344556677, SrcEndpoint: 1, ClusterID: 0x0006, DstAddress: zigbee.Address{Mode: zigbee.AddressModeIEEE, Extended: 0x1122334455667788}, DstEndpoint: 11}},
+	TestCase{ClusterID: 0x0022, Data: "AB77665544330200AA01080001" + "3412", Command: &UnbindReq{SrcAddress: 0xAA00023344556677, SrcEndpoint: 1, ClusterID: 0x0008, DstAddress: zigbee.Address{Mode: zigbee.AddressModeGroup, Short: 0x1234}}},
+	TestCase{ClusterID: 0x0023, Data: "AB77665544330200AA", Command: &BindRegisterReq{NodeAddress: 0xAA00023344556677}},
+	TestCase{ClusterID: 0x0024, Data: "AB77665544330200AA01" + "887766554433221102", Command: &ReplaceDeviceReq{OldAddress: 0xAA00023344556677, OldEndpoint: 1, NewAddress: 0x1122334455667788, NewEndpoint: 2}},
+
+	TestCase{ClusterID: 0x8000, Data: "AB0077665544330200AA3412", Command: &NWKAddrRsp{Status: StatusSuccess, IEEEAddrRemoteDev: 0xAA00023344556677, NWKAddrRemoteDev: 0x1234}},
+	TestCase{ClusterID: 0x8000, Data: "AB0077665544330200AA341200", Command: &NWKAddrRsp{Status: StatusSuccess, IEEEAddrRemoteDev: 0xAA00023344556677, NWKAddrRemoteDev: 0x1234, NWKAddrAssocDevs: []uint16{}}},
+	TestCase{ClusterID: 0x8001, Data: "AB0077665544330200AA34120205" + "01000200", Command: &IEEEAddrRsp{Status: StatusSuccess, IEEEAddrRemoteDev: 0xAA00023344556677, NWKAddrRemoteDev: 0x1234, StartIndex: 5, NWKAddrAssocDevs: []uint16{0x0001, 0x0002}}},
+	TestCase{ClusterID: 0x8002, Data: "AB003412" + "02408037107F6400002C640000", Command: &NodeDescRsp{Status: StatusSuccess, NWKAddrOfInterest: 0x1234, NodeDescriptor: NodeDescriptor{
+		LogicalType:             LogicalTypeEndDevice,
+		FrequencyBand:           FrequencyBand2400MHz,
+		MACCapabilities:         MACCapabilityAllocateAddress,
+		ManufacturerCode:        0x1037,
+		MaxBufferSize:           0x7F,
+		MaxIncomingTransferSize: 100,
+		ServerMask:              0x2C00,
+		MaxOutgoingTransferSize: 100,
+	}}},
+	TestCase{ClusterID: 0x8002, Data: "AB893412", Command: &NodeDescRsp{Status: StatusNoDescriptor, NWKAddrOfInterest: 0x1234}},
+	TestCase{ClusterID: 0x8003, Data: "AB003412" + "10C1", Command: &PowerDescRsp{Status: StatusSuccess, NWKAddrOfInterest: 0x1234, PowerDescriptor: PowerDescriptor{
+		CurrentPowerMode:        PowerModeReceiverOnWhenIdle,
+		AvailablePowerSources:   PowerSourceMains,
+		CurrentPowerSource:      PowerSourceMains,
+		CurrentPowerSourceLevel: PowerLevel100,
+	}}},
+	TestCase{ClusterID: 0x8004, Data: "AB003412" + "0E" + "0104010001" + "01020000060001" + "1900", Command: &SimpleDescRsp{Status: StatusSuccess, NWKAddrOfInterest: 0x1234, SimpleDescriptor: SimpleDescriptor{
+		Endpoint:      1,
+		ProfileID:     zigbee.ProfileHomeAutomation,
+		DeviceID:      0x0100,
+		DeviceVersion: 1,
+		InClusters:    []uint16{0x0000, 0x0006},
+		OutClusters:   []uint16{0x0019},
+	}}},
+	TestCase{ClusterID: 0x8004, Data: "AB82341200", Command: &SimpleDescRsp{Status: StatusInvalidEP, NWKAddrOfInterest: 0x1234}},
+	TestCase{ClusterID: 0x8005, Data: "AB003412020102", Command: &ActiveEPRsp{Status: StatusSuccess, NWKAddrOfInterest: 0x1234, ActiveEPs: []uint8{1, 2}}},
+	TestCase{ClusterID: 0x8010, Data: "AB00341202" + "0102", Command: &ComplexDescRsp{Status: StatusSuccess, NWKAddrOfInterest: 0x1234, ComplexDescriptor: []uint8{0x01, 0x02}}},
+	TestCase{ClusterID: 0x8011, Data: "AB00341207" + "4B69746368656E", Command: &UserDescRsp{Status: StatusSuccess, NWKAddrOfInterest: 0x1234, UserDescriptor: "Kitchen"}},
+	TestCase{ClusterID: 0x8006, Data: "AB0034120101", Command: &MatchDescRsp{Status: StatusSuccess, NWKAddrOfInterest: 0x1234, Matches: []uint8{1}}},
+	TestCase{ClusterID: 0x801F, Data: "AB0001" + "77665544330200AA", Command: &ParentAnnceRsp{Status: StatusSuccess, ChildInfo: []zigbee.MACAddress{0xAA00023344556677}}},
+	TestCase{ClusterID: 0x8020, Data: "AB00", Command: &EndDeviceBindRsp{Status: StatusSuccess}},
+	TestCase{ClusterID: 0x8021, Data: "AB8C", Command: &BindRsp{Status: StatusTableFull}},
+	TestCase{ClusterID: 0x8022, Data: "AB88", Command: &UnbindRsp{Status: StatusNoEntry}},
+	TestCase{ClusterID: 0x8023, Data: "AB0002000100" + "77665544330200AA01060001" + "3412", Command: &BindRegisterRsp{Status: StatusSuccess, BindingTableEntries: 2, BindingTableCount: 1, BindingTable: []BindingTableEntry{{SrcAddress: 0xAA00023344556677, SrcEndpoint: 1, ClusterID: 0x0006, DstAddress: zigbee.Address{Mode: zigbee.AddressModeGroup, Short: 0x1234}}}}},
+	TestCase{ClusterID: 0x8024, Data: "AB00", Command: &ReplaceDeviceRsp{Status: StatusSuccess}},
+
+	TestCase{ClusterID: 0x0033, Data: "AB02", Command: &MgmtBindReq{StartIndex: 2}},
+	TestCase{ClusterID: 0x8033, Data: "AB00030202" + "77665544330200AA01060001" + "3412" + "77665544330200AA02080003" + "8877665544332211" + "01", Command: &MgmtBindRsp{Status: StatusSuccess, BindingTableEntries: 3, StartIndex: 2, BindingTableList: []BindingTableEntry{
+		{SrcAddress: 0xAA00023344556677, SrcEndpoint: 1, ClusterID: 0x0006, DstAddress: zigbee.Address{Mode: zigbee.AddressModeGroup, Short: 0x1234}},
+		{SrcAddress: 0xAA00023344556677, SrcEndpoint: 2, ClusterID: 0x0008, DstAddress: zigbee.Address{Mode: zigbee.AddressModeIEEE, Extended: 0x1122334455667788}, DstEndpoint: 1},
+	}}},
+	TestCase{ClusterID: 0x8033, Data: "AB84", Command: &MgmtBindRsp{Status: StatusNotSupported}},
+
+	TestCase{ClusterID: 0x0030, Data: "AB00F8FF070300", Command: &MgmtNWKDiscReq{ScanChannels: AllChannels, ScanDuration: 3, StartIndex: 0}},
+	TestCase{ClusterID: 0x8030, Data: "AB00010001" + "DDDDDDDDDDDDDDDD0B22FF01", Command: &MgmtNWKDiscRsp{Status: StatusSuccess, NetworkCount: 1, StartIndex: 0, NetworkList: []NetworkDescriptor{
+		{ExtendedPANID: 0xDDDDDDDDDDDDDDDD, LogicalChannel: 11, StackProfile: 2, ZigBeeVersion: 2, BeaconOrder: 15, SuperframeOrder: 15, PermitJoining: true},
+	}}},
+	TestCase{ClusterID: 0x0031, Data: "AB00", Command: &MgmtLqiReq{StartIndex: 0}},
+	TestCase{ClusterID: 0x8031, Data: "AB00020001" + "DDDDDDDDDDDDDDDD" + "77665544330200AA" + "3412" + "150201FF", Command: &MgmtLqiRsp{Status: StatusSuccess, NeighborTableEntries: 2, StartIndex: 0, NeighborTableList: []NeighborTableEntry{
+		{ExtendedPANID: 0xDDDDDDDDDDDDDDDD, ExtendedAddr: 0xAA00023344556677, NetworkAddr: 0x1234, DeviceType: LogicalTypeRouter, RxOnWhenIdle: NeighborFlagOn, Relationship: RelationshipChild, PermitJoining: NeighborFlagUnknown, Depth: 1, LQI: 255},
+	}}},
+	TestCase{ClusterID: 0x0032, Data: "AB01", Command: &MgmtRtgReq{StartIndex: 1}},
+	TestCase{ClusterID: 0x8032, Data: "AB00010001" + "3412100000", Command: &MgmtRtgRsp{Status: StatusSuccess, RoutingTableEntries: 1, StartIndex: 0, RoutingTableList: []RoutingTableEntry{
+		{DestinationAddr: 0x1234, Status: RouteStatusActive, ManyToOne: true, NextHopAddr: 0x0000},
+	}}},
+	TestCase{ClusterID: 0x8032, Data: "AB84", Command: &MgmtRtgRsp{Status: StatusNotSupported}},
+	TestCase{ClusterID: 0x0034, Data: "AB" + "0000000000000000" + "80", Command: &MgmtLeaveReq{Rejoin: true}},
+	TestCase{ClusterID: 0x0034, Data: "AB" + "77665544330200AA" + "40", Command: &MgmtLeaveReq{DeviceAddress: 0xAA00023344556677, RemoveChildren: true}},
+	TestCase{ClusterID: 0x8034, Data: "AB00", Command: &MgmtLeaveRsp{Status: StatusSuccess}},
+	TestCase{ClusterID: 0x0038, Data: "AB000800000201", Command: &MgmtNWKUpdateReq{ScanChannels: ChannelMask(11), ScanDuration: 2, ScanCount: 1}},
+	TestCase{ClusterID: 0x0038, Data: "AB00800000FE03", Command: ChannelChangeRequest(15, 3)},
+	TestCase{ClusterID: 0x0038, Data: "AB00F8FF07FF010000", Command: &MgmtNWKUpdateReq{ScanChannels: AllChannels, ScanDuration: ScanDurationChangeManager, NWKUpdateID: 1, NWKManagerAddr: 0x0000}},
+	TestCase{ClusterID: 0x8038, Data: "AB00" + "00180000" + "1000" + "0100" + "02A0B0", Command: &MgmtNWKUpdateNotify{Status: StatusSuccess, ScannedChannels: ChannelMask(11, 12), TotalTransmissions: 16, TransmissionFailures: 1, EnergyValues: []uint8{0xA0, 0xB0}}},
 }
 
 func TestParseFrame(t *testing.T) {
@@ -54,3 +139,35 @@ func TestSerializeFrame(t *testing.T) {
 		})
 	}
 }
+
+func TestParseFrameErrors(t *testing.T) {
+	type ErrorCase struct {
+		ClusterID uint16
+		Data      string
+	}
+
+	errorCases := []ErrorCase{
+		ErrorCase{ClusterID: 0x0005, Data: "AB34"},
+		ErrorCase{ClusterID: 0x001F, Data: "AB02" + "77665544330200AA"},
+		ErrorCase{ClusterID: 0x0021, Data: "AB77665544330200AA01060002" + "3412"},
+		ErrorCase{ClusterID: 0x8000, Data: "AB0077665544330200AA34120205" + "0100"},
+	}
+
+	for _, tc := range errorCases {
+		data, _ := hex.DecodeString(tc.Data)
+		if _, _, err := ParseFrame(tc.ClusterID, data); err != ErrInvalidData {
+			t.Errorf("%s %s: expected ErrInvalidData, got %v", ClusterName(tc.ClusterID), tc.Data, err)
+		}
+	}
+}
+
+func TestParseErrorResponse(t *testing.T) {
+	// Some devices only send the status if the request failed.
+	_, cmd, err := ParseFrame(ClusterNWKAddrRsp, []byte{0xAB, byte(StatusDeviceNotFound)})
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if expected := (&NWKAddrRsp{Status: StatusDeviceNotFound}); !reflect.DeepEqual(cmd, expected) {
+		t.Errorf("wrong command: expected %+v, actual %+v", expected, cmd)
+	}
+}
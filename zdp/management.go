@@ -0,0 +1,190 @@
+package zdp
+
+import (
+	"fmt"
+
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+// Values of the ScanDuration field of MgmtNWKUpdateReq. Values from 0x00 to
+// 0x05 request an energy scan.
+const (
+	ScanDurationChannelChange uint8 = 0xfe
+	ScanDurationChangeManager uint8 = 0xff
+)
+
+// ChannelMask returns the channel mask containing the given channels (11 to 26
+// for 2.4 GHz).
+func ChannelMask(channels ...uint8) uint32 {
+	var mask uint32
+	for _, channel := range channels {
+		mask |= 1 << channel
+	}
+	return mask
+}
+
+// AllChannels is the channel mask of all 2.4 GHz channels.
+const AllChannels uint32 = 0x07fff800
+
+type NetworkDescriptor struct {
+	ExtendedPANID   uint64
+	LogicalChannel  uint8
+	StackProfile    uint8 // 4 bits
+	ZigBeeVersion   uint8 // 4 bits
+	BeaconOrder     uint8 // 4 bits
+	SuperframeOrder uint8 // 4 bits
+	PermitJoining   bool
+}
+
+type Relationship uint8
+
+const (
+	RelationshipParent        Relationship = 0
+	RelationshipChild         Relationship = 1
+	RelationshipSibling       Relationship = 2
+	RelationshipNone          Relationship = 3
+	RelationshipPreviousChild Relationship = 4
+)
+
+func (r Relationship) String() string {
+	switch r {
+	case RelationshipParent:
+		return "Parent"
+	case RelationshipChild:
+		return "Child"
+	case RelationshipSibling:
+		return "Sibling"
+	case RelationshipNone:
+		return "None"
+	case RelationshipPreviousChild:
+		return "PreviousChild"
+	default:
+		return fmt.Sprintf("Relationship(%d)", uint8(r))
+	}
+}
+
+// Values of the RxOnWhenIdle and PermitJoining fields of NeighborTableEntry.
+const (
+	NeighborFlagOff     uint8 = 0
+	NeighborFlagOn      uint8 = 1
+	NeighborFlagUnknown uint8 = 2
+)
+
+type NeighborTableEntry struct {
+	ExtendedPANID uint64
+	ExtendedAddr  zigbee.MACAddress
+	NetworkAddr   uint16
+	DeviceType    LogicalType // 2 bits, 3 = unknown
+	RxOnWhenIdle  uint8       // 2 bits
+	Relationship  Relationship
+	PermitJoining uint8 // 2 bits
+	Depth         uint8
+	LQI           uint8
+}
+
+type RouteStatus uint8
+
+const (
+	RouteStatusActive             RouteStatus = 0
+	RouteStatusDiscoveryUnderway  RouteStatus = 1
+	RouteStatusDiscoveryFailed    RouteStatus = 2
+	RouteStatusInactive           RouteStatus = 3
+	RouteStatusValidationUnderway RouteStatus = 4
+)
+
+func (s RouteStatus) String() string {
+	switch s {
+	case RouteStatusActive:
+		return "Active"
+	case RouteStatusDiscoveryUnderway:
+		return "DiscoveryUnderway"
+	case RouteStatusDiscoveryFailed:
+		return "DiscoveryFailed"
+	case RouteStatusInactive:
+		return "Inactive"
+	case RouteStatusValidationUnderway:
+		return "ValidationUnderway"
+	default:
+		return fmt.Sprintf("RouteStatus(%d)", uint8(s))
+	}
+}
+
+type RoutingTableEntry struct {
+	DestinationAddr     uint16
+	Status              RouteStatus // 3 bits
+	MemoryConstrained   bool
+	ManyToOne           bool
+	RouteRecordRequired bool
+	NextHopAddr         uint16
+}
+
+func (r *reader) networkDescriptor() (d NetworkDescriptor) {
+	d.ExtendedPANID = uint64(r.macAddress())
+	d.LogicalChannel = r.uint8()
+	value := r.uint8()
+	d.StackProfile = value & 0x0f
+	d.ZigBeeVersion = value >> 4
+	value = r.uint8()
+	d.BeaconOrder = value & 0x0f
+	d.SuperframeOrder = value >> 4
+	d.PermitJoining = r.uint8()&0x01 != 0
+	return
+}
+
+func appendNetworkDescriptor(data []byte, d NetworkDescriptor) []byte {
+	data = appendMACAddress(data, zigbee.MACAddress(d.ExtendedPANID))
+	var permitJoining uint8
+	if d.PermitJoining {
+		permitJoining = 1
+	}
+	return append(data, d.LogicalChannel, d.StackProfile&0x0f|d.ZigBeeVersion<<4, d.BeaconOrder&0x0f|d.SuperframeOrder<<4, permitJoining)
+}
+
+func (r *reader) neighborTableEntry() (e NeighborTableEntry) {
+	e.ExtendedPANID = uint64(r.macAddress())
+	e.ExtendedAddr = r.macAddress()
+	e.NetworkAddr = r.uint16()
+	value := r.uint8()
+	e.DeviceType = LogicalType(value & 0x03)
+	e.RxOnWhenIdle = (value >> 2) & 0x03
+	e.Relationship = Relationship((value >> 4) & 0x07)
+	e.PermitJoining = r.uint8() & 0x03
+	e.Depth = r.uint8()
+	e.LQI = r.uint8()
+	return
+}
+
+func appendNeighborTableEntry(data []byte, e NeighborTableEntry) []byte {
+	data = appendMACAddress(data, zigbee.MACAddress(e.ExtendedPANID))
+	data = appendMACAddress(data, e.ExtendedAddr)
+	data = appendUint16(data, e.NetworkAddr)
+	value := uint8(e.DeviceType)&0x03 | (e.RxOnWhenIdle&0x03)<<2 | (uint8(e.Relationship)&0x07)<<4
+	return append(data, value, e.PermitJoining&0x03, e.Depth, e.LQI)
+}
+
+func (r *reader) routingTableEntry() (e RoutingTableEntry) {
+	e.DestinationAddr = r.uint16()
+	value := r.uint8()
+	e.Status = RouteStatus(value & 0x07)
+	e.MemoryConstrained = value&0x08 != 0
+	e.ManyToOne = value&0x10 != 0
+	e.RouteRecordRequired = value&0x20 != 0
+	e.NextHopAddr = r.uint16()
+	return
+}
+
+func appendRoutingTableEntry(data []byte, e RoutingTableEntry) []byte {
+	data = appendUint16(data, e.DestinationAddr)
+	value := uint8(e.Status) & 0x07
+	if e.MemoryConstrained {
+		value |= 0x08
+	}
+	if e.ManyToOne {
+		value |= 0x10
+	}
+	if e.RouteRecordRequired {
+		value |= 0x20
+	}
+	data = append(data, value)
+	return appendUint16(data, e.NextHopAddr)
+}
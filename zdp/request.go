@@ -0,0 +1,227 @@
+package zdp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrUnexpectedResponse = errors.New("unexpected response")
+
+// ErrNotSupported is returned by requesters which cannot send a request.
+var ErrNotSupported = errors.New("request not supported by controller")
+
+// ErrTableTooLarge is returned when reading a table whose remaining entries
+// cannot be requested, because the start index of the requests is limited to
+// 0xff.
+var ErrTableTooLarge = errors.New("table too large")
+
+// A Requester sends a ZDP request to the device with the given network address
+// and returns the response (see dispatch.Dispatcher.RequestZDP).
+type Requester interface {
+	RequestZDP(ctx context.Context, nwkAddr uint16, command interface{}) (interface{}, error)
+}
+
+// StatusError is returned by the helper functions of this package if the
+// remote device responds with a status other than success.
+type StatusError struct {
+	ClusterID uint16 // of the response
+	Status    Status
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: %v", ClusterName(e.ClusterID), e.Status)
+}
+
+func checkStatus(clusterID uint16, status Status) error {
+	if status != StatusSuccess {
+		return &StatusError{ClusterID: clusterID, Status: status}
+	}
+	return nil
+}
+
+// Bind creates a binding on the source device of the request, which must be
+// the device with the given network address. The destination can be a group
+// (e.g. to bind a remote directly to a group of lights) or a specific endpoint
+// of a device identified by its IEEE address.
+func Bind(ctx context.Context, requester Requester, nwkAddr uint16, request BindReq) error {
+	response, err := requester.RequestZDP(ctx, nwkAddr, &request)
+	if err != nil {
+		return err
+	}
+	rsp, ok := response.(*BindRsp)
+	if !ok {
+		return ErrUnexpectedResponse
+	}
+	return checkStatus(ClusterBindRsp, rsp.Status)
+}
+
+// Unbind removes a binding from the device with the given network address.
+func Unbind(ctx context.Context, requester Requester, nwkAddr uint16, request UnbindReq) error {
+	response, err := requester.RequestZDP(ctx, nwkAddr, &request)
+	if err != nil {
+		return err
+	}
+	rsp, ok := response.(*UnbindRsp)
+	if !ok {
+		return ErrUnexpectedResponse
+	}
+	return checkStatus(ClusterUnbindRsp, rsp.Status)
+}
+
+// readTable reads a table in multiple parts. The read function must request
+// the part starting at the given index and return the total number of entries
+// in the table and the number of entries in the response. The start index is
+// at most 0xff.
+func readTable(read func(start int) (total int, count int, err error)) error {
+	start := 0
+	for {
+		total, count, err := read(start)
+		if err != nil {
+			return err
+		}
+		start += count
+		if count == 0 || start >= total {
+			return nil
+		}
+		if start > 0xff {
+			return ErrTableTooLarge
+		}
+	}
+}
+
+// BindingTable reads the complete binding table of the device with the given
+// network address using as many Mgmt_Bind_req requests as necessary.
+func BindingTable(ctx context.Context, requester Requester, nwkAddr uint16) ([]BindingTableEntry, error) {
+	var entries []BindingTableEntry
+	err := readTable(func(start int) (int, int, error) {
+		response, err := requester.RequestZDP(ctx, nwkAddr, &MgmtBindReq{StartIndex: uint8(start)})
+		if err != nil {
+			return 0, 0, err
+		}
+		rsp, ok := response.(*MgmtBindRsp)
+		if !ok || (rsp.Status == StatusSuccess && int(rsp.StartIndex) != start) {
+			return 0, 0, ErrUnexpectedResponse
+		}
+		if err := checkStatus(ClusterMgmtBindRsp, rsp.Status); err != nil {
+			return 0, 0, err
+		}
+		entries = append(entries, rsp.BindingTableList...)
+		return int(rsp.BindingTableEntries), len(rsp.BindingTableList), nil
+	})
+	return entries, err
+}
+
+// NeighborTable reads the complete neighbor table of the device with the
+// given network address using as many Mgmt_Lqi_req requests as necessary.
+func NeighborTable(ctx context.Context, requester Requester, nwkAddr uint16) ([]NeighborTableEntry, error) {
+	var entries []NeighborTableEntry
+	err := readTable(func(start int) (int, int, error) {
+		response, err := requester.RequestZDP(ctx, nwkAddr, &MgmtLqiReq{StartIndex: uint8(start)})
+		if err != nil {
+			return 0, 0, err
+		}
+		rsp, ok := response.(*MgmtLqiRsp)
+		if !ok || (rsp.Status == StatusSuccess && int(rsp.StartIndex) != start) {
+			return 0, 0, ErrUnexpectedResponse
+		}
+		if err := checkStatus(ClusterMgmtLqiRsp, rsp.Status); err != nil {
+			return 0, 0, err
+		}
+		entries = append(entries, rsp.NeighborTableList...)
+		return int(rsp.NeighborTableEntries), len(rsp.NeighborTableList), nil
+	})
+	return entries, err
+}
+
+// RoutingTable reads the complete routing table of the device with the given
+// network address using as many Mgmt_Rtg_req requests as necessary.
+func RoutingTable(ctx context.Context, requester Requester, nwkAddr uint16) ([]RoutingTableEntry, error) {
+	var entries []RoutingTableEntry
+	err := readTable(func(start int) (int, int, error) {
+		response, err := requester.RequestZDP(ctx, nwkAddr, &MgmtRtgReq{StartIndex: uint8(start)})
+		if err != nil {
+			return 0, 0, err
+		}
+		rsp, ok := response.(*MgmtRtgRsp)
+		if !ok || (rsp.Status == StatusSuccess && int(rsp.StartIndex) != start) {
+			return 0, 0, ErrUnexpectedResponse
+		}
+		if err := checkStatus(ClusterMgmtRtgRsp, rsp.Status); err != nil {
+			return 0, 0, err
+		}
+		entries = append(entries, rsp.RoutingTableList...)
+		return int(rsp.RoutingTableEntries), len(rsp.RoutingTableList), nil
+	})
+	return entries, err
+}
+
+// DiscoverNetworks asks the device with the given network address to scan
+// the given channels for other networks and returns all networks found. The
+// scan duration is specified as the exponent used by the MAC layer (the scan
+// takes about 15.36 ms * (2^duration + 1) per channel), so the context should
+// allow for enough time.
+func DiscoverNetworks(ctx context.Context, requester Requester, nwkAddr uint16, channels uint32, duration uint8) ([]NetworkDescriptor, error) {
+	var networks []NetworkDescriptor
+	err := readTable(func(start int) (int, int, error) {
+		response, err := requester.RequestZDP(ctx, nwkAddr, &MgmtNWKDiscReq{ScanChannels: channels, ScanDuration: duration, StartIndex: uint8(start)})
+		if err != nil {
+			return 0, 0, err
+		}
+		rsp, ok := response.(*MgmtNWKDiscRsp)
+		if !ok || (rsp.Status == StatusSuccess && int(rsp.StartIndex) != start) {
+			return 0, 0, ErrUnexpectedResponse
+		}
+		if err := checkStatus(ClusterMgmtNWKDiscRsp, rsp.Status); err != nil {
+			return 0, 0, err
+		}
+		networks = append(networks, rsp.NetworkList...)
+		return int(rsp.NetworkCount), len(rsp.NetworkList), nil
+	})
+	return networks, err
+}
+
+// Leave asks the device with the given network address to leave the network
+// (or to remove one of its children, see MgmtLeaveReq).
+func Leave(ctx context.Context, requester Requester, nwkAddr uint16, request MgmtLeaveReq) error {
+	response, err := requester.RequestZDP(ctx, nwkAddr, &request)
+	if err != nil {
+		return err
+	}
+	rsp, ok := response.(*MgmtLeaveRsp)
+	if !ok {
+		return ErrUnexpectedResponse
+	}
+	return checkStatus(ClusterMgmtLeaveRsp, rsp.Status)
+}
+
+// EnergyScan asks the device with the given network address to measure the
+// energy on the given channels. The scan is repeated count times (at most 5);
+// duration has the same meaning as for DiscoverNetworks (at most 5).
+func EnergyScan(ctx context.Context, requester Requester, nwkAddr uint16, channels uint32, duration uint8, count uint8) (*MgmtNWKUpdateNotify, error) {
+	if duration > 0x05 {
+		return nil, ErrInvalidData
+	}
+	response, err := requester.RequestZDP(ctx, nwkAddr, &MgmtNWKUpdateReq{ScanChannels: channels, ScanDuration: duration, ScanCount: count})
+	if err != nil {
+		return nil, err
+	}
+	rsp, ok := response.(*MgmtNWKUpdateNotify)
+	if !ok {
+		return nil, ErrUnexpectedResponse
+	}
+	return rsp, checkStatus(ClusterMgmtNWKUpdateNotify, rsp.Status)
+}
+
+// ChannelChangeRequest returns the request which tells all devices to switch
+// to another channel. It must be broadcast to all routers and the coordinator
+// (0xfffc) or all devices with receivers on when idle (0xfffd) and is not
+// answered, therefore it must be sent without a Requester. The update ID must
+// be incremented for each change of the network configuration.
+func ChannelChangeRequest(channel uint8, nwkUpdateID uint8) *MgmtNWKUpdateReq {
+	return &MgmtNWKUpdateReq{
+		ScanChannels: ChannelMask(channel),
+		ScanDuration: ScanDurationChannelChange,
+		NWKUpdateID:  nwkUpdateID,
+	}
+}
@@ -0,0 +1,145 @@
+package zdp
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/GreenLightning/zigbee-conductor/zigbee"
+)
+
+// testRequester answers requests with the responses returned by respond.
+type testRequester struct {
+	requests []interface{}
+	respond  func(command interface{}) interface{}
+}
+
+func (r *testRequester) RequestZDP(ctx context.Context, nwkAddr uint16, command interface{}) (interface{}, error) {
+	r.requests = append(r.requests, command)
+	return r.respond(command), nil
+}
+
+func TestBindingTable(t *testing.T) {
+	var table []BindingTableEntry
+	for i := 0; i < 5; i++ {
+		table = append(table, BindingTableEntry{
+			SrcAddress:  0xAA00023344556677,
+			SrcEndpoint: 1,
+			ClusterID:   uint16(i),
+			DstAddress:  zigbee.Address{Mode: zigbee.AddressModeGroup, Short: uint16(i)},
+		})
+	}
+
+	// The device returns at most two entries per response.
+	requester := &testRequester{respond: func(command interface{}) interface{} {
+		start := int(command.(*MgmtBindReq).StartIndex)
+		end := start + 2
+		if end > len(table) {
+			end = len(table)
+		}
+		return &MgmtBindRsp{Status: StatusSuccess, BindingTableEntries: uint8(len(table)), StartIndex: uint8(start), BindingTableList: table[start:end]}
+	}}
+
+	entries, err := BindingTable(context.Background(), requester, 0x1234)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if !reflect.DeepEqual(entries, table) {
+		t.Errorf("wrong entries: %+v", entries)
+	}
+	if len(requester.requests) != 3 {
+		t.Errorf("wrong number of requests: %d", len(requester.requests))
+	}
+}
+
+func TestReadTableTooLarge(t *testing.T) {
+	var starts []int
+	err := readTable(func(start int) (int, int, error) {
+		starts = append(starts, start)
+		return 0x200, 0x80, nil
+	})
+	if err != ErrTableTooLarge {
+		t.Fatal("unexpected err:", err)
+	}
+	if !reflect.DeepEqual(starts, []int{0x00, 0x80}) {
+		t.Errorf("wrong start indices: %v", starts)
+	}
+}
+
+func TestBindingTableNotSupported(t *testing.T) {
+	requester := &testRequester{respond: func(command interface{}) interface{} {
+		return &MgmtBindRsp{Status: StatusNotSupported}
+	}}
+
+	_, err := BindingTable(context.Background(), requester, 0x1234)
+	statusErr, ok := err.(*StatusError)
+	if !ok || statusErr.Status != StatusNotSupported {
+		t.Errorf("unexpected err: %v", err)
+	}
+}
+
+func TestBind(t *testing.T) {
+	requester := &testRequester{respond: func(command interface{}) interface{} {
+		if command.(*BindReq).DstAddress.Mode != zigbee.AddressModeGroup {
+			return &BindRsp{Status: StatusNotSupported}
+		}
+		return &BindRsp{Status: StatusSuccess}
+	}}
+
+	request := BindReq{
+		SrcAddress:  0xAA00023344556677,
+		SrcEndpoint: 1,
+		ClusterID:   0x0006,
+		DstAddress:  zigbee.Address{Mode: zigbee.AddressModeGroup, Short: 0x0001},
+	}
+	if err := Bind(context.Background(), requester, 0x1234, request); err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+
+	request.DstAddress = zigbee.Address{Mode: zigbee.AddressModeIEEE, Extended: 0x1122334455667788}
+	if err := Bind(context.Background(), requester, 0x1234, request); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestNeighborTable(t *testing.T) {
+	// The device claims to have five entries, but only returns three.
+	requester := &testRequester{respond: func(command interface{}) interface{} {
+		start := command.(*MgmtLqiReq).StartIndex
+		rsp := &MgmtLqiRsp{Status: StatusSuccess, NeighborTableEntries: 5, StartIndex: start}
+		if start < 3 {
+			rsp.NeighborTableList = []NeighborTableEntry{{NetworkAddr: uint16(start)}}
+		}
+		return rsp
+	}}
+
+	entries, err := NeighborTable(context.Background(), requester, 0x0000)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if len(entries) != 3 || entries[2].NetworkAddr != 2 {
+		t.Errorf("wrong entries: %+v", entries)
+	}
+	if len(requester.requests) != 4 {
+		t.Errorf("wrong number of requests: %d", len(requester.requests))
+	}
+}
+
+func TestEnergyScan(t *testing.T) {
+	requester := &testRequester{respond: func(command interface{}) interface{} {
+		request := command.(*MgmtNWKUpdateReq)
+		return &MgmtNWKUpdateNotify{Status: StatusSuccess, ScannedChannels: request.ScanChannels, EnergyValues: []uint8{0x10, 0x20}}
+	}}
+
+	result, err := EnergyScan(context.Background(), requester, 0x0000, ChannelMask(11, 15), 2, 1)
+	if err != nil {
+		t.Fatal("unexpected err:", err)
+	}
+	if result.ScannedChannels != ChannelMask(11, 15) || len(result.EnergyValues) != 2 {
+		t.Errorf("wrong result: %+v", result)
+	}
+
+	if _, err := EnergyScan(context.Background(), requester, 0x0000, AllChannels, ScanDurationChannelChange, 1); err != ErrInvalidData {
+		t.Errorf("unexpected err: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package zigbee
+
+import (
+	"sync"
+	"time"
+)
+
+// AddressLookupInterval is the minimum time between two requests for the IEEE
+// address of the same device (see AddressMap.StartLookup).
+const AddressLookupInterval = time.Minute
+
+// AddressMap maps between the network and IEEE addresses of devices. It is
+// used by controllers to report the IEEE address of the source of incoming
+// messages and to resolve destinations given only by IEEE address.
+//
+// The network address of a device can change when it rejoins the network,
+// therefore a new mapping replaces all previous mappings of either address.
+//
+// The zero value is an empty map ready to use. An AddressMap is safe for
+// concurrent use.
+type AddressMap struct {
+	mutex  sync.Mutex
+	byNWK  map[uint16]MACAddress
+	byIEEE map[MACAddress]uint16
+
+	lookups map[uint16]time.Time
+}
+
+// Update records that the device with the given IEEE address uses the given
+// network address.
+func (m *AddressMap) Update(nwkAddr uint16, ieeeAddr MACAddress) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.byNWK == nil {
+		m.byNWK = make(map[uint16]MACAddress)
+		m.byIEEE = make(map[MACAddress]uint16)
+	}
+
+	if oldIEEE, ok := m.byNWK[nwkAddr]; ok {
+		delete(m.byIEEE, oldIEEE)
+	}
+	if oldNWK, ok := m.byIEEE[ieeeAddr]; ok {
+		delete(m.byNWK, oldNWK)
+	}
+	m.byNWK[nwkAddr] = ieeeAddr
+	m.byIEEE[ieeeAddr] = nwkAddr
+	delete(m.lookups, nwkAddr)
+}
+
+// IEEEAddress returns the IEEE address of the device using the given network address.
+func (m *AddressMap) IEEEAddress(nwkAddr uint16) (MACAddress, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	ieeeAddr, ok := m.byNWK[nwkAddr]
+	return ieeeAddr, ok
+}
+
+// NetworkAddress returns the network address of the device with the given IEEE address.
+func (m *AddressMap) NetworkAddress(ieeeAddr MACAddress) (uint16, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	nwkAddr, ok := m.byIEEE[ieeeAddr]
+	return nwkAddr, ok
+}
+
+// Complete returns the address with AddressModeCombined if the missing part
+// of a network or IEEE address is known. Other addresses are returned
+// unchanged.
+func (m *AddressMap) Complete(address Address) Address {
+	switch address.Mode {
+	case AddressModeNWK:
+		if ieeeAddr, ok := m.IEEEAddress(address.Short); ok {
+			return Address{Mode: AddressModeCombined, Short: address.Short, Extended: ieeeAddr}
+		}
+	case AddressModeIEEE:
+		if nwkAddr, ok := m.NetworkAddress(address.Extended); ok {
+			return Address{Mode: AddressModeCombined, Short: nwkAddr, Extended: address.Extended}
+		}
+	}
+	return address
+}
+
+// StartLookup returns true if the controller should request the IEEE address
+// of the device with the given network address. This is the case if the
+// address is unknown and has not been requested within the
+// AddressLookupInterval, so that a device sending many messages does not cause
+// a flood of requests.
+func (m *AddressMap) StartLookup(nwkAddr uint16) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, ok := m.byNWK[nwkAddr]; ok {
+		return false
+	}
+
+	now := time.Now()
+	if last, ok := m.lookups[nwkAddr]; ok && now.Sub(last) < AddressLookupInterval {
+		return false
+	}
+	if m.lookups == nil {
+		m.lookups = make(map[uint16]time.Time)
+	}
+	m.lookups[nwkAddr] = now
+	return true
+}
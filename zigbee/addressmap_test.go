@@ -0,0 +1,43 @@
+package zigbee
+
+import "testing"
+
+func TestAddressMap(t *testing.T) {
+	var m AddressMap
+
+	if address := m.Complete(Address{Mode: AddressModeNWK, Short: 0x1234}); address.Mode != AddressModeNWK {
+		t.Errorf("unknown address completed: %+v", address)
+	}
+	if !m.StartLookup(0x1234) {
+		t.Errorf("lookup not started")
+	}
+	if m.StartLookup(0x1234) {
+		t.Errorf("lookup repeated too early")
+	}
+
+	m.Update(0x1234, 0x00158d0001234567)
+	expected := Address{Mode: AddressModeCombined, Short: 0x1234, Extended: 0x00158d0001234567}
+	if address := m.Complete(Address{Mode: AddressModeNWK, Short: 0x1234}); address != expected {
+		t.Errorf("wrong address: %+v", address)
+	}
+	if address := m.Complete(Address{Mode: AddressModeIEEE, Extended: 0x00158d0001234567}); address != expected {
+		t.Errorf("wrong address: %+v", address)
+	}
+	if m.StartLookup(0x1234) {
+		t.Errorf("lookup started for known address")
+	}
+
+	// The device rejoins with a new network address, which was used by
+	// another device before.
+	m.Update(0x5678, 0x00124b0001abcdef)
+	m.Update(0x5678, 0x00158d0001234567)
+	if _, ok := m.IEEEAddress(0x1234); ok {
+		t.Errorf("old network address still mapped")
+	}
+	if _, ok := m.NetworkAddress(0x00124b0001abcdef); ok {
+		t.Errorf("replaced device still mapped")
+	}
+	if nwkAddr, ok := m.NetworkAddress(0x00158d0001234567); !ok || nwkAddr != 0x5678 {
+		t.Errorf("wrong network address: 0x%04x", nwkAddr)
+	}
+}
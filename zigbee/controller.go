@@ -1,11 +1,24 @@
 package zigbee
 
-import "io"
+import (
+	"errors"
+	"io"
+)
+
+// ErrAddressUnresolved is reported if the network address of a destination
+// given only by IEEE address could not be found in time.
+var ErrAddressUnresolved = errors.New("network address unresolved")
 
 type ControllerSettings struct {
 	Port        string
 	LogCommands bool
 	LogErrors   bool
+
+	// OnSendError is called if a message accepted by Send cannot be sent
+	// later, for example because its destination could not be resolved
+	// (ErrAddressUnresolved). It is called from a goroutine of the controller
+	// and should not block.
+	OnSendError func(message OutgoingMessage, err error)
 }
 
 // A controller allows interacting with the ZigBee network on the application level.
@@ -26,15 +39,31 @@ type Controller interface {
 	PermitJoining(enabled bool) error
 }
 
+// An IncomingMessage is a message received from a device. Controllers report
+// the source with AddressModeCombined if they know the IEEE address of the
+// device and with AddressModeNWK otherwise.
+//
+// Broadcast is true if the message was sent to a broadcast address or to a
+// group instead of to the controller alone. Such messages must not be answered
+// with a response that is only sent because of the request (e.g. a ZCL
+// Default Response).
 type IncomingMessage struct {
 	Source              Address
 	SourceEndpoint      uint8
 	DestinationEndpoint uint8
 	ClusterID           uint16
 	LinkQuality         uint8
+	Broadcast           bool
 	Data                []byte
 }
 
+// DefaultRadius is the maximum number of hops used for messages sent by the
+// higher-level packages of this module.
+const DefaultRadius = 30
+
+// An OutgoingMessage is a message sent to a device. The destination may be
+// given by network address, by IEEE address (which the controller resolves to
+// the current network address of the device) or by both.
 type OutgoingMessage struct {
 	Destination         Address
 	DestinationEndpoint uint8